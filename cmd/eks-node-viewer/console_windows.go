@@ -0,0 +1,51 @@
+//go:build windows
+
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// cpUTF8 is the UTF-8 code page
+const cpUTF8 = 65001
+
+// prepareConsole sets up the console for the UI, returning a function that restores it. The output is switched to
+// UTF-8 so the block characters of the progress bars aren't garbled by the OEM code page in ConPTY sessions that pass
+// the output through as bytes, e.g. over SSH. Escape sequences are enabled for output written before the program
+// starts, and newlines are no longer implied when a line fills the terminal, which otherwise scrolls the screen and
+// shifts every following line down when a bar is as wide as the terminal.
+func prepareConsole() func() {
+	out := windows.Handle(os.Stdout.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(out, &mode); err != nil {
+		// not a console, e.g. the output is redirected
+		return func() {}
+	}
+	cp, cpErr := windows.GetConsoleOutputCP()
+	if cpErr == nil {
+		_ = windows.SetConsoleOutputCP(cpUTF8)
+	}
+	_ = windows.SetConsoleMode(out, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING|windows.DISABLE_NEWLINE_AUTO_RETURN)
+	return func() {
+		_ = windows.SetConsoleMode(out, mode)
+		if cpErr == nil {
+			_ = windows.SetConsoleOutputCP(cp)
+		}
+	}
+}
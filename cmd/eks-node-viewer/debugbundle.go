@@ -0,0 +1,193 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awslabs/eks-node-viewer/pkg/client"
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// sensitiveSettings are the flags and config file keys whose values are left out of the debug bundle. The OTLP endpoint
+// may carry credentials in its URL, the contexts and kubeconfig path name the clusters, and node actions are commands
+// that may include hosts or tokens.
+var sensitiveSettings = []string{"gcp-api-key", "as", "as-group", "otlp-endpoint", "kubeconfig", "context", "node-action"}
+
+var (
+	// clusterARNRe matches the ARNs of EKS clusters, which EKS contexts are named after by default
+	clusterARNRe = regexp.MustCompile(`\barn:aws[a-z-]*:eks:[a-z0-9-]+:\d{12}:cluster/[A-Za-z0-9_-]+`)
+	// eksctlContextRe matches the context names written by eksctl, e.g. user@cluster.us-west-2.eksctl.io
+	eksctlContextRe = regexp.MustCompile(`[^\s@"'=]+@[A-Za-z0-9_-]+\.[a-z0-9-]+\.eksctl\.io\b`)
+	// accountIDRe matches AWS account IDs, e.g. in the ARNs of EKS contexts
+	accountIDRe = regexp.MustCompile(`\b\d{12}\b`)
+	// ipRe matches IPv4 addresses, including those in EC2 host names such as ip-10-0-1-2
+	ipRe = regexp.MustCompile(`\b\d{1,3}[.-]\d{1,3}[.-]\d{1,3}[.-]\d{1,3}\b`)
+)
+
+// debugBundle collects what's written to the debug bundle while the viewer runs
+type debugBundle struct {
+	path    string
+	started time.Time
+	mu      sync.Mutex
+	// synced is how long each cluster took to list its nodes and pods and price its nodes
+	synced map[string]time.Duration
+}
+
+func newDebugBundle(path string) *debugBundle {
+	return &debugBundle{path: path, started: time.Now(), synced: map[string]time.Duration{}}
+}
+
+// timeSync records how long each of the clusters takes to sync, the clusters are numbered rather than named
+func (b *debugBundle) timeSync(ctx context.Context, controllers []*client.Controller) {
+	for i, c := range controllers {
+		name := fmt.Sprintf("cluster-%d", i+1)
+		go func() {
+			if c.WaitForSync(ctx) {
+				b.mu.Lock()
+				b.synced[name] = time.Since(b.started)
+				b.mu.Unlock()
+			}
+		}()
+	}
+}
+
+// write writes the bundle to its path as a zip file
+func (b *debugBundle) write(flags Flags, m *model.UIModel) error {
+	f, err := os.Create(b.path)
+	if err != nil {
+		return fmt.Errorf("creating debug bundle, %w", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	files := []struct {
+		name  string
+		write func(w io.Writer) error
+	}{
+		{"version.txt", writeVersion},
+		{"flags.txt", func(w io.Writer) error { return writeSettings(w, flags.Settings) }},
+		{"config.txt", writeConfig},
+		{"snapshot.json", func(w io.Writer) error { return model.WriteDebugSnapshot(w, m.DebugSnapshot(time.Now())) }},
+		{"logs.txt", func(w io.Writer) error {
+			var buf bytes.Buffer
+			if _, err := recentLogs.WriteTo(&buf); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, sanitize(buf.String()))
+			return err
+		}},
+		{"timings.txt", b.writeTimings},
+	}
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return fmt.Errorf("adding %s to debug bundle, %w", file.name, err)
+		}
+		if err := file.write(w); err != nil {
+			return fmt.Errorf("writing %s to debug bundle, %w", file.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("writing debug bundle, %w", err)
+	}
+	return f.Close()
+}
+
+func writeVersion(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "version: %s\ncommit: %s\nbuilt at: %s\nbuilt by: %s\ngo: %s\nplatform: %s/%s\ncpus: %d\n",
+		version, commit, date, builtBy, runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+	return err
+}
+
+// writeSettings writes the settings sorted by name, leaving out the values of sensitive settings
+func writeSettings(w io.Writer, settings map[string]string) error {
+	var names []string
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := settings[name]
+		if value != "" && slices.Contains(sensitiveSettings, name) {
+			value = "<redacted>"
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", name, sanitize(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeConfig writes the settings that are read from the config file
+func writeConfig(w io.Writer) error {
+	cfg, err := loadConfigFile()
+	if err != nil {
+		_, err = fmt.Fprintf(w, "reading %s, %s\n", sanitize(configPath), err)
+		return err
+	}
+	if len(cfg) == 0 {
+		_, err = fmt.Fprintf(w, "no settings in %s\n", sanitize(configPath))
+		return err
+	}
+	return writeSettings(w, cfg)
+}
+
+func (b *debugBundle) writeTimings(w io.Writer) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, err := fmt.Fprintf(w, "uptime: %s\n", time.Since(b.started).Round(time.Millisecond)); err != nil {
+		return err
+	}
+	var names []string
+	for name := range b.synced {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "%s synced and priced after: %s\n", name, b.synced[name].Round(time.Millisecond)); err != nil {
+			return err
+		}
+	}
+	if len(names) == 0 {
+		_, err := fmt.Fprintln(w, "no clusters synced")
+		return err
+	}
+	return nil
+}
+
+// sanitize replaces the home directory, EKS cluster ARNs and context names, AWS account IDs and IP addresses, which
+// identify the user or their cluster
+func sanitize(s string) string {
+	if homeDir != "" {
+		s = strings.ReplaceAll(s, homeDir, "~")
+	}
+	s = clusterARNRe.ReplaceAllString(s, "<cluster-arn>")
+	s = eksctlContextRe.ReplaceAllString(s, "<context>")
+	s = accountIDRe.ReplaceAllString(s, "<account-id>")
+	return ipRe.ReplaceAllString(s, "<ip>")
+}
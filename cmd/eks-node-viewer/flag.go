@@ -22,8 +22,12 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
 )
 
 var (
@@ -41,16 +45,85 @@ func init() {
 }
 
 type Flags struct {
-	Context         string
-	NodeSelector    string
-	ExtraLabels     string
-	NodeSort        string
-	Style           string
-	Kubeconfig      string
-	Resources       string
-	DisablePricing  bool
-	ShowAttribution bool
-	Version         bool
+	Context           string
+	AllContexts       bool
+	NodeSelector      string
+	Zones             string
+	InstanceTypes     string
+	ExtraLabels       string
+	Columns           []string
+	NodeActions       []string
+	Layout            string
+	ColumnPriority    string
+	NodeSort          string
+	GroupBy           string
+	Style             string
+	ContextColors     string
+	StatusWords       string
+	ExportCSV         string
+	NodeListFile      string
+	NodeListField     string
+	NotesFile         string
+	Kubeconfig        string
+	Resources         string
+	ExcludeNamespaces string
+	UsageSource       string
+	PriceUnit         string
+	MaxNodeLifetime   string
+	MaxReserved       int
+	StalePeriods      int
+	RawQuantities     bool
+	NormalizeAlloc    bool
+	ScoreWeights      string
+	As                string
+	AsGroups          string
+	DisablePricing    bool
+	CommitmentPricing bool
+	PriceFile         string
+	StaticPrices      string
+	PricingBundle     string
+	PricingCacheTTL   time.Duration
+	EC2Endpoint       string
+	PricingEndpoint   string
+	CABundle          string
+	CloudProvider     string
+	GCPAPIKey         string
+	CheckCapacityType bool
+	CPUCredits        bool
+	NodeGroups        bool
+	LegacyMachines    bool
+	Tracing           bool
+	OTLPEndpoint      string
+	NoTTY             bool
+	Refresh           time.Duration
+	WaitFor           string
+	Check             bool
+	MaxCostPerHour    float64
+	MinCPUUtilization float64
+	MaxPendingPods    int
+	Timeout           time.Duration
+	StatusConfigMap   string
+	StatusInterval    time.Duration
+	UpdateInterval    time.Duration
+	FrameInterval     time.Duration
+	PageSize          int
+	Record            string
+	Replay            string
+	Serve             string
+	Emit              string
+	EmitInterval      time.Duration
+	EmitFile          string
+	LogFile           string
+	LogLevel          string
+	FixedLayout       bool
+	ShowAttribution   bool
+	Version           bool
+	// KubectlOverrides are set by kubectl's standard flags, e.g. --cluster and --token, when running as a kubectl plugin
+	KubectlOverrides clientcmd.ConfigOverrides
+	DebugBundle      string
+	// Settings are the values of every flag after parsing, including those read from the config file, for the debug
+	// bundle
+	Settings map[string]string
 }
 
 func ParseFlags() (Flags, error) {
@@ -66,38 +139,256 @@ func ParseFlags() (Flags, error) {
 	flagSet.BoolVar(&flags.Version, "version", false, "Display eks-node-viewer version")
 
 	contextDefault := cfg.getValue("context", "")
-	flagSet.StringVar(&flags.Context, "context", contextDefault, "Name of the kubernetes context to use")
+	flagSet.StringVar(&flags.Context, "context", contextDefault, "A comma separated set of kubernetes contexts to use, if empty the current context is used")
+
+	allContextsDefault := cfg.getBoolValue("all-contexts", false)
+	flagSet.BoolVar(&flags.AllContexts, "all-contexts", allContextsDefault, "View the clusters for all of the contexts in the kubeconfig")
 
 	nodeSelectorDefault := cfg.getValue("node-selector", "")
 	flagSet.StringVar(&flags.NodeSelector, "node-selector", nodeSelectorDefault, "Node label selector used to filter nodes, if empty all nodes are selected ")
 
+	zonesDefault := cfg.getValue("zones", "")
+	flagSet.StringVar(&flags.Zones, "zones", zonesDefault, "A comma separated list of availability zones, only nodes in these zones are watched")
+
+	instanceTypesDefault := cfg.getValue("instance-types", "")
+	flagSet.StringVar(&flags.InstanceTypes, "instance-types", instanceTypesDefault, "A comma separated list of instance types, only nodes of these instance types are watched")
+
 	extraLabelsDefault := cfg.getValue("extra-labels", "")
-	flagSet.StringVar(&flags.ExtraLabels, "extra-labels", extraLabelsDefault, "A comma separated set of extra node labels to display")
+	flagSet.StringVar(&flags.ExtraLabels, "extra-labels", extraLabelsDefault, "A comma separated set of extra node labels to display, or annotations prefixed with annotation:, e.g. annotation:karpenter.sh/do-not-disrupt")
+
+	layoutDefault := cfg.getValue("columns", strings.Join(model.DefaultLayout, ","))
+	flagSet.StringVar(&flags.Layout, "columns", layoutDefault, fmt.Sprintf("A comma separated list of the columns to display and their order, any of %s or label:<label>", strings.Join(model.NodeColumnNames(), ", ")))
+
+	columnPriorityDefault := cfg.getValue("column-priority", strings.Join(model.DefaultColumnPriority, ","))
+	flagSet.StringVar(&flags.ColumnPriority, "column-priority", columnPriorityDefault, "A comma separated list of columns from the most to the least important, when the terminal is too narrow the least important columns are hidden first, starting with the columns that aren't listed")
+
+	var nodeActions stringSliceFlag
+	flagSet.Var(&nodeActions, "node-action", fmt.Sprintf("An action of the form name=command that's run against the selected node with x, the command is a Go template of the node's .Name, .InstanceID, .ProviderID, .InstanceType, .Zone, .Region and .Context, e.g. shell=aws ssm start-session --target {{.InstanceID}}. Replaces the preset with the same name, one of %s. May be repeated.", strings.Join(nodeActionPresetNames(), ", ")))
+
+	var columns stringSliceFlag
+	flagSet.Var(&columns, "column", "A custom column of the form name={jsonpath} evaluated against the node, e.g. kubelet={.status.nodeInfo.kubeletVersion}. May be repeated.")
 
 	nodeSort := cfg.getValue("node-sort", "creation=dsc")
 	flagSet.StringVar(&flags.NodeSort, "node-sort", nodeSort, "Sort order for the nodes, either 'creation' or a label name. The sort order defaults to ascending and can be controlled by appending =asc or =dsc to the value.")
 
+	groupByDefault := cfg.getValue("group-by", "")
+	flagSet.StringVar(&flags.GroupBy, "group-by", groupByDefault, "Group the nodes by a label, e.g. karpenter.sh/nodepool, showing the node count, price and utilization of each group. Grouping can be toggled with 'g'.")
+
 	style := cfg.getValue("style", "#04B575,#FFFF00,#FF0000")
 	flagSet.StringVar(&flags.Style, "style", style, "Three color to use for styling 'good','ok' and 'bad' values. These are also used in the gradients displayed from bad -> good.")
 
+	contextColorsDefault := cfg.getValue("context-colors", "")
+	flagSet.StringVar(&flags.ContextColors, "context-colors", contextColorsDefault, "A comma separated list of pattern=color pairs that color the cluster statusline of contexts or clusters containing the pattern, e.g. prod=red,staging=yellow")
+
+	statusWordsDefault := cfg.getValue("status-words", "")
+	flagSet.StringVar(&flags.StatusWords, "status-words", statusWordsDefault, fmt.Sprintf("A comma separated list of word=text[:color] replacements for the words of the status and ready columns, e.g. Ready=OK:green,Cordoned=GESPERRT,NotReady=:red. The words are %s.", strings.Join(model.StatusWords, ", ")))
+
+	exportCSVDefault := cfg.getValue("export-csv", "")
+	flagSet.StringVar(&flags.ExportCSV, "export-csv", exportCSVDefault, "Path to write the displayed nodes to as CSV when pressing 'e', if empty a timestamped file in the current directory is used")
+
+	nodeListFileDefault := cfg.getValue("node-list-file", "")
+	flagSet.StringVar(&flags.NodeListFile, "node-list-file", nodeListFileDefault, "Path to write the filtered nodes to when pressing 'Y', if empty they're copied to the clipboard unless there are too many")
+
+	nodeListFieldDefault := cfg.getValue("node-list-field", model.NodeListName)
+	flagSet.StringVar(&flags.NodeListField, "node-list-field", nodeListFieldDefault, "The field of the filtered nodes listed when pressing 'Y', either 'name' or 'instance-id'")
+
+	notesFileDefault := cfg.getValue("notes-file", model.DefaultNotesPath())
+	flagSet.StringVar(&flags.NotesFile, "notes-file", notesFileDefault, "Path to the session file that notes attached to nodes with 'n' are saved to, if empty notes are only kept until the viewer exits")
+
 	// flag overrides env. var. and env. var. overrides config file
 	kubeconfigDefault := getStringEnv("KUBECONFIG", cfg.getValue("kubeconfig", filepath.Join(homeDir, ".kube", "config")))
 	flagSet.StringVar(&flags.Kubeconfig, "kubeconfig", kubeconfigDefault, "Absolute path to the kubeconfig file")
 
+	asDefault := cfg.getValue("as", "")
+	flagSet.StringVar(&flags.As, "as", asDefault, "Username to impersonate when talking to the API server")
+
+	asGroupsDefault := cfg.getValue("as-group", "")
+	flagSet.StringVar(&flags.AsGroups, "as-group", asGroupsDefault, "A comma separated set of groups to impersonate when talking to the API server")
+
 	resourcesDefault := cfg.getValue("resources", "cpu")
-	flagSet.StringVar(&flags.Resources, "resources", resourcesDefault, "List of comma separated resources to monitor")
+	flagSet.StringVar(&flags.Resources, "resources", resourcesDefault, "List of comma separated resources to monitor, including extended resources such as nvidia.com/mig-1g.5gb and gpu-memory for the memory of MIG slices")
+
+	excludeNamespacesDefault := cfg.getValue("exclude-namespaces", "")
+	flagSet.StringVar(&flags.ExcludeNamespaces, "exclude-namespaces", excludeNamespacesDefault, "A comma separated list of namespaces whose pods don't count toward the usage of the nodes, their requests are displayed separately")
+
+	usageSourceDefault := cfg.getValue("usage-source", "requests")
+	flagSet.StringVar(&flags.UsageSource, "usage-source", usageSourceDefault, "Source of the displayed resource usage, either 'requests' or 'metrics' to also poll the actual usage from metrics-server")
+
+	priceUnitDefault := cfg.getValue("price-unit", "hourly")
+	flagSet.StringVar(&flags.PriceUnit, "price-unit", priceUnitDefault, "Unit of time that node prices and the cluster's cost are displayed in, one of hourly, daily, monthly or yearly")
+
+	maxNodeLifetimeDefault := cfg.getValue("max-node-lifetime", "")
+	flagSet.StringVar(&flags.MaxNodeLifetime, "max-node-lifetime", maxNodeLifetimeDefault, "Highlight nodes older than this lifetime, e.g. 30d or 72h, if empty nodes are never highlighted")
+
+	maxReservedDefault := cfg.getIntValue("max-reserved", 50)
+	flagSet.IntVar(&flags.MaxReserved, "max-reserved", maxReservedDefault, "Highlight nodes where more than this percent of a resource's capacity isn't allocatable, 0 disables the check")
+
+	stalePeriodsDefault := cfg.getIntValue("stale-periods", 3)
+	flagSet.IntVar(&flags.StalePeriods, "stale-periods", stalePeriodsDefault, "Mark ready nodes as stale when they haven't been updated for this many of the kubelet's 5m status reports, 0 disables the check")
+
+	scoreWeightsDefault := cfg.getValue("score-weights", model.DefaultScoreWeights.String())
+	flagSet.StringVar(&flags.ScoreWeights, "score-weights", scoreWeightsDefault, "Weights of the utilization, price, age and status of nodes in their attention score, displayed by the score column and sortable with eks-node-viewer/node-score")
+
+	normalizeAllocDefault := cfg.getBoolValue("normalize-allocatable", false)
+	flagSet.BoolVar(&flags.NormalizeAlloc, "normalize-allocatable", normalizeAllocDefault, "Measure usage against the nodes' capacity rather than their allocatable resources, so resources reserved for the system and kubelet count as unused. This can be toggled with 'a'.")
+
+	rawQuantitiesDefault := cfg.getBoolValue("raw-quantities", false)
+	flagSet.BoolVar(&flags.RawQuantities, "raw-quantities", rawQuantitiesDefault, "Display memory and storage as raw Kubernetes quantities, e.g. 16252928Ki, rather than in binary units such as GiB. This can be toggled with 'r'.")
 
 	disablePricingDefault := cfg.getBoolValue("disable-pricing", false)
 	flagSet.BoolVar(&flags.DisablePricing, "disable-pricing", disablePricingDefault, "Disable pricing lookups")
 
+	commitmentPricingDefault := cfg.getBoolValue("commitment-pricing", false)
+	flagSet.BoolVar(&flags.CommitmentPricing, "commitment-pricing", commitmentPricingDefault, "Apply the account's Reserved Instances and Savings Plans to display effective on-demand prices")
+
+	priceFileDefault := cfg.getValue("price-file", "")
+	flagSet.StringVar(&flags.PriceFile, "price-file", priceFileDefault, "Path to a YAML file of instance type prices to use instead of the AWS pricing APIs")
+
+	staticPricesDefault := cfg.getValue("static-prices", "")
+	flagSet.StringVar(&flags.StaticPrices, "static-prices", staticPricesDefault, "Path to a JSON file of on-demand prices written by 'eks-node-viewer generate-prices' that updates the static prices used until the pricing APIs respond")
+
+	pricingBundleDefault := cfg.getValue("pricing-bundle", "")
+	flagSet.StringVar(&flags.PricingBundle, "pricing-bundle", pricingBundleDefault, "Path to a directory of static prices files, such as one written by 'eks-node-viewer generate-prices -bundle', for partitions without access to the pricing API")
+
+	pricingCacheTTLDefault := cfg.getDurationValue("pricing-cache-ttl", 12*time.Hour)
+	flagSet.DurationVar(&flags.PricingCacheTTL, "pricing-cache-ttl", pricingCacheTTLDefault, "How long AWS prices cached on disk are used before the pricing APIs are called at startup, 0 disables the cache")
+
+	ec2EndpointDefault := cfg.getValue("ec2-endpoint", "")
+	flagSet.StringVar(&flags.EC2Endpoint, "ec2-endpoint", ec2EndpointDefault, "URL of the EC2 API to use in place of the regional endpoint, e.g. a VPC endpoint or LocalStack")
+
+	pricingEndpointDefault := cfg.getValue("pricing-endpoint", "")
+	flagSet.StringVar(&flags.PricingEndpoint, "pricing-endpoint", pricingEndpointDefault, "URL of the AWS Pricing API to use in place of the regional endpoint, e.g. a VPC endpoint or LocalStack")
+
+	caBundleDefault := cfg.getValue("ca-bundle", "")
+	flagSet.StringVar(&flags.CABundle, "ca-bundle", caBundleDefault, "Path to a PEM file of the certificate authorities to trust when calling the AWS APIs, e.g. through a proxy that intercepts TLS, in place of AWS_CA_BUNDLE")
+
+	cloudProviderDefault := cfg.getValue("cloud-provider", "aws")
+	flagSet.StringVar(&flags.CloudProvider, "cloud-provider", cloudProviderDefault, "Cloud provider whose pricing API is used, one of 'aws', 'azure', 'gcp' or 'auto' to detect each node's platform from its provider ID")
+
+	gcpAPIKeyDefault := cfg.getValue("gcp-api-key", "")
+	flagSet.StringVar(&flags.GCPAPIKey, "gcp-api-key", gcpAPIKeyDefault, "API key for the Google Cloud Billing Catalog API, required to price GKE nodes")
+
+	checkCapacityTypeDefault := cfg.getBoolValue("check-capacity-type", false)
+	flagSet.BoolVar(&flags.CheckCapacityType, "check-capacity-type", checkCapacityTypeDefault, "Use EC2 DescribeInstances to flag nodes whose capacity type label doesn't match how the instance was launched, and to detect nodes with dedicated or host tenancy")
+
+	cpuCreditsDefault := cfg.getBoolValue("cpu-credits", false)
+	flagSet.BoolVar(&flags.CPUCredits, "cpu-credits", cpuCreditsDefault, "Poll the CPU credit balance of burstable T-family nodes from CloudWatch, displaying it in a CREDITS column and warning when it's low")
+
+	nodeGroupsDefault := cfg.getBoolValue("node-groups", false)
+	flagSet.BoolVar(&flags.NodeGroups, "node-groups", nodeGroupsDefault, "Poll the EKS and Auto Scaling APIs for the desired and actual capacity of managed node groups, displaying it in the cluster summary")
+
+	legacyMachinesDefault := cfg.getBoolValue("legacy-machines", false)
+	flagSet.BoolVar(&flags.LegacyMachines, "legacy-machines", legacyMachinesDefault, "Also watch the legacy karpenter.sh/v1alpha5 Machines used by Karpenter versions prior to v0.32")
+
+	tracingDefault := cfg.getBoolValue("tracing", false)
+	flagSet.BoolVar(&flags.Tracing, "tracing", tracingDefault, "Export OpenTelemetry traces of API server, pricing and rendering latency, and histograms of API server and pricing latency")
+
+	otlpEndpointDefault := cfg.getValue("otlp-endpoint", "")
+	flagSet.StringVar(&flags.OTLPEndpoint, "otlp-endpoint", otlpEndpointDefault, "OTLP/HTTP endpoint URL of the collector to export traces and metrics to, e.g. http://localhost:4318, if empty the OTEL_EXPORTER_OTLP_* environment variables are used")
+
+	noTTYDefault := cfg.getBoolValue("no-tty", false)
+	flagSet.BoolVar(&flags.NoTTY, "no-tty", noTTYDefault, "Print the cluster summary and nodes to stdout every refresh interval instead of displaying the interactive view")
+
+	refreshDefault := cfg.getDurationValue("refresh", 5*time.Second)
+	flagSet.DurationVar(&flags.Refresh, "refresh", refreshDefault, "How often the nodes are printed when running with -no-tty, or recorded with -record")
+
+	flagSet.StringVar(&flags.WaitFor, "wait-for", "", "Run without the interactive view until a condition such as 'nodes_ready==nodes_total && pending_pods==0' is met, exiting with 0 if it's met or 1 if it times out")
+	flagSet.DurationVar(&flags.Timeout, "timeout", 0, "How long to wait for the -wait-for condition or for the cluster to settle with -check before exiting with 1, 0 waits indefinitely")
+	flagSet.BoolVar(&flags.Check, "check", false, "Run without the interactive view until the cluster settles, then print a report of the -max-cost-per-hour, -min-cpu-utilization and -max-pending-pods thresholds, exiting with 1 if any are violated")
+	flagSet.Float64Var(&flags.MaxCostPerHour, "max-cost-per-hour", 0, "With -check, the maximum hourly price of the nodes, 0 disables the check")
+	flagSet.Float64Var(&flags.MinCPUUtilization, "min-cpu-utilization", 0, "With -check, the minimum percentage of the nodes' allocatable CPU that's requested, 0 disables the check")
+	flagSet.IntVar(&flags.MaxPendingPods, "max-pending-pods", -1, "With -check, the maximum number of pending pods, -1 disables the check")
+
+	statusConfigMapDefault := cfg.getValue("status-configmap", "")
+	flagSet.StringVar(&flags.StatusConfigMap, "status-configmap", statusConfigMapDefault, "Periodically publish a summary of the cost, node counts and problems of each cluster to this namespace/name ConfigMap")
+
+	statusIntervalDefault := cfg.getDurationValue("status-interval", time.Minute)
+	flagSet.DurationVar(&flags.StatusInterval, "status-interval", statusIntervalDefault, "How often the status is published with -status-configmap")
+
+	updateIntervalDefault := cfg.getDurationValue("update-interval", 100*time.Millisecond)
+	flagSet.DurationVar(&flags.UpdateInterval, "update-interval", updateIntervalDefault, "How often the display checks for changes to the cluster, it's rendered at least once a second regardless")
+
+	frameIntervalDefault := cfg.getDurationValue("frame-interval", 250*time.Millisecond)
+	flagSet.DurationVar(&flags.FrameInterval, "frame-interval", frameIntervalDefault, "The least time between renders of changes to the cluster, so that bursts of events are coalesced, it's longer when rendering is slow")
+
+	pageSizeDefault := cfg.getIntValue("page-size", 0)
+	flagSet.IntVar(&flags.PageSize, "page-size", pageSizeDefault, "Number of nodes displayed on each page, 0 fits as many nodes as the terminal's height allows")
+
+	fixedLayoutDefault := cfg.getBoolValue("fixed-layout", false)
+	flagSet.BoolVar(&flags.FixedLayout, "fixed-layout", fixedLayoutDefault, "Keep the number of nodes on each page the same until the terminal is resized, so nodes don't move between pages as the cluster summary changes")
+
+	flagSet.StringVar(&flags.Record, "record", "", "Append a snapshot of the clusters to this file every refresh interval so that it can be replayed with -replay")
+	flagSet.StringVar(&flags.Replay, "replay", "", "Play back the snapshots recorded to this file with -record instead of watching the clusters")
+
+	serveDefault := cfg.getValue("serve", "")
+	flagSet.StringVar(&flags.Serve, "serve", serveDefault, "Serve the nodes, pods and stats over an HTTP and websocket API on this address, e.g. :8080, with -no-tty only the API is served")
+
+	emitDefault := cfg.getValue("emit", "")
+	flagSet.StringVar(&flags.Emit, "emit", emitDefault, "Write the stats and nodes of the clusters every -emit-interval for log pipelines such as Loki, CloudWatch Logs or vector, only 'jsonl' is supported. Unless -emit-file is set they're written to stdout instead of displaying the interactive view.")
+
+	emitIntervalDefault := cfg.getDurationValue("emit-interval", 30*time.Second)
+	flagSet.DurationVar(&flags.EmitInterval, "emit-interval", emitIntervalDefault, "How often a line is written with -emit")
+
+	emitFileDefault := cfg.getValue("emit-file", "")
+	flagSet.StringVar(&flags.EmitFile, "emit-file", emitFileDefault, "Path to append the lines written with -emit to, if empty they're written to stdout")
+
+	logFileDefault := cfg.getValue("log-file", "")
+	flagSet.StringVar(&flags.LogFile, "log-file", logFileDefault, "Path to append log messages to, if empty they're written to stderr once the interactive view exits")
+
+	logLevelDefault := cfg.getValue("log-level", "info")
+	flagSet.StringVar(&flags.LogLevel, "log-level", logLevelDefault, "Minimum level of the log messages that are written, one of debug, info, warn or error")
+
+	debugBundleDefault := cfg.getValue("debug-bundle", "")
+	flagSet.StringVar(&flags.DebugBundle, "debug-bundle", debugBundleDefault, "Path to write a zip file of the version, flags, an anonymized snapshot of the clusters, recent log messages and timings to when exiting, to attach to bug reports")
+
 	flagSet.BoolVar(&flags.ShowAttribution, "attribution", false, "Show the Open Source Attribution")
 
-	if err := flagSet.Parse(os.Args[1:]); err != nil {
+	if isKubectlPlugin() {
+		err = parseKubectlFlags(flagSet, &flags, kubeconfigDefault, nodeSelectorDefault)
+	} else {
+		err = flagSet.Parse(os.Args[1:])
+	}
+	if err != nil {
 		return Flags{}, err
 	}
+	flags.Settings = map[string]string{}
+	flagSet.VisitAll(func(f *flag.Flag) {
+		flags.Settings[f.Name] = f.Value.String()
+	})
+	// multiple columns in the config file are separated by semicolons
+	flags.Columns = columns
+	if len(flags.Columns) == 0 {
+		flags.Columns = strings.FieldsFunc(cfg.getValue("column", ""), func(r rune) bool { return r == ';' })
+	}
+	flags.NodeActions = nodeActions
+	if len(flags.NodeActions) == 0 {
+		flags.NodeActions = strings.FieldsFunc(cfg.getValue("node-action", ""), func(r rune) bool { return r == ';' })
+	}
 	return flags, nil
 }
 
+// nodeActionPresetNames returns the names of the preset node actions
+func nodeActionPresetNames() []string {
+	var names []string
+	for _, def := range model.NodeActionPresets {
+		name, _, _ := strings.Cut(def, "=")
+		names = append(names, name)
+	}
+	return names
+}
+
+// stringSliceFlag is a flag that collects the values of each time it's repeated
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 // --- env vars ---
 
 func getStringEnv(envName string, defaultValue string) string {
@@ -128,6 +419,24 @@ func (c configFile) getBoolValue(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+func (c configFile) getIntValue(key string, defaultValue int) int {
+	if val, ok := c[key]; ok {
+		if intVal, err := strconv.Atoi(val); err == nil {
+			return intVal
+		}
+	}
+	return defaultValue
+}
+
+func (c configFile) getDurationValue(key string, defaultValue time.Duration) time.Duration {
+	if val, ok := c[key]; ok {
+		if durationVal, err := time.ParseDuration(val); err == nil {
+			return durationVal
+		}
+	}
+	return defaultValue
+}
+
 func loadConfigFile() (configFile, error) {
 	fileContent := make(map[string]string)
 	if _, err := os.Stat(configPath); errors.Is(err, os.ErrNotExist) {
@@ -159,3 +468,15 @@ func loadConfigFile() (configFile, error) {
 	}
 	return fileContent, nil
 }
+
+// parseLifetime parses a duration that may also be specified in days, e.g. 30d
+func parseLifetime(lifetime string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(lifetime, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid lifetime %q", lifetime)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	return time.ParseDuration(lifetime)
+}
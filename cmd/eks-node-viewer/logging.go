@@ -0,0 +1,142 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// logWriter writes log messages to stderr, except while the interactive view is on the alt screen when they're held
+// and written once it exits rather than being drawn over it
+type logWriter struct {
+	mu      sync.Mutex
+	holding bool
+	held    bytes.Buffer
+}
+
+func (l *logWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holding {
+		return l.held.Write(p)
+	}
+	return os.Stderr.Write(p)
+}
+
+// hold starts holding log messages, it has no effect if they're written to a log file
+func (l *logWriter) hold() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.holding = true
+}
+
+// release writes the held log messages to stderr and stops holding them
+func (l *logWriter) release() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.holding = false
+	_, _ = l.held.WriteTo(os.Stderr)
+}
+
+// debugBundleLogLines is the number of the most recent log lines that are kept for the debug bundle
+const debugBundleLogLines = 1000
+
+// recentLogs keeps the most recent log messages that were written for the debug bundle
+var recentLogs = &logBuffer{max: debugBundleLogLines}
+
+// logBuffer is a writer that keeps the last lines written to it
+type logBuffer struct {
+	mu    sync.Mutex
+	max   int
+	lines []string
+}
+
+func (l *logBuffer) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		l.lines = append(l.lines, line)
+	}
+	if extra := len(l.lines) - l.max; extra > 0 {
+		l.lines = append(l.lines[:0], l.lines[extra:]...)
+	}
+	return len(p), nil
+}
+
+// WriteTo writes the kept lines
+func (l *logBuffer) WriteTo(w io.Writer) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var total int64
+	for _, line := range l.lines {
+		n, err := fmt.Fprintln(w, line)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// setupLogging replaces the default structured logger, which is also used by client-go, with one that writes to the
+// log file at the given level. If path is empty the messages are written to stderr through the returned writer,
+// otherwise the returned writer is nil and the file should be closed by calling the returned function.
+func setupLogging(path, level string) (*logWriter, func(), error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, nil, fmt.Errorf("parsing log level %q, must be one of debug, info, warn or error", level)
+	}
+
+	var out io.Writer
+	var lw *logWriter
+	closeFn := func() {}
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("opening log file, %w", err)
+		}
+		out = f
+		closeFn = func() { _ = f.Close() }
+	} else {
+		lw = &logWriter{}
+		out = lw
+	}
+
+	// the messages are also kept for the debug bundle
+	logger := slog.New(slog.NewTextHandler(io.MultiWriter(out, recentLogs), &slog.HandlerOptions{Level: lvl}))
+	slog.SetDefault(logger)
+	// setting the default logger also redirects the log package, which is only used for the errors that stop the
+	// program before the interactive view starts, so they're left on stderr
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.LstdFlags)
+	// watch errors and retries from client-go would otherwise be written to stderr over the interactive view
+	klog.SetSlogLogger(logger)
+	return lw, closeFn, nil
+}
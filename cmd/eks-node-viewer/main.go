@@ -19,24 +19,50 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/session"
 	tea "github.com/charmbracelet/bubbletea"
-	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/awslabs/eks-node-viewer/pkg/aws"
+	"github.com/awslabs/eks-node-viewer/pkg/azure"
 	"github.com/awslabs/eks-node-viewer/pkg/client"
+	"github.com/awslabs/eks-node-viewer/pkg/gcp"
 	"github.com/awslabs/eks-node-viewer/pkg/model"
+	"github.com/awslabs/eks-node-viewer/pkg/pricing"
+	"github.com/awslabs/eks-node-viewer/pkg/server"
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
+	"github.com/awslabs/eks-node-viewer/pkg/viewer"
 )
 
+// serverPushInterval is how often changes to the clusters are pushed to websocket clients of the API
+const serverPushInterval = time.Second
+
 //go:generate cp -r ../../ATTRIBUTION.md ./
 //go:embed ATTRIBUTION.md
 var attribution string
 
 func main() {
+	// exitCode is the result of waiting for a condition, os.Exit is deferred so that traces are still flushed
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
+	if len(os.Args) > 1 && os.Args[1] == generatePricesCommand {
+		os.Exit(generatePrices(os.Args[2:]))
+	}
+
 	flags, err := ParseFlags()
 	if err != nil {
 		if errors.Is(err, flag.ErrHelp) {
@@ -58,42 +84,637 @@ func main() {
 		os.Exit(0)
 	}
 
-	cs, err := client.NewKubernetes(flags.Kubeconfig, flags.Context)
+	logs, closeLog, err := setupLogging(flags.LogFile, flags.LogLevel)
 	if err != nil {
-		log.Fatalf("creating client, %s", err)
+		log.Fatalf("setting up logging, %s", err)
 	}
-	nodeClaimClient, err := client.NewNodeClaims(flags.Kubeconfig, flags.Context)
-	if err != nil {
-		log.Fatalf("creating node claim client, %s", err)
+	defer closeLog()
+
+	if flags.Tracing {
+		shutdown, err := tracing.Start(context.Background(), flags.OTLPEndpoint, version)
+		if err != nil {
+			log.Fatalf("starting tracing, %s", err)
+		}
+		defer func() {
+			if err := shutdown(context.Background()); err != nil {
+				slog.Error("flushing traces", "error", err)
+			}
+		}()
+	}
+
+	conn := client.Connection{
+		Kubeconfig: flags.Kubeconfig,
+		Impersonate: client.Impersonation{
+			User:   flags.As,
+			Groups: strings.FieldsFunc(flags.AsGroups, func(r rune) bool { return r == ',' }),
+		},
+		Overrides: flags.KubectlOverrides,
+	}
+	contexts := strings.FieldsFunc(flags.Context, func(r rune) bool { return r == ',' })
+	if flags.AllContexts {
+		if contexts, err = client.Contexts(conn); err != nil {
+			log.Fatalf("listing contexts, %s", err)
+		}
+	}
+	// an empty context name uses the current context
+	if len(contexts) == 0 {
+		contexts = []string{""}
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 
-	pprov := aws.NewStaticPricingProvider()
 	style, err := model.ParseStyle(flags.Style)
 	if err != nil {
 		log.Fatalf("creating style, %s", err)
 	}
 	m := model.NewUIModel(strings.Split(flags.ExtraLabels, ","), flags.NodeSort, style)
 	m.DisablePricing = flags.DisablePricing
+	m.RawQuantities = flags.RawQuantities
+	m.NormalizeAllocatable = flags.NormalizeAlloc
+	m.ColumnPriority = strings.FieldsFunc(flags.ColumnPriority, func(r rune) bool { return r == ',' })
+	m.ExportPath = flags.ExportCSV
+	if flags.NodeListField != model.NodeListName && flags.NodeListField != model.NodeListInstanceID {
+		log.Fatalf("node list field must be %s or %s, got %q", model.NodeListName, model.NodeListInstanceID, flags.NodeListField)
+	}
+	m.NodeListField = flags.NodeListField
+	m.NodeListPath = flags.NodeListFile
+	if m.Notes, err = model.LoadNotes(flags.NotesFile); err != nil {
+		log.Fatalf("loading notes, %s", err)
+	}
+	m.MaxReserved = float64(flags.MaxReserved) / 100
+	if flags.StalePeriods < 0 {
+		log.Fatalf("stale periods must not be negative, got %d", flags.StalePeriods)
+	}
+	m.StaleAfter = time.Duration(flags.StalePeriods) * model.NodeStatusReportPeriod
+	if m.ScoreWeights, err = model.ParseScoreWeights(flags.ScoreWeights); err != nil {
+		log.Fatalf("parsing score weights, %s", err)
+	}
+	if m.ContextColors, err = model.ParseContextColors(flags.ContextColors); err != nil {
+		log.Fatalf("parsing context colors, %s", err)
+	}
+	if m.StatusWords, err = model.ParseStatusWords(flags.StatusWords); err != nil {
+		log.Fatalf("parsing status words, %s", err)
+	}
+	if flags.UpdateInterval <= 0 {
+		log.Fatalf("update interval must be positive, got %s", flags.UpdateInterval)
+	}
+	m.UpdateInterval = flags.UpdateInterval
+	if flags.FrameInterval < 0 {
+		log.Fatalf("frame interval must not be negative, got %s", flags.FrameInterval)
+	}
+	m.FrameInterval = flags.FrameInterval
+	if flags.PageSize < 0 {
+		log.Fatalf("page size must not be negative, got %d", flags.PageSize)
+	}
+	m.PageSize = flags.PageSize
+	m.FixedLayout = flags.FixedLayout
+	if flags.GroupBy != "" {
+		m.SetGroupBy(flags.GroupBy)
+	}
+	if flags.MaxNodeLifetime != "" {
+		if m.MaxNodeLifetime, err = parseLifetime(flags.MaxNodeLifetime); err != nil {
+			log.Fatalf("parsing max node lifetime, %s", err)
+		}
+	}
+	var columns []model.Column
+	for _, def := range flags.Columns {
+		c, err := model.ParseColumn(def)
+		if err != nil {
+			log.Fatalf("parsing column, %s", err)
+		}
+		columns = append(columns, c)
+	}
+	m.SetColumns(columns)
+	nodeActions, err := model.ParseNodeActions(flags.NodeActions)
+	if err != nil {
+		log.Fatalf("parsing node actions, %s", err)
+	}
+	m.SetNodeActions(nodeActions)
+	layout := strings.FieldsFunc(flags.Layout, func(r rune) bool { return r == ',' })
+	if flags.CPUCredits && !slices.Contains(layout, "cpu-credits") {
+		layout = append(layout, "cpu-credits")
+	}
+	if err := m.SetLayout(layout); err != nil {
+		log.Fatalf("setting columns, %s", err)
+	}
 	m.SetResources(strings.FieldsFunc(flags.Resources, func(r rune) bool { return r == ',' }))
+	m.SetExcludedNamespaces(strings.FieldsFunc(flags.ExcludeNamespaces, func(r rune) bool { return r == ',' }))
+	if err := m.SetUsageSource(flags.UsageSource); err != nil {
+		log.Fatalf("setting usage source, %s", err)
+	}
+	if err := m.SetPriceUnit(flags.PriceUnit); err != nil {
+		log.Fatalf("setting price unit, %s", err)
+	}
+
+	// nodes are selected by the watches, the nodes of a recording are selected by the display instead
+	nodeSelector, err := client.NodeSelector(flags.NodeSelector,
+		strings.FieldsFunc(flags.Zones, func(r rune) bool { return r == ',' }),
+		strings.FieldsFunc(flags.InstanceTypes, func(r rune) bool { return r == ',' }))
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+
+	if flags.Replay != "" {
+		if err := m.SetSelector(nodeSelector.String()); err != nil {
+			log.Fatalf("setting selector, %s", err)
+		}
+		replay(m, flags.Replay)
+		return
+	}
+
+	var statusNamespace, statusName string
+	if flags.StatusConfigMap != "" {
+		var ok bool
+		if statusNamespace, statusName, ok = strings.Cut(flags.StatusConfigMap, "/"); !ok || statusNamespace == "" || statusName == "" {
+			log.Fatalf("parsing status configmap %q, must be namespace/name", flags.StatusConfigMap)
+		}
+		if flags.StatusInterval <= 0 {
+			log.Fatalf("status interval must be positive, got %s", flags.StatusInterval)
+		}
+	}
+
+	if flags.Emit != "" {
+		if flags.Emit != "jsonl" {
+			log.Fatalf("unsupported emit format %q, must be jsonl", flags.Emit)
+		}
+		if flags.EmitInterval <= 0 {
+			log.Fatalf("emit interval must be positive, got %s", flags.EmitInterval)
+		}
+	}
+
+	var condition *model.Condition
+	if flags.WaitFor != "" {
+		if condition, err = model.ParseCondition(flags.WaitFor); err != nil {
+			log.Fatalf("%s", err)
+		}
+	}
+
+	var thresholds []model.Threshold
+	if flags.Check {
+		if thresholds = checkThresholds(flags); len(thresholds) == 0 {
+			log.Fatalf("-check requires at least one of -max-cost-per-hour, -min-cpu-utilization or -max-pending-pods")
+		}
+		if condition != nil {
+			log.Fatalf("-check and -wait-for can't be used together")
+		}
+	}
+
+	// nodes are priced by the first provider in the chain with a price for them, a price file replaces the cloud
+	// provider pricing APIs
+	links := []pricing.Link{{Name: "label", Provider: pricing.NewLabelProvider()}}
+	pricingAPI := !flags.DisablePricing && flags.PriceFile == ""
+	if flags.PriceFile != "" {
+		fprov, err := pricing.NewFileProvider(flags.PriceFile)
+		if err != nil {
+			log.Fatalf("loading price file, %s", err)
+		}
+		links = append(links, pricing.Link{Name: "file", Provider: fprov})
+	}
+
+	region := ""
+	var sess *session.Session
+	if pricingAPI || flags.CheckCapacityType || flags.CPUCredits || flags.NodeGroups {
+		if sess, err = aws.NewSession(aws.SessionOptions{
+			EC2Endpoint:     flags.EC2Endpoint,
+			PricingEndpoint: flags.PricingEndpoint,
+			CABundle:        flags.CABundle,
+		}); err != nil {
+			log.Fatalf("creating AWS session, %s", err)
+		}
+		if sess.Config.Region != nil {
+			region = *sess.Config.Region
+		}
+	}
+	// a static prices file takes precedence over the pricing bundle
+	var staticPrices aws.StaticPrices
+	if flags.PricingBundle != "" {
+		if staticPrices, err = aws.ReadPricingBundle(flags.PricingBundle); err != nil {
+			log.Fatalf("loading pricing bundle, %s", err)
+		}
+	}
+	if flags.StaticPrices != "" {
+		filePrices, err := aws.ReadStaticPrices(flags.StaticPrices)
+		if err != nil {
+			log.Fatalf("loading static prices, %s", err)
+		}
+		staticPrices = staticPrices.Merge(filePrices)
+	}
+
+	// prices, instance types and capacity types are looked up in the region of each cluster, clusters whose region
+	// isn't known from the kubeconfig use the region of the session
+	regional := map[string]providers{}
+	providersFor := func(clusterRegion string) providers {
+		if clusterRegion == "" {
+			clusterRegion = region
+		}
+		if p, ok := regional[clusterRegion]; ok {
+			return p
+		}
+		p, err := newProviders(ctx, flags, sess, clusterRegion, links, pricingAPI, staticPrices)
+		if err != nil {
+			log.Fatalf("creating providers for %s, %s", clusterRegion, err)
+		}
+		regional[clusterRegion] = p
+		return p
+	}
+
+	v, err := viewer.New(viewer.Options{
+		Connection:   conn,
+		Contexts:     contexts,
+		NodeSelector: nodeSelector,
+		// kubectl's --namespace limits the pods that are displayed when running as a kubectl plugin
+		PodNamespace:   flags.KubectlOverrides.Context.Namespace,
+		LegacyMachines: flags.LegacyMachines,
+		ForCluster: func(id client.Identity, opts viewer.Options) viewer.Options {
+			p := providersFor(id.Region)
+			opts.Pricing, opts.OnDemand, opts.InstanceTypes, opts.Lifecycle = p.pricing, p.onDemand, p.instanceTypes, p.lifecycle
+			return opts
+		},
+		Cluster: func(i int, kubeContext string) *model.Cluster {
+			if i == 0 {
+				m.Cluster().SetName(kubeContext)
+				return m.Cluster()
+			}
+			return m.AddCluster(kubeContext)
+		},
+	})
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	defer v.Stop()
+	// a single cluster is identified by the statusline, the current context is resolved from the kubeconfig as the
+	// context flag is empty
+	if clusters := v.Clusters(); len(clusters) == 1 {
+		m.SetIdentity(clusters[0].Identity.Context, clusters[0].Identity.Cluster)
+	}
+	// the spot price table is of the first cluster's region
+	if sp := providersFor(v.Clusters()[0].Identity.Region).spotPrices; sp != nil {
+		m.SpotPrices = sp
+	}
+	controllers := v.Controllers()
+	for _, c := range v.Clusters() {
+		if statusName != "" {
+			c.Controller.StartStatusPublisher(ctx, statusNamespace, statusName, flags.StatusInterval)
+		}
+		if flags.UsageSource == "metrics" {
+			c.Controller.StartUsageMetrics(ctx)
+		}
+		if credits := providersFor(c.Identity.Region).credits; credits != nil {
+			c.Controller.StartCPUCredits(ctx, credits)
+		}
+		if flags.NodeGroups {
+			c.Controller.StartManagedNodeGroups(ctx, aws.NewManagedNodeGroupSource(sess, c.Identity.Cluster, c.Identity.Region))
+		}
+	}
+
+	if flags.DebugBundle != "" {
+		bundle := newDebugBundle(flags.DebugBundle)
+		bundle.timeSync(ctx, controllers)
+		// deferred so that the bundle is also written after waiting for a condition or checking thresholds
+		defer func() {
+			if err := bundle.write(flags, m); err != nil {
+				slog.Error("writing debug bundle", "error", err)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "wrote debug bundle to %s\n", flags.DebugBundle)
+		}()
+	}
+
+	if flags.Record != "" {
+		f, err := os.OpenFile(flags.Record, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			log.Fatalf("opening recording, %s", err)
+		}
+		go record(ctx, m, controllers, f, flags.Refresh)
+	}
 
-	var nodeSelector labels.Selector
-	if ns, err := labels.Parse(flags.NodeSelector); err != nil {
-		log.Fatalf("parsing node selector: %s", err)
+	if flags.Emit != "" && flags.EmitFile != "" {
+		f, err := os.OpenFile(flags.EmitFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			log.Fatalf("opening emit file, %s", err)
+		}
+		go func() {
+			defer f.Close()
+			emit(ctx, m, controllers, f, flags.EmitInterval)
+		}()
+	}
+
+	if flags.Serve != "" {
+		srv := server.New(m.Clusters(), serverPushInterval)
+		// an API that can't be served stops the viewer rather than exiting from here, so that the deferred trace flush
+		// and debug bundle still run
+		serveErr := make(chan error, 1)
+		go func() {
+			if err := srv.ListenAndServe(ctx, flags.Serve); err != nil {
+				serveErr <- err
+				cancel()
+			}
+		}()
+		defer func() {
+			select {
+			case err := <-serveErr:
+				log.Printf("serving API, %s", err)
+				exitCode = 1
+			default:
+			}
+		}()
+	}
+
+	if condition != nil {
+		exitCode = waitFor(ctx, m, controllers, condition, flags.Timeout, flags.Refresh)
+		cancel()
+		return
+	}
+	if thresholds != nil {
+		exitCode = check(ctx, m, controllers, thresholds, flags.Timeout, flags.Refresh)
+		cancel()
+		return
+	}
+
+	if flags.Emit != "" && flags.EmitFile == "" {
+		// the lines are written to stdout, so nothing else is printed to it that would break parsing them
+		emitCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		emit(emitCtx, m, controllers, os.Stdout, flags.EmitInterval)
+		stop()
+		cancel()
+		return
+	}
+	if flags.NoTTY && flags.Serve != "" {
+		// the API is the only consumer of the clusters, so nothing is printed
+		serveCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		<-serveCtx.Done()
+		stop()
+	} else if flags.NoTTY {
+		watch(ctx, m, flags.Refresh)
 	} else {
-		nodeSelector = ns
+		logs.hold()
+		restoreConsole := prepareConsole()
+		// the program is stopped along with everything else if the API can't be served
+		_, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+		restoreConsole()
+		logs.release()
+		if err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+			log.Printf("error running tea: %s", err)
+			exitCode = 1
+		}
+	}
+	cancel()
+	var interruptions []model.Interruption
+	for _, c := range m.Clusters() {
+		interruptions = append(interruptions, c.Interruptions()...)
 	}
+	model.WriteInterruptionSummary(os.Stdout, interruptions)
+}
+
+// newPricingProvider returns a provider for the pricing API of the cloud provider selected by the flags
+// providers are what the clusters in a region are priced by and what looks up their instance types and capacity types
+type providers struct {
+	pricing       pricing.Provider
+	onDemand      pricing.OnDemandProvider
+	spotPrices    model.SpotPriceSource
+	instanceTypes pricing.InstanceTypeProvider
+	lifecycle     pricing.LifecycleProvider
+	credits       client.CPUCreditSource
+}
 
-	if !flags.DisablePricing {
-		sess := session.Must(session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable}))
-		pprov = aws.NewPricingProvider(ctx, sess)
+// newProviders returns the providers of a region, the links are those that aren't regional such as a price file, and
+// are followed by the pricing API of the region if pricingAPI is true and the static prices of the region
+func newProviders(ctx context.Context, flags Flags, sess *session.Session, region string, links []pricing.Link,
+	pricingAPI bool, staticPrices aws.StaticPrices) (providers, error) {
+	var p providers
+	links = slices.Clone(links)
+	if sess != nil {
+		sess = aws.RegionalSession(sess, region)
+		if pricingAPI {
+			apiprov, err := newPricingProvider(ctx, flags, sess)
+			if err != nil {
+				return providers{}, fmt.Errorf("creating pricing provider, %w", err)
+			}
+			links = append(links, pricing.Link{Name: flags.CloudProvider, Provider: apiprov})
+			if flags.CloudProvider == "aws" || flags.CloudProvider == "auto" {
+				p.instanceTypes = aws.NewInstanceTypeProvider(ctx, sess)
+			}
+		}
+		if flags.CheckCapacityType {
+			p.lifecycle = aws.NewLifecycleProvider(ctx, sess)
+		}
+		if flags.CPUCredits {
+			p.credits = aws.NewCPUCreditSource(sess)
+		}
+	}
+	links = append(links, pricing.Link{Name: "static", Provider: aws.NewStaticPricingProviderWithPrices(region, staticPrices)})
+	if p.instanceTypes == nil {
+		p.instanceTypes = aws.NewStaticInstanceTypeProvider()
+	}
+	p.pricing = pricing.NewChainProvider(links...)
+	// the spot price table is only available from providers that know the price in each zone
+	for _, link := range links {
+		if sp, ok := link.Provider.(model.SpotPriceSource); ok {
+			p.spotPrices = sp
+			break
+		}
+	}
+	// spot savings are compared against the on-demand prices of the first provider that knows them
+	for _, link := range links {
+		if od, ok := link.Provider.(pricing.OnDemandProvider); ok {
+			p.onDemand = od
+			break
+		}
 	}
-	controller := client.NewController(cs, nodeClaimClient, m, nodeSelector, pprov)
+	return p, nil
+}
 
-	controller.Start(ctx)
+func newPricingProvider(ctx context.Context, flags Flags, sess *session.Session) (pricing.Provider, error) {
+	switch flags.CloudProvider {
+	case "aws":
+		return aws.NewPricingProvider(ctx, sess, flags.CommitmentPricing, flags.PricingCacheTTL), nil
+	case "azure":
+		return azure.NewPricingProvider(ctx), nil
+	case "gcp":
+		if flags.GCPAPIKey == "" {
+			return nil, errors.New("--gcp-api-key is required to price GKE nodes")
+		}
+		return gcp.NewPricingProvider(ctx, flags.GCPAPIKey), nil
+	case "auto":
+		providers := map[string]pricing.Provider{
+			"aws":   aws.NewPricingProvider(ctx, sess, flags.CommitmentPricing, flags.PricingCacheTTL),
+			"azure": azure.NewPricingProvider(ctx),
+		}
+		if flags.GCPAPIKey != "" {
+			providers["gce"] = gcp.NewPricingProvider(ctx, flags.GCPAPIKey)
+		}
+		return pricing.NewPlatformProvider(providers), nil
+	}
+	return nil, fmt.Errorf("unknown cloud provider %q, must be one of aws, azure, gcp or auto", flags.CloudProvider)
+}
 
-	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+// waitFor prints the values used by the condition every refresh interval until it's met, returning the exit code of 0
+// if it was met or 1 if it timed out or was interrupted first
+func waitFor(ctx context.Context, m *model.UIModel, controllers []*client.Controller, condition *model.Condition, timeout, refresh time.Duration) int {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	// the condition isn't meaningful until the current state of every cluster has been listed
+	for _, c := range controllers {
+		if !c.WaitForSync(ctx) {
+			fmt.Printf("stopped waiting for %s, %s\n", condition, context.Cause(ctx))
+			return 1
+		}
+	}
+	for {
+		var clusterStats []model.Stats
+		for _, c := range m.Clusters() {
+			clusterStats = append(clusterStats, c.Stats())
+		}
+		stats := model.MergeStats(clusterStats...)
+		if condition.Evaluate(stats) {
+			fmt.Printf("%s %s: met\n", time.Now().Format(time.RFC3339), condition.Values(stats))
+			return 0
+		}
+		fmt.Printf("%s %s: waiting\n", time.Now().Format(time.RFC3339), condition.Values(stats))
+		select {
+		case <-ctx.Done():
+			fmt.Printf("stopped waiting for %s, %s\n", condition, context.Cause(ctx))
+			return 1
+		case <-time.After(refresh):
+		}
+	}
+}
+
+// checkThresholds returns the thresholds that are enabled by the flags
+func checkThresholds(flags Flags) []model.Threshold {
+	var thresholds []model.Threshold
+	if flags.MaxCostPerHour > 0 {
+		thresholds = append(thresholds, model.Threshold{Variable: "price_per_hour", Limit: flags.MaxCostPerHour})
+	}
+	if flags.MinCPUUtilization > 0 {
+		thresholds = append(thresholds, model.Threshold{Variable: "cpu_utilization", Limit: flags.MinCPUUtilization, Min: true})
+	}
+	if flags.MaxPendingPods >= 0 {
+		thresholds = append(thresholds, model.Threshold{Variable: "pending_pods", Limit: float64(flags.MaxPendingPods)})
+	}
+	return thresholds
+}
+
+// check waits for the clusters to settle, which is when the values of the thresholds are unchanged between two refresh
+// intervals, e.g. once the nodes have been priced, then prints a report of the thresholds. It returns the exit code of
+// 0 if every threshold is met or 1 if any are violated, or if the clusters didn't settle before timing out.
+func check(ctx context.Context, m *model.UIModel, controllers []*client.Controller, thresholds []model.Threshold, timeout, refresh time.Duration) int {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	for _, c := range controllers {
+		if !c.WaitForSync(ctx) {
+			fmt.Printf("stopped waiting for the clusters to settle, %s\n", context.Cause(ctx))
+			return 1
+		}
+	}
+	var prev []model.ThresholdResult
+	for {
+		var clusterStats []model.Stats
+		for _, c := range m.Clusters() {
+			clusterStats = append(clusterStats, c.Stats())
+		}
+		results, ok := model.Check(model.MergeStats(clusterStats...), thresholds)
+		if slices.Equal(results, prev) {
+			model.WriteCheckReport(os.Stdout, results)
+			if !ok {
+				return 1
+			}
+			return 0
+		}
+		prev = results
+		select {
+		case <-ctx.Done():
+			fmt.Printf("stopped waiting for the clusters to settle, %s\n", context.Cause(ctx))
+			model.WriteCheckReport(os.Stdout, results)
+			return 1
+		case <-time.After(refresh):
+		}
+	}
+}
+
+// record appends a frame of the clusters to the file every refresh interval until the context is done
+func record(ctx context.Context, m *model.UIModel, controllers []*client.Controller, f *os.File, refresh time.Duration) {
+	defer f.Close()
+	// frames recorded before the informers have synced would show the cluster as empty
+	for _, c := range controllers {
+		if !c.WaitForSync(ctx) {
+			return
+		}
+	}
+	for {
+		if err := model.WriteFrame(f, m.Frame(time.Now())); err != nil {
+			slog.Error("recording frame", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(refresh):
+		}
+	}
+}
+
+// emit writes a JSON line of the stats and nodes of the clusters every interval until the context is done
+func emit(ctx context.Context, m *model.UIModel, controllers []*client.Controller, w io.Writer, interval time.Duration) {
+	// lines written before the informers have synced would show the cluster as empty
+	for _, c := range controllers {
+		if !c.WaitForSync(ctx) {
+			return
+		}
+	}
+	for {
+		if err := server.WriteLine(w, m.Clusters(), time.Now()); err != nil {
+			slog.Error("emitting stats", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// replay plays back the frames recorded to a file
+func replay(m *model.UIModel, path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("opening recording, %s", err)
+	}
+	frames, err := model.ReadFrames(f)
+	f.Close()
+	if err != nil {
+		log.Fatalf("reading recording, %s", err)
+	}
+	m.Replay(frames)
+	restoreConsole := prepareConsole()
+	_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+	restoreConsole()
+	if err != nil {
 		log.Fatalf("error running tea: %s", err)
 	}
-	cancel()
+}
+
+// watch prints the nodes to stdout every refresh interval until interrupted
+func watch(ctx context.Context, m *model.UIModel, refresh time.Duration) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	for {
+		fmt.Printf("--- %s ---\n", time.Now().Format(time.RFC3339))
+		if err := m.WriteSnapshot(os.Stdout); err != nil {
+			log.Fatalf("writing nodes, %s", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(refresh):
+		}
+	}
 }
@@ -0,0 +1,48 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/aws"
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestNewProvidersAreRegional(t *testing.T) {
+	node := model.NewNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "mynode", Labels: map[string]string{
+		v1.LabelInstanceTypeStable:   "m5.large",
+		"karpenter.sh/capacity-type": "on-demand",
+	}}})
+	prices := map[string]float64{}
+	for _, region := range []string{"us-east-1", "us-gov-west-1"} {
+		p, err := newProviders(context.Background(), Flags{CloudProvider: "aws"}, nil, region, nil, false, aws.StaticPrices{})
+		if err != nil {
+			t.Fatalf("creating providers for %s, %s", region, err)
+		}
+		price, ok := p.pricing.NodePrice(node)
+		if !ok {
+			t.Fatalf("expected an on-demand price for m5.large in %s", region)
+		}
+		prices[region] = price
+	}
+	if prices["us-east-1"] == prices["us-gov-west-1"] {
+		t.Errorf("expected the regions to be priced differently, both cost %v", prices["us-east-1"])
+	}
+}
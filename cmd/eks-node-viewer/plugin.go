@@ -0,0 +1,71 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// kubectlPluginName is the name of the binary when it's installed as a kubectl plugin, e.g. with krew, so that it's run
+// as "kubectl node-viewer"
+const kubectlPluginName = "kubectl-node_viewer"
+
+// replacedByKubectl are the flags that are replaced by kubectl's standard flags when running as a kubectl plugin, -v is
+// replaced as kubectl uses it for the log level
+var replacedByKubectl = []string{"context", "kubeconfig", "as", "as-group", "node-selector", "v"}
+
+// isKubectlPlugin returns true if the binary is being run as a kubectl plugin
+func isKubectlPlugin() bool {
+	return strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe") == kubectlPluginName
+}
+
+// parseKubectlFlags parses the command line following kubectl's conventions. The flags are the same as when running
+// eks-node-viewer directly, except that the cluster and credentials are selected with kubectl's standard flags, e.g.
+// --context, --namespace and --kubeconfig, and nodes are selected with -l.
+func parseKubectlFlags(flagSet *flag.FlagSet, flags *Flags, kubeconfigDefault, nodeSelectorDefault string) error {
+	fs := pflag.NewFlagSet("kubectl node-viewer", pflag.ContinueOnError)
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if !slices.Contains(replacedByKubectl, f.Name) {
+			fs.AddGoFlag(f)
+		}
+	})
+	var kubeconfig string
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file to use for CLI requests.")
+	clientcmd.BindOverrideFlags(&flags.KubectlOverrides, fs, clientcmd.RecommendedConfigOverrideFlags(""))
+	fs.StringVarP(&flags.NodeSelector, "selector", "l", nodeSelectorDefault, "Selector (label query) to filter nodes on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return flag.ErrHelp
+		}
+		return err
+	}
+	// the context and impersonation are only taken from kubectl's flags, as the config file's values would otherwise
+	// override the --context, --as and --as-group given on the command line
+	flags.Context, flags.As, flags.AsGroups = "", "", ""
+	// like kubectl, an explicit kubeconfig takes precedence over KUBECONFIG
+	flags.Kubeconfig = kubeconfigDefault
+	if kubeconfig != "" {
+		flags.Kubeconfig = kubeconfig
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/awslabs/eks-node-viewer/pkg/aws"
+)
+
+// generatePricesCommand is the subcommand that writes a static prices file for --static-prices, or a pricing bundle
+// for --pricing-bundle
+const generatePricesCommand = "generate-prices"
+
+// generatePrices fetches the current on-demand prices from the AWS pricing API and writes them as a static prices
+// file or to a pricing bundle, returning the exit code
+func generatePrices(args []string) int {
+	fs := flag.NewFlagSet(generatePricesCommand, flag.ContinueOnError)
+	output := fs.String("o", "", "Path to write the prices to, defaults to stdout")
+	bundle := fs.String("bundle", "", "Directory of a pricing bundle to write the prices to as a file per partition, updating the regions already in it")
+	regions := fs.String("regions", strings.Join(aws.StaticPriceRegions(), ","), "A comma separated list of the regions to fetch prices for")
+	endpoint := fs.String("pricing-endpoint", "", "URL of the AWS Pricing API to use in place of the regional endpoint")
+	caBundle := fs.String("ca-bundle", "", "Path to a PEM file of the certificate authorities to trust when calling the Pricing API")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *output != "" && *bundle != "" {
+		log.Printf("only one of -o and -bundle can be used")
+		return 2
+	}
+
+	sess, err := aws.NewSession(aws.SessionOptions{PricingEndpoint: *endpoint, CABundle: *caBundle})
+	if err != nil {
+		log.Printf("creating AWS session, %s", err)
+		return 1
+	}
+	prices, err := aws.FetchStaticPrices(context.Background(), sess,
+		strings.FieldsFunc(*regions, func(r rune) bool { return r == ',' }))
+	// regions in other partitions can't be fetched with the same credentials, so only fail if nothing was fetched
+	if err != nil {
+		log.Printf("fetching prices, %s", err)
+	}
+	if len(prices.Regions) == 0 {
+		return 1
+	}
+
+	if *bundle != "" {
+		written, err := aws.WritePricingBundle(*bundle, prices)
+		if err != nil {
+			log.Printf("writing pricing bundle, %s", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "wrote prices for %d regions to %s\n", len(prices.Regions), strings.Join(written, ", "))
+		return 0
+	}
+
+	var buf bytes.Buffer
+	if err := aws.WriteStaticPrices(&buf, prices); err != nil {
+		log.Printf("encoding prices, %s", err)
+		return 1
+	}
+	if *output == "" {
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			log.Printf("writing prices, %s", err)
+			return 1
+		}
+		return 0
+	}
+	if err := os.WriteFile(*output, buf.Bytes(), 0o644); err != nil {
+		log.Printf("writing prices, %s", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "wrote prices for %d regions to %s\n", len(prices.Regions), *output)
+	return 0
+}
@@ -0,0 +1,134 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// priceCache holds the prices fetched from the pricing and EC2 APIs, saved to disk so that later runs display current
+// prices immediately rather than the embedded static prices, and don't call the APIs until the cache expires
+type priceCache struct {
+	Region                     string                                       `json:"region"`
+	Updated                    time.Time                                    `json:"updated"`
+	OnDemand                   map[ec2types.InstanceType]float64            `json:"onDemand"`
+	Dedicated                  map[ec2types.InstanceType]float64            `json:"dedicated,omitempty"`
+	Spot                       map[ec2types.InstanceType]map[string]float64 `json:"spot"`
+	WindowsOnDemand            map[ec2types.InstanceType]float64            `json:"windowsOnDemand,omitempty"`
+	WindowsSpot                map[ec2types.InstanceType]map[string]float64 `json:"windowsSpot,omitempty"`
+	FargateVCPUPricePerHour    float64                                      `json:"fargateVCPUPricePerHour"`
+	FargateGBPricePerHour      float64                                      `json:"fargateGBPricePerHour"`
+	FargateStoragePricePerHour float64                                      `json:"fargateStoragePricePerHour"`
+}
+
+// priceCachePath returns the path of the price cache for a region, or an empty string if there is no cache directory
+func priceCachePath(region string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "eks-node-viewer", fmt.Sprintf("pricing-%s.json", region))
+}
+
+// loadPriceCache replaces the current prices with those from the cache, returning when the cached prices were fetched
+func (p *pricingProvider) loadPriceCache() (time.Time, error) {
+	contents, err := os.ReadFile(p.cachePath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var cache priceCache
+	if err := json.Unmarshal(contents, &cache); err != nil {
+		return time.Time{}, fmt.Errorf("parsing %s, %w", p.cachePath, err)
+	}
+	if cache.Region != p.region || len(cache.OnDemand) == 0 {
+		return time.Time{}, fmt.Errorf("no prices for %s in %s", p.region, p.cachePath)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onDemandPrices = cache.OnDemand
+	p.dedicatedPrices = cache.Dedicated
+	p.spotPrices = map[ec2types.InstanceType]zonalPricing{}
+	for it, zoneData := range cache.Spot {
+		p.spotPrices[it] = newZonalPricing(p.onDemandPrices[it])
+		for zone, price := range zoneData {
+			p.spotPrices[it].prices[zone] = price
+		}
+	}
+	p.windowsOnDemandPrices = cache.WindowsOnDemand
+	p.windowsSpotPrices = map[ec2types.InstanceType]zonalPricing{}
+	for it, zoneData := range cache.WindowsSpot {
+		p.windowsSpotPrices[it] = newZonalPricing(p.windowsOnDemandPrices[it])
+		for zone, price := range zoneData {
+			p.windowsSpotPrices[it].prices[zone] = price
+		}
+	}
+	p.fargateVCPUPricePerHour = cache.FargateVCPUPricePerHour
+	p.fargateGBPricePerHour = cache.FargateGBPricePerHour
+	p.fargateStoragePricePerHour = cache.FargateStoragePricePerHour
+	return cache.Updated, nil
+}
+
+// savePriceCache writes the current prices to the cache
+func (p *pricingProvider) savePriceCache() error {
+	p.mu.RLock()
+	cache := priceCache{
+		Region:                     p.region,
+		Updated:                    time.Now(),
+		OnDemand:                   p.onDemandPrices,
+		Dedicated:                  p.dedicatedPrices,
+		Spot:                       map[ec2types.InstanceType]map[string]float64{},
+		WindowsOnDemand:            p.windowsOnDemandPrices,
+		WindowsSpot:                map[ec2types.InstanceType]map[string]float64{},
+		FargateVCPUPricePerHour:    p.fargateVCPUPricePerHour,
+		FargateGBPricePerHour:      p.fargateGBPricePerHour,
+		FargateStoragePricePerHour: p.fargateStoragePricePerHour,
+	}
+	for it, zp := range p.spotPrices {
+		cache.Spot[it] = zp.prices
+	}
+	for it, zp := range p.windowsSpotPrices {
+		cache.WindowsSpot[it] = zp.prices
+	}
+	contents, err := json.Marshal(cache)
+	p.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.cachePath), 0o700); err != nil {
+		return err
+	}
+	// write to a temporary file first so that a concurrently starting viewer never reads a partial cache
+	tmp, err := os.CreateTemp(filepath.Dir(p.cachePath), filepath.Base(p.cachePath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(contents); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p.cachePath)
+}
@@ -0,0 +1,149 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func testCacheProvider(t *testing.T, region string) *pricingProvider {
+	return &pricingProvider{
+		region:            region,
+		cachePath:         filepath.Join(t.TempDir(), "pricing-"+region+".json"),
+		onDemandPrices:    map[ec2types.InstanceType]float64{},
+		spotPrices:        map[ec2types.InstanceType]zonalPricing{},
+		windowsSpotPrices: map[ec2types.InstanceType]zonalPricing{},
+	}
+}
+
+func TestPriceCacheRoundTrip(t *testing.T) {
+	saved := testCacheProvider(t, "us-west-2")
+	saved.onDemandPrices = map[ec2types.InstanceType]float64{"m5.large": 0.096, "c5.large": 0.085}
+	saved.dedicatedPrices = map[ec2types.InstanceType]float64{"m5.large": 0.106}
+	saved.spotPrices["m5.large"] = zonalPricing{defaultPrice: 0.096, prices: map[string]float64{"us-west-2a": 0.035}}
+	saved.windowsOnDemandPrices = map[ec2types.InstanceType]float64{"m5.large": 0.188}
+	saved.windowsSpotPrices["m5.large"] = zonalPricing{defaultPrice: 0.188, prices: map[string]float64{"us-west-2b": 0.127}}
+	saved.fargateVCPUPricePerHour = 0.04048
+	saved.fargateGBPricePerHour = 0.004445
+	saved.fargateStoragePricePerHour = 0.000111
+	before := time.Now()
+	if err := saved.savePriceCache(); err != nil {
+		t.Fatalf("saving the price cache, %s", err)
+	}
+
+	loaded := testCacheProvider(t, "us-west-2")
+	loaded.cachePath = saved.cachePath
+	updated, err := loaded.loadPriceCache()
+	if err != nil {
+		t.Fatalf("loading the price cache, %s", err)
+	}
+	if updated.Before(before) || updated.After(time.Now()) {
+		t.Errorf("expected the cache to be updated when it was saved, got %s", updated)
+	}
+	if got, ok := loaded.OnDemandPrice("c5.large"); !ok || got != 0.085 {
+		t.Errorf("expected the c5.large on-demand price to be 0.085, got %f", got)
+	}
+	if got, ok := loaded.DedicatedPrice("m5.large"); !ok || got != 0.106 {
+		t.Errorf("expected the m5.large dedicated price to be 0.106, got %f", got)
+	}
+	if got, ok := loaded.SpotPrice("m5.large", "us-west-2a"); !ok || got != 0.035 {
+		t.Errorf("expected the m5.large spot price in us-west-2a to be 0.035, got %f", got)
+	}
+	// zones without spot prices fall back to the on-demand price, which is restored from the cache too
+	if got, ok := loaded.SpotPrice("m5.large", "us-west-2c"); !ok || got != 0.096 {
+		t.Errorf("expected the m5.large spot price in us-west-2c to fall back to 0.096, got %f", got)
+	}
+	if got, ok := loaded.windowsSpotPrices["m5.large"].price("us-west-2b"); !ok || got != 0.127 {
+		t.Errorf("expected the m5.large Windows spot price in us-west-2b to be 0.127, got %f", got)
+	}
+	if loaded.windowsOnDemandPrices["m5.large"] != 0.188 {
+		t.Errorf("expected the m5.large Windows on-demand price to be 0.188, got %f", loaded.windowsOnDemandPrices["m5.large"])
+	}
+	if loaded.fargateVCPUPricePerHour != 0.04048 || loaded.fargateGBPricePerHour != 0.004445 || loaded.fargateStoragePricePerHour != 0.000111 {
+		t.Errorf("expected the Fargate prices to be restored, got %f, %f and %f", loaded.fargateVCPUPricePerHour,
+			loaded.fargateGBPricePerHour, loaded.fargateStoragePricePerHour)
+	}
+}
+
+func TestPriceCacheErrors(t *testing.T) {
+	saved := testCacheProvider(t, "us-west-2")
+	saved.onDemandPrices = map[ec2types.InstanceType]float64{"m5.large": 0.096}
+	if err := saved.savePriceCache(); err != nil {
+		t.Fatalf("saving the price cache, %s", err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		setup func(p *pricingProvider)
+	}{
+		{name: "missing", setup: func(p *pricingProvider) {}},
+		{name: "region mismatch", setup: func(p *pricingProvider) {
+			p.cachePath = saved.cachePath
+			p.region = "eu-west-1"
+		}},
+		{name: "corrupt", setup: func(p *pricingProvider) {
+			if err := os.WriteFile(p.cachePath, []byte(`{"region": "us-west-2", "onDemand": {`), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{name: "no on-demand prices", setup: func(p *pricingProvider) {
+			if err := os.WriteFile(p.cachePath, []byte(`{"region": "us-west-2", "onDemand": {}}`), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := testCacheProvider(t, "us-west-2")
+			p.onDemandPrices = map[ec2types.InstanceType]float64{"c5.large": 0.085}
+			tc.setup(p)
+			if _, err := p.loadPriceCache(); err == nil {
+				t.Fatalf("expected an error loading the price cache")
+			}
+			// the current prices are kept when the cache can't be used
+			if got, ok := p.OnDemandPrice("c5.large"); !ok || got != 0.085 {
+				t.Errorf("expected the c5.large price to be kept, got %f", got)
+			}
+		})
+	}
+}
+
+func TestInitialUpdateDelay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ttl := 6 * time.Hour
+	for _, tc := range []struct {
+		name   string
+		cached time.Time
+		ttl    time.Duration
+		exp    time.Duration
+	}{
+		{name: "no cache", cached: time.Time{}, ttl: ttl, exp: 0},
+		{name: "fresh", cached: now.Add(-time.Hour), ttl: ttl, exp: 5 * time.Hour},
+		{name: "expired", cached: now.Add(-ttl), ttl: ttl, exp: 0},
+		{name: "caching disabled", cached: now.Add(-time.Hour), ttl: 0, exp: 0},
+		// a cache written by a machine whose clock is ahead is treated as just written, not as younger than that
+		{name: "future", cached: now.Add(3 * time.Hour), ttl: ttl, exp: ttl},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := initialUpdateDelay(tc.cached, now, tc.ttl); got != tc.exp {
+				t.Errorf("expected the first update after %s, got %s", tc.exp, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/savingsplans"
+	"go.uber.org/multierr"
+
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
+)
+
+// commitments tracks the Reserved Instances and Savings Plans purchased by the account and which nodes they are
+// applied to. Coverage is handed out to on-demand nodes as they are priced, reserved instances first and then savings
+// plans until the hourly commitment is used up.
+type commitments struct {
+	mu                    sync.Mutex
+	reservations          map[ec2types.InstanceType]*reservation
+	savingsPlanRates      map[ec2types.InstanceType]float64
+	savingsPlanCommitment float64
+	savingsPlanUsed       float64
+	assignments           map[string]assignment
+}
+
+type reservation struct {
+	count int
+	used  int
+	price float64
+}
+
+type assignment struct {
+	instanceType ec2types.InstanceType
+	price        float64
+	reserved     bool
+}
+
+func newCommitments() *commitments {
+	return &commitments{
+		reservations:     map[ec2types.InstanceType]*reservation{},
+		savingsPlanRates: map[ec2types.InstanceType]float64{},
+		assignments:      map[string]assignment{},
+	}
+}
+
+// price returns the effective hourly price of an on-demand instance, applying any available commitment coverage.
+// Instances without an ID, e.g. nodes without a provider ID, can't be told apart so they aren't covered.
+func (c *commitments) price(instanceID string, instanceType ec2types.InstanceType, onDemandPrice float64) float64 {
+	if instanceID == "" {
+		return onDemandPrice
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if a, ok := c.assignments[instanceID]; ok && a.instanceType == instanceType {
+		return a.price
+	}
+
+	if r, ok := c.reservations[instanceType]; ok && r.used < r.count {
+		r.used++
+		c.assignments[instanceID] = assignment{instanceType: instanceType, price: r.price, reserved: true}
+		return r.price
+	}
+
+	if rate, ok := c.savingsPlanRates[instanceType]; ok && c.savingsPlanUsed+rate <= c.savingsPlanCommitment {
+		c.savingsPlanUsed += rate
+		c.assignments[instanceID] = assignment{instanceType: instanceType, price: rate}
+		return rate
+	}
+	return onDemandPrice
+}
+
+// release makes the coverage used by an instance available to other instances
+func (c *commitments) release(instanceID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a, ok := c.assignments[instanceID]
+	if !ok {
+		return
+	}
+	if a.reserved {
+		c.reservations[a.instanceType].used--
+	} else {
+		c.savingsPlanUsed -= a.price
+	}
+	delete(c.assignments, instanceID)
+}
+
+// reset replaces the known commitments, dropping any existing coverage so that it's reassigned as nodes are re-priced
+func (c *commitments) reset(reservations map[ec2types.InstanceType]*reservation, rates map[ec2types.InstanceType]float64, commitment float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reservations = reservations
+	c.savingsPlanRates = rates
+	c.savingsPlanCommitment = commitment
+	c.savingsPlanUsed = 0
+	c.assignments = map[string]assignment{}
+}
+
+func (p *pricingProvider) updateCommitments(ctx context.Context) (err error) {
+	ctx, span := tracing.StartPricingSpan(ctx, "UpdateCommitments")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	reservations, riErr := p.fetchReservations(ctx)
+	rates, commitment, spErr := p.fetchSavingsPlans(ctx)
+	if err := multierr.Append(riErr, spErr); err != nil {
+		return err
+	}
+	p.commitments.reset(reservations, rates, commitment)
+	return nil
+}
+
+func (p *pricingProvider) fetchReservations(ctx context.Context) (map[ec2types.InstanceType]*reservation, error) {
+	out, err := p.ec2.DescribeReservedInstancesWithContext(ctx, &ec2.DescribeReservedInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("state"),
+				Values: []*string{aws.String(ec2.ReservedInstanceStateActive)},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reservations := map[ec2types.InstanceType]*reservation{}
+	for _, ri := range out.ReservedInstances {
+		// amortize any upfront payment over the term of the reservation
+		hourly := aws.Float64Value(ri.UsagePrice)
+		if hours := float64(aws.Int64Value(ri.Duration)) / 3600; hours > 0 {
+			hourly += aws.Float64Value(ri.FixedPrice) / hours
+		}
+		for _, rc := range ri.RecurringCharges {
+			if aws.StringValue(rc.Frequency) == ec2.RecurringChargeFrequencyHourly {
+				hourly += aws.Float64Value(rc.Amount)
+			}
+		}
+
+		instanceType := ec2types.InstanceType(aws.StringValue(ri.InstanceType))
+		r, ok := reservations[instanceType]
+		if !ok {
+			r = &reservation{}
+			reservations[instanceType] = r
+		}
+		// track the average price if there are reservations of the same type with different terms
+		count := int(aws.Int64Value(ri.InstanceCount))
+		if r.count+count > 0 {
+			r.price = (r.price*float64(r.count) + hourly*float64(count)) / float64(r.count+count)
+		}
+		r.count += count
+	}
+	return reservations, nil
+}
+
+func (p *pricingProvider) fetchSavingsPlans(ctx context.Context) (map[ec2types.InstanceType]float64, float64, error) {
+	rates := map[ec2types.InstanceType]float64{}
+	commitment := 0.0
+
+	input := &savingsplans.DescribeSavingsPlansInput{
+		States: []*string{aws.String(savingsplans.SavingsPlanStateActive)},
+	}
+	for {
+		out, err := p.savingsPlans.DescribeSavingsPlansWithContext(ctx, input)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, sp := range out.SavingsPlans {
+			// EC2 instance savings plans are regional, compute savings plans apply everywhere
+			if region := aws.StringValue(sp.Region); region != "" && region != p.region {
+				continue
+			}
+			spCommitment, err := strconv.ParseFloat(aws.StringValue(sp.Commitment), 64)
+			if err != nil {
+				slog.Warn("unable to parse savings plan commitment", "commitment", aws.StringValue(sp.Commitment))
+				continue
+			}
+			if err := p.fetchSavingsPlanRates(ctx, aws.StringValue(sp.SavingsPlanId), rates); err != nil {
+				return nil, 0, err
+			}
+			commitment += spCommitment
+		}
+		if aws.StringValue(out.NextToken) == "" {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return rates, commitment, nil
+}
+
+// fetchSavingsPlanRates records the lowest Linux shared tenancy rate per instance type for the savings plan
+func (p *pricingProvider) fetchSavingsPlanRates(ctx context.Context, savingsPlanID string, rates map[ec2types.InstanceType]float64) error {
+	input := &savingsplans.DescribeSavingsPlanRatesInput{
+		SavingsPlanId: aws.String(savingsPlanID),
+		Filters: []*savingsplans.SavingsPlanRateFilter{
+			{
+				Name:   aws.String(savingsplans.SavingsPlanRateFilterNameRegion),
+				Values: []*string{aws.String(p.region)},
+			},
+			{
+				Name:   aws.String(savingsplans.SavingsPlanRateFilterNameProductType),
+				Values: []*string{aws.String(savingsplans.SavingsPlanProductTypeEc2)},
+			},
+			{
+				Name:   aws.String(savingsplans.SavingsPlanRateFilterNameProductDescription),
+				Values: []*string{aws.String("Linux/UNIX")},
+			},
+			{
+				Name:   aws.String(savingsplans.SavingsPlanRateFilterNameTenancy),
+				Values: []*string{aws.String("shared")},
+			},
+		},
+	}
+	for {
+		out, err := p.savingsPlans.DescribeSavingsPlanRatesWithContext(ctx, input)
+		if err != nil {
+			return err
+		}
+		for _, rate := range out.SearchResults {
+			price, err := strconv.ParseFloat(aws.StringValue(rate.Rate), 64)
+			if err != nil {
+				continue
+			}
+			for _, prop := range rate.Properties {
+				if aws.StringValue(prop.Name) != savingsplans.SavingsPlanRatePropertyKeyInstanceType {
+					continue
+				}
+				instanceType := ec2types.InstanceType(aws.StringValue(prop.Value))
+				if existing, ok := rates[instanceType]; !ok || price < existing {
+					rates[instanceType] = price
+				}
+			}
+		}
+		if aws.StringValue(out.NextToken) == "" {
+			return nil
+		}
+		input.NextToken = out.NextToken
+	}
+}
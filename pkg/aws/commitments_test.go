@@ -0,0 +1,280 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/savingsplans"
+	"github.com/aws/aws-sdk-go/service/savingsplans/savingsplansiface"
+)
+
+// fakeEC2 returns the reserved instances, the other EC2 APIs aren't used by the tests
+type fakeEC2 struct {
+	ec2iface.EC2API
+	reservedInstances []*ec2.ReservedInstances
+}
+
+func (f *fakeEC2) DescribeReservedInstancesWithContext(aws.Context, *ec2.DescribeReservedInstancesInput, ...request.Option) (*ec2.DescribeReservedInstancesOutput, error) {
+	return &ec2.DescribeReservedInstancesOutput{ReservedInstances: f.reservedInstances}, nil
+}
+
+// fakeSavingsPlans returns the savings plans and the rates of each savings plan a page at a time
+type fakeSavingsPlans struct {
+	savingsplansiface.SavingsPlansAPI
+	savingsPlans [][]*savingsplans.SavingsPlan
+	rates        map[string][][]*savingsplans.SavingsPlanRate
+}
+
+// page returns the page of the token and the token of the next page, which is empty after the last page
+func page(token *string, pages int) (int, *string) {
+	i := 0
+	if token != nil {
+		i = int(aws.StringValue(token)[0] - '0')
+	}
+	if i+1 < pages {
+		return i, aws.String(string(rune('0' + i + 1)))
+	}
+	return i, nil
+}
+
+func (f *fakeSavingsPlans) DescribeSavingsPlansWithContext(_ aws.Context, input *savingsplans.DescribeSavingsPlansInput, _ ...request.Option) (*savingsplans.DescribeSavingsPlansOutput, error) {
+	i, next := page(input.NextToken, len(f.savingsPlans))
+	return &savingsplans.DescribeSavingsPlansOutput{SavingsPlans: f.savingsPlans[i], NextToken: next}, nil
+}
+
+func (f *fakeSavingsPlans) DescribeSavingsPlanRatesWithContext(_ aws.Context, input *savingsplans.DescribeSavingsPlanRatesInput, _ ...request.Option) (*savingsplans.DescribeSavingsPlanRatesOutput, error) {
+	pages := f.rates[aws.StringValue(input.SavingsPlanId)]
+	i, next := page(input.NextToken, len(pages))
+	return &savingsplans.DescribeSavingsPlanRatesOutput{SearchResults: pages[i], NextToken: next}, nil
+}
+
+func savingsPlanRate(instanceType, rate string) *savingsplans.SavingsPlanRate {
+	return &savingsplans.SavingsPlanRate{
+		Rate: aws.String(rate),
+		Properties: []*savingsplans.SavingsPlanRateProperty{
+			{Name: aws.String(savingsplans.SavingsPlanRatePropertyKeyInstanceType), Value: aws.String(instanceType)},
+		},
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCommitmentsAllocation(t *testing.T) {
+	// each step prices an instance, or releases it when no price is expected
+	type step struct {
+		instanceID   string
+		instanceType ec2types.InstanceType
+		exp          float64
+	}
+	release := func(instanceID string) step { return step{instanceID: instanceID} }
+	for _, tc := range []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "reserved instances before savings plans",
+			steps: []step{
+				{"i-1", "m5.large", 0.06},
+				{"i-2", "m5.large", 0.07},
+				{"i-3", "c5.large", 0.05},
+			},
+		},
+		{
+			name: "savings plan commitment exhausted",
+			steps: []step{
+				{"i-1", "m5.large", 0.06},
+				{"i-2", "m5.large", 0.07},
+				{"i-3", "m5.large", 0.07},
+				{"i-4", "m5.large", 0.096},
+			},
+		},
+		{
+			name: "repriced instances keep their coverage",
+			steps: []step{
+				{"i-1", "m5.large", 0.06},
+				{"i-1", "m5.large", 0.06},
+				{"i-2", "m5.large", 0.07},
+			},
+		},
+		{
+			name: "instances without an ID aren't covered",
+			steps: []step{
+				{"", "m5.large", 0.096},
+				{"", "m5.large", 0.096},
+				{"i-1", "m5.large", 0.06},
+			},
+		},
+		{
+			name: "released reservations are reassigned",
+			steps: []step{
+				{"i-1", "m5.large", 0.06},
+				{"i-2", "m5.large", 0.07},
+				{"i-3", "m5.large", 0.07},
+				{"i-4", "m5.large", 0.096},
+				release("i-1"),
+				{"i-5", "m5.large", 0.06},
+			},
+		},
+		{
+			name: "released savings plan coverage is reassigned",
+			steps: []step{
+				{"i-1", "m5.large", 0.06},
+				{"i-2", "m5.large", 0.07},
+				{"i-3", "m5.large", 0.07},
+				{"i-4", "m5.large", 0.096},
+				release("i-2"),
+				{"i-5", "m5.large", 0.07},
+			},
+		},
+		{
+			name: "releasing unknown instances has no effect",
+			steps: []step{
+				{"i-1", "m5.large", 0.06},
+				{"i-2", "m5.large", 0.07},
+				{"i-3", "m5.large", 0.07},
+				release("i-9"),
+				release(""),
+				{"i-4", "m5.large", 0.096},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newCommitments()
+			// one m5.large reservation and a savings plan that covers two m5.large or three c5.large
+			c.reset(map[ec2types.InstanceType]*reservation{"m5.large": {count: 1, price: 0.06}},
+				map[ec2types.InstanceType]float64{"m5.large": 0.07, "c5.large": 0.05}, 0.15)
+			onDemand := map[ec2types.InstanceType]float64{"m5.large": 0.096, "c5.large": 0.085}
+			for _, s := range tc.steps {
+				if s.instanceType == "" {
+					c.release(s.instanceID)
+					continue
+				}
+				if got := c.price(s.instanceID, s.instanceType, onDemand[s.instanceType]); !approxEqual(got, s.exp) {
+					t.Errorf("expected %s %q to be priced at %f, got %f", s.instanceType, s.instanceID, s.exp, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchReservations(t *testing.T) {
+	p := &pricingProvider{ec2: &fakeEC2{reservedInstances: []*ec2.ReservedInstances{
+		// no upfront, billed hourly
+		{
+			InstanceType:  aws.String("m5.large"),
+			InstanceCount: aws.Int64(2),
+			Duration:      aws.Int64(365 * 24 * 3600),
+			FixedPrice:    aws.Float64(0),
+			RecurringCharges: []*ec2.RecurringCharge{
+				{Frequency: aws.String(ec2.RecurringChargeFrequencyHourly), Amount: aws.Float64(0.06)},
+			},
+		},
+		// all upfront, amortized over the one year term
+		{
+			InstanceType:  aws.String("m5.large"),
+			InstanceCount: aws.Int64(1),
+			Duration:      aws.Int64(365 * 24 * 3600),
+			FixedPrice:    aws.Float64(438),
+		},
+		// partial upfront over three years, along with the usage price of older reservations
+		{
+			InstanceType:  aws.String("c5.large"),
+			InstanceCount: aws.Int64(1),
+			Duration:      aws.Int64(3 * 365 * 24 * 3600),
+			FixedPrice:    aws.Float64(525.6),
+			UsagePrice:    aws.Float64(0.01),
+			RecurringCharges: []*ec2.RecurringCharge{
+				{Frequency: aws.String(ec2.RecurringChargeFrequencyHourly), Amount: aws.Float64(0.02)},
+			},
+		},
+	}}}
+	reservations, err := p.fetchReservations(context.Background())
+	if err != nil {
+		t.Fatalf("fetching reservations, %s", err)
+	}
+	for _, tc := range []struct {
+		instanceType ec2types.InstanceType
+		count        int
+		price        float64
+	}{
+		// 438/8760 = 0.05 an hour, averaged with two at 0.06
+		{"m5.large", 3, (0.06*2 + 0.05) / 3},
+		// 525.6/26280 = 0.02 an hour, plus 0.01 usage and 0.02 recurring
+		{"c5.large", 1, 0.05},
+	} {
+		r, ok := reservations[tc.instanceType]
+		if !ok {
+			t.Errorf("expected reservations of %s", tc.instanceType)
+			continue
+		}
+		if r.count != tc.count || !approxEqual(r.price, tc.price) {
+			t.Errorf("expected %d %s reservations at %f, got %d at %f", tc.count, tc.instanceType, tc.price, r.count, r.price)
+		}
+	}
+}
+
+func TestFetchSavingsPlans(t *testing.T) {
+	p := &pricingProvider{region: "us-west-2", savingsPlans: &fakeSavingsPlans{
+		savingsPlans: [][]*savingsplans.SavingsPlan{
+			{
+				// a compute savings plan applies in every region
+				{SavingsPlanId: aws.String("compute"), Commitment: aws.String("1.5")},
+				// an EC2 instance savings plan in another region is ignored
+				{SavingsPlanId: aws.String("other-region"), Commitment: aws.String("10"), Region: aws.String("eu-west-1")},
+			},
+			{
+				{SavingsPlanId: aws.String("instance"), Commitment: aws.String("0.5"), Region: aws.String("us-west-2")},
+				{SavingsPlanId: aws.String("invalid"), Commitment: aws.String("n/a")},
+			},
+		},
+		rates: map[string][][]*savingsplans.SavingsPlanRate{
+			"compute": {
+				{savingsPlanRate("m5.large", "0.07"), savingsPlanRate("c5.large", "0.06")},
+				{savingsPlanRate("r5.large", "0.09")},
+			},
+			"instance": {
+				// the lowest rate of the savings plans is used
+				{savingsPlanRate("m5.large", "0.065"), savingsPlanRate("c5.large", "0.08")},
+			},
+			"other-region": {{savingsPlanRate("m5.large", "0.01")}},
+			"invalid":      {{savingsPlanRate("m5.large", "0.01")}},
+		},
+	}}
+	rates, commitment, err := p.fetchSavingsPlans(context.Background())
+	if err != nil {
+		t.Fatalf("fetching savings plans, %s", err)
+	}
+	if !approxEqual(commitment, 2) {
+		t.Errorf("expected a commitment of 2, got %f", commitment)
+	}
+	for instanceType, exp := range map[ec2types.InstanceType]float64{"m5.large": 0.065, "c5.large": 0.06, "r5.large": 0.09} {
+		if got := rates[instanceType]; !approxEqual(got, exp) {
+			t.Errorf("expected the %s rate to be %f, got %f", instanceType, exp, got)
+		}
+	}
+	if len(rates) != 3 {
+		t.Errorf("expected 3 rates, got %v", rates)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
+)
+
+// maxMetricDataQueries is the maximum number of queries that can be passed to a single GetMetricData call
+const maxMetricDataQueries = 500
+
+// cpuCreditPeriod is the period of the CPUCreditBalance metric, which is published every five minutes
+const cpuCreditPeriod = 5 * time.Minute
+
+// CPUCreditSource looks up the CPU credit balance of burstable instances from CloudWatch
+type CPUCreditSource struct {
+	cloudWatch cloudwatchiface.CloudWatchAPI
+}
+
+// NewCPUCreditSource returns a source of the CPU credit balance of burstable instances
+func NewCPUCreditSource(sess *session.Session) *CPUCreditSource {
+	return &CPUCreditSource{cloudWatch: cloudwatch.New(sess)}
+}
+
+// CPUCreditBalances returns the latest CPU credit balance of each of the instances, instances without a recent data
+// point are omitted
+func (c *CPUCreditSource) CPUCreditBalances(ctx context.Context, instanceIDs []string) (balances map[string]float64, err error) {
+	ctx, span := tracing.StartSpan(ctx, "GetCPUCreditBalances", attribute.Int("instances", len(instanceIDs)))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	balances = map[string]float64{}
+	for start := 0; start < len(instanceIDs); start += maxMetricDataQueries {
+		end := min(start+maxMetricDataQueries, len(instanceIDs))
+		if err := c.getBalances(ctx, instanceIDs[start:end], balances); err != nil {
+			return balances, err
+		}
+	}
+	return balances, nil
+}
+
+func (c *CPUCreditSource) getBalances(ctx context.Context, instanceIDs []string, balances map[string]float64) error {
+	// query IDs must start with a lowercase letter and can't contain the instance ID's dash
+	var queries []*cloudwatch.MetricDataQuery
+	for i, id := range instanceIDs {
+		queries = append(queries, &cloudwatch.MetricDataQuery{
+			Id: aws.String(fmt.Sprintf("q%d", i)),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: &cloudwatch.Metric{
+					Namespace:  aws.String("AWS/EC2"),
+					MetricName: aws.String("CPUCreditBalance"),
+					Dimensions: []*cloudwatch.Dimension{{Name: aws.String("InstanceId"), Value: aws.String(id)}},
+				},
+				Period: aws.Int64(int64(cpuCreditPeriod.Seconds())),
+				Stat:   aws.String(cloudwatch.StatisticMinimum),
+			},
+		})
+	}
+	now := time.Now()
+	return c.cloudWatch.GetMetricDataPagesWithContext(ctx, &cloudwatch.GetMetricDataInput{
+		MetricDataQueries: queries,
+		StartTime:         aws.Time(now.Add(-3 * cpuCreditPeriod)),
+		EndTime:           aws.Time(now),
+		ScanBy:            aws.String(cloudwatch.ScanByTimestampDescending),
+	}, func(output *cloudwatch.GetMetricDataOutput, b bool) bool {
+		for _, r := range output.MetricDataResults {
+			var i int
+			if _, err := fmt.Sscanf(aws.StringValue(r.Id), "q%d", &i); err != nil || i >= len(instanceIDs) {
+				continue
+			}
+			// values are sorted newest first, and a later page can only contain older values
+			if _, ok := balances[instanceIDs[i]]; !ok && len(r.Values) > 0 {
+				balances[instanceIDs[i]] = aws.Float64Value(r.Values[0])
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,133 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+	nvp "github.com/awslabs/eks-node-viewer/pkg/pricing"
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
+)
+
+// instanceTypeUpdatePeriod is how often the instance types are described after the initial update on startup
+const instanceTypeUpdatePeriod = 24 * time.Hour
+
+type instanceTypeProvider struct {
+	ec2 ec2iface.EC2API
+
+	mu            sync.RWMutex
+	onUpdateFuncs []func()
+	instanceTypes map[ec2types.InstanceType]model.InstanceTypeInfo
+}
+
+// NewStaticInstanceTypeProvider returns a provider that only uses the static table of common instance types
+func NewStaticInstanceTypeProvider() nvp.InstanceTypeProvider {
+	return &instanceTypeProvider{instanceTypes: map[ec2types.InstanceType]model.InstanceTypeInfo{}}
+}
+
+// NewInstanceTypeProvider returns a provider that looks up the hardware of instance types via EC2
+// DescribeInstanceTypes, falling back to the static table of common instance types until the lookup completes or if it
+// fails
+func NewInstanceTypeProvider(ctx context.Context, sess *session.Session) nvp.InstanceTypeProvider {
+	p := &instanceTypeProvider{
+		ec2:           ec2.New(sess),
+		instanceTypes: map[ec2types.InstanceType]model.InstanceTypeInfo{},
+	}
+	go func() {
+		for {
+			if err := p.updateInstanceTypes(ctx); err != nil {
+				slog.Error("describing instance types", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(instanceTypeUpdatePeriod):
+			}
+		}
+	}()
+	return p
+}
+
+func (p *instanceTypeProvider) OnUpdate(onUpdate func()) {
+	p.onUpdateFuncs = append(p.onUpdateFuncs, onUpdate)
+}
+
+// InstanceTypeInfo returns the hardware of the instance type, or false if it's unknown
+func (p *instanceTypeProvider) InstanceTypeInfo(instanceType ec2types.InstanceType) (model.InstanceTypeInfo, bool) {
+	p.mu.RLock()
+	info, ok := p.instanceTypes[instanceType]
+	p.mu.RUnlock()
+	if ok {
+		return info, true
+	}
+	return staticInstanceTypeInfo(instanceType)
+}
+
+func (p *instanceTypeProvider) updateInstanceTypes(ctx context.Context) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "DescribeInstanceTypes")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	instanceTypes := map[ec2types.InstanceType]model.InstanceTypeInfo{}
+	err = p.ec2.DescribeInstanceTypesPagesWithContext(ctx, &ec2.DescribeInstanceTypesInput{},
+		func(output *ec2.DescribeInstanceTypesOutput, b bool) bool {
+			for _, it := range output.InstanceTypes {
+				instanceTypes[ec2types.InstanceType(aws.StringValue(it.InstanceType))] = instanceTypeInfo(it)
+			}
+			return true
+		})
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.instanceTypes = instanceTypes
+	p.mu.Unlock()
+	for _, f := range p.onUpdateFuncs {
+		f()
+	}
+	return nil
+}
+
+// instanceTypeInfo returns the hardware of an instance type described by EC2
+func instanceTypeInfo(it *ec2.InstanceTypeInfo) model.InstanceTypeInfo {
+	var info model.InstanceTypeInfo
+	if it.VCpuInfo != nil {
+		info.VCPUs = aws.Int64Value(it.VCpuInfo.DefaultVCpus)
+	}
+	if it.MemoryInfo != nil {
+		info.MemoryMiB = aws.Int64Value(it.MemoryInfo.SizeInMiB)
+	}
+	if it.NetworkInfo != nil {
+		info.NetworkPerformance = aws.StringValue(it.NetworkInfo.NetworkPerformance)
+		info.MaxENIs = aws.Int64Value(it.NetworkInfo.MaximumNetworkInterfaces)
+		info.IPv4PerENI = aws.Int64Value(it.NetworkInfo.Ipv4AddressesPerInterface)
+	}
+	if it.GpuInfo != nil {
+		for _, gpu := range it.GpuInfo.Gpus {
+			info.GPUs += aws.Int64Value(gpu.Count)
+		}
+	}
+	return info
+}
@@ -0,0 +1,122 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"strconv"
+	"strings"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// staticFamily describes the hardware of the sizes of an instance family for use when EC2 DescribeInstanceTypes isn't
+// available. Most families scale their memory and GPUs with the size, sizes that don't are listed explicitly.
+type staticFamily struct {
+	memoryGiBPerVCPU float64
+	gpus             int64
+	sizes            map[string]model.InstanceTypeInfo
+}
+
+// burstableSizes are the sizes of the t3, t3a and t4g families, which all have 2 vCPUs up to the large size
+var burstableSizes = map[string]model.InstanceTypeInfo{
+	"nano":    {VCPUs: 2, MemoryMiB: 512},
+	"micro":   {VCPUs: 2, MemoryMiB: 1024},
+	"small":   {VCPUs: 2, MemoryMiB: 2048},
+	"medium":  {VCPUs: 2, MemoryMiB: 4096},
+	"large":   {VCPUs: 2, MemoryMiB: 8192},
+	"xlarge":  {VCPUs: 4, MemoryMiB: 16384},
+	"2xlarge": {VCPUs: 8, MemoryMiB: 32768},
+}
+
+// multiGPUSizes are the sizes of the g5 and g6 families with more than one GPU
+var multiGPUSizes = map[string]model.InstanceTypeInfo{
+	"12xlarge": {VCPUs: 48, MemoryMiB: 196608, GPUs: 4},
+	"24xlarge": {VCPUs: 96, MemoryMiB: 393216, GPUs: 4},
+	"48xlarge": {VCPUs: 192, MemoryMiB: 786432, GPUs: 8},
+}
+
+// staticFamilies are the instance families commonly used by EKS nodes, the network performance of each size isn't
+// known without EC2
+var staticFamilies = map[string]staticFamily{
+	"c5": {memoryGiBPerVCPU: 2}, "c5a": {memoryGiBPerVCPU: 2}, "c5d": {memoryGiBPerVCPU: 2},
+	"c6a": {memoryGiBPerVCPU: 2}, "c6g": {memoryGiBPerVCPU: 2}, "c6gd": {memoryGiBPerVCPU: 2},
+	"c6i": {memoryGiBPerVCPU: 2}, "c6id": {memoryGiBPerVCPU: 2}, "c7a": {memoryGiBPerVCPU: 2},
+	"c7g": {memoryGiBPerVCPU: 2}, "c7gd": {memoryGiBPerVCPU: 2}, "c7i": {memoryGiBPerVCPU: 2},
+	"c8g": {memoryGiBPerVCPU: 2},
+	"m5":  {memoryGiBPerVCPU: 4}, "m5a": {memoryGiBPerVCPU: 4}, "m5d": {memoryGiBPerVCPU: 4},
+	"m6a": {memoryGiBPerVCPU: 4}, "m6g": {memoryGiBPerVCPU: 4}, "m6gd": {memoryGiBPerVCPU: 4},
+	"m6i": {memoryGiBPerVCPU: 4}, "m6id": {memoryGiBPerVCPU: 4}, "m7a": {memoryGiBPerVCPU: 4},
+	"m7g": {memoryGiBPerVCPU: 4}, "m7gd": {memoryGiBPerVCPU: 4}, "m7i": {memoryGiBPerVCPU: 4},
+	"m8g": {memoryGiBPerVCPU: 4},
+	"r5":  {memoryGiBPerVCPU: 8}, "r5a": {memoryGiBPerVCPU: 8}, "r5d": {memoryGiBPerVCPU: 8},
+	"r6a": {memoryGiBPerVCPU: 8}, "r6g": {memoryGiBPerVCPU: 8}, "r6gd": {memoryGiBPerVCPU: 8},
+	"r6i": {memoryGiBPerVCPU: 8}, "r6id": {memoryGiBPerVCPU: 8}, "r7a": {memoryGiBPerVCPU: 8},
+	"r7g": {memoryGiBPerVCPU: 8}, "r7gd": {memoryGiBPerVCPU: 8}, "r7i": {memoryGiBPerVCPU: 8},
+	"r8g": {memoryGiBPerVCPU: 8},
+	"t3":  {sizes: burstableSizes}, "t3a": {sizes: burstableSizes}, "t4g": {sizes: burstableSizes},
+	"g4dn": {memoryGiBPerVCPU: 4, gpus: 1, sizes: map[string]model.InstanceTypeInfo{
+		"12xlarge": {VCPUs: 48, MemoryMiB: 196608, GPUs: 4},
+	}},
+	"g5": {memoryGiBPerVCPU: 4, gpus: 1, sizes: multiGPUSizes},
+	"g6": {memoryGiBPerVCPU: 4, gpus: 1, sizes: multiGPUSizes},
+}
+
+// staticInstanceTypeInfo returns the hardware of an instance type from the static table, or false if its family or
+// size isn't in the table, e.g. metal sizes
+func staticInstanceTypeInfo(instanceType ec2types.InstanceType) (model.InstanceTypeInfo, bool) {
+	name, size, _ := strings.Cut(string(instanceType), ".")
+	family, ok := staticFamilies[name]
+	if !ok {
+		return model.InstanceTypeInfo{}, false
+	}
+	if info, ok := family.sizes[size]; ok {
+		return info, true
+	}
+	if family.memoryGiBPerVCPU == 0 {
+		return model.InstanceTypeInfo{}, false
+	}
+	vcpus, ok := sizeVCPUs(size)
+	if !ok {
+		return model.InstanceTypeInfo{}, false
+	}
+	return model.InstanceTypeInfo{
+		VCPUs:     vcpus,
+		MemoryMiB: int64(float64(vcpus) * family.memoryGiBPerVCPU * 1024),
+		GPUs:      family.gpus,
+	}, true
+}
+
+// sizeVCPUs returns the vCPUs of an instance size that scales with the size, e.g. 2 for large and 32 for 8xlarge
+func sizeVCPUs(size string) (int64, bool) {
+	switch size {
+	case "medium":
+		return 1, true
+	case "large":
+		return 2, true
+	case "xlarge":
+		return 4, true
+	}
+	multiple, ok := strings.CutSuffix(size, "xlarge")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(multiple, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return 4 * n, true
+}
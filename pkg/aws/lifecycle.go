@@ -0,0 +1,172 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"go.opentelemetry.io/otel/attribute"
+
+	nvp "github.com/awslabs/eks-node-viewer/pkg/pricing"
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
+)
+
+// lifecycleUpdatePeriod is how often we look up the lifecycle of newly discovered instances
+const lifecycleUpdatePeriod = 15 * time.Second
+
+// maxDescribeInstanceIDs is the maximum number of instance IDs that can be passed to a single DescribeInstances call
+const maxDescribeInstanceIDs = 1000
+
+type lifecycleProvider struct {
+	ec2 ec2iface.EC2API
+
+	mu            sync.RWMutex
+	onUpdateFuncs []func()
+	lifecycles    map[string]string
+	tenancies     map[string]string
+	pending       map[string]struct{}
+}
+
+// instanceInfo is what's looked up about an instance with DescribeInstances
+type instanceInfo struct {
+	lifecycle string
+	tenancy   string
+}
+
+// NewLifecycleProvider returns a provider that looks up the purchase option and tenancy of instances via EC2
+// DescribeInstances. Instances are looked up in batches in the background the first time they are requested.
+func NewLifecycleProvider(ctx context.Context, sess *session.Session) nvp.TenancyProvider {
+	l := &lifecycleProvider{
+		ec2:        ec2.New(sess),
+		lifecycles: map[string]string{},
+		tenancies:  map[string]string{},
+		pending:    map[string]struct{}{},
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(lifecycleUpdatePeriod):
+				l.updateLifecycles(ctx)
+			}
+		}
+	}()
+	return l
+}
+
+func (l *lifecycleProvider) OnUpdate(onUpdate func()) {
+	l.onUpdateFuncs = append(l.onUpdateFuncs, onUpdate)
+}
+
+// InstanceLifecycle returns the purchase option, either "spot" or "on-demand", of the instance. If it isn't known yet,
+// the instance is queued to be looked up and false is returned.
+func (l *lifecycleProvider) InstanceLifecycle(instanceID string) (string, bool) {
+	// fargate and other non-EC2 nodes don't have an instance to look up
+	if !strings.HasPrefix(instanceID, "i-") {
+		return "", false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lifecycle, ok := l.lifecycles[instanceID]; ok {
+		return lifecycle, true
+	}
+	l.pending[instanceID] = struct{}{}
+	return "", false
+}
+
+// InstanceTenancy returns the tenancy, one of "default", "dedicated" or "host", of the instance. It's looked up along
+// with the purchase option, so false is returned until InstanceLifecycle has been called and the lookup completes.
+func (l *lifecycleProvider) InstanceTenancy(instanceID string) (string, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	tenancy, ok := l.tenancies[instanceID]
+	return tenancy, ok
+}
+
+func (l *lifecycleProvider) updateLifecycles(ctx context.Context) {
+	l.mu.Lock()
+	var instanceIDs []*string
+	for id := range l.pending {
+		instanceIDs = append(instanceIDs, aws.String(id))
+	}
+	l.pending = map[string]struct{}{}
+	l.mu.Unlock()
+
+	if len(instanceIDs) == 0 {
+		return
+	}
+
+	found := false
+	for start := 0; start < len(instanceIDs); start += maxDescribeInstanceIDs {
+		end := start + maxDescribeInstanceIDs
+		if end > len(instanceIDs) {
+			end = len(instanceIDs)
+		}
+		instances, err := l.describeInstances(ctx, instanceIDs[start:end])
+		if err != nil {
+			slog.Error("describing instances", "error", err)
+			continue
+		}
+		l.mu.Lock()
+		for id, info := range instances {
+			l.lifecycles[id] = info.lifecycle
+			l.tenancies[id] = info.tenancy
+			found = true
+		}
+		l.mu.Unlock()
+	}
+
+	if found {
+		for _, f := range l.onUpdateFuncs {
+			f()
+		}
+	}
+}
+
+func (l *lifecycleProvider) describeInstances(ctx context.Context, instanceIDs []*string) (instances map[string]instanceInfo, err error) {
+	ctx, span := tracing.StartSpan(ctx, "DescribeInstances", attribute.Int("instances", len(instanceIDs)))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	instances = map[string]instanceInfo{}
+	err = l.ec2.DescribeInstancesPagesWithContext(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: instanceIDs,
+	}, func(output *ec2.DescribeInstancesOutput, b bool) bool {
+		for _, r := range output.Reservations {
+			for _, inst := range r.Instances {
+				// instances launched as on-demand have no lifecycle set
+				lifecycle := "on-demand"
+				if aws.StringValue(inst.InstanceLifecycle) == ec2.InstanceLifecycleTypeSpot {
+					lifecycle = "spot"
+				}
+				tenancy := ec2.TenancyDefault
+				if inst.Placement != nil && inst.Placement.Tenancy != nil {
+					tenancy = aws.StringValue(inst.Placement.Tenancy)
+				}
+				instances[aws.StringValue(inst.InstanceId)] = instanceInfo{lifecycle: lifecycle, tenancy: tenancy}
+			}
+		}
+		return true
+	})
+	return instances, err
+}
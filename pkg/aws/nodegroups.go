@@ -0,0 +1,113 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
+)
+
+// ManagedNodeGroupSource looks up the capacity of a cluster's managed node groups from the EKS API and the instances
+// they've launched from the Auto Scaling API
+type ManagedNodeGroupSource struct {
+	clusterName string
+	eks         eksiface.EKSAPI
+	autoScaling autoscalingiface.AutoScalingAPI
+}
+
+// NewManagedNodeGroupSource returns a source of the capacity of the managed node groups of an EKS cluster, the region
+// of the session is used if region is empty
+func NewManagedNodeGroupSource(sess *session.Session, clusterName, region string) *ManagedNodeGroupSource {
+	sess = RegionalSession(sess, region)
+	return &ManagedNodeGroupSource{
+		clusterName: clusterName,
+		eks:         eks.New(sess),
+		autoScaling: autoscaling.New(sess),
+	}
+}
+
+// ManagedNodeGroups returns the managed node groups of the cluster along with the number of instances in service in
+// each of their Auto Scaling groups
+func (s *ManagedNodeGroupSource) ManagedNodeGroups(ctx context.Context) (groups []model.ManagedNodeGroup, err error) {
+	ctx, span := tracing.StartSpan(ctx, "GetManagedNodeGroups", attribute.String("cluster", s.clusterName))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var names []*string
+	if err := s.eks.ListNodegroupsPagesWithContext(ctx, &eks.ListNodegroupsInput{ClusterName: aws.String(s.clusterName)},
+		func(output *eks.ListNodegroupsOutput, b bool) bool {
+			names = append(names, output.Nodegroups...)
+			return true
+		}); err != nil {
+		return nil, err
+	}
+
+	// the instances of each node group are counted across all of its Auto Scaling groups
+	asgNodeGroup := map[string]int{}
+	var asgNames []*string
+	for _, name := range names {
+		output, err := s.eks.DescribeNodegroupWithContext(ctx, &eks.DescribeNodegroupInput{
+			ClusterName:   aws.String(s.clusterName),
+			NodegroupName: name,
+		})
+		if err != nil {
+			return nil, err
+		}
+		ng := output.Nodegroup
+		group := model.ManagedNodeGroup{Name: aws.StringValue(ng.NodegroupName), Status: aws.StringValue(ng.Status)}
+		if sc := ng.ScalingConfig; sc != nil {
+			group.Desired = int(aws.Int64Value(sc.DesiredSize))
+			group.Min = int(aws.Int64Value(sc.MinSize))
+			group.Max = int(aws.Int64Value(sc.MaxSize))
+		}
+		if ng.Resources != nil {
+			for _, asg := range ng.Resources.AutoScalingGroups {
+				asgNodeGroup[aws.StringValue(asg.Name)] = len(groups)
+				asgNames = append(asgNames, asg.Name)
+			}
+		}
+		groups = append(groups, group)
+	}
+	if len(asgNames) == 0 {
+		return groups, nil
+	}
+
+	err = s.autoScaling.DescribeAutoScalingGroupsPagesWithContext(ctx, &autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: asgNames,
+	}, func(output *autoscaling.DescribeAutoScalingGroupsOutput, b bool) bool {
+		for _, asg := range output.AutoScalingGroups {
+			i, ok := asgNodeGroup[aws.StringValue(asg.AutoScalingGroupName)]
+			if !ok {
+				continue
+			}
+			for _, instance := range asg.Instances {
+				if aws.StringValue(instance.LifecycleState) == autoscaling.LifecycleStateInService {
+					groups[i].Instances++
+				}
+			}
+		}
+		return true
+	})
+	return groups, err
+}
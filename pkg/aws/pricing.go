@@ -18,7 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"log"
+	"log/slog"
 	"math"
 	"os"
 	"strconv"
@@ -33,23 +33,38 @@ import (
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/pricing"
 	"github.com/aws/aws-sdk-go/service/pricing/pricingiface"
+	"github.com/aws/aws-sdk-go/service/savingsplans"
+	"github.com/aws/aws-sdk-go/service/savingsplans/savingsplansiface"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/multierr"
 
 	"github.com/awslabs/eks-node-viewer/pkg/model"
 	nvp "github.com/awslabs/eks-node-viewer/pkg/pricing"
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
 )
 
 type pricingProvider struct {
-	ec2     ec2iface.EC2API
-	pricing pricingiface.PricingAPI
-	region  string
+	ec2          ec2iface.EC2API
+	pricing      pricingiface.PricingAPI
+	savingsPlans savingsplansiface.SavingsPlansAPI
+	region       string
+	commitments  *commitments
+	// cachePath is the file that prices are cached in between runs, caching is disabled if it's empty
+	cachePath string
 
 	mu                      sync.RWMutex
 	onUpdateFuncs           []func()
 	onDemandPrices          map[ec2types.InstanceType]float64
+	dedicatedPrices         map[ec2types.InstanceType]float64
 	spotPrices              map[ec2types.InstanceType]zonalPricing
 	fargateVCPUPricePerHour float64
 	fargateGBPricePerHour   float64
+	// fargateStoragePricePerHour is the price per GB of ephemeral storage beyond what Fargate provides for free
+	fargateStoragePricePerHour float64
+	// windowsOnDemandPrices and windowsSpotPrices include the Windows license, Windows nodes are unpriced until
+	// they're fetched as the static prices are for Linux
+	windowsOnDemandPrices map[ec2types.InstanceType]float64
+	windowsSpotPrices     map[ec2types.InstanceType]zonalPricing
 }
 
 func (p *pricingProvider) OnUpdate(onUpdate func()) {
@@ -57,18 +72,40 @@ func (p *pricingProvider) OnUpdate(onUpdate func()) {
 }
 
 func (p *pricingProvider) NodePrice(n *model.Node) (float64, bool) {
-	if n.IsOnDemand() {
+	switch n.Tenancy() {
+	case model.TenancyHost:
+		// dedicated hosts are billed for the whole host, not the instances running on them
+		return math.NaN(), false
+	}
+	if n.OperatingSystem() == model.OSWindows {
+		return p.windowsNodePrice(n)
+	}
+	switch n.Tenancy() {
+	case model.TenancyDedicated:
+		if price, ok := p.DedicatedPrice(n.InstanceType()); ok {
+			return price, true
+		}
+		return math.NaN(), false
+	}
+	isOnDemand, isSpot := n.IsOnDemand(), n.IsSpot()
+	// price based on how EC2 says the instance was launched rather than how it's labeled
+	if n.CapacityTypeMismatch() {
+		isOnDemand, isSpot = isSpot, isOnDemand
+	}
+	if isOnDemand {
 		if price, ok := p.OnDemandPrice(n.InstanceType()); ok {
+			if p.commitments != nil {
+				price = p.commitments.price(n.InstanceID(), n.InstanceType(), price)
+			}
 			return price, true
 		}
-	} else if n.IsSpot() {
+	} else if isSpot {
 		if price, ok := p.SpotPrice(n.InstanceType(), n.Zone()); ok {
 			return price, true
 		}
-	} else if n.IsFargate() && len(n.Pods()) == 1 {
-		cpu, mem, ok := n.Pods()[0].FargateCapacityProvisioned()
-		if ok {
-			if price, ok := p.FargatePrice(cpu, mem); ok {
+	} else if n.IsFargate() {
+		if capacity, ok := n.FargateCapacity(); ok {
+			if price, ok := p.FargatePrice(capacity); ok {
 				return price, true
 			}
 		}
@@ -76,12 +113,42 @@ func (p *pricingProvider) NodePrice(n *model.Node) (float64, bool) {
 	return math.NaN(), false
 }
 
+// windowsNodePrice returns the price of a Windows node including the Windows license. Reserved Instances and Savings
+// Plans aren't applied as they're matched against Linux prices, and dedicated Windows nodes are unpriced.
+func (p *pricingProvider) windowsNodePrice(n *model.Node) (float64, bool) {
+	if n.Tenancy() == model.TenancyDedicated {
+		return math.NaN(), false
+	}
+	isOnDemand, isSpot := n.IsOnDemand(), n.IsSpot()
+	if n.CapacityTypeMismatch() {
+		isOnDemand, isSpot = isSpot, isOnDemand
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if isOnDemand {
+		if price, ok := p.windowsOnDemandPrices[n.InstanceType()]; ok {
+			return price, true
+		}
+	} else if isSpot {
+		if price, ok := p.windowsSpotPrices[n.InstanceType()].price(n.Zone()); ok {
+			return price, true
+		}
+	}
+	return math.NaN(), false
+}
+
+func (p *pricingProvider) NodeDeleted(n *model.Node) {
+	if p.commitments != nil {
+		p.commitments.release(n.InstanceID())
+	}
+}
+
 // zonalPricing is used to capture the per-zone price
 // for spot data as well as the default price
-// based on on-demand price when the controller first
-// comes up
+// based on on-demand price which is used for zones
+// without spot data, e.g. newly added ones
 type zonalPricing struct {
-	defaultPrice float64 // Used until we get the spot pricing data
+	defaultPrice float64 // Used for zones without spot pricing data
 	prices       map[string]float64
 }
 
@@ -93,6 +160,17 @@ func newZonalPricing(defaultPrice float64) zonalPricing {
 	return z
 }
 
+// price returns the price in a zone, falling back to the default price for zones without spot pricing data
+func (z zonalPricing) price(zone string) (float64, bool) {
+	if price, ok := z.prices[zone]; ok {
+		return price, true
+	}
+	if z.defaultPrice == 0 {
+		return 0.0, false
+	}
+	return z.defaultPrice, true
+}
+
 // pricingUpdatePeriod is how often we try to update our pricing information after the initial update on startup
 const pricingUpdatePeriod = 12 * time.Hour
 
@@ -119,6 +197,16 @@ var allPrices = []map[string]map[ec2types.InstanceType]float64{
 	InitialOnDemandPricesCN,
 }
 
+// hasEmbeddedPrices returns true if the region has on-demand prices embedded at build time
+func hasEmbeddedPrices(region string) bool {
+	for _, priceSet := range allPrices {
+		if _, ok := priceSet[region]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 func getStaticPrices(region string) map[ec2types.InstanceType]float64 {
 	for _, priceSet := range allPrices {
 		if prices, ok := priceSet[region]; ok {
@@ -128,47 +216,109 @@ func getStaticPrices(region string) map[ec2types.InstanceType]float64 {
 	return InitialOnDemandPricesAWS["us-east-1"]
 }
 
-func NewStaticPricingProvider() nvp.Provider {
-	region := os.Getenv("AWS_REGION")
+// NewStaticPricingProvider returns a provider of the on-demand prices embedded at build time for a region, or for the
+// region in AWS_REGION if it's empty
+func NewStaticPricingProvider(region string) nvp.Provider {
+	return NewStaticPricingProviderWithPrices(region, StaticPrices{})
+}
+
+// NewStaticPricingProviderWithPrices returns a provider of the on-demand prices embedded at build time for a region,
+// updated with the region's prices from a static prices file or pricing bundle, e.g. one written by generate-prices
+func NewStaticPricingProviderWithPrices(region string, static StaticPrices) nvp.Provider {
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
 	if region == "" {
 		region = "us-east-1"
 	}
 
+	prices := map[ec2types.InstanceType]float64{}
+	// regions that only the static prices file has prices for, e.g. in partitions without embedded prices, don't fall
+	// back to the embedded prices of us-east-1
+	if _, ok := static.Regions[region]; !ok || hasEmbeddedPrices(region) {
+		for it, price := range getStaticPrices(region) {
+			prices[it] = price
+		}
+	}
+	for it, price := range static.Regions[region] {
+		prices[it] = price
+	}
 	return &pricingProvider{
-		onDemandPrices: getStaticPrices(region),
-		spotPrices:     map[ec2types.InstanceType]zonalPricing{},
+		onDemandPrices:    prices,
+		spotPrices:        map[ec2types.InstanceType]zonalPricing{},
+		windowsSpotPrices: map[ec2types.InstanceType]zonalPricing{},
 	}
 }
 
-func NewPricingProvider(ctx context.Context, sess *session.Session) nvp.Provider {
+// NewPricingProvider returns a provider that periodically updates prices from the AWS pricing and EC2 APIs, nodes have
+// no price until the prices are loaded from the cache or the APIs so that the static prices can be used instead. If
+// includeCommitments is true, the account's Reserved Instances and Savings Plans are used to display the effective
+// price of on-demand nodes rather than the public on-demand rate. If cacheTTL is non-zero, prices are cached on disk
+// and the APIs aren't called at startup while the cached prices are younger than cacheTTL.
+func NewPricingProvider(ctx context.Context, sess *session.Session, includeCommitments bool, cacheTTL time.Duration) nvp.Provider {
 	region := "us-west-2"
 	if aws.StringValue(sess.Config.Region) != "" {
 		region = aws.StringValue(sess.Config.Region)
 	}
 	p := &pricingProvider{
-		region:         region,
-		onDemandPrices: getStaticPrices(region),
-		spotPrices:     map[ec2types.InstanceType]zonalPricing{},
-		ec2:            ec2.New(sess),
-		pricing:        NewPricingAPI(sess, region),
+		region:            region,
+		onDemandPrices:    map[ec2types.InstanceType]float64{},
+		spotPrices:        map[ec2types.InstanceType]zonalPricing{},
+		windowsSpotPrices: map[ec2types.InstanceType]zonalPricing{},
+		ec2:               ec2.New(sess),
+		pricing:           NewPricingAPI(sess, region),
+	}
+	if includeCommitments {
+		// savings plans are a global service
+		p.savingsPlans = savingsplans.New(sess, &aws.Config{Region: aws.String("us-east-1")})
+		p.commitments = newCommitments()
+	}
+
+	var cached time.Time
+	if cacheTTL > 0 {
+		p.cachePath = priceCachePath(region)
+	}
+	if p.cachePath != "" {
+		var err error
+		if cached, err = p.loadPriceCache(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("loading cached prices", "path", p.cachePath, "error", err)
+		}
 	}
 
 	go func() {
-		// perform an initial price update at startup
-		p.updatePricing(ctx)
+		// perform an initial price update at startup unless the cached prices are fresh
+		next := initialUpdateDelay(cached, time.Now(), cacheTTL)
+		if next > 0 && p.commitments != nil {
+			p.updateCommitmentsOnly(ctx)
+		}
 
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(pricingUpdatePeriod):
+			case <-time.After(next):
 				p.updatePricing(ctx)
 			}
+			next = pricingUpdatePeriod
 		}
 	}()
 	return p
 }
 
+// initialUpdateDelay returns how long to wait before the first price update, which is immediate unless the prices
+// cached at the given time are younger than the TTL. A cache from the future, e.g. written by a machine whose clock is
+// ahead, is treated as just written so that the first update is never further away than the TTL.
+func initialUpdateDelay(cached, now time.Time, cacheTTL time.Duration) time.Duration {
+	if cached.IsZero() {
+		return 0
+	}
+	age := max(now.Sub(cached), 0)
+	if age >= cacheTTL {
+		return 0
+	}
+	return cacheTTL - age
+}
+
 // OnDemandPrice returns the last known on-demand price for a given instance type, returning an error if there is no
 // known on-demand pricing for the instance type.
 func (p *pricingProvider) OnDemandPrice(instanceType ec2types.InstanceType) (float64, bool) {
@@ -181,13 +331,36 @@ func (p *pricingProvider) OnDemandPrice(instanceType ec2types.InstanceType) (flo
 	return price, true
 }
 
-func (p *pricingProvider) FargatePrice(cpu, memory float64) (float64, bool) {
+// OnDemandNodePrice returns the on-demand list price of a node's instance type, including the Windows license for
+// Windows nodes. Reserved Instances and Savings Plans aren't applied, as spot nodes are compared against what they'd
+// cost if launched on-demand.
+func (p *pricingProvider) OnDemandNodePrice(n *model.Node) (float64, bool) {
+	if n.OperatingSystem() == model.OSWindows {
+		p.mu.RLock()
+		defer p.mu.RUnlock()
+		price, ok := p.windowsOnDemandPrices[n.InstanceType()]
+		return price, ok
+	}
+	return p.OnDemandPrice(n.InstanceType())
+}
+
+// DedicatedPrice returns the last known on-demand price for a given instance type with dedicated tenancy
+func (p *pricingProvider) DedicatedPrice(instanceType ec2types.InstanceType) (float64, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	price, ok := p.dedicatedPrices[instanceType]
+	return price, ok
+}
+
+// FargatePrice returns the hourly price of the capacity that Fargate provisioned for a pod
+func (p *pricingProvider) FargatePrice(capacity model.FargateCapacity) (float64, bool) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 	if p.fargateGBPricePerHour == 0 || p.fargateVCPUPricePerHour == 0 {
 		return 0, false
 	}
-	return cpu*p.fargateVCPUPricePerHour + memory*p.fargateGBPricePerHour, true
+	return capacity.CPU*p.fargateVCPUPricePerHour + capacity.Memory*p.fargateGBPricePerHour +
+		capacity.BillableEphemeralStorage()*p.fargateStoragePricePerHour, true
 }
 
 // SpotPrice returns the last known spot price for a given instance type and zone, returning an error
@@ -195,30 +368,39 @@ func (p *pricingProvider) FargatePrice(cpu, memory float64) (float64, bool) {
 func (p *pricingProvider) SpotPrice(instanceType ec2types.InstanceType, zone string) (float64, bool) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-	if val, ok := p.spotPrices[instanceType]; ok {
-		if price, ok := p.spotPrices[instanceType].prices[zone]; ok {
-			return price, true
-		}
-		return val.defaultPrice, true
+	return p.spotPrices[instanceType].price(zone)
+}
+
+// ZonalSpotPrices returns the last known spot price of an instance type in each zone that it's offered in
+func (p *pricingProvider) ZonalSpotPrices(instanceType ec2types.InstanceType) map[string]float64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	prices := map[string]float64{}
+	for zone, price := range p.spotPrices[instanceType].prices {
+		prices[zone] = price
 	}
-	return 0.0, false
+	return prices
 }
 
 func (p *pricingProvider) updatePricing(ctx context.Context) {
+	ctx, span := tracing.StartSpan(ctx, "UpdatePricing", attribute.String("region", p.region))
+	defer span.End()
+
 	var wg sync.WaitGroup
+	var onDemandErr, spotErr error
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := p.updateOnDemandPricing(ctx); err != nil {
-			log.Printf("updating on-demand pricing, %s, using existing pricing data", err)
+		if onDemandErr = p.updateOnDemandPricing(ctx); onDemandErr != nil {
+			slog.Error("updating on-demand pricing, using existing pricing data", "region", p.region, "error", onDemandErr)
 		}
 	}()
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := p.updateSpotPricing(ctx); err != nil {
-			log.Printf("updating spot pricing, %s, using existing pricing data", err)
+		if spotErr = p.updateSpotPricing(ctx); spotErr != nil {
+			slog.Error("updating spot pricing, using existing pricing data", "region", p.region, "error", spotErr)
 		}
 	}()
 
@@ -226,18 +408,126 @@ func (p *pricingProvider) updatePricing(ctx context.Context) {
 	go func() {
 		defer wg.Done()
 		if err := p.updateFargatePricing(ctx); err != nil {
-			log.Printf("updating fargate pricing, %s", err)
+			slog.Error("updating fargate pricing", "region", p.region, "error", err)
 		}
 	}()
+
+	if p.commitments != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.updateCommitments(ctx); err != nil {
+				slog.Error("updating reserved instances and savings plans, using existing commitments", "error", err)
+			}
+		}()
+	}
 	wg.Wait()
 
+	// only cache complete prices, so that a failed update is retried by the next run
+	if p.cachePath != "" && onDemandErr == nil && spotErr == nil {
+		if err := p.savePriceCache(); err != nil {
+			slog.Warn("caching prices", "path", p.cachePath, "error", err)
+		}
+	}
+
+	slog.Info("updated pricing", "region", p.region)
 	// notify anyone that cares
 	for _, f := range p.onUpdateFuncs {
 		f()
 	}
 }
 
+// updateCommitmentsOnly updates the account's Reserved Instances and Savings Plans without updating prices, which is
+// used at startup when the prices were loaded from the cache
+func (p *pricingProvider) updateCommitmentsOnly(ctx context.Context) {
+	if err := p.updateCommitments(ctx); err != nil {
+		slog.Error("updating reserved instances and savings plans, using existing commitments", "error", err)
+	}
+	for _, f := range p.onUpdateFuncs {
+		f()
+	}
+}
+
 func (p *pricingProvider) updateOnDemandPricing(ctx context.Context) error {
+	var wg sync.WaitGroup
+	var onDemandPrices map[ec2types.InstanceType]float64
+	var onDemandErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		onDemandPrices, onDemandErr = p.fetchLinuxOnDemandPricing(ctx)
+	}()
+
+	// dedicated tenancy prices, which aren't required to price the rest of the nodes
+	var dedicatedPrices map[ec2types.InstanceType]float64
+	var dedicatedErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dedicatedPrices, dedicatedErr = p.fetchOnDemandPricing(ctx, "Linux",
+			&pricing.Filter{
+				Field: aws.String("tenancy"),
+				Type:  aws.String("TERM_MATCH"),
+				Value: aws.String("Dedicated"),
+			},
+			&pricing.Filter{
+				Field: aws.String("productFamily"),
+				Type:  aws.String("TERM_MATCH"),
+				Value: aws.String("Compute Instance"),
+			})
+	}()
+
+	// Windows prices with the license included, which are only needed by Windows nodes
+	var windowsPrices map[ec2types.InstanceType]float64
+	var windowsErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		windowsPrices, windowsErr = p.fetchOnDemandPricing(ctx, "Windows",
+			&pricing.Filter{
+				Field: aws.String("tenancy"),
+				Type:  aws.String("TERM_MATCH"),
+				Value: aws.String("Shared"),
+			},
+			&pricing.Filter{
+				Field: aws.String("productFamily"),
+				Type:  aws.String("TERM_MATCH"),
+				Value: aws.String("Compute Instance"),
+			},
+			&pricing.Filter{
+				Field: aws.String("licenseModel"),
+				Type:  aws.String("TERM_MATCH"),
+				Value: aws.String("No License required"),
+			})
+	}()
+
+	wg.Wait()
+	if onDemandErr != nil {
+		return onDemandErr
+	}
+	if dedicatedErr != nil {
+		slog.Warn("updating dedicated tenancy pricing, using existing pricing data", "region", p.region, "error", dedicatedErr)
+	}
+	if windowsErr != nil {
+		slog.Warn("updating windows pricing, using existing pricing data", "region", p.region, "error", windowsErr)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onDemandPrices = onDemandPrices
+	if dedicatedErr == nil {
+		p.dedicatedPrices = dedicatedPrices
+	}
+	if windowsErr == nil {
+		p.windowsOnDemandPrices = windowsPrices
+	}
+	p.seedSpotDefaults()
+	return nil
+}
+
+// fetchLinuxOnDemandPricing returns the on-demand prices of Linux instances with shared tenancy, including bare metal
+// instances
+func (p *pricingProvider) fetchLinuxOnDemandPricing(ctx context.Context) (map[ec2types.InstanceType]float64, error) {
 	// standard on-demand instances
 	var wg sync.WaitGroup
 	var onDemandPrices, onDemandMetalPrices map[ec2types.InstanceType]float64
@@ -246,7 +536,7 @@ func (p *pricingProvider) updateOnDemandPricing(ctx context.Context) error {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		onDemandPrices, onDemandErr = p.fetchOnDemandPricing(ctx,
+		onDemandPrices, onDemandErr = p.fetchOnDemandPricing(ctx, "Linux",
 			&pricing.Filter{
 				Field: aws.String("tenancy"),
 				Type:  aws.String("TERM_MATCH"),
@@ -263,7 +553,7 @@ func (p *pricingProvider) updateOnDemandPricing(ctx context.Context) error {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		onDemandMetalPrices, onDemandMetalErr = p.fetchOnDemandPricing(ctx,
+		onDemandMetalPrices, onDemandMetalErr = p.fetchOnDemandPricing(ctx, "Linux",
 			&pricing.Filter{
 				Field: aws.String("tenancy"),
 				Type:  aws.String("TERM_MATCH"),
@@ -277,28 +567,42 @@ func (p *pricingProvider) updateOnDemandPricing(ctx context.Context) error {
 	}()
 
 	wg.Wait()
-	err := multierr.Append(onDemandErr, onDemandMetalErr)
-	if err != nil {
-		return err
+	if err := multierr.Append(onDemandErr, onDemandMetalErr); err != nil {
+		return nil, err
 	}
-
 	if len(onDemandPrices) == 0 || len(onDemandMetalPrices) == 0 {
-		return errors.New("no on-demand pricing found")
+		return nil, errors.New("no on-demand pricing found")
 	}
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	p.onDemandPrices = map[ec2types.InstanceType]float64{}
+	prices := map[ec2types.InstanceType]float64{}
 	for _, m := range []map[ec2types.InstanceType]float64{onDemandPrices, onDemandMetalPrices} {
 		for k, v := range m {
-			p.onDemandPrices[k] = v
+			prices[k] = v
 		}
 	}
-	return nil
+	return prices, nil
 }
 
-func (p *pricingProvider) fetchOnDemandPricing(ctx context.Context, additionalFilters ...*pricing.Filter) (map[ec2types.InstanceType]float64, error) {
-	prices := map[ec2types.InstanceType]float64{}
+// seedSpotDefaults sets the default spot prices to the on-demand prices, the caller must hold the lock
+func (p *pricingProvider) seedSpotDefaults() {
+	for it, zp := range p.spotPrices {
+		if price, ok := p.onDemandPrices[it]; ok {
+			zp.defaultPrice = price
+			p.spotPrices[it] = zp
+		}
+	}
+	for it, zp := range p.windowsSpotPrices {
+		if price, ok := p.windowsOnDemandPrices[it]; ok {
+			zp.defaultPrice = price
+			p.windowsSpotPrices[it] = zp
+		}
+	}
+}
+
+func (p *pricingProvider) fetchOnDemandPricing(ctx context.Context, operatingSystem string, additionalFilters ...*pricing.Filter) (prices map[ec2types.InstanceType]float64, err error) {
+	ctx, span := tracing.StartPricingSpan(ctx, "FetchOnDemandPricing", attribute.String("operatingSystem", operatingSystem))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	prices = map[ec2types.InstanceType]float64{}
 	filters := append([]*pricing.Filter{
 		{
 			Field: aws.String("regionCode"),
@@ -318,7 +622,7 @@ func (p *pricingProvider) fetchOnDemandPricing(ctx context.Context, additionalFi
 		{
 			Field: aws.String("operatingSystem"),
 			Type:  aws.String("TERM_MATCH"),
-			Value: aws.String("Linux"),
+			Value: aws.String(operatingSystem),
 		},
 		{
 			Field: aws.String("capacitystatus"),
@@ -368,12 +672,12 @@ func (p *pricingProvider) onDemandPage(prices map[ec2types.InstanceType]float64)
 			var buf bytes.Buffer
 			enc := json.NewEncoder(&buf)
 			if err := enc.Encode(outer); err != nil {
-				log.Printf("encoding %s", err)
+				slog.Warn("encoding price item", "error", err)
 			}
 			dec := json.NewDecoder(&buf)
 			var pItem priceItem
 			if err := dec.Decode(&pItem); err != nil {
-				log.Printf("decoding %s", err)
+				slog.Warn("decoding price item", "error", err)
 			}
 			if pItem.Product.Attributes.InstanceType == "" {
 				continue
@@ -393,12 +697,17 @@ func (p *pricingProvider) onDemandPage(prices map[ec2types.InstanceType]float64)
 }
 
 // nolint: gocyclo
-func (p *pricingProvider) updateSpotPricing(ctx context.Context) error {
+func (p *pricingProvider) updateSpotPricing(ctx context.Context) (err error) {
+	ctx, span := tracing.StartPricingSpan(ctx, "UpdateSpotPricing")
+	defer func() { tracing.EndSpan(span, err) }()
+
 	totalOfferings := 0
 
 	prices := map[ec2types.InstanceType]map[string]float64{}
+	windowsPrices := map[ec2types.InstanceType]map[string]float64{}
 	if err := p.ec2.DescribeSpotPriceHistoryPagesWithContext(ctx, &ec2.DescribeSpotPriceHistoryInput{
-		ProductDescriptions: []*string{aws.String("Linux/UNIX"), aws.String("Linux/UNIX (Amazon VPC)")},
+		ProductDescriptions: []*string{aws.String("Linux/UNIX"), aws.String("Linux/UNIX (Amazon VPC)"),
+			aws.String("Windows"), aws.String("Windows (Amazon VPC)")},
 		// get the latest spot price for each instance type
 		StartTime: aws.Time(time.Now()),
 	}, func(output *ec2.DescribeSpotPriceHistoryOutput, b bool) bool {
@@ -407,7 +716,7 @@ func (p *pricingProvider) updateSpotPricing(ctx context.Context) error {
 			spotPrice, err := strconv.ParseFloat(spotPriceStr, 64)
 			// these errors shouldn't occur, but if pricing API does have an error, we ignore the record
 			if err != nil {
-				log.Printf("unable to parse price record %#v", sph)
+				slog.Warn("unable to parse spot price record", "record", sph.String())
 				continue
 			}
 			if sph.Timestamp == nil {
@@ -415,11 +724,15 @@ func (p *pricingProvider) updateSpotPricing(ctx context.Context) error {
 			}
 			instanceType := ec2types.InstanceType(aws.StringValue(sph.InstanceType))
 			az := aws.StringValue(sph.AvailabilityZone)
-			_, ok := prices[instanceType]
+			osPrices := prices
+			if strings.HasPrefix(aws.StringValue(sph.ProductDescription), "Windows") {
+				osPrices = windowsPrices
+			}
+			_, ok := osPrices[instanceType]
 			if !ok {
-				prices[instanceType] = map[string]float64{}
+				osPrices[instanceType] = map[string]float64{}
 			}
-			prices[instanceType][az] = spotPrice
+			osPrices[instanceType][az] = spotPrice
 		}
 		return true
 	}); err != nil {
@@ -433,17 +746,28 @@ func (p *pricingProvider) updateSpotPricing(ctx context.Context) error {
 
 	for it, zoneData := range prices {
 		if _, ok := p.spotPrices[it]; !ok {
-			p.spotPrices[it] = newZonalPricing(0)
+			p.spotPrices[it] = newZonalPricing(p.onDemandPrices[it])
 		}
 		for zone, price := range zoneData {
 			p.spotPrices[it].prices[zone] = price
 		}
 		totalOfferings += len(zoneData)
 	}
+	for it, zoneData := range windowsPrices {
+		if _, ok := p.windowsSpotPrices[it]; !ok {
+			p.windowsSpotPrices[it] = newZonalPricing(p.windowsOnDemandPrices[it])
+		}
+		for zone, price := range zoneData {
+			p.windowsSpotPrices[it].prices[zone] = price
+		}
+	}
 	return nil
 }
 
-func (p *pricingProvider) updateFargatePricing(ctx context.Context) error {
+func (p *pricingProvider) updateFargatePricing(ctx context.Context) (err error) {
+	ctx, span := tracing.StartPricingSpan(ctx, "UpdateFargatePricing")
+	defer func() { tracing.EndSpan(span, err) }()
+
 	filters := []*pricing.Filter{
 		{
 			Field: aws.String("regionCode"),
@@ -484,12 +808,12 @@ func (p *pricingProvider) fargatePage(output *pricing.GetProductsOutput, _ bool)
 		var buf bytes.Buffer
 		enc := json.NewEncoder(&buf)
 		if err := enc.Encode(outer); err != nil {
-			log.Printf("encoding %s", err)
+			slog.Warn("encoding price item", "error", err)
 		}
 		dec := json.NewDecoder(&buf)
 		var pItem priceItem
 		if err := dec.Decode(&pItem); err != nil {
-			log.Printf("decoding %s", err)
+			slog.Warn("decoding price item", "error", err)
 		}
 		if !strings.Contains(pItem.Product.Attributes.UsageType, "Fargate") {
 			continue
@@ -501,7 +825,11 @@ func (p *pricingProvider) fargatePage(output *pricing.GetProductsOutput, _ bool)
 				if err != nil || price == 0 {
 					continue
 				}
-				if strings.Contains(name, "vCPU-Hours") {
+				if strings.Contains(name, "EphemeralStorage-GB-Hours") {
+					p.mu.Lock()
+					p.fargateStoragePricePerHour = price
+					p.mu.Unlock()
+				} else if strings.Contains(name, "vCPU-Hours") {
 					p.mu.Lock()
 					p.fargateVCPUPricePerHour = price
 					p.mu.Unlock()
@@ -510,7 +838,7 @@ func (p *pricingProvider) fargatePage(output *pricing.GetProductsOutput, _ bool)
 					p.fargateGBPricePerHour = price
 					p.mu.Unlock()
 				} else {
-					log.Println("unsupported fargate price information found", name)
+					slog.Debug("unsupported fargate price information found", "usage_type", name)
 				}
 			}
 		}
@@ -0,0 +1,83 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+// SessionOptions are the settings for reaching the AWS APIs from networks without direct access to them
+type SessionOptions struct {
+	// EC2Endpoint replaces the endpoint of the EC2 API, e.g. with a VPC endpoint or LocalStack
+	EC2Endpoint string
+	// PricingEndpoint replaces the endpoint of the Pricing API
+	PricingEndpoint string
+	// CABundle is the path to a PEM file of the certificate authorities to trust in place of the system's, e.g. one that
+	// includes the authority of a proxy that intercepts TLS. AWS_CA_BUNDLE or the shared config's ca_bundle is used if
+	// it's empty.
+	CABundle string
+}
+
+// NewSession returns a session configured from the environment and shared config files. Requests are sent through the
+// proxy set by HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+func NewSession(opts SessionOptions) (*session.Session, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	sessOpts := session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config: aws.Config{
+			HTTPClient:       &http.Client{Transport: transport},
+			EndpointResolver: opts.resolver(),
+		},
+	}
+	if opts.CABundle != "" {
+		f, err := os.Open(opts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("opening CA bundle, %w", err)
+		}
+		defer f.Close()
+		sessOpts.CustomCABundle = f
+	}
+	return session.NewSessionWithOptions(sessOpts)
+}
+
+// RegionalSession returns a copy of the session for a region, e.g. that of a cluster in a different region than the
+// session's, or the session itself if the region is empty
+func RegionalSession(sess *session.Session, region string) *session.Session {
+	if region == "" || aws.StringValue(sess.Config.Region) == region {
+		return sess
+	}
+	return sess.Copy(&aws.Config{Region: aws.String(region)})
+}
+
+// resolver returns the endpoint resolver that replaces the endpoints of the EC2 and Pricing APIs, the endpoints of
+// every other service and those that aren't replaced are the defaults for the region
+func (o SessionOptions) resolver() endpoints.Resolver {
+	custom := map[string]string{ec2.EndpointsID: o.EC2Endpoint, pricing.EndpointsID: o.PricingEndpoint}
+	return endpoints.ResolverFunc(func(service, region string, optFns ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		if url := custom[service]; url != "" {
+			return endpoints.ResolvedEndpoint{URL: url, SigningRegion: region}, nil
+		}
+		return endpoints.DefaultResolver().EndpointFor(service, region, optFns...)
+	})
+}
@@ -0,0 +1,190 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"go.uber.org/multierr"
+)
+
+// StaticPrices are the on-demand prices of each region, used to keep the static fallback prices current between
+// releases rather than relying on the prices embedded at build time
+type StaticPrices struct {
+	Generated time.Time                                    `json:"generated"`
+	Regions   map[string]map[ec2types.InstanceType]float64 `json:"regions"`
+}
+
+// ReadStaticPrices reads a static prices file
+func ReadStaticPrices(path string) (StaticPrices, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return StaticPrices{}, err
+	}
+	var prices StaticPrices
+	if err := json.Unmarshal(contents, &prices); err != nil {
+		return StaticPrices{}, fmt.Errorf("parsing %s, %w", path, err)
+	}
+	return prices, nil
+}
+
+// WriteStaticPrices writes a static prices file
+func WriteStaticPrices(w io.Writer, prices StaticPrices) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(prices)
+}
+
+// Merge returns the prices with the regions of other added, replacing the prices of regions in both rather than
+// merging them so that instance types that are no longer offered in a region don't linger
+func (s StaticPrices) Merge(other StaticPrices) StaticPrices {
+	merged := StaticPrices{Generated: s.Generated, Regions: map[string]map[ec2types.InstanceType]float64{}}
+	if other.Generated.After(merged.Generated) {
+		merged.Generated = other.Generated
+	}
+	for region, prices := range s.Regions {
+		merged.Regions[region] = prices
+	}
+	for region, prices := range other.Regions {
+		merged.Regions[region] = prices
+	}
+	return merged
+}
+
+// Partition returns the AWS partition of a region, e.g. aws-us-gov for us-gov-west-1
+func Partition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	case strings.HasPrefix(region, "us-isob-"):
+		return "aws-iso-b"
+	case strings.HasPrefix(region, "us-isof-"):
+		return "aws-iso-f"
+	case strings.HasPrefix(region, "eu-isoe-"):
+		return "aws-iso-e"
+	case strings.HasPrefix(region, "us-iso-"):
+		return "aws-iso"
+	}
+	return "aws"
+}
+
+// ReadPricingBundle reads a pricing bundle, a directory of static prices files such as those written by
+// WritePricingBundle. Where files have prices for the same region, those from the most recently generated file are
+// used.
+func ReadPricingBundle(dir string) (StaticPrices, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return StaticPrices{}, err
+	}
+	if len(paths) == 0 {
+		return StaticPrices{}, fmt.Errorf("no price files found in %s", dir)
+	}
+	var files []StaticPrices
+	for _, path := range paths {
+		prices, err := ReadStaticPrices(path)
+		if err != nil {
+			return StaticPrices{}, err
+		}
+		files = append(files, prices)
+	}
+	sort.SliceStable(files, func(a, b int) bool { return files[a].Generated.Before(files[b].Generated) })
+	var bundle StaticPrices
+	for _, prices := range files {
+		bundle = bundle.Merge(prices)
+	}
+	return bundle, nil
+}
+
+// WritePricingBundle writes the prices to a pricing bundle as a static prices file for each partition, named after the
+// partition, e.g. aws-us-gov.json. Regions already in the bundle that aren't in prices, e.g. because they couldn't be
+// fetched, keep their previous prices. It returns the paths of the files that were written.
+func WritePricingBundle(dir string, prices StaticPrices) ([]string, error) {
+	partitions := map[string]StaticPrices{}
+	for region, regionPrices := range prices.Regions {
+		partition := Partition(region)
+		if _, ok := partitions[partition]; !ok {
+			partitions[partition] = StaticPrices{Generated: prices.Generated, Regions: map[string]map[ec2types.InstanceType]float64{}}
+		}
+		partitions[partition].Regions[region] = regionPrices
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var written []string
+	for partition, partitionPrices := range partitions {
+		path := filepath.Join(dir, partition+".json")
+		existing, err := ReadStaticPrices(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return written, err
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			return written, err
+		}
+		err = WriteStaticPrices(f, existing.Merge(partitionPrices))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return written, fmt.Errorf("writing %s, %w", path, err)
+		}
+		written = append(written, path)
+	}
+	sort.Strings(written)
+	return written, nil
+}
+
+// StaticPriceRegions returns the regions that have prices embedded at build time
+func StaticPriceRegions() []string {
+	var regions []string
+	for _, priceSet := range allPrices {
+		for region := range priceSet {
+			regions = append(regions, region)
+		}
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// FetchStaticPrices fetches the current on-demand prices of the regions from the AWS pricing API. Regions that can't
+// be fetched, e.g. those in another partition, are left out and their errors are returned along with the prices of the
+// other regions.
+func FetchStaticPrices(ctx context.Context, sess *session.Session, regions []string) (StaticPrices, error) {
+	prices := StaticPrices{Generated: time.Now().UTC(), Regions: map[string]map[ec2types.InstanceType]float64{}}
+	var errs error
+	for _, region := range regions {
+		p := &pricingProvider{region: region, pricing: NewPricingAPI(sess, region)}
+		regionPrices, err := p.fetchLinuxOnDemandPricing(ctx)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("fetching prices for %s, %w", region, err))
+			continue
+		}
+		prices.Regions[region] = regionPrices
+	}
+	return prices, errs
+}
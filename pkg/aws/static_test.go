@@ -0,0 +1,178 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestPartition(t *testing.T) {
+	for region, exp := range map[string]string{
+		"us-east-1":       "aws",
+		"eu-west-1":       "aws",
+		"cn-north-1":      "aws-cn",
+		"us-gov-west-1":   "aws-us-gov",
+		"us-iso-east-1":   "aws-iso",
+		"us-isob-east-1":  "aws-iso-b",
+		"us-isof-south-1": "aws-iso-f",
+		"eu-isoe-west-1":  "aws-iso-e",
+	} {
+		if got := Partition(region); got != exp {
+			t.Errorf("expected %s to be in %s, got %s", region, exp, got)
+		}
+	}
+}
+
+func TestPricingBundleRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "prices")
+	generated := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	prices := StaticPrices{Generated: generated, Regions: map[string]map[ec2types.InstanceType]float64{
+		"us-east-1":     {"m5.large": 0.1},
+		"us-gov-west-1": {"m5.large": 0.2},
+		"us-iso-east-1": {"m5.large": 0.3},
+	}}
+	written, err := WritePricingBundle(dir, prices)
+	if err != nil {
+		t.Fatalf("writing bundle, %s", err)
+	}
+	var exp []string
+	for _, name := range []string{"aws-iso.json", "aws-us-gov.json", "aws.json"} {
+		exp = append(exp, filepath.Join(dir, name))
+	}
+	if len(written) != len(exp) {
+		t.Fatalf("expected %v to be written, got %v", exp, written)
+	}
+	for i := range exp {
+		if written[i] != exp[i] {
+			t.Errorf("expected %s to be written, got %s", exp[i], written[i])
+		}
+	}
+
+	// a later update that couldn't fetch a region keeps its previous prices
+	updated := StaticPrices{Generated: generated.Add(time.Hour), Regions: map[string]map[ec2types.InstanceType]float64{
+		"us-east-1": {"m5.large": 0.15},
+	}}
+	if _, err := WritePricingBundle(dir, updated); err != nil {
+		t.Fatalf("updating bundle, %s", err)
+	}
+	bundle, err := ReadPricingBundle(dir)
+	if err != nil {
+		t.Fatalf("reading bundle, %s", err)
+	}
+	if !bundle.Generated.Equal(updated.Generated) {
+		t.Errorf("expected the bundle to be generated at %s, got %s", updated.Generated, bundle.Generated)
+	}
+	for region, exp := range map[string]float64{"us-east-1": 0.15, "us-gov-west-1": 0.2, "us-iso-east-1": 0.3} {
+		if got := bundle.Regions[region]["m5.large"]; got != exp {
+			t.Errorf("expected m5.large to cost %v in %s, got %v", exp, region, got)
+		}
+	}
+}
+
+func TestReadPricingBundleNewestFileWins(t *testing.T) {
+	dir := t.TempDir()
+	older := StaticPrices{Generated: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Regions: map[string]map[ec2types.InstanceType]float64{"us-iso-east-1": {"m5.large": 0.3}}}
+	newer := StaticPrices{Generated: older.Generated.Add(time.Hour),
+		Regions: map[string]map[ec2types.InstanceType]float64{"us-iso-east-1": {"m5.xlarge": 0.6}}}
+	// the newer file sorts first by name, so it's the generation time rather than the name that decides
+	for name, prices := range map[string]StaticPrices{"a.json": newer, "b.json": older} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteStaticPrices(f, prices); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	bundle, err := ReadPricingBundle(dir)
+	if err != nil {
+		t.Fatalf("reading bundle, %s", err)
+	}
+	if _, ok := bundle.Regions["us-iso-east-1"]["m5.large"]; ok {
+		t.Errorf("expected the older file's prices to be replaced")
+	}
+	if got := bundle.Regions["us-iso-east-1"]["m5.xlarge"]; got != 0.6 {
+		t.Errorf("expected m5.xlarge to cost 0.6, got %v", got)
+	}
+}
+
+func TestReadPricingBundleErrors(t *testing.T) {
+	if _, err := ReadPricingBundle(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("expected an error for a missing bundle")
+	}
+	if _, err := ReadPricingBundle(t.TempDir()); err == nil {
+		t.Errorf("expected an error for an empty bundle")
+	}
+	corrupt := t.TempDir()
+	if err := os.WriteFile(filepath.Join(corrupt, "aws.json"), []byte(`{"regions": `), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadPricingBundle(corrupt); err == nil {
+		t.Errorf("expected an error for a corrupt bundle")
+	}
+}
+
+func TestStaticPricesOverrideEmbeddedPrices(t *testing.T) {
+	embedded, ok := getStaticPrices("us-east-1")["m5.large"]
+	if !ok {
+		t.Fatalf("expected an embedded price for m5.large in us-east-1")
+	}
+	static := StaticPrices{Regions: map[string]map[ec2types.InstanceType]float64{
+		"us-east-1":     {"m5.large": embedded * 2},
+		"us-iso-east-1": {"m5.large": 0.3},
+	}}
+
+	p := NewStaticPricingProviderWithPrices("us-east-1", static).(*pricingProvider)
+	if got, _ := p.OnDemandPrice("m5.large"); got != embedded*2 {
+		t.Errorf("expected the static price %v to override the embedded price, got %v", embedded*2, got)
+	}
+	// instance types that aren't in the static prices keep their embedded prices
+	if _, ok := p.OnDemandPrice("m5.xlarge"); !ok {
+		t.Errorf("expected m5.xlarge to keep its embedded price")
+	}
+
+	// a region without embedded prices is priced from the static prices alone
+	if hasEmbeddedPrices("us-iso-east-1") {
+		t.Fatalf("expected no embedded prices for us-iso-east-1")
+	}
+	p = NewStaticPricingProviderWithPrices("us-iso-east-1", static).(*pricingProvider)
+	if got, _ := p.OnDemandPrice("m5.large"); got != 0.3 {
+		t.Errorf("expected m5.large to cost 0.3, got %v", got)
+	}
+	if _, ok := p.OnDemandPrice("m5.xlarge"); ok {
+		t.Errorf("expected m5.xlarge to be unpriced rather than priced from us-east-1")
+	}
+}
+
+func TestHasEmbeddedPrices(t *testing.T) {
+	for region, exp := range map[string]bool{
+		"us-east-1":     true,
+		"us-gov-west-1": true,
+		"cn-north-1":    true,
+		"us-iso-east-1": false,
+		"":              false,
+	} {
+		if got := hasEmbeddedPrices(region); got != exp {
+			t.Errorf("expected hasEmbeddedPrices(%q) = %t, got %t", region, exp, got)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+	nvp "github.com/awslabs/eks-node-viewer/pkg/pricing"
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
+)
+
+// retailPricesURL is the Azure Retail Prices API which doesn't require authentication
+const retailPricesURL = "https://prices.azure.com/api/retail/prices"
+
+// regionPrices are the hourly prices of the Linux VM sizes in a region
+type regionPrices struct {
+	onDemand map[string]float64
+	spot     map[string]float64
+}
+
+type pricingProvider struct {
+	client *http.Client
+	prices *nvp.RegionalCache[regionPrices]
+}
+
+// NewPricingProvider returns a provider that prices AKS nodes from the Azure Retail Prices API. The prices for a region
+// are loaded the first time a node in that region is seen.
+func NewPricingProvider(ctx context.Context) nvp.Provider {
+	p := &pricingProvider{client: http.DefaultClient}
+	p.prices = nvp.NewRegionalCache(ctx, p.fetchPrices)
+	return p
+}
+
+func (p *pricingProvider) NodePrice(n *model.Node) (float64, bool) {
+	region, _ := n.Label(v1.LabelTopologyRegion)
+	if region == "" {
+		return math.NaN(), false
+	}
+	prices, ok := p.prices.Get(region)
+	if !ok {
+		return math.NaN(), false
+	}
+	vmSize := strings.ToLower(string(n.InstanceType()))
+	var price float64
+	if n.IsSpot() {
+		price, ok = prices.spot[vmSize]
+	} else {
+		price, ok = prices.onDemand[vmSize]
+	}
+	if !ok {
+		return math.NaN(), false
+	}
+	return price, true
+}
+
+func (p *pricingProvider) NodeDeleted(n *model.Node) {}
+
+func (p *pricingProvider) OnUpdate(onUpdate func()) {
+	p.prices.OnUpdate(onUpdate)
+}
+
+// this isn't the full price item, just the portions we care about
+type retailPrice struct {
+	RetailPrice   float64 `json:"retailPrice"`
+	UnitOfMeasure string  `json:"unitOfMeasure"`
+	ArmSkuName    string  `json:"armSkuName"`
+	SkuName       string  `json:"skuName"`
+	ProductName   string  `json:"productName"`
+}
+
+type retailPricesPage struct {
+	Items        []retailPrice `json:"Items"`
+	NextPageLink string        `json:"NextPageLink"`
+}
+
+func (p *pricingProvider) fetchPrices(ctx context.Context, region string) (prices regionPrices, err error) {
+	ctx, span := tracing.StartPricingSpan(ctx, "FetchAzurePricing", attribute.String("region", region))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	prices = regionPrices{
+		onDemand: map[string]float64{},
+		spot:     map[string]float64{},
+	}
+	query := url.Values{}
+	query.Set("$filter", fmt.Sprintf("serviceName eq 'Virtual Machines' and armRegionName eq '%s' and priceType eq 'Consumption'", region))
+	next := retailPricesURL + "?" + query.Encode()
+	for next != "" {
+		page, err := p.fetchPage(ctx, next)
+		if err != nil {
+			return prices, err
+		}
+		for _, item := range page.Items {
+			// low priority is the predecessor of spot and isn't available on AKS
+			if item.UnitOfMeasure != "1 Hour" || strings.Contains(item.ProductName, "Windows") ||
+				strings.Contains(item.SkuName, "Low Priority") {
+				continue
+			}
+			vmSize := strings.ToLower(item.ArmSkuName)
+			if strings.HasSuffix(item.SkuName, " Spot") {
+				prices.spot[vmSize] = item.RetailPrice
+			} else {
+				prices.onDemand[vmSize] = item.RetailPrice
+			}
+		}
+		next = page.NextPageLink
+	}
+	if len(prices.onDemand) == 0 {
+		return prices, errors.New("no on-demand pricing found")
+	}
+	return prices, nil
+}
+
+func (p *pricingProvider) fetchPage(ctx context.Context, pageURL string) (*retailPricesPage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching retail prices, %s", resp.Status)
+	}
+	var page retailPricesPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("decoding retail prices, %w", err)
+	}
+	return &page, nil
+}
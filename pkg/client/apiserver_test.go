@@ -0,0 +1,98 @@
+//go:build integration
+
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	karpv1apis "sigs.k8s.io/karpenter/pkg/apis"
+)
+
+// startAPIServer runs a real API server and etcd for the integration tests, serving NodeClaims in the given versions,
+// and returns the path of a kubeconfig for an admin user. The binaries are found through KUBEBUILDER_ASSETS, which
+// `make integration` downloads and sets.
+func startAPIServer(t *testing.T, nodeClaimVersions ...string) string {
+	t.Helper()
+	env := &envtest.Environment{CRDs: []*apiextensionsv1.CustomResourceDefinition{nodeClaimCRD(t, nodeClaimVersions)}}
+	if _, err := env.Start(); err != nil {
+		t.Fatalf("starting API server, %s", err)
+	}
+	// cleanups run in reverse, so the server is stopped after anything the test started that's watching it
+	t.Cleanup(func() {
+		if err := env.Stop(); err != nil {
+			t.Errorf("stopping API server, %s", err)
+		}
+	})
+
+	user, err := env.AddUser(envtest.User{Name: "eks-node-viewer", Groups: []string{"system:masters"}}, nil)
+	if err != nil {
+		t.Fatalf("adding user, %s", err)
+	}
+	kubeconfig, err := user.KubeConfig()
+	if err != nil {
+		t.Fatalf("creating kubeconfig, %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(path, kubeconfig, 0600); err != nil {
+		t.Fatalf("writing kubeconfig, %s", err)
+	}
+	return path
+}
+
+// nodeClaimCRD returns Karpenter's NodeClaim CRD, serving the given versions. v1 is served with Karpenter's schema, so
+// the API server validates NodeClaims as it would in a cluster. Karpenter no longer ships the older versions, so those
+// are served without a schema, which is enough to test that they're discovered and decoded.
+func nodeClaimCRD(t *testing.T, versions []string) *apiextensionsv1.CustomResourceDefinition {
+	t.Helper()
+	i := slices.IndexFunc(karpv1apis.CRDs, func(crd *apiextensionsv1.CustomResourceDefinition) bool {
+		return crd.Name == "nodeclaims."+karpv1apis.Group
+	})
+	if i < 0 {
+		t.Fatal("Karpenter's NodeClaim CRD not found")
+	}
+	crd := karpv1apis.CRDs[i].DeepCopy()
+	j := slices.IndexFunc(crd.Spec.Versions, func(v apiextensionsv1.CustomResourceDefinitionVersion) bool {
+		return v.Name == "v1"
+	})
+	if j < 0 {
+		t.Fatal("Karpenter's NodeClaim CRD doesn't have a v1 version")
+	}
+	schema := crd.Spec.Versions[j]
+
+	preserveUnknownFields := true
+	crd.Spec.Versions = nil
+	for _, version := range versions {
+		served := *schema.DeepCopy()
+		served.Name, served.Served, served.Storage = version, true, len(crd.Spec.Versions) == 0
+		if version != "v1" {
+			served.Schema = &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+				Type:                   "object",
+				XPreserveUnknownFields: &preserveUnknownFields,
+			}}
+			served.AdditionalPrinterColumns = nil
+		}
+		crd.Spec.Versions = append(crd.Spec.Versions, served)
+	}
+	// there's no webhook to convert between versions, without one the API server only changes the apiVersion
+	crd.Spec.Conversion = nil
+	return crd
+}
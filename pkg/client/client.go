@@ -15,6 +15,7 @@ limitations under the License.
 package client
 
 import (
+	"sort"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -28,8 +29,24 @@ import (
 	karpv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 )
 
-func NewKubernetes(kubeconfig, context string) (*kubernetes.Clientset, error) {
-	config, err := getConfig(kubeconfig, context)
+// Impersonation holds the user and groups to impersonate when talking to the API server
+type Impersonation struct {
+	User   string
+	Groups []string
+}
+
+// Connection selects the kubeconfig and the settings that override it when connecting to a cluster
+type Connection struct {
+	// Kubeconfig is a colon separated list of kubeconfig files
+	Kubeconfig  string
+	Impersonate Impersonation
+	// Overrides are applied on top of the kubeconfig, e.g. from kubectl's standard --cluster, --user and --token flags
+	// when running as a kubectl plugin
+	Overrides clientcmd.ConfigOverrides
+}
+
+func NewKubernetes(conn Connection, context string) (*kubernetes.Clientset, error) {
+	config, err := getConfig(conn, context)
 	if err != nil {
 		return nil, err
 	}
@@ -40,8 +57,8 @@ func NewKubernetes(kubeconfig, context string) (*kubernetes.Clientset, error) {
 	return clientset, err
 }
 
-func NewNodeClaims(kubeconfig, context string) (*rest.RESTClient, error) {
-	c, err := getConfig(kubeconfig, context)
+func NewNodeClaims(conn Connection, context string) (*rest.RESTClient, error) {
+	c, err := getConfig(conn, context)
 	if err != nil {
 		return nil, err
 	}
@@ -60,9 +77,42 @@ func NewNodeClaims(kubeconfig, context string) (*rest.RESTClient, error) {
 	return rest.RESTClientFor(&config)
 }
 
-func getConfig(kubeconfig, context string) (*rest.Config, error) {
-	// use the current context in kubeconfig
+// Contexts returns the names of all of the contexts in the kubeconfig
+func Contexts(conn Connection) ([]string, error) {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{Precedence: strings.Split(conn.Kubeconfig, ":")},
+		&clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return nil, err
+	}
+	var contexts []string
+	for name := range config.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return contexts, nil
+}
+
+func getConfig(conn Connection, context string) (*rest.Config, error) {
+	// use the current context in kubeconfig, empty context and impersonation values leave the overrides untouched
+	overrides := conn.Overrides
+	if context != "" {
+		overrides.CurrentContext = context
+	}
+	if conn.Impersonate.User != "" {
+		overrides.AuthInfo.Impersonate = conn.Impersonate.User
+	}
+	// groups split from a comma separated list may be padded, e.g. "system:masters, viewers", or empty
+	var groups []string
+	for _, group := range conn.Impersonate.Groups {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	if len(groups) > 0 {
+		overrides.AuthInfo.ImpersonateGroups = groups
+	}
 	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{Precedence: strings.Split(kubeconfig, ":")},
-		&clientcmd.ConfigOverrides{CurrentContext: context}).ClientConfig()
+		&clientcmd.ClientConfigLoadingRules{Precedence: strings.Split(conn.Kubeconfig, ":")},
+		&overrides).ClientConfig()
 }
@@ -0,0 +1,99 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
+)
+
+// clockSkewPeriod is how often the API server's clock is compared to the local clock
+const clockSkewPeriod = 10 * time.Minute
+
+// minClockSkew is the smallest skew that's corrected for, the Date header only has a resolution of a second
+const minClockSkew = 2 * time.Second
+
+// StartClockSkew periodically measures how far the API server's clock is from the local clock, so that the ages of
+// nodes and how long they've been NotReady are correct when the local clock has drifted
+func (m Controller) StartClockSkew(ctx context.Context) {
+	go func() {
+		loggedErr := false
+		for {
+			skew, err := m.measureClockSkew(ctx)
+			if err != nil && !loggedErr {
+				slog.Warn("measuring clock skew", "error", err)
+				loggedErr = true
+			}
+			if err == nil {
+				if skew.Abs() < minClockSkew {
+					skew = 0
+				}
+				model.SetClockSkew(skew)
+				m.cluster.Invalidate()
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(clockSkewPeriod):
+			}
+		}
+	}()
+}
+
+// measureClockSkew returns how far the API server's clock is ahead of the local clock, based on the Date header of a
+// request for the server's version
+func (m Controller) measureClockSkew(ctx context.Context) (skew time.Duration, err error) {
+	ctx, span := tracing.StartSpan(ctx, "MeasureClockSkew")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	rc, ok := m.kubeClient.Discovery().RESTClient().(*rest.RESTClient)
+	if !ok || rc.Client == nil {
+		return 0, errors.New("no HTTP client for the API server")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rc.Get().AbsPath("/version").URL().String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	sent := time.Now()
+	resp, err := rc.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	received := time.Now()
+	resp.Body.Close()
+	return clockSkewFromDate(resp.Header.Get("Date"), sent, received)
+}
+
+// clockSkewFromDate returns how far the time in a Date header is ahead of the local time that the response was
+// generated, which is assumed to be halfway between sending the request and receiving the response
+func clockSkewFromDate(date string, sent, received time.Time) (time.Duration, error) {
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return 0, fmt.Errorf("parsing date %q, %w", date, err)
+	}
+	// the Date header is truncated to the second, so the server's time was on average half a second later
+	serverTime = serverTime.Add(500 * time.Millisecond)
+	local := sent.Add(received.Sub(sent) / 2)
+	return serverTime.Sub(local), nil
+}
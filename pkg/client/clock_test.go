@@ -0,0 +1,43 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClockSkewFromDate(t *testing.T) {
+	sent := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	received := sent.Add(time.Second)
+	for date, exp := range map[string]time.Duration{
+		// in sync, the response was generated halfway through the request
+		"Wed, 01 May 2024 12:00:00 GMT": 0,
+		"Wed, 01 May 2024 12:05:00 GMT": 5 * time.Minute,
+		"Wed, 01 May 2024 11:58:00 GMT": -2 * time.Minute,
+	} {
+		skew, err := clockSkewFromDate(date, sent, received)
+		if err != nil {
+			t.Errorf("parsing %s, %s", date, err)
+			continue
+		}
+		if skew != exp {
+			t.Errorf("expected a skew of %s for %s, got %s", exp, date, skew)
+		}
+	}
+	if _, err := clockSkewFromDate("", sent, received); err == nil {
+		t.Errorf("expected an error without a date")
+	}
+}
@@ -15,165 +15,206 @@ package client
 
 import (
 	"context"
-	"log"
-	"math"
-	"strconv"
+	"log/slog"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
-	karpv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 
 	"github.com/awslabs/eks-node-viewer/pkg/model"
 	"github.com/awslabs/eks-node-viewer/pkg/pricing"
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
 )
 
 type Controller struct {
 	kubeClient      *kubernetes.Clientset
-	uiModel         *model.UIModel
+	cluster         *model.Cluster
 	pricing         pricing.Provider
+	lifecycle       pricing.LifecycleProvider
 	nodeSelector    labels.Selector
 	nodeClaimClient *rest.RESTClient
+	machineClient   *rest.RESTClient
+	synced          *informersSynced
+	// podNamespace is the namespace that pods are watched in, all namespaces are watched if it's empty
+	podNamespace string
+	// instanceTypes provides the hardware of the nodes' instance types, it's optional
+	instanceTypes pricing.InstanceTypeProvider
+	// prices are the nodes waiting to be priced
+	prices *priceQueue
+	// onDemand provides the on-demand prices that spot nodes are compared against, it's optional
+	onDemand pricing.OnDemandProvider
+	// nodeClaimV1Beta1Client watches the v1beta1 NodeClaims of clusters that don't serve v1, it's optional
+	nodeClaimV1Beta1Client *rest.RESTClient
 }
 
-func NewController(kubeClient *kubernetes.Clientset, nodeClaimClient *rest.RESTClient, uiModel *model.UIModel, nodeSelector labels.Selector, pricing pricing.Provider) *Controller {
+// informersSynced tracks whether the informers that have been started have completed their initial list
+type informersSynced struct {
+	mu     sync.Mutex
+	synced []cache.InformerSynced
+}
+
+// NewController constructs a controller that watches a cluster and updates the model of it. The lifecycle provider is
+// optional and, if non-nil, is used to detect nodes whose capacity type label doesn't match EC2. The machine client is
+// also optional and, if non-nil, is used to watch legacy Karpenter Machines in addition to NodeClaims.
+func NewController(kubeClient *kubernetes.Clientset, nodeClaimClient, machineClient *rest.RESTClient, cluster *model.Cluster, nodeSelector labels.Selector, pricing pricing.Provider, lifecycle pricing.LifecycleProvider) *Controller {
 	c := &Controller{
 		kubeClient:      kubeClient,
-		uiModel:         uiModel,
+		cluster:         cluster,
 		pricing:         pricing,
+		lifecycle:       lifecycle,
 		nodeSelector:    nodeSelector,
 		nodeClaimClient: nodeClaimClient,
+		machineClient:   machineClient,
+		synced:          &informersSynced{},
+		prices:          newPriceQueue(),
 	}
 	pricing.OnUpdate(c.RefreshNodePrices)
+	if lifecycle != nil {
+		lifecycle.OnUpdate(c.RefreshNodePrices)
+	}
 	return c
 }
 
+// SetPodNamespace limits the pods that are watched to a namespace, by default pods in all namespaces are watched
+func (m *Controller) SetPodNamespace(namespace string) {
+	m.podNamespace = namespace
+}
+
+// SetOnDemandProvider sets the provider used to look up the on-demand price of the nodes' instance types, which spot
+// nodes are compared against to display their savings
+func (m *Controller) SetOnDemandProvider(onDemand pricing.OnDemandProvider) {
+	m.onDemand = onDemand
+	onDemand.OnUpdate(m.RefreshNodePrices)
+}
+
+// SetInstanceTypeProvider sets the provider used to look up the hardware of the nodes' instance types, nodes are
+// re-priced when it's updated so that their price per vCPU and GiB reflects the update
+func (m *Controller) SetInstanceTypeProvider(instanceTypes pricing.InstanceTypeProvider) {
+	m.instanceTypes = instanceTypes
+	instanceTypes.OnUpdate(m.RefreshNodePrices)
+}
+
+// SetNodeClaimV1Beta1Client sets the client used to watch karpenter.sh/v1beta1 NodeClaims on clusters running Karpenter
+// versions that don't serve v1
+func (m *Controller) SetNodeClaimV1Beta1Client(client *rest.RESTClient) {
+	m.nodeClaimV1Beta1Client = client
+}
+
 func (m Controller) Start(ctx context.Context) {
-	cluster := m.uiModel.Cluster()
+	cluster := m.cluster
 
+	m.startPriceWorkers(ctx)
 	m.startPodWatch(ctx, cluster)
 	m.startNodeWatch(ctx, cluster)
+	// Spot interruption events are optional, so skip watching them if we don't have permission
+	if _, err := m.kubeClient.CoreV1().Events(v1.NamespaceAll).List(ctx, metav1.ListOptions{Limit: 1}); err == nil {
+		m.startInterruptionWatch(ctx, cluster)
+		m.startDisruptionBlockedWatch(ctx, cluster)
+	}
 
-	// If a NodeClaims Get returns an error, then don't startup the nodeclaims controller since the CRD is not registered
-	if err := m.nodeClaimClient.Get().Do(ctx).Error(); err == nil {
-		m.startNodeClaimWatch(ctx, cluster)
+	// NodeClaims are only watched if the cluster serves them, i.e. Karpenter is installed
+	m.startNodeClaimWatches(ctx, cluster)
+	// Likewise for the legacy Machines, which only exist on clusters running Karpenter versions prior to v0.32
+	if m.machineClient != nil {
+		if err := m.machineClient.Get().Do(ctx).Error(); err == nil {
+			m.startMachineWatch(ctx, cluster)
+		}
 	}
 }
 
-func (m Controller) startNodeClaimWatch(ctx context.Context, cluster *model.Cluster) {
-	nodeClaimWatchList := cache.NewFilteredListWatchFromClient(m.nodeClaimClient, "nodeclaims",
-		v1.NamespaceAll, func(options *metav1.ListOptions) {
-			options.LabelSelector = m.nodeSelector.String()
-		})
-	_, nodeClaimController := cache.NewInformer(
-		nodeClaimWatchList,
-		&karpv1.NodeClaim{},
-		time.Second*0,
-		cache.ResourceEventHandlerFuncs{
-			AddFunc: func(obj interface{}) {
-				nc := obj.(*karpv1.NodeClaim)
-				if nc.Status.ProviderID == "" {
-					return
-				}
-				if _, ok := cluster.GetNode(nc.Status.ProviderID); ok {
-					return
-				}
-				node := model.NewNodeFromNodeClaim(nc)
-				m.updatePrice(node)
-				n := cluster.AddNode(node)
-				n.Show()
-			},
-			DeleteFunc: func(obj interface{}) {
-				cluster.DeleteNode(ignoreDeletedFinalStateUnknown(obj).(*karpv1.NodeClaim).Status.ProviderID)
-			},
-			UpdateFunc: func(oldObj, newObj interface{}) {
-				nc := newObj.(*karpv1.NodeClaim)
-				if nc.Status.ProviderID == "" {
-					return
-				}
-				if _, ok := cluster.GetNode(nc.Status.ProviderID); ok {
-					return
-				}
-				node := model.NewNodeFromNodeClaim(nc)
-				m.updatePrice(node)
-				n := cluster.AddNode(node)
-				n.Show()
-			},
-		},
-	)
-	go nodeClaimController.Run(ctx.Done())
+// run runs the informer until the context is cancelled
+func (m Controller) run(ctx context.Context, informer cache.Controller) {
+	m.synced.mu.Lock()
+	m.synced.synced = append(m.synced.synced, informer.HasSynced)
+	m.synced.mu.Unlock()
+	go informer.Run(ctx.Done())
+}
+
+// WaitForSync waits for the informers started by Start to list the current state of the cluster and for the nodes to
+// be priced, returning false if the context is done first
+func (m Controller) WaitForSync(ctx context.Context) bool {
+	m.synced.mu.Lock()
+	synced := append([]cache.InformerSynced{}, m.synced.synced...)
+	m.synced.mu.Unlock()
+	return cache.WaitForCacheSync(ctx.Done(), synced...) && m.waitForPrices(ctx)
 }
 
 func (m Controller) startNodeWatch(ctx context.Context, cluster *model.Cluster) {
-	nodeWatchList := cache.NewFilteredListWatchFromClient(m.kubeClient.CoreV1().RESTClient(), "nodes",
+	nodeWatchList := tracedListWatch(ctx, "nodes", cache.NewFilteredListWatchFromClient(m.kubeClient.CoreV1().RESTClient(), "nodes",
 		v1.NamespaceAll, func(options *metav1.ListOptions) {
 			options.LabelSelector = m.nodeSelector.String()
-		})
+		}))
 	_, nodeController := cache.NewInformer(
 		nodeWatchList,
 		&v1.Node{},
 		time.Second*0,
-		cache.ResourceEventHandlerFuncs{
+		invalidating(cluster, cache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
-				node := model.NewNode(obj.(*v1.Node))
-				m.updatePrice(node)
-				n := cluster.AddNode(node)
+				n := cluster.AddNode(newNode(model.NewNode(obj.(*v1.Node))))
+				m.queuePrice(n)
+				setInterruptionNotices(n, obj.(*v1.Node))
 				n.Show()
 			},
 			DeleteFunc: func(obj interface{}) {
-				cluster.DeleteNode(ignoreDeletedFinalStateUnknown(obj).(*v1.Node).Spec.ProviderID)
+				m.deleteNode(cluster, cluster.NodeKey(ignoreDeletedFinalStateUnknown(obj).(*v1.Node)))
 			},
 			UpdateFunc: func(oldObj, newObj interface{}) {
 				n := newObj.(*v1.Node)
 				if !n.DeletionTimestamp.IsZero() && len(n.Finalizers) == 0 {
-					cluster.DeleteNode(n.Spec.ProviderID)
+					m.deleteNode(cluster, cluster.NodeKey(n))
 				} else {
-					node, ok := cluster.GetNode(n.Spec.ProviderID)
+					node, ok := cluster.GetNode(cluster.NodeKey(n))
 					if !ok {
-						log.Println("unable to find node", n.Name)
+						slog.Warn("unable to find node", "node", n.Name)
 					} else {
 						node.Update(n)
-						m.updatePrice(node)
+						m.queuePrice(node)
+						setInterruptionNotices(node, n)
+						node.Show()
 					}
-					node.Show()
 				}
 			},
-		},
+		}),
 	)
-	go nodeController.Run(ctx.Done())
+	m.run(ctx, nodeController)
 }
 
 func (m Controller) startPodWatch(ctx context.Context, cluster *model.Cluster) {
-	podWatchList := cache.NewListWatchFromClient(m.kubeClient.CoreV1().RESTClient(), "pods",
-		v1.NamespaceAll, fields.Everything())
+	podWatchList := tracedListWatch(ctx, "pods", cache.NewListWatchFromClient(m.kubeClient.CoreV1().RESTClient(), "pods",
+		m.podNamespace, fields.Everything()))
 
 	_, podController := cache.NewInformer(
 		podWatchList,
 		&v1.Pod{},
 		time.Second*0,
-		cache.ResourceEventHandlerFuncs{
+		invalidating(cluster, cache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
 				p := obj.(*v1.Pod)
+				trackTerminating(cluster, p)
 				if !isTerminalPod(p) {
 					cluster.AddPod(model.NewPod(p))
-					node, ok := cluster.GetNodeByName(p.Spec.NodeName)
-					// need to potentially update node price as we need the fargate pod in order to figure out the cost
-					if ok && node.IsFargate() && !node.HasPrice() {
-						m.updatePrice(node)
-					}
+					m.updateFargatePrice(cluster, p)
 				}
 			},
 			DeleteFunc: func(obj interface{}) {
 				p := ignoreDeletedFinalStateUnknown(obj).(*v1.Pod)
 				cluster.DeletePod(p.Namespace, p.Name)
+				if node, ok := cluster.GetNodeByName(p.Spec.NodeName); ok {
+					node.ClearTerminating(p.Namespace, p.Name)
+				}
 			},
 			UpdateFunc: func(oldObj, newObj interface{}) {
 				p := newObj.(*v1.Pod)
+				trackTerminating(cluster, p)
 				if isTerminalPod(p) {
 					cluster.DeletePod(p.Namespace, p.Name)
 				} else {
@@ -184,34 +225,249 @@ func (m Controller) startPodWatch(ctx context.Context, cluster *model.Cluster) {
 						pod.Update(p)
 						cluster.AddPod(pod)
 					}
+					m.updateFargatePrice(cluster, p)
+				}
+			},
+		}),
+	)
+	m.run(ctx, podController)
+}
+
+// updateFargatePrice updates the price of the Fargate node that a pod runs on, as the price depends on the capacity
+// provisioned for the pod which is estimated until Fargate annotates the pod with it
+func (m Controller) updateFargatePrice(cluster *model.Cluster, p *v1.Pod) {
+	if node, ok := cluster.GetNodeByName(p.Spec.NodeName); ok && node.IsFargate() {
+		m.queuePrice(node)
+	}
+}
+
+// startInterruptionWatch watches for the events that Karpenter publishes when a node receives a spot interruption
+// notice or a rebalance recommendation
+func (m Controller) startInterruptionWatch(ctx context.Context, cluster *model.Cluster) {
+	// field selectors can't match either reason, so each is watched separately
+	for reason, setNotice := range map[string]func(n *model.Node, t time.Time){
+		"SpotInterrupted":             (*model.Node).SetInterrupted,
+		"SpotRebalanceRecommendation": (*model.Node).SetRebalanceRecommended,
+	} {
+		eventWatchList := tracedListWatch(ctx, "events", cache.NewListWatchFromClient(m.kubeClient.CoreV1().RESTClient(), "events",
+			v1.NamespaceAll, fields.AndSelectors(
+				fields.OneTermEqualSelector("reason", reason),
+				fields.OneTermEqualSelector("involvedObject.kind", "Node"))))
+
+		onEvent := func(obj interface{}) {
+			e := obj.(*v1.Event)
+			node, ok := cluster.GetNodeByName(e.InvolvedObject.Name)
+			if !ok {
+				return
+			}
+			ts := e.EventTime.Time
+			if ts.IsZero() {
+				ts = e.FirstTimestamp.Time
+			}
+			if ts.IsZero() {
+				ts = time.Now()
+			}
+			setNotice(node, ts)
+		}
+		_, eventController := cache.NewInformer(
+			eventWatchList,
+			&v1.Event{},
+			time.Second*0,
+			invalidating(cluster, cache.ResourceEventHandlerFuncs{
+				AddFunc: onEvent,
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					onEvent(newObj)
+				},
+			}),
+		)
+		m.run(ctx, eventController)
+	}
+}
+
+// startDisruptionBlockedWatch watches for the events that Karpenter publishes when it can't disrupt a node, e.g. due to
+// a PodDisruptionBudget, so that consolidation candidates that are stuck can be seen
+func (m Controller) startDisruptionBlockedWatch(ctx context.Context, cluster *model.Cluster) {
+	eventWatchList := tracedListWatch(ctx, "events", cache.NewListWatchFromClient(m.kubeClient.CoreV1().RESTClient(), "events",
+		v1.NamespaceAll, fields.AndSelectors(
+			fields.OneTermEqualSelector("reason", "DisruptionBlocked"),
+			fields.OneTermEqualSelector("involvedObject.kind", "Node"))))
+
+	onEvent := func(obj interface{}) {
+		e := obj.(*v1.Event)
+		if node, ok := cluster.GetNodeByName(e.InvolvedObject.Name); ok {
+			node.SetDisruptionBlocked(e.Message)
+		}
+	}
+	_, eventController := cache.NewInformer(
+		eventWatchList,
+		&v1.Event{},
+		time.Second*0,
+		invalidating(cluster, cache.ResourceEventHandlerFuncs{
+			AddFunc: onEvent,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				onEvent(newObj)
+			},
+			// events expire, after which the node is no longer considered blocked
+			DeleteFunc: func(obj interface{}) {
+				e := ignoreDeletedFinalStateUnknown(obj).(*v1.Event)
+				if node, ok := cluster.GetNodeByName(e.InvolvedObject.Name); ok && node.DisruptionBlockedEvent() == e.Message {
+					node.SetDisruptionBlocked("")
 				}
 			},
-		},
+		}),
 	)
-	go podController.Run(ctx.Done())
+	m.run(ctx, eventController)
 }
 
 func (m Controller) updatePrice(node *model.Node) {
-	// If the node has the instance-price override label, don't look up pricing
-	// and use the value here.
-	if val, ok := node.Labels()["eks-node-viewer/instance-price"]; ok {
-		if price, err := strconv.ParseFloat(val, 64); err == nil {
-			node.SetPrice(price)
-			return
+	if m.lifecycle != nil {
+		if lifecycle, ok := m.lifecycle.InstanceLifecycle(node.InstanceID()); ok {
+			node.SetInstanceLifecycle(lifecycle)
+		}
+		if tp, ok := m.lifecycle.(pricing.TenancyProvider); ok {
+			if tenancy, ok := tp.InstanceTenancy(node.InstanceID()); ok {
+				node.SetInstanceTenancy(tenancy)
+			}
 		}
 	}
-	// lookup our n price
-	node.Price = math.NaN()
-	if price, ok := m.pricing.NodePrice(node); ok {
-		node.SetPrice(price)
+	if m.instanceTypes != nil {
+		if info, ok := m.instanceTypes.InstanceTypeInfo(node.InstanceType()); ok {
+			node.SetInstanceTypeInfo(info)
+		}
+	}
+	if m.onDemand != nil {
+		if price, ok := m.onDemand.OnDemandNodePrice(node); ok {
+			node.SetOnDemandPrice(price)
+		}
 	}
+	// lookup our n price, recording which provider priced it when using a chain of providers. The price is only set
+	// once it's known, so a node being re-priced, e.g. when it registers, doesn't briefly show as unpriced.
+	var price float64
+	var source string
+	var ok bool
+	if sp, isSource := m.pricing.(pricing.SourceProvider); isSource {
+		price, source, ok = sp.NodePriceSource(node)
+	} else {
+		price, ok = m.pricing.NodePrice(node)
+	}
+	if !ok {
+		return
+	}
+	node.SetPrice(price)
+	node.SetPriceSource(source)
+}
 
+func (m Controller) deleteNode(cluster *model.Cluster, key string) {
+	if node, ok := cluster.GetNode(key); ok {
+		m.pricing.NodeDeleted(node)
+	}
+	cluster.DeleteNode(key)
 }
 
-func (m Controller) RefreshNodePrices() {
-	m.uiModel.Cluster().ForEachNode(func(n *model.Node) {
-		m.updatePrice(n)
-	})
+// deleteNodeClaim removes the node launched by a NodeClaim that's been deleted, unless the node has registered in which
+// case it's removed when the node is, and only the NodeClaim's lifecycle is forgotten
+func (m Controller) deleteNodeClaim(cluster *model.Cluster, providerID string) {
+	if node, ok := cluster.GetNode(providerID); ok && node.Registered() {
+		node.ClearNodeClaim()
+		return
+	}
+	m.deleteNode(cluster, providerID)
+}
+
+// invalidating wraps the handlers of an informer so that the cluster is marked as changed after each event, allowing
+// the display to skip rendering when nothing has changed
+func invalidating(cluster *model.Cluster, h cache.ResourceEventHandlerFuncs) cache.ResourceEventHandlerFuncs {
+	wrapped := h
+	if h.AddFunc != nil {
+		wrapped.AddFunc = func(obj interface{}) {
+			h.AddFunc(obj)
+			cluster.Invalidate()
+		}
+	}
+	if h.UpdateFunc != nil {
+		wrapped.UpdateFunc = func(oldObj, newObj interface{}) {
+			h.UpdateFunc(oldObj, newObj)
+			cluster.Invalidate()
+		}
+	}
+	if h.DeleteFunc != nil {
+		wrapped.DeleteFunc = func(obj interface{}) {
+			h.DeleteFunc(obj)
+			cluster.Invalidate()
+		}
+	}
+	return wrapped
+}
+
+// tracedListWatch wraps the list and watch calls made by an informer with tracing spans that are children of the
+// context's span, recording their latency so that the API server's responsiveness can be observed
+func tracedListWatch(ctx context.Context, resource string, lw *cache.ListWatch) *cache.ListWatch {
+	listFunc, watchFunc := lw.ListFunc, lw.WatchFunc
+	lw.ListFunc = func(options metav1.ListOptions) (runtime.Object, error) {
+		_, span := tracing.StartAPISpan(ctx, "List", attribute.String("resource", resource))
+		obj, err := listFunc(options)
+		tracing.EndSpan(span, err)
+		return obj, err
+	}
+	lw.WatchFunc = func(options metav1.ListOptions) (watch.Interface, error) {
+		_, span := tracing.StartAPISpan(ctx, "Watch", attribute.String("resource", resource))
+		w, err := watchFunc(options)
+		tracing.EndSpan(span, err)
+		return w, err
+	}
+	return lw
+}
+
+const (
+	// spotInterruptionTaint and rebalanceRecommendationTaint are added by the AWS Node Termination Handler
+	spotInterruptionTaint        = "aws-node-termination-handler/spot-itn"
+	rebalanceRecommendationTaint = "aws-node-termination-handler/rebalance-recommendation"
+	// spotInterruptionAnnotation and rebalanceRecommendationAnnotation can be set by an agent on the node that polls
+	// the EC2 instance metadata service, to the RFC 3339 time of the notice
+	spotInterruptionAnnotation        = "eks-node-viewer/spot-interruption"
+	rebalanceRecommendationAnnotation = "eks-node-viewer/rebalance-recommendation"
+)
+
+// setInterruptionNotices records the spot interruption notice and rebalance recommendation that the node's taints and
+// annotations show it has received
+func setInterruptionNotices(node *model.Node, n *v1.Node) {
+	if ts, ok := noticeTime(n, spotInterruptionTaint, spotInterruptionAnnotation); ok {
+		node.SetInterrupted(ts)
+	}
+	if ts, ok := noticeTime(n, rebalanceRecommendationTaint, rebalanceRecommendationAnnotation); ok {
+		node.SetRebalanceRecommended(ts)
+	}
+}
+
+// noticeTime returns the time that the node was tainted or annotated with a notice, the current time is used if the
+// time is unknown
+func noticeTime(n *v1.Node, taint, annotation string) (time.Time, bool) {
+	for _, t := range n.Spec.Taints {
+		if t.Key == taint {
+			if t.TimeAdded != nil {
+				return t.TimeAdded.Time, true
+			}
+			return time.Now(), true
+		}
+	}
+	if val, ok := n.Annotations[annotation]; ok {
+		if ts, err := time.Parse(time.RFC3339, val); err == nil {
+			return ts, true
+		}
+		return time.Now(), true
+	}
+	return time.Time{}, false
+}
+
+// trackTerminating records when a deleting pod should be gone by so that pods stuck terminating can be counted, the
+// deletion timestamp already includes the pod's grace period
+func trackTerminating(cluster *model.Cluster, p *v1.Pod) {
+	if p.DeletionTimestamp.IsZero() || p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
+		return
+	}
+	if node, ok := cluster.GetNodeByName(p.Spec.NodeName); ok {
+		node.SetTerminating(p.Namespace, p.Name, p.DeletionTimestamp.Time)
+	}
 }
 
 // isTerminalPod returns true if the pod is deleting or in a terminal state
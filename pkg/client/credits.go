@@ -0,0 +1,74 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// cpuCreditPollPeriod is how often the CPU credit balance of burstable nodes is polled, this matches the period that
+// EC2 publishes the balance to CloudWatch
+const cpuCreditPollPeriod = 5 * time.Minute
+
+// CPUCreditSource provides the CPU credit balance of burstable instances, keyed by instance ID
+type CPUCreditSource interface {
+	CPUCreditBalances(ctx context.Context, instanceIDs []string) (map[string]float64, error)
+}
+
+// StartCPUCredits periodically polls the CPU credit balance of the burstable nodes
+func (m Controller) StartCPUCredits(ctx context.Context, source CPUCreditSource) {
+	go func() {
+		loggedErr := false
+		for {
+			if err := m.updateCPUCredits(ctx, source); err != nil && !loggedErr {
+				slog.Error("polling CPU credits", "error", err)
+				loggedErr = true
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(cpuCreditPollPeriod):
+			}
+		}
+	}()
+}
+
+func (m Controller) updateCPUCredits(ctx context.Context, source CPUCreditSource) error {
+	nodes := map[string]*model.Node{}
+	m.cluster.ForEachNode(func(n *model.Node) {
+		// fargate and other non-EC2 nodes don't have an instance to look up
+		if n.Burstable() && strings.HasPrefix(n.InstanceID(), "i-") {
+			nodes[n.InstanceID()] = n
+		}
+	})
+	if len(nodes) == 0 {
+		return nil
+	}
+	var instanceIDs []string
+	for id := range nodes {
+		instanceIDs = append(instanceIDs, id)
+	}
+	balances, err := source.CPUCreditBalances(ctx, instanceIDs)
+	for id, balance := range balances {
+		nodes[id].SetCPUCreditBalance(balance)
+	}
+	m.cluster.Invalidate()
+	return err
+}
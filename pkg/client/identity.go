@@ -0,0 +1,115 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Identity names the cluster that a context connects to
+type Identity struct {
+	// Context is the name of the kubeconfig context
+	Context string
+	// Cluster is the name of the cluster, for EKS clusters this is the name of the cluster in EKS rather than the name
+	// of the kubeconfig entry
+	Cluster string
+	// Region is the AWS region of an EKS cluster, it's empty if it isn't known
+	Region string
+}
+
+// Identify returns the identity of the cluster that a context connects to, an empty context identifies the current
+// context. It's read from the kubeconfig alone so that it doesn't depend on kubectl or the AWS CLI being installed.
+func Identify(conn Connection, context string) (Identity, error) {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{Precedence: strings.Split(conn.Kubeconfig, ":")},
+		&clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return Identity{}, err
+	}
+	id := Identity{Context: context}
+	if id.Context == "" {
+		id.Context = conn.Overrides.CurrentContext
+	}
+	if id.Context == "" {
+		id.Context = config.CurrentContext
+	}
+	kubeContext := config.Contexts[id.Context]
+	if kubeContext == nil {
+		kubeContext = &clientcmdapi.Context{}
+	}
+	clusterKey := kubeContext.Cluster
+	if conn.Overrides.Context.Cluster != "" {
+		clusterKey = conn.Overrides.Context.Cluster
+	}
+	authKey := kubeContext.AuthInfo
+	if conn.Overrides.Context.AuthInfo != "" {
+		authKey = conn.Overrides.Context.AuthInfo
+	}
+	id.Cluster, id.Region = clusterName(clusterKey)
+	if name, region := execClusterName(config.AuthInfos[authKey]); name != "" {
+		id.Cluster = name
+		if region != "" {
+			id.Region = region
+		}
+	}
+	if id.Cluster == "" {
+		id.Cluster = id.Context
+	}
+	return id, nil
+}
+
+// execClusterName returns the cluster name and region passed to the credential plugin of a user, e.g. by
+// 'aws eks get-token --cluster-name' or 'aws-iam-authenticator token -i'
+func execClusterName(authInfo *clientcmdapi.AuthInfo) (name, region string) {
+	if authInfo == nil || authInfo.Exec == nil {
+		return "", ""
+	}
+	args := authInfo.Exec.Args
+	for i, arg := range args {
+		flag, value, hasValue := strings.Cut(arg, "=")
+		if !hasValue && i+1 < len(args) {
+			value = args[i+1]
+		}
+		switch flag {
+		case "--cluster-name", "--cluster-id", "-i":
+			name = value
+		case "--region":
+			region = value
+		}
+	}
+	return name, region
+}
+
+// clusterName returns the name and region of an EKS cluster from its kubeconfig entry, the AWS CLI names entries with
+// the cluster's ARN and eksctl with <name>.<region>.eksctl.io
+func clusterName(key string) (name, region string) {
+	if strings.HasPrefix(key, "arn:") {
+		// arn:<partition>:eks:<region>:<account>:cluster/<name>
+		if fields := strings.SplitN(key, ":", 6); len(fields) == 6 {
+			if name, ok := strings.CutPrefix(fields[5], "cluster/"); ok {
+				return name, fields[3]
+			}
+		}
+	}
+	if prefix, ok := strings.CutSuffix(key, ".eksctl.io"); ok {
+		if name, region, ok := strings.Cut(prefix, "."); ok && name != "" {
+			return name, region
+		}
+	}
+	return key, ""
+}
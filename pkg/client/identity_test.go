@@ -0,0 +1,116 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: prod
+contexts:
+- name: prod
+  context:
+    cluster: arn:aws:eks:us-west-2:123456789012:cluster/prod-east
+    user: prod
+- name: staging
+  context:
+    cluster: staging.us-west-2.eksctl.io
+    user: staging
+- name: kind
+  context:
+    cluster: kind-local
+    user: kind
+clusters:
+- name: arn:aws:eks:us-west-2:123456789012:cluster/prod-east
+  cluster:
+    server: https://prod.example.com
+- name: staging.us-west-2.eksctl.io
+  cluster:
+    server: https://staging.example.com
+- name: kind-local
+  cluster:
+    server: https://127.0.0.1:6443
+users:
+- name: prod
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: aws
+      args: ["--region", "us-west-2", "eks", "get-token", "--cluster-name", "prod-west", "--output", "json"]
+- name: staging
+  user:
+    token: abc
+- name: kind
+  user:
+    token: abc
+`
+
+func TestIdentify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	conn := Connection{Kubeconfig: path}
+	for context, exp := range map[string]Identity{
+		// the current context, the exec plugin's cluster name wins over the kubeconfig entry
+		"":        {Context: "prod", Cluster: "prod-west", Region: "us-west-2"},
+		"staging": {Context: "staging", Cluster: "staging", Region: "us-west-2"},
+		"kind":    {Context: "kind", Cluster: "kind-local"},
+		// unknown contexts are named after the context
+		"missing": {Context: "missing", Cluster: "missing"},
+	} {
+		id, err := Identify(conn, context)
+		if err != nil {
+			t.Fatalf("identifying %q, %s", context, err)
+		}
+		if id != exp {
+			t.Errorf("expected %q to be %+v, got %+v", context, exp, id)
+		}
+	}
+
+	// kubectl's --context and --cluster flags override the kubeconfig when running as a plugin
+	conn.Overrides = clientcmd.ConfigOverrides{CurrentContext: "staging"}
+	if id, _ := Identify(conn, ""); id.Context != "staging" {
+		t.Errorf("expected the overridden context, got %+v", id)
+	}
+	conn.Overrides.Context.Cluster = "arn:aws:eks:eu-west-1:123456789012:cluster/other"
+	if id, _ := Identify(conn, ""); id.Cluster != "other" || id.Region != "eu-west-1" {
+		t.Errorf("expected the overridden cluster, got %+v", id)
+	}
+}
+
+func TestImpersonateGroups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := getConfig(Connection{Kubeconfig: path, Impersonate: Impersonation{
+		User:   "viewer",
+		Groups: []string{" dashboards", "", "viewers ", "  "},
+	}}, "kind")
+	if err != nil {
+		t.Fatalf("getting config, %s", err)
+	}
+	if exp, got := []string{"dashboards", "viewers"}, config.Impersonate.Groups; !slices.Equal(exp, got) {
+		t.Errorf("expected to impersonate the groups %q, got %q", exp, got)
+	}
+}
@@ -0,0 +1,338 @@
+//go:build integration
+
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/awslabs/operatorpkg/status"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	karpv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// testEnv runs a controller against a local API server. Objects are created through the API, as they would be in a
+// cluster, and the tests wait for the model to reflect them.
+type testEnv struct {
+	t          *testing.T
+	ctx        context.Context
+	kube       *kubernetes.Clientset
+	nodeClaims *rest.RESTClient
+	// nodeClaimsV1Beta1 creates NodeClaims on servers that only serve v1beta1
+	nodeClaimsV1Beta1 *rest.RESTClient
+	ui                *model.UIModel
+	cluster           *model.Cluster
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	return newTestEnvServing(t, "v1")
+}
+
+// newTestEnvServing runs the controller against a cluster that serves NodeClaims in the given versions
+func newTestEnvServing(t *testing.T, nodeClaimVersions ...string) *testEnv {
+	conn := Connection{Kubeconfig: startAPIServer(t, nodeClaimVersions...)}
+	kube, err := NewKubernetes(conn, "")
+	if err != nil {
+		t.Fatalf("creating client, %s", err)
+	}
+	nodeClaims, err := NewNodeClaims(conn, "")
+	if err != nil {
+		t.Fatalf("creating nodeclaims client, %s", err)
+	}
+	nodeClaimsV1Beta1, err := NewNodeClaimsV1Beta1(conn, "")
+	if err != nil {
+		t.Fatalf("creating v1beta1 nodeclaims client, %s", err)
+	}
+
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "creation", style)
+	ui.SetResources([]string{"cpu"})
+
+	// the controller is stopped before the server is closed, so that its watches have ended
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	controller := NewController(kube, nodeClaims, nil, ui.Cluster(), labels.Everything(),
+		&countingProvider{priced: map[string]int{}}, nil)
+	controller.SetNodeClaimV1Beta1Client(nodeClaimsV1Beta1)
+	controller.Start(ctx)
+	syncCtx, syncCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer syncCancel()
+	if !controller.WaitForSync(syncCtx) {
+		t.Fatal("timed out waiting for the controller to sync")
+	}
+	return &testEnv{t: t, ctx: ctx, kube: kube, nodeClaims: nodeClaims, nodeClaimsV1Beta1: nodeClaimsV1Beta1, ui: ui,
+		cluster: ui.Cluster()}
+}
+
+// eventually fails the test if the condition isn't met soon, the model is updated asynchronously by the informers
+func (e *testEnv) eventually(what string, condition func() bool) {
+	e.t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			e.t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// createNode creates a node and then sets its status, which the API server ignores on create as it's a subresource
+// that the kubelet updates
+func (e *testEnv) createNode(n *v1.Node) {
+	e.t.Helper()
+	created, err := e.kube.CoreV1().Nodes().Create(e.ctx, n, metav1.CreateOptions{})
+	if err != nil {
+		e.t.Fatalf("creating node %s, %s", n.Name, err)
+	}
+	created.Status = n.Status
+	if _, err := e.kube.CoreV1().Nodes().UpdateStatus(e.ctx, created, metav1.UpdateOptions{}); err != nil {
+		e.t.Fatalf("updating node %s status, %s", n.Name, err)
+	}
+}
+
+func (e *testEnv) deleteNode(name string) {
+	e.t.Helper()
+	if err := e.kube.CoreV1().Nodes().Delete(e.ctx, name, metav1.DeleteOptions{}); err != nil {
+		e.t.Fatalf("deleting node %s, %s", name, err)
+	}
+}
+
+// createPod creates a pod and then sets its status, as the kubelet would once it's running
+func (e *testEnv) createPod(p *v1.Pod) {
+	e.t.Helper()
+	created, err := e.kube.CoreV1().Pods(p.Namespace).Create(e.ctx, p, metav1.CreateOptions{})
+	if err != nil {
+		e.t.Fatalf("creating pod %s/%s, %s", p.Namespace, p.Name, err)
+	}
+	created.Status = p.Status
+	if _, err := e.kube.CoreV1().Pods(p.Namespace).UpdateStatus(e.ctx, created, metav1.UpdateOptions{}); err != nil {
+		e.t.Fatalf("updating pod %s/%s status, %s", p.Namespace, p.Name, err)
+	}
+}
+
+// deletePod deletes a pod immediately, there's no kubelet to stop it and finish a graceful deletion
+func (e *testEnv) deletePod(namespace, name string) {
+	e.t.Helper()
+	immediately := int64(0)
+	if err := e.kube.CoreV1().Pods(namespace).Delete(e.ctx, name,
+		metav1.DeleteOptions{GracePeriodSeconds: &immediately}); err != nil {
+		e.t.Fatalf("deleting pod %s/%s, %s", namespace, name, err)
+	}
+}
+
+// createNodeClaim creates a NodeClaim and then sets its status, as Karpenter would once it's launched
+func (e *testEnv) createNodeClaim(nc *karpv1.NodeClaim) {
+	e.t.Helper()
+	created := &karpv1.NodeClaim{}
+	if err := e.nodeClaims.Post().Resource("nodeclaims").Body(nc).Do(e.ctx).Into(created); err != nil {
+		e.t.Fatalf("creating nodeclaim %s, %s", nc.Name, err)
+	}
+	created.Status = nc.Status
+	if err := e.nodeClaims.Put().Resource("nodeclaims").Name(nc.Name).SubResource("status").Body(created).
+		Do(e.ctx).Error(); err != nil {
+		e.t.Fatalf("updating nodeclaim %s status, %s", nc.Name, err)
+	}
+}
+
+func (e *testEnv) createNodeClaimV1Beta1(nc *NodeClaimV1Beta1) {
+	e.t.Helper()
+	created := &NodeClaimV1Beta1{}
+	if err := e.nodeClaimsV1Beta1.Post().Resource("nodeclaims").Body(nc).Do(e.ctx).Into(created); err != nil {
+		e.t.Fatalf("creating v1beta1 nodeclaim %s, %s", nc.Name, err)
+	}
+	created.Status = nc.Status
+	if err := e.nodeClaimsV1Beta1.Put().Resource("nodeclaims").Name(nc.Name).SubResource("status").Body(created).
+		Do(e.ctx).Error(); err != nil {
+		e.t.Fatalf("updating v1beta1 nodeclaim %s status, %s", nc.Name, err)
+	}
+}
+
+func (e *testEnv) nodeCount() int {
+	count := 0
+	e.cluster.ForEachNode(func(*model.Node) { count++ })
+	return count
+}
+
+func testNode(name string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.NodeSpec{ProviderID: "aws:///us-west-2a/" + name},
+		Status: v1.NodeStatus{
+			Capacity:    v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+			Allocatable: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+			Conditions:  []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}},
+		},
+	}
+}
+
+func testPod(name, nodeName string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{{
+				Name:  "app",
+				Image: "app",
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+				},
+			}},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func TestIntegrationNodesAndPods(t *testing.T) {
+	env := newTestEnv(t)
+	env.createNode(testNode("node-a"))
+	env.createPod(testPod("web", "node-a"))
+
+	var node *model.Node
+	env.eventually("the pod to be bound to the node", func() bool {
+		var ok bool
+		node, ok = env.cluster.GetNodeByName("node-a")
+		return ok && node.NumPods() == 1
+	})
+	if used := node.Used()[v1.ResourceCPU]; used.Cmp(resource.MustParse("1")) != 0 {
+		t.Errorf("expected 1 CPU to be used, got %s", used.String())
+	}
+	env.eventually("the node to be priced", node.HasPrice)
+
+	var buf bytes.Buffer
+	if err := env.ui.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	if !strings.Contains(buf.String(), "node-a") || !strings.Contains(buf.String(), "1 pods") {
+		t.Errorf("expected the snapshot to show node-a and its pod, got\n%s", buf.String())
+	}
+
+	env.deletePod("default", "web")
+	env.eventually("the pod to be unbound", func() bool { return node.NumPods() == 0 })
+
+	env.deleteNode("node-a")
+	env.eventually("the node to be removed", func() bool {
+		_, ok := env.cluster.GetNodeByName("node-a")
+		return !ok
+	})
+}
+
+func TestIntegrationPodBeforeNode(t *testing.T) {
+	env := newTestEnv(t)
+	// the pod is seen before the node that it's scheduled to
+	env.createPod(testPod("web", "node-b"))
+	env.eventually("the pod to be added", func() bool {
+		_, ok := env.cluster.GetPod("default", "web")
+		return ok
+	})
+
+	env.createNode(testNode("node-b"))
+	env.eventually("the pod to be bound once the node is seen", func() bool {
+		node, ok := env.cluster.GetNodeByName("node-b")
+		return ok && node.NumPods() == 1
+	})
+}
+
+func TestIntegrationNodeClaimHandoff(t *testing.T) {
+	env := newTestEnv(t)
+	node := testNode("node-c")
+	env.createNodeClaim(&karpv1.NodeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-abcde"},
+		// the API server validates NodeClaims against Karpenter's schema, which requires a node class and requirements
+		Spec: karpv1.NodeClaimSpec{
+			NodeClassRef: &karpv1.NodeClassReference{Group: "karpenter.k8s.aws", Kind: "EC2NodeClass", Name: "default"},
+			Requirements: []karpv1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
+				Key: karpv1.CapacityTypeLabelKey, Operator: v1.NodeSelectorOpIn, Values: []string{karpv1.CapacityTypeOnDemand},
+			}}},
+		},
+		Status: karpv1.NodeClaimStatus{
+			ProviderID: node.Spec.ProviderID,
+			Capacity:   node.Status.Capacity,
+			Conditions: []status.Condition{
+				{Type: "Launched", Status: metav1.ConditionTrue, LastTransitionTime: metav1.Now()},
+				{Type: "Registered", Status: metav1.ConditionUnknown, LastTransitionTime: metav1.Now()},
+			},
+		},
+	})
+
+	var claimed *model.Node
+	env.eventually("the nodeclaim to be shown", func() bool {
+		var ok bool
+		claimed, ok = env.cluster.GetNode(node.Spec.ProviderID)
+		return ok
+	})
+	if lifecycle := claimed.NodeClaimLifecycle(); lifecycle != "NotRegistered" {
+		t.Errorf("expected the nodeclaim to be NotRegistered, got %s", lifecycle)
+	}
+
+	// the node registers, and takes over from the nodeclaim rather than being shown twice
+	env.createNode(node)
+	env.eventually("the node to be indexed by name", func() bool {
+		_, ok := env.cluster.GetNodeByName("node-c")
+		return ok
+	})
+	registered, _ := env.cluster.GetNodeByName("node-c")
+	if registered != claimed {
+		t.Error("expected the node to replace the nodeclaim's node")
+	}
+	if count := env.nodeCount(); count != 1 {
+		t.Errorf("expected a single node, got %d", count)
+	}
+	if lifecycle := registered.NodeClaimLifecycle(); lifecycle != "NotRegistered" {
+		t.Errorf("expected the nodeclaim's lifecycle to be kept, got %s", lifecycle)
+	}
+}
+
+func TestIntegrationNodeClaimV1Beta1(t *testing.T) {
+	// a cluster running a Karpenter version that only serves v1beta1
+	env := newTestEnvServing(t, "v1beta1")
+	node := testNode("node-d")
+	env.createNodeClaimV1Beta1(&NodeClaimV1Beta1{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-fghij"},
+		Status: NodeClaimV1Beta1Status{
+			ProviderID: node.Spec.ProviderID,
+			Capacity:   node.Status.Capacity,
+			Conditions: []status.Condition{
+				{Type: "Launched", Status: metav1.ConditionTrue, LastTransitionTime: metav1.Now()},
+				{Type: "Registered", Status: metav1.ConditionFalse, Reason: "NodeNotFound",
+					LastTransitionTime: metav1.Now()},
+			},
+		},
+	})
+
+	var claimed *model.Node
+	env.eventually("the nodeclaim to be shown", func() bool {
+		var ok bool
+		claimed, ok = env.cluster.GetNode(node.Spec.ProviderID)
+		return ok
+	})
+	if lifecycle := claimed.NodeClaimLifecycle(); lifecycle != "NotRegistered/NodeNotFound" {
+		t.Errorf("expected the nodeclaim to be NotRegistered/NodeNotFound, got %s", lifecycle)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	karpv1apis "sigs.k8s.io/karpenter/pkg/apis"
+	karpv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// Machine is the subset of the legacy karpenter.sh/v1alpha5 Machine that we display. Machines were replaced by
+// NodeClaims and the type is no longer part of the Karpenter module, so only the fields we need are decoded.
+type Machine struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              MachineSpec   `json:"spec,omitempty"`
+	Status            MachineStatus `json:"status,omitempty"`
+}
+
+type MachineSpec struct {
+	Taints []v1.Taint `json:"taints,omitempty"`
+}
+
+type MachineStatus struct {
+	NodeName    string          `json:"nodeName,omitempty"`
+	ProviderID  string          `json:"providerID,omitempty"`
+	Capacity    v1.ResourceList `json:"capacity,omitempty"`
+	Allocatable v1.ResourceList `json:"allocatable,omitempty"`
+}
+
+// MachineList is a list of legacy Machines
+type MachineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Machine `json:"items"`
+}
+
+func (m *Machine) DeepCopyInto(out *Machine) {
+	*out = *m
+	out.TypeMeta = m.TypeMeta
+	m.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if m.Spec.Taints != nil {
+		out.Spec.Taints = make([]v1.Taint, len(m.Spec.Taints))
+		for i := range m.Spec.Taints {
+			m.Spec.Taints[i].DeepCopyInto(&out.Spec.Taints[i])
+		}
+	}
+	out.Status.Capacity = m.Status.Capacity.DeepCopy()
+	out.Status.Allocatable = m.Status.Allocatable.DeepCopy()
+}
+
+func (m *Machine) DeepCopyObject() runtime.Object {
+	out := &Machine{}
+	m.DeepCopyInto(out)
+	return out
+}
+
+func (m *MachineList) DeepCopyObject() runtime.Object {
+	out := &MachineList{TypeMeta: m.TypeMeta}
+	m.ListMeta.DeepCopyInto(&out.ListMeta)
+	if m.Items != nil {
+		out.Items = make([]Machine, len(m.Items))
+		for i := range m.Items {
+			m.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+	return out
+}
+
+// NodeClaim converts the machine to the equivalent NodeClaim so that it's displayed the same way
+func (m *Machine) NodeClaim() *karpv1.NodeClaim {
+	nc := &karpv1.NodeClaim{}
+	nc.ObjectMeta = m.ObjectMeta
+	nc.Spec.Taints = m.Spec.Taints
+	nc.Status.NodeName = m.Status.NodeName
+	nc.Status.ProviderID = m.Status.ProviderID
+	nc.Status.Capacity = m.Status.Capacity
+	nc.Status.Allocatable = m.Status.Allocatable
+	return nc
+}
+
+// NewMachines returns a client for the legacy karpenter.sh/v1alpha5 Machine resources used by Karpenter versions
+// prior to v0.32
+func NewMachines(conn Connection, context string) (*rest.RESTClient, error) {
+	c, err := getConfig(conn, context)
+	if err != nil {
+		return nil, err
+	}
+
+	gv := schema.GroupVersion{Group: karpv1apis.Group, Version: "v1alpha5"}
+	scheme.Scheme.AddKnownTypes(gv,
+		&Machine{},
+		&MachineList{})
+
+	config := *c
+	config.ContentConfig.GroupVersion = &gv
+	config.APIPath = "/apis"
+	config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	config.UserAgent = rest.DefaultKubernetesUserAgent()
+
+	return rest.RESTClientFor(&config)
+}
+
+func (m Controller) startMachineWatch(ctx context.Context, cluster *model.Cluster) {
+	machineWatchList := tracedListWatch(ctx, "machines", cache.NewFilteredListWatchFromClient(m.machineClient, "machines",
+		v1.NamespaceAll, func(options *metav1.ListOptions) {
+			options.LabelSelector = m.nodeSelector.String()
+		}))
+	addMachine := func(obj interface{}) {
+		nc := obj.(*Machine).NodeClaim()
+		if nc.Status.ProviderID == "" {
+			return
+		}
+		if n, ok := cluster.GetNode(nc.Status.ProviderID); ok {
+			n.UpdateNodeClaim(nc)
+			return
+		}
+		n := cluster.AddNode(newNode(model.NewNodeFromNodeClaim(nc)))
+		m.queuePrice(n)
+		n.Show()
+	}
+	_, machineController := cache.NewInformer(
+		machineWatchList,
+		&Machine{},
+		time.Second*0,
+		invalidating(cluster, cache.ResourceEventHandlerFuncs{
+			AddFunc: addMachine,
+			DeleteFunc: func(obj interface{}) {
+				m.deleteNodeClaim(cluster, ignoreDeletedFinalStateUnknown(obj).(*Machine).Status.ProviderID)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				addMachine(newObj)
+			},
+		}),
+	)
+	m.run(ctx, machineController)
+}
@@ -0,0 +1,81 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
+)
+
+// metricsPollPeriod is how often node usage is polled from the metrics API, this matches the default metrics-server
+// resolution
+const metricsPollPeriod = 15 * time.Second
+
+// nodeMetricsList mirrors the metrics.k8s.io NodeMetricsList, only the fields we use are decoded
+type nodeMetricsList struct {
+	Items []struct {
+		metav1.ObjectMeta `json:"metadata"`
+		Usage             v1.ResourceList `json:"usage"`
+	} `json:"items"`
+}
+
+// StartUsageMetrics periodically polls the metrics API for the actual resource usage of each node
+func (m Controller) StartUsageMetrics(ctx context.Context) {
+	go func() {
+		loggedErr := false
+		for {
+			if err := m.updateUsageMetrics(ctx); err != nil && !loggedErr {
+				slog.Error("polling node metrics", "error", err)
+				loggedErr = true
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(metricsPollPeriod):
+			}
+		}
+	}()
+}
+
+func (m Controller) updateUsageMetrics(ctx context.Context) (err error) {
+	ctx, span := tracing.StartAPISpan(ctx, "ListNodeMetrics")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	raw, err := m.kubeClient.Discovery().RESTClient().Get().
+		AbsPath("/apis/metrics.k8s.io/v1beta1/nodes").
+		Param("labelSelector", m.nodeSelector.String()).
+		Do(ctx).Raw()
+	if err != nil {
+		return err
+	}
+	var metrics nodeMetricsList
+	if err := json.Unmarshal(raw, &metrics); err != nil {
+		return err
+	}
+	for _, nm := range metrics.Items {
+		if node, ok := m.cluster.GetNodeByName(nm.Name); ok {
+			node.SetActualUsage(nm.Usage)
+		}
+	}
+	m.cluster.Invalidate()
+	return nil
+}
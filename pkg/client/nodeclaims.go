@@ -0,0 +1,205 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/awslabs/operatorpkg/status"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	karpv1apis "sigs.k8s.io/karpenter/pkg/apis"
+	karpv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// NodeClaimV1Beta1 is the subset of the karpenter.sh/v1beta1 NodeClaim that we display. It's served by Karpenter
+// versions from v0.32 until v1.1, which dropped it, so the type is no longer part of the Karpenter module and only the
+// fields we need are decoded.
+type NodeClaimV1Beta1 struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              NodeClaimV1Beta1Spec   `json:"spec,omitempty"`
+	Status            NodeClaimV1Beta1Status `json:"status,omitempty"`
+}
+
+type NodeClaimV1Beta1Spec struct {
+	Taints []v1.Taint `json:"taints,omitempty"`
+}
+
+type NodeClaimV1Beta1Status struct {
+	NodeName    string          `json:"nodeName,omitempty"`
+	ProviderID  string          `json:"providerID,omitempty"`
+	Capacity    v1.ResourceList `json:"capacity,omitempty"`
+	Allocatable v1.ResourceList `json:"allocatable,omitempty"`
+	// Conditions have the same type, status and reason as v1, their severity isn't displayed so it isn't decoded
+	Conditions []status.Condition `json:"conditions,omitempty"`
+}
+
+// NodeClaimV1Beta1List is a list of v1beta1 NodeClaims
+type NodeClaimV1Beta1List struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeClaimV1Beta1 `json:"items"`
+}
+
+func (nc *NodeClaimV1Beta1) DeepCopyInto(out *NodeClaimV1Beta1) {
+	*out = *nc
+	out.TypeMeta = nc.TypeMeta
+	nc.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if nc.Spec.Taints != nil {
+		out.Spec.Taints = make([]v1.Taint, len(nc.Spec.Taints))
+		for i := range nc.Spec.Taints {
+			nc.Spec.Taints[i].DeepCopyInto(&out.Spec.Taints[i])
+		}
+	}
+	out.Status.Capacity = nc.Status.Capacity.DeepCopy()
+	out.Status.Allocatable = nc.Status.Allocatable.DeepCopy()
+	if nc.Status.Conditions != nil {
+		out.Status.Conditions = make([]status.Condition, len(nc.Status.Conditions))
+		for i := range nc.Status.Conditions {
+			out.Status.Conditions[i] = nc.Status.Conditions[i]
+			nc.Status.Conditions[i].LastTransitionTime.DeepCopyInto(&out.Status.Conditions[i].LastTransitionTime)
+		}
+	}
+}
+
+func (nc *NodeClaimV1Beta1) DeepCopyObject() runtime.Object {
+	out := &NodeClaimV1Beta1{}
+	nc.DeepCopyInto(out)
+	return out
+}
+
+func (l *NodeClaimV1Beta1List) DeepCopyObject() runtime.Object {
+	out := &NodeClaimV1Beta1List{TypeMeta: l.TypeMeta}
+	l.ListMeta.DeepCopyInto(&out.ListMeta)
+	if l.Items != nil {
+		out.Items = make([]NodeClaimV1Beta1, len(l.Items))
+		for i := range l.Items {
+			l.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+	return out
+}
+
+// NodeClaim converts the v1beta1 NodeClaim to v1 so that it's displayed the same way
+func (nc *NodeClaimV1Beta1) NodeClaim() *karpv1.NodeClaim {
+	out := &karpv1.NodeClaim{}
+	out.ObjectMeta = nc.ObjectMeta
+	out.Spec.Taints = nc.Spec.Taints
+	out.Status.NodeName = nc.Status.NodeName
+	out.Status.ProviderID = nc.Status.ProviderID
+	out.Status.Capacity = nc.Status.Capacity
+	out.Status.Allocatable = nc.Status.Allocatable
+	out.Status.Conditions = nc.Status.Conditions
+	return out
+}
+
+// NewNodeClaimsV1Beta1 returns a client for the karpenter.sh/v1beta1 NodeClaims served by Karpenter versions prior to
+// v1.1, which is used when the cluster doesn't serve v1
+func NewNodeClaimsV1Beta1(conn Connection, context string) (*rest.RESTClient, error) {
+	c, err := getConfig(conn, context)
+	if err != nil {
+		return nil, err
+	}
+
+	gv := schema.GroupVersion{Group: karpv1apis.Group, Version: "v1beta1"}
+	// the types are named for their version, so they're registered under the kinds the API server uses
+	scheme.Scheme.AddKnownTypeWithName(gv.WithKind("NodeClaim"), &NodeClaimV1Beta1{})
+	scheme.Scheme.AddKnownTypeWithName(gv.WithKind("NodeClaimList"), &NodeClaimV1Beta1List{})
+
+	config := *c
+	config.ContentConfig.GroupVersion = &gv
+	config.APIPath = "/apis"
+	config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	config.UserAgent = rest.DefaultKubernetesUserAgent()
+
+	return rest.RESTClientFor(&config)
+}
+
+// servesNodeClaims returns true if the API server serves NodeClaims in the given version of the karpenter.sh group
+func servesNodeClaims(client discovery.DiscoveryInterface, version string) bool {
+	resources, err := client.ServerResourcesForGroupVersion(karpv1apis.Group + "/" + version)
+	if err != nil {
+		return false
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == "nodeclaims" {
+			return true
+		}
+	}
+	return false
+}
+
+// startNodeClaimWatches watches the NodeClaims in the newest version that the cluster serves. While Karpenter is being
+// upgraded both versions may be served, they're the same objects so only one is watched to avoid seeing each twice.
+func (m Controller) startNodeClaimWatches(ctx context.Context, cluster *model.Cluster) {
+	switch {
+	case servesNodeClaims(m.kubeClient.Discovery(), "v1"):
+		m.startNodeClaimWatch(ctx, cluster, m.nodeClaimClient, &karpv1.NodeClaim{}, func(obj interface{}) *karpv1.NodeClaim {
+			return obj.(*karpv1.NodeClaim)
+		})
+	case m.nodeClaimV1Beta1Client != nil && servesNodeClaims(m.kubeClient.Discovery(), "v1beta1"):
+		m.startNodeClaimWatch(ctx, cluster, m.nodeClaimV1Beta1Client, &NodeClaimV1Beta1{}, func(obj interface{}) *karpv1.NodeClaim {
+			return obj.(*NodeClaimV1Beta1).NodeClaim()
+		})
+	}
+}
+
+// startNodeClaimWatch watches the NodeClaims returned by the client, which are converted to v1 to update the model
+func (m Controller) startNodeClaimWatch(ctx context.Context, cluster *model.Cluster, client *rest.RESTClient, objType runtime.Object, toNodeClaim func(interface{}) *karpv1.NodeClaim) {
+	nodeClaimWatchList := tracedListWatch(ctx, "nodeclaims", cache.NewFilteredListWatchFromClient(client, "nodeclaims",
+		v1.NamespaceAll, func(options *metav1.ListOptions) {
+			options.LabelSelector = m.nodeSelector.String()
+		}))
+	addNodeClaim := func(obj interface{}) {
+		nc := toNodeClaim(obj)
+		if nc.Status.ProviderID == "" {
+			return
+		}
+		// the node may have been seen first, in which case it picks up the NodeClaim's lifecycle, and the lifecycle
+		// conditions are kept up to date even after the node has registered so drift is visible
+		if n, ok := cluster.GetNode(nc.Status.ProviderID); ok {
+			n.UpdateNodeClaim(nc)
+			return
+		}
+		n := cluster.AddNode(newNode(model.NewNodeFromNodeClaim(nc)))
+		m.queuePrice(n)
+		n.Show()
+	}
+	_, nodeClaimController := cache.NewInformer(
+		nodeClaimWatchList,
+		objType,
+		time.Second*0,
+		invalidating(cluster, cache.ResourceEventHandlerFuncs{
+			AddFunc: addNodeClaim,
+			DeleteFunc: func(obj interface{}) {
+				m.deleteNodeClaim(cluster, toNodeClaim(ignoreDeletedFinalStateUnknown(obj)).Status.ProviderID)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				addNodeClaim(newObj)
+			},
+		}),
+	)
+	m.run(ctx, nodeClaimController)
+}
@@ -0,0 +1,58 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/awslabs/operatorpkg/status"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	karpv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestDeleteNodeClaim(t *testing.T) {
+	m := Controller{cluster: model.NewCluster(), pricing: &countingProvider{priced: map[string]int{}}}
+	nodeClaim := func(providerID string) *karpv1.NodeClaim {
+		nc := &karpv1.NodeClaim{}
+		nc.Status.ProviderID = providerID
+		nc.Status.Conditions = []status.Condition{{Type: "Launched", Status: metav1.ConditionTrue}}
+		return nc
+	}
+
+	// a NodeClaim that's deleted before its node registers takes the node with it
+	m.cluster.AddNode(model.NewNodeFromNodeClaim(nodeClaim("unregistered-id")))
+	m.deleteNodeClaim(m.cluster, "unregistered-id")
+	if _, ok := m.cluster.GetNode("unregistered-id"); ok {
+		t.Errorf("expected the unregistered node to be deleted with its NodeClaim")
+	}
+
+	// once the node has registered it outlives its NodeClaim, which only forgets the NodeClaim's lifecycle
+	m.cluster.AddNode(model.NewNodeFromNodeClaim(nodeClaim("registered-id")))
+	m.cluster.AddNode(model.NewNode(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "registered"},
+		Spec:       v1.NodeSpec{ProviderID: "registered-id"},
+	}))
+	m.deleteNodeClaim(m.cluster, "registered-id")
+	node, ok := m.cluster.GetNode("registered-id")
+	if !ok {
+		t.Fatalf("expected the registered node to outlive its NodeClaim")
+	}
+	if exp, got := "-", node.NodeClaimLifecycle(); exp != got {
+		t.Errorf("expected the NodeClaim lifecycle to be %q, got %q", exp, got)
+	}
+}
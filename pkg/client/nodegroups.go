@@ -0,0 +1,54 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// managedNodeGroupPollPeriod is how often the capacity of the managed node groups is polled
+const managedNodeGroupPollPeriod = time.Minute
+
+// ManagedNodeGroupSource provides the desired and actual capacity of a cluster's managed node groups
+type ManagedNodeGroupSource interface {
+	ManagedNodeGroups(ctx context.Context) ([]model.ManagedNodeGroup, error)
+}
+
+// StartManagedNodeGroups periodically polls the capacity of the cluster's managed node groups
+func (m Controller) StartManagedNodeGroups(ctx context.Context, source ManagedNodeGroupSource) {
+	go func() {
+		loggedErr := false
+		for {
+			groups, err := source.ManagedNodeGroups(ctx)
+			if err != nil {
+				if !loggedErr {
+					slog.Error("polling managed node groups", "error", err)
+					loggedErr = true
+				}
+			} else {
+				m.cluster.SetManagedNodeGroups(groups)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(managedNodeGroupPollPeriod):
+			}
+		}
+	}()
+}
@@ -0,0 +1,130 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// priceWorkers is the number of nodes that are priced concurrently
+const priceWorkers = 4
+
+// priceQueue holds the nodes waiting to be priced, so that pricing happens outside of the informers' event handlers and
+// a slow pricing provider can't delay the processing of node events. A node that's queued again before it's priced is
+// only priced once.
+type priceQueue struct {
+	queue workqueue.TypedInterface[*model.Node]
+	// refresh signals that every node should be re-priced, requests made while one is already pending are coalesced
+	refresh        chan struct{}
+	refreshPending atomic.Bool
+	// busy is the number of nodes being priced, along with a pending refresh
+	busy atomic.Int64
+}
+
+func newPriceQueue() *priceQueue {
+	return &priceQueue{
+		queue:   workqueue.NewTyped[*model.Node](),
+		refresh: make(chan struct{}, 1),
+	}
+}
+
+// idle returns true if there are no nodes waiting to be priced or being priced
+func (q *priceQueue) idle() bool {
+	return q.queue.Len() == 0 && q.busy.Load() == 0
+}
+
+// queuePrice queues a node to be priced, its price is unknown until then
+func (m Controller) queuePrice(node *model.Node) {
+	m.prices.queue.Add(node)
+}
+
+// newNode returns the node for a newly observed node, its price is unknown rather than zero until it's been priced
+func newNode(node *model.Node) *model.Node {
+	node.SetPrice(math.NaN())
+	return node
+}
+
+// RefreshNodePrices re-prices every node, it's called when the prices of a provider are updated. Refreshes requested
+// while one is pending are coalesced, as each provider may update several times in quick succession.
+func (m Controller) RefreshNodePrices() {
+	if m.prices.refreshPending.CompareAndSwap(false, true) {
+		m.prices.busy.Add(1)
+		m.prices.refresh <- struct{}{}
+	}
+}
+
+// startPriceWorkers prices the queued nodes until the context is done
+func (m Controller) startPriceWorkers(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		m.prices.queue.ShutDown()
+	}()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.prices.refresh:
+				// a refresh requested while the nodes are being queued may be for prices that they've already missed
+				m.prices.refreshPending.Store(false)
+				m.cluster.ForEachNode(m.queuePrice)
+				m.prices.busy.Add(-1)
+			}
+		}
+	}()
+	for range priceWorkers {
+		go func() {
+			for m.priceNext() {
+			}
+		}()
+	}
+}
+
+// priceNext prices the next queued node, returning false once the queue has been shut down
+func (m Controller) priceNext() bool {
+	node, shutdown := m.prices.queue.Get()
+	if shutdown {
+		return false
+	}
+	m.prices.busy.Add(1)
+	defer m.prices.busy.Add(-1)
+	defer m.prices.queue.Done(node)
+	// nodes deleted while they were queued no longer need a price
+	if !m.cluster.HasNode(node) {
+		return true
+	}
+	m.updatePrice(node)
+	m.cluster.Invalidate()
+	return true
+}
+
+// waitForPrices waits for the queued nodes to be priced, returning false if the context is done first
+func (m Controller) waitForPrices(ctx context.Context) bool {
+	for !m.prices.idle() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// countingProvider prices every node at $1, counting the nodes that it has priced
+type countingProvider struct {
+	mu     sync.Mutex
+	priced map[string]int
+}
+
+func (p *countingProvider) NodePrice(n *model.Node) (float64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.priced[n.Name()]++
+	return 1, true
+}
+
+func (p *countingProvider) NodeDeleted(*model.Node) {}
+
+func (p *countingProvider) OnUpdate(func()) {}
+
+func (p *countingProvider) total() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	total := 0
+	for _, count := range p.priced {
+		total += count
+	}
+	return total
+}
+
+func TestPriceQueue(t *testing.T) {
+	provider := &countingProvider{priced: map[string]int{}}
+	m := Controller{cluster: model.NewCluster(), pricing: provider, prices: newPriceQueue()}
+	var nodes []*model.Node
+	for i := range 3 {
+		name := fmt.Sprintf("node-%d", i)
+		n := m.cluster.AddNode(newNode(model.NewNode(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.NodeSpec{ProviderID: name + "-id"},
+		})))
+		if n.HasPrice() {
+			t.Errorf("expected %s to have an unknown price until it's priced", name)
+		}
+		nodes = append(nodes, n)
+	}
+	// nodes queued again before they're priced are only priced once, and refreshes are coalesced
+	for _, n := range nodes {
+		m.queuePrice(n)
+		m.queuePrice(n)
+	}
+	for range 5 {
+		m.RefreshNodePrices()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.startPriceWorkers(ctx)
+	if !m.waitForPrices(ctx) {
+		t.Fatal("expected the nodes to be priced")
+	}
+	for _, n := range nodes {
+		if !n.HasPrice() || n.Price() != 1 {
+			t.Errorf("expected %s to be priced at $1, got %f", n.Name(), n.Price())
+		}
+	}
+	// the refresh may find the nodes still queued from before, or priced already
+	if total := provider.total(); total < 3 || total > 6 {
+		t.Errorf("expected each node to be priced once or twice, got %d prices", total)
+	}
+
+	// nodes that are deleted while queued aren't priced
+	before := provider.total()
+	m.cluster.DeleteNode(nodes[0].ProviderID())
+	m.queuePrice(nodes[0])
+	if !m.waitForPrices(ctx) {
+		t.Fatal("expected the queue to drain")
+	}
+	if total := provider.total(); total != before {
+		t.Errorf("expected the deleted node not to be priced, got %d more prices", total-before)
+	}
+}
+
+// pricedProvider prices nodes at a fixed price, or not at all once it's cleared
+type pricedProvider struct {
+	countingProvider
+	price float64
+	ok    bool
+}
+
+func (p *pricedProvider) NodePrice(*model.Node) (float64, bool) {
+	return p.price, p.ok
+}
+
+func TestUpdatePriceKeepsKnownPrice(t *testing.T) {
+	provider := &pricedProvider{price: 2, ok: true}
+	m := Controller{cluster: model.NewCluster(), pricing: provider}
+	n := m.cluster.AddNode(model.NewNode(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Spec:       v1.NodeSpec{ProviderID: "node-id"},
+	}))
+	m.updatePrice(n)
+	if !n.HasPrice() || n.Price() != 2 {
+		t.Fatalf("expected the node to be priced at $2, got %f", n.Price())
+	}
+
+	// re-pricing with a provider that no longer knows the price keeps the old one
+	provider.ok = false
+	m.updatePrice(n)
+	if !n.HasPrice() || n.Price() != 2 {
+		t.Errorf("expected the node to keep its $2 price, got %f", n.Price())
+	}
+}
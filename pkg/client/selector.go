@@ -0,0 +1,43 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// NodeSelector returns the label selector for the node watches, the selector restricted to nodes in any of the zones
+// and of any of the instance types. Empty zones or instance types don't restrict the nodes.
+func NodeSelector(selector string, zones, instanceTypes []string) (labels.Selector, error) {
+	s, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("parsing node selector, %w", err)
+	}
+	for label, values := range map[string][]string{v1.LabelTopologyZone: zones, v1.LabelInstanceTypeStable: instanceTypes} {
+		if len(values) == 0 {
+			continue
+		}
+		r, err := labels.NewRequirement(label, selection.In, values)
+		if err != nil {
+			return nil, fmt.Errorf("selecting %s, %w", label, err)
+		}
+		s = s.Add(*r)
+	}
+	return s, nil
+}
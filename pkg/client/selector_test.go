@@ -0,0 +1,65 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestNodeSelector(t *testing.T) {
+	for _, tc := range []struct {
+		selector      string
+		zones         []string
+		instanceTypes []string
+		expected      string
+	}{
+		{selector: "", expected: ""},
+		{selector: "karpenter.sh/nodepool", expected: "karpenter.sh/nodepool"},
+		{zones: []string{"us-west-2a", "us-west-2b"}, expected: "topology.kubernetes.io/zone in (us-west-2a,us-west-2b)"},
+		{
+			selector:      "karpenter.sh/capacity-type=spot",
+			zones:         []string{"us-west-2a"},
+			instanceTypes: []string{"m5.large"},
+			expected:      "karpenter.sh/capacity-type=spot,node.kubernetes.io/instance-type in (m5.large),topology.kubernetes.io/zone in (us-west-2a)",
+		},
+	} {
+		s, err := NodeSelector(tc.selector, tc.zones, tc.instanceTypes)
+		if err != nil {
+			t.Fatalf("selecting %+v, %s", tc, err)
+		}
+		if got := s.String(); got != tc.expected {
+			t.Errorf("expected %q, got %q", tc.expected, got)
+		}
+	}
+
+	s, err := NodeSelector("", []string{"us-west-2a"}, []string{"m5.large", "c5.large"})
+	if err != nil {
+		t.Fatalf("selecting nodes, %s", err)
+	}
+	if !s.Matches(labels.Set{"topology.kubernetes.io/zone": "us-west-2a", "node.kubernetes.io/instance-type": "c5.large"}) {
+		t.Errorf("expected a c5.large in us-west-2a to match")
+	}
+	if s.Matches(labels.Set{"topology.kubernetes.io/zone": "us-west-2b", "node.kubernetes.io/instance-type": "c5.large"}) {
+		t.Errorf("expected a node in us-west-2b not to match")
+	}
+	if _, err := NodeSelector("", []string{"not a zone!"}, nil); err == nil {
+		t.Errorf("expected an invalid zone to be an error")
+	}
+	if _, err := NodeSelector("a in (", nil, nil); err == nil {
+		t.Errorf("expected an invalid selector to be an error")
+	}
+}
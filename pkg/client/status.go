@@ -0,0 +1,95 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
+)
+
+// StartStatusPublisher periodically writes a summary of the cluster to a ConfigMap, creating it if it doesn't exist, so
+// that other controllers and dashboards can consume it without running eks-node-viewer themselves
+func (m Controller) StartStatusPublisher(ctx context.Context, namespace, name string, interval time.Duration) {
+	go func() {
+		// publishing before the informers have synced would briefly report an empty cluster
+		if !m.WaitForSync(ctx) {
+			return
+		}
+		loggedErr := false
+		for {
+			if err := m.publishStatus(ctx, namespace, name); err != nil && !loggedErr {
+				slog.Error("publishing status to configmap", "namespace", namespace, "name", name, "error", err)
+				loggedErr = true
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+}
+
+func (m Controller) publishStatus(ctx context.Context, namespace, name string) (err error) {
+	ctx, span := tracing.StartAPISpan(ctx, "PublishStatus", attribute.String("configmap", namespace+"/"+name))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	status := model.NewStatus(m.cluster.Stats(), time.Now().UTC())
+	raw, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	// the most commonly used values are also published as individual keys so that they can be consumed without
+	// parsing the JSON
+	data := map[string]string{
+		"status.json":  string(raw),
+		"updated":      status.Updated.Format(time.RFC3339),
+		"nodes":        strconv.Itoa(status.Nodes),
+		"readyNodes":   strconv.Itoa(status.ReadyNodes),
+		"pendingPods":  strconv.Itoa(status.PendingPods),
+		"pricePerHour": strconv.FormatFloat(status.PricePerHour, 'f', 3, 64),
+		"problems":     strconv.Itoa(len(status.Problems)),
+	}
+
+	configMaps := m.kubeClient.CoreV1().ConfigMaps(namespace)
+	cm, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{"app.kubernetes.io/managed-by": "eks-node-viewer"},
+			},
+			Data: data,
+		}, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+	cm.Data = data
+	_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,236 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+	nvp "github.com/awslabs/eks-node-viewer/pkg/pricing"
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
+)
+
+// computeEngineSKUsURL lists the SKUs of the Compute Engine service in the Cloud Billing Catalog API
+const computeEngineSKUsURL = "https://cloudbilling.googleapis.com/v1/services/6F81-5844-456A/skus"
+
+// spotPrefix is the prefix of the description of spot VM SKUs, e.g. "Spot Preemptible E2 Instance Core running in
+// Americas"
+const spotPrefix = "Spot Preemptible "
+
+// resourceKey identifies the price of a vCPU or GiB of memory for a machine family, e.g. E2 or N2D
+type resourceKey struct {
+	family string
+	spot   bool
+}
+
+// regionPrices are the hourly prices per vCPU and per GiB of memory of the machine families in a region
+type regionPrices struct {
+	core map[resourceKey]float64
+	ram  map[resourceKey]float64
+}
+
+type pricingProvider struct {
+	client *http.Client
+	apiKey string
+	prices *nvp.RegionalCache[regionPrices]
+}
+
+// NewPricingProvider returns a provider that prices GKE nodes from the Cloud Billing Catalog API, which requires an API
+// key. Compute Engine bills machine types by the vCPU and GiB of memory, so a node's price is computed from its
+// capacity and the prices of its machine family in its region.
+func NewPricingProvider(ctx context.Context, apiKey string) nvp.Provider {
+	p := &pricingProvider{client: http.DefaultClient, apiKey: apiKey}
+	p.prices = nvp.NewRegionalCache(ctx, p.fetchPrices)
+	return p
+}
+
+func (p *pricingProvider) NodePrice(n *model.Node) (float64, bool) {
+	region, _ := n.Label(v1.LabelTopologyRegion)
+	if region == "" {
+		return math.NaN(), false
+	}
+	prices, ok := p.prices.Get(region)
+	if !ok {
+		return math.NaN(), false
+	}
+	// machine types are named <family>-<type>-<size>, e.g. e2-standard-4
+	family, _, _ := strings.Cut(string(n.InstanceType()), "-")
+	key := resourceKey{family: strings.ToUpper(family), spot: n.IsSpot()}
+	corePrice, coreOK := prices.core[key]
+	ramPrice, ramOK := prices.ram[key]
+	if !coreOK || !ramOK {
+		return math.NaN(), false
+	}
+	capacity := n.Capacity()
+	cores := capacity.Cpu().AsApproximateFloat64()
+	memGiB := capacity.Memory().AsApproximateFloat64() / (1024 * 1024 * 1024)
+	return cores*corePrice + memGiB*ramPrice, true
+}
+
+func (p *pricingProvider) NodeDeleted(n *model.Node) {}
+
+func (p *pricingProvider) OnUpdate(onUpdate func()) {
+	p.prices.OnUpdate(onUpdate)
+}
+
+// this isn't the full SKU, just the portions we care about
+type sku struct {
+	Description string `json:"description"`
+	Category    struct {
+		ResourceGroup string `json:"resourceGroup"`
+		UsageType     string `json:"usageType"`
+	} `json:"category"`
+	ServiceRegions []string `json:"serviceRegions"`
+	PricingInfo    []struct {
+		PricingExpression struct {
+			TieredRates []struct {
+				UnitPrice struct {
+					Units string `json:"units"`
+					Nanos int64  `json:"nanos"`
+				} `json:"unitPrice"`
+			} `json:"tieredRates"`
+		} `json:"pricingExpression"`
+	} `json:"pricingInfo"`
+}
+
+type skusPage struct {
+	SKUs          []sku  `json:"skus"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+func (p *pricingProvider) fetchPrices(ctx context.Context, region string) (prices regionPrices, err error) {
+	ctx, span := tracing.StartPricingSpan(ctx, "FetchGCPPricing", attribute.String("region", region))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if p.apiKey == "" {
+		return prices, errors.New("an API key is required to use the Cloud Billing Catalog API")
+	}
+	prices = regionPrices{
+		core: map[resourceKey]float64{},
+		ram:  map[resourceKey]float64{},
+	}
+	pageToken := ""
+	for {
+		page, err := p.fetchPage(ctx, pageToken)
+		if err != nil {
+			return prices, err
+		}
+		for i := range page.SKUs {
+			addPrice(prices, region, &page.SKUs[i])
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+	if len(prices.core) == 0 {
+		return prices, fmt.Errorf("no pricing found for %s", region)
+	}
+	return prices, nil
+}
+
+// addPrice adds the price of a SKU if it's the price of a vCPU or GiB of memory of a predefined machine type in the
+// region, e.g. "N2D AMD Instance Core running in Americas"
+func addPrice(prices regionPrices, region string, s *sku) {
+	if !slices.Contains(s.ServiceRegions, region) || len(s.PricingInfo) == 0 {
+		return
+	}
+	var target map[resourceKey]float64
+	switch {
+	case s.Category.ResourceGroup != "CPU" && s.Category.ResourceGroup != "RAM" && s.Category.ResourceGroup != "N1Standard":
+		return
+	case strings.Contains(s.Description, " Instance Core running in "):
+		target = prices.core
+	case strings.Contains(s.Description, " Instance Ram running in "):
+		target = prices.ram
+	default:
+		return
+	}
+	desc := s.Description
+	key := resourceKey{}
+	switch s.Category.UsageType {
+	case "OnDemand":
+	case "Preemptible":
+		if !strings.HasPrefix(desc, spotPrefix) {
+			return
+		}
+		key.spot = true
+		desc = strings.TrimPrefix(desc, spotPrefix)
+	default:
+		return
+	}
+	for _, exclude := range []string{"Custom", "Sole Tenancy", "Extended", "Commitment"} {
+		if strings.Contains(desc, exclude) {
+			return
+		}
+	}
+	key.family, _, _ = strings.Cut(desc, " ")
+
+	rates := s.PricingInfo[len(s.PricingInfo)-1].PricingExpression.TieredRates
+	if len(rates) == 0 {
+		return
+	}
+	// the last tier is the price once any free usage has been used
+	unitPrice := rates[len(rates)-1].UnitPrice
+	units, err := strconv.ParseFloat(unitPrice.Units, 64)
+	if err != nil {
+		return
+	}
+	target[key] = units + float64(unitPrice.Nanos)/1e9
+}
+
+func (p *pricingProvider) fetchPage(ctx context.Context, pageToken string) (*skusPage, error) {
+	query := url.Values{}
+	query.Set("key", p.apiKey)
+	query.Set("currencyCode", "USD")
+	query.Set("pageSize", "5000")
+	if pageToken != "" {
+		query.Set("pageToken", pageToken)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, computeEngineSKUsURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		// don't include the URL in the error as it contains the API key
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("fetching compute engine SKUs, %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching compute engine SKUs, %s", resp.Status)
+	}
+	var page skusPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("decoding compute engine SKUs, %w", err)
+	}
+	return &page, nil
+}
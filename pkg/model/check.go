@@ -0,0 +1,73 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Threshold is a limit on one of the condition variables, e.g. that price_per_hour is at most 10, that's checked in CI
+// pipelines to gate on the cost and utilization of a cluster
+type Threshold struct {
+	Variable string
+	Limit    float64
+	// Min is true if the variable must be at least the limit, otherwise it must be at most the limit
+	Min bool
+}
+
+// ThresholdResult is the value of a threshold's variable and whether it's within the limit
+type ThresholdResult struct {
+	Threshold
+	Value float64
+	OK    bool
+}
+
+// Check evaluates the thresholds against the stats, returning the result of each and whether they were all within
+// their limits
+func Check(st Stats, thresholds []Threshold) ([]ThresholdResult, bool) {
+	var results []ThresholdResult
+	ok := true
+	for _, t := range thresholds {
+		value := conditionVariables[t.Variable](st)
+		r := ThresholdResult{Threshold: t, Value: value, OK: value <= t.Limit}
+		if t.Min {
+			r.OK = value >= t.Limit
+		}
+		ok = ok && r.OK
+		results = append(results, r)
+	}
+	return results, ok
+}
+
+// WriteCheckReport writes a table of the threshold results
+func WriteCheckReport(w io.Writer, results []ThresholdResult) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tVALUE\tLIMIT\tRESULT")
+	for _, r := range results {
+		op, result := "<=", "PASS"
+		if r.Min {
+			op = ">="
+		}
+		if !r.OK {
+			result = "FAIL"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s %s\t%s\n", r.Variable, strconv.FormatFloat(r.Value, 'f', 2, 64), op,
+			strconv.FormatFloat(r.Limit, 'f', -1, 64), result)
+	}
+	tw.Flush()
+}
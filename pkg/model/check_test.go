@@ -0,0 +1,54 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestCheck(t *testing.T) {
+	stats := testConditionStats()
+	results, ok := model.Check(stats, []model.Threshold{
+		{Variable: "pending_pods", Limit: 0},
+		{Variable: "pods_total", Limit: 1, Min: true},
+	})
+	if ok {
+		t.Errorf("expected the pending pod to fail the check")
+	}
+	if len(results) != 2 || results[0].OK || results[0].Value != 1 || !results[1].OK {
+		t.Errorf("unexpected results, %+v", results)
+	}
+
+	var buf bytes.Buffer
+	model.WriteCheckReport(&buf, results)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and two results, got %q", buf.String())
+	}
+	if !strings.Contains(lines[1], "pending_pods") || !strings.HasSuffix(lines[1], "FAIL") {
+		t.Errorf("expected pending_pods to fail, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], ">= 1") || !strings.HasSuffix(lines[2], "PASS") {
+		t.Errorf("expected pods_total to pass, got %q", lines[2])
+	}
+
+	if _, ok := model.Check(stats, []model.Threshold{{Variable: "nodes_total", Limit: 3}}); !ok {
+		t.Errorf("expected nodes_total<=3 to pass")
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// copyToClipboard copies the text to the clipboard. The native clipboard is used where there is one, i.e. on Windows
+// where the console host doesn't support OSC 52, unless the viewer is run over SSH where it would be the remote host's.
+// Otherwise the text is copied with an OSC 52 escape sequence, which works over SSH in terminals that support it.
+func copyToClipboard(text string) error {
+	if os.Getenv("SSH_CONNECTION") == "" {
+		if err := nativeClipboard(text); err == nil {
+			return nil
+		}
+	}
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	}
+	_, err := seq.WriteTo(os.Stdout)
+	return err
+}
+
+// copyNodeName copies the name of the node to the clipboard, returning a status message for the help line
+func (u *UIModel) copyNodeName(n *Node) string {
+	if err := copyToClipboard(n.Name()); err != nil {
+		return fmt.Sprintf("copying node name failed, %s", err)
+	}
+	return fmt.Sprintf("copied %s", n.Name())
+}
@@ -0,0 +1,96 @@
+//go:build windows
+
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+)
+
+var (
+	user32               = windows.NewLazySystemDLL("user32.dll")
+	kernel32             = windows.NewLazySystemDLL("kernel32.dll")
+	procOpenClipboard    = user32.NewProc("OpenClipboard")
+	procCloseClipboard   = user32.NewProc("CloseClipboard")
+	procEmptyClipboard   = user32.NewProc("EmptyClipboard")
+	procSetClipboardData = user32.NewProc("SetClipboardData")
+	procGlobalAlloc      = kernel32.NewProc("GlobalAlloc")
+	procGlobalFree       = kernel32.NewProc("GlobalFree")
+	procGlobalLock       = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock     = kernel32.NewProc("GlobalUnlock")
+	procRtlMoveMemory    = kernel32.NewProc("RtlMoveMemory")
+)
+
+// nativeClipboard copies the text to the Windows clipboard, as neither the console host nor older versions of Windows
+// Terminal support OSC 52
+func nativeClipboard(text string) error {
+	// the clipboard is opened by the calling thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	// only one window can open the clipboard at a time, so wait briefly for any other application to close it
+	opened := false
+	for i := 0; i < 10 && !opened; i++ {
+		if r, _, _ := procOpenClipboard.Call(0); r != 0 {
+			opened = true
+		} else {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if !opened {
+		return errors.New("clipboard is in use")
+	}
+	defer procCloseClipboard.Call()
+
+	if r, _, err := procEmptyClipboard.Call(); r == 0 {
+		return fmt.Errorf("emptying clipboard, %w", err)
+	}
+	// Windows applications expect CRLF line endings when pasting
+	data, err := windows.UTF16FromString(strings.ReplaceAll(text, "\n", "\r\n"))
+	if err != nil {
+		return err
+	}
+	size := uintptr(len(data)) * unsafe.Sizeof(data[0])
+	mem, _, err := procGlobalAlloc.Call(gmemMoveable, size)
+	if mem == 0 {
+		return fmt.Errorf("allocating clipboard memory, %w", err)
+	}
+	ptr, _, err := procGlobalLock.Call(mem)
+	if ptr == 0 {
+		procGlobalFree.Call(mem)
+		return fmt.Errorf("locking clipboard memory, %w", err)
+	}
+	procRtlMoveMemory.Call(ptr, uintptr(unsafe.Pointer(&data[0])), size)
+	procGlobalUnlock.Call(mem)
+	if r, _, err := procSetClipboardData.Call(cfUnicodeText, mem); r == 0 {
+		procGlobalFree.Call(mem)
+		return fmt.Errorf("setting clipboard data, %w", err)
+	}
+	// the clipboard owns the memory once it's been set
+	return nil
+}
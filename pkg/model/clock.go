@@ -0,0 +1,46 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// clockSkew is how far the API server's clock is ahead of the local clock, it's added to the local time when computing
+// durations from timestamps set by the cluster. A single skew is used for every cluster as it's the local clock that
+// usually drifts, e.g. when running in a VM.
+var clockSkew atomic.Int64
+
+// SetClockSkew records how far the API server's clock is ahead of the local clock, negative if it's behind
+func SetClockSkew(skew time.Duration) {
+	clockSkew.Store(int64(skew))
+}
+
+// ClockSkew returns how far the API server's clock is ahead of the local clock
+func ClockSkew() time.Duration {
+	return time.Duration(clockSkew.Load())
+}
+
+// serverNow returns the current time according to the API server's clock
+func serverNow() time.Time {
+	return time.Now().Add(ClockSkew())
+}
+
+// since returns the time elapsed since a timestamp set by the cluster, it's never negative even if the clocks differ
+// by more than the measured skew
+func since(t time.Time) time.Duration {
+	return max(serverNow().Sub(t), 0)
+}
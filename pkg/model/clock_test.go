@@ -0,0 +1,40 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestClockSkew(t *testing.T) {
+	defer model.SetClockSkew(0)
+	n := testNode("mynode")
+	// created by an API server whose clock is an hour ahead of ours
+	n.CreationTimestamp = metav1.NewTime(time.Now().Add(time.Hour - 5*time.Minute))
+	node := model.NewNode(n)
+
+	if got := node.ComputeLabel("eks-node-viewer/node-age"); got != "0s" {
+		t.Errorf("expected the age to be clamped at zero, got %s", got)
+	}
+	model.SetClockSkew(time.Hour)
+	if got := node.ComputeLabel("eks-node-viewer/node-age"); got != "5m" {
+		t.Errorf("expected the age relative to the API server's clock, got %s", got)
+	}
+}
@@ -15,54 +15,176 @@ limitations under the License.
 package model
 
 import (
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 )
 
 type Cluster struct {
 	mu        sync.RWMutex
+	name      string
 	nodes     map[string]*Node
 	pods      map[objectKey]*Pod
 	resources []v1.ResourceName
+	// interruptions are the interrupted spot nodes that have been removed from the cluster
+	interruptions []Interruption
+	// generation is incremented each time the cluster changes so that the display is only rendered when needed
+	generation atomic.Uint64
+	// nodesByName indexes the nodes by name, pods, events and metrics refer to their node by name
+	nodesByName map[string]*Node
+	// podsByNodeName indexes the scheduled pods by the name of their node, which may not be known yet. podNodeNames is
+	// the name each pod is indexed under, as a pod is updated in place before it's re-added.
+	podsByNodeName map[string]map[objectKey]*Pod
+	podNodeNames   map[objectKey]string
+	// managedNodeGroups are the EKS managed node groups of the cluster, they're only set when the EKS API is polled
+	managedNodeGroups []ManagedNodeGroup
+	// excludedNamespaces are the namespaces whose pods don't count toward the usage of the nodes
+	excludedNamespaces []string
+
+	// totals are the totals of the visible nodes and their pods. They're updated as the nodes and pods change from what
+	// each node and pod adds to them, rather than by totalling every node each time they're displayed.
+	totals     clusterTotals
+	nodeTotals map[*Node]*nodeTotals
+	podTotals  map[objectKey]podTotals
+	// changed are the nodes that have changed since the totals were last updated, nodes report their changes while
+	// they're locked so the set has its own lock
+	changedMu sync.Mutex
+	changed   map[*Node]struct{}
+	// order is the visible nodes sorted by the sorter
+	order  []*Node
+	sorter *nodeSorter
+	// scoring is what the scores of the nodes are relative to, it's replaced each time the totals are updated so that
+	// nodes can be scored without locking the cluster
+	scoring      atomic.Pointer[scoreContext]
+	scoreWeights ScoreWeights
+	maxReserved  float64
 }
 
 func NewCluster() *Cluster {
-	return &Cluster{
-		nodes:     map[string]*Node{},
-		pods:      map[objectKey]*Pod{},
-		resources: []v1.ResourceName{v1.ResourceCPU},
+	c := &Cluster{
+		nodes:          map[string]*Node{},
+		nodesByName:    map[string]*Node{},
+		pods:           map[objectKey]*Pod{},
+		podsByNodeName: map[string]map[objectKey]*Pod{},
+		podNodeNames:   map[objectKey]string{},
+		resources:      []v1.ResourceName{v1.ResourceCPU},
+		totals:         newClusterTotals(),
+		nodeTotals:     map[*Node]*nodeTotals{},
+		podTotals:      map[objectKey]podTotals{},
+		changed:        map[*Node]struct{}{},
+		sorter:         makeNodeSorter("creation=dsc"),
+		scoreWeights:   DefaultScoreWeights,
 	}
+	c.scoring.Store(&scoreContext{weights: c.scoreWeights, resources: c.resources})
+	return c
+}
+
+// Name returns the name of the cluster, this is the kubeconfig context name when viewing multiple clusters
+func (c *Cluster) Name() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.name
+}
+
+func (c *Cluster) SetName(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.name = name
+}
+
+// SetResources sets the resources whose usage is tracked for the nodes of the cluster
+func (c *Cluster) SetResources(resources []v1.ResourceName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resources = resources
+}
+
+// Invalidate marks the cluster as changed, this is called after changes to the nodes and pods of the cluster that
+// aren't made through the cluster, e.g. updating a node's price
+func (c *Cluster) Invalidate() {
+	c.generation.Add(1)
+}
+
+// Generation returns a counter that is incremented each time the cluster changes
+func (c *Cluster) Generation() uint64 {
+	return c.generation.Load()
 }
+
 func (c *Cluster) AddNode(node *Node) *Node {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if existing, ok := c.nodes[node.ProviderID()]; ok {
+	defer c.Invalidate()
+	key := c.nodeKey(node.identity())
+	if existing, ok := c.nodes[key]; ok {
+		// the node for a NodeClaim is only named once it registers, so it's merged into the existing entry rather than
+		// replacing it, which keeps the row, selection and lifecycle, and is re-indexed under its new name
+		_, _, oldName := existing.identity()
 		existing.Update(&node.node)
+		if _, _, name := existing.identity(); name != oldName {
+			if c.nodesByName[oldName] == existing {
+				delete(c.nodesByName, oldName)
+			}
+			c.indexNodeName(existing)
+		}
 		return existing
 	}
 
-	c.nodes[node.ProviderID()] = node
+	// a node keyed by its UID despite having a provider ID shares it with another node, both are flagged
+	if providerID := node.ProviderID(); key != providerID && providerID != "" {
+		node.setDuplicateProviderID(true)
+		if other, ok := c.nodes[providerID]; ok {
+			other.setDuplicateProviderID(true)
+		}
+	}
+	c.nodes[key] = node
+	node.cluster.Store(c)
+	c.nodeChanged(node)
+	c.indexNodeName(node)
 	return node
 }
 
-func (c *Cluster) DeleteNode(providerID string) {
+// indexNodeName indexes the node by name and binds any pods that were scheduled to it before it was known
+func (c *Cluster) indexNodeName(n *Node) {
+	_, _, name := n.identity()
+	if name == "" {
+		return
+	}
+	c.nodesByName[name] = n
+	for _, p := range c.podsByNodeName[name] {
+		n.BindPod(p)
+	}
+}
+
+// DeleteNode deletes the node stored under the key, which is its provider ID unless it's keyed by UID, see NodeKey
+func (c *Cluster) DeleteNode(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	n, ok := c.nodes[providerID]
+	defer c.Invalidate()
+	n, ok := c.nodes[key]
 	if !ok {
 		return
 	}
-	var podsToDelete []objectKey
-	for k, p := range c.pods {
-		if p.NodeName() == n.node.Name {
-			podsToDelete = append(podsToDelete, k)
-		}
+	_, _, name := n.identity()
+	for k := range c.podsByNodeName[name] {
+		c.deletePod(k)
+	}
+	if n.Interrupted() {
+		c.interruptions = append(c.interruptions, newInterruption(n, time.Now()))
 	}
-	for _, k := range podsToDelete {
-		delete(c.pods, k)
+	delete(c.nodes, key)
+	// the node's totals are subtracted when the totals are next updated
+	n.cluster.Store(nil)
+	c.nodeChanged(n)
+	if c.nodesByName[name] == n {
+		delete(c.nodesByName, name)
+	}
+	if n.DuplicateProviderID() {
+		c.clearDuplicateProviderID(n.ProviderID())
 	}
-	delete(c.nodes, providerID)
 }
 
 func (c *Cluster) ForEachNode(f func(n *Node)) {
@@ -73,27 +195,37 @@ func (c *Cluster) ForEachNode(f func(n *Node)) {
 	}
 }
 
-func (c *Cluster) GetNode(providerID string) (*Node, bool) {
+// GetNode returns the node stored under the key, which is its provider ID unless it's keyed by UID, see NodeKey
+func (c *Cluster) GetNode(key string) (*Node, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	n, ok := c.nodes[providerID]
+	n, ok := c.nodes[key]
 	return n, ok
 }
 
 func (c *Cluster) GetNodeByName(name string) (*Node, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	for _, n := range c.nodes {
-		if n.node.Name == name {
-			return n, true
-		}
-	}
-	return nil, false
+	n, ok := c.nodesByName[name]
+	return n, ok
 }
 
 func (c *Cluster) AddPod(pod *Pod) (totalPods int) {
+	defer c.Invalidate()
 	c.mu.Lock()
-	c.pods[objectKey{namespace: pod.Namespace(), name: pod.Name()}] = pod
+	pod.SetExcluded(c.excluded(pod))
+	key := objectKey{namespace: pod.Namespace(), name: pod.Name()}
+	c.deletePod(key)
+	c.pods[key] = pod
+	if name := pod.NodeName(); name != "" {
+		if c.podsByNodeName[name] == nil {
+			c.podsByNodeName[name] = map[objectKey]*Pod{}
+		}
+		c.podsByNodeName[name][key] = pod
+		c.podNodeNames[key] = name
+	}
+	c.podTotals[key] = newPodTotals(pod)
+	c.totals.addPod(c.podTotals[key], 1)
 	totalPods = len(c.pods)
 	c.mu.Unlock()
 
@@ -109,6 +241,7 @@ func (c *Cluster) AddPod(pod *Pod) (totalPods int) {
 }
 
 func (c *Cluster) DeletePod(namespace, name string) (totalPods int) {
+	defer c.Invalidate()
 	p, ok := c.GetPod(namespace, name)
 	if ok && p.IsScheduled() {
 		n, ok := c.GetNodeByName(p.NodeName())
@@ -117,7 +250,7 @@ func (c *Cluster) DeletePod(namespace, name string) (totalPods int) {
 		}
 	}
 	c.mu.Lock()
-	delete(c.pods, objectKey{namespace: namespace, name: name})
+	c.deletePod(objectKey{namespace: namespace, name: name})
 	totalPods = len(c.pods)
 	c.mu.Unlock()
 	return
@@ -130,44 +263,182 @@ func (c *Cluster) GetPod(namespace string, name string) (*Pod, bool) {
 	return pod, ok
 }
 
-func (c *Cluster) Stats() Stats {
+// Pods returns the pods in the cluster sorted by namespace and name
+func (c *Cluster) Pods() []*Pod {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	st := Stats{
-		AllocatableResources: v1.ResourceList{},
-		UsedResources:        v1.ResourceList{},
-		PercentUsedResoruces: map[v1.ResourceName]float64{},
-		PodsByPhase:          map[v1.PodPhase]int{},
-	}
-
+	pods := make([]*Pod, 0, len(c.pods))
 	for _, p := range c.pods {
-		// skip pods bound to non-visible nodes
-		if n, ok := c.nodes[p.NodeName()]; ok && !n.Visible() {
-			continue
+		pods = append(pods, p)
+	}
+	sort.Slice(pods, func(a, b int) bool {
+		if pods[a].Namespace() != pods[b].Namespace() {
+			return pods[a].Namespace() < pods[b].Namespace()
 		}
+		return pods[a].Name() < pods[b].Name()
+	})
+	return pods
+}
 
-		st.TotalPods++
-		st.PodsByPhase[p.Phase()]++
-		if p.NodeName() != "" {
-			st.BoundPodCount++
+// deletePod deletes the pod stored under the key along with its totals and its entry in the index by node name, it must
+// be called with the lock held
+func (c *Cluster) deletePod(key objectKey) {
+	if pt, ok := c.podTotals[key]; ok {
+		c.totals.addPod(pt, -1)
+		delete(c.podTotals, key)
+	}
+	if name, ok := c.podNodeNames[key]; ok {
+		delete(c.podsByNodeName[name], key)
+		if len(c.podsByNodeName[name]) == 0 {
+			delete(c.podsByNodeName, name)
 		}
+		delete(c.podNodeNames, key)
 	}
+	delete(c.pods, key)
+}
 
+// nodeChanged records that the node has changed, so that its totals and its place in the order of the nodes are updated
+// before they're next read
+func (c *Cluster) nodeChanged(n *Node) {
+	c.changedMu.Lock()
+	defer c.changedMu.Unlock()
+	c.changed[n] = struct{}{}
+}
+
+// Stats returns the totals of the visible nodes and their pods, along with the nodes
+func (c *Cluster) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.updateTotals(false)
+	return c.totals.stats(slices.Clone(c.order))
+}
+
+// sortedStats returns the stats of the cluster with the nodes sorted by the sorter, scoring them with the weights and
+// counting the resources whose allocatable amount is more than maxReserved below capacity
+func (c *Cluster) sortedStats(sorter *nodeSorter, weights ScoreWeights, maxReserved float64) Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if maxReserved != c.maxReserved {
+		c.maxReserved = maxReserved
+		c.changeAll()
+	}
+	resort := sorter.nodeSort != c.sorter.nodeSort || sorter.volatile || weights != c.scoreWeights
+	c.sorter = sorter
+	c.scoreWeights = weights
+	c.updateTotals(resort)
+	return c.totals.stats(slices.Clone(c.order))
+}
+
+// changeAll records that every node has changed, it must be called with the lock held
+func (c *Cluster) changeAll() {
+	c.changedMu.Lock()
+	defer c.changedMu.Unlock()
 	for _, n := range c.nodes {
-		if !n.Visible() {
+		c.changed[n] = struct{}{}
+	}
+}
+
+// updateTotals updates the totals and order of the nodes that have changed since they were last updated, re-sorting
+// every node if resort is set. It must be called with the lock held.
+func (c *Cluster) updateTotals(resort bool) {
+	c.changedMu.Lock()
+	changed := c.changed
+	c.changed = map[*Node]struct{}{}
+	c.changedMu.Unlock()
+
+	// re-sorting is quicker than moving each node when many have changed, e.g. when the nodes are first listed
+	resort = resort || len(changed) > len(c.order)/4
+	for n := range changed {
+		if old, ok := c.nodeTotals[n]; ok {
+			c.totals.addNode(old, -1)
+			if old.visible && !resort {
+				c.removeFromOrder(n, old.sortKey)
+			}
+			delete(c.nodeTotals, n)
+		}
+		// deleted nodes are only subtracted
+		if n.cluster.Load() != c {
 			continue
 		}
-		// only add the price if it's not NaN which is used to indicate an unknown
-		// price
-		if n.HasPrice() {
-			st.TotalPrice += n.Price
+		nt := newNodeTotals(n, c.maxReserved)
+		c.totals.addNode(nt, 1)
+		c.nodeTotals[n] = nt
+	}
+
+	scoring := &scoreContext{weights: c.scoreWeights, resources: c.resources}
+	if len(c.totals.prices) > 0 {
+		scoring.maxPrice = c.totals.prices[len(c.totals.prices)-1]
+	}
+	if len(c.totals.created) > 0 {
+		scoring.oldest = c.totals.created[0]
+	}
+	c.scoring.Store(scoring)
+
+	if resort {
+		c.order = c.order[:0]
+		for n, nt := range c.nodeTotals {
+			if nt.visible {
+				nt.sortKey = c.sorter.key(n, scoring)
+				c.order = append(c.order, n)
+			}
 		}
-		st.NumNodes++
-		st.Nodes = append(st.Nodes, n)
-		addResources(st.AllocatableResources, n.Allocatable())
-		addResources(st.UsedResources, n.Used())
+		slices.SortFunc(c.order, func(a, b *Node) int {
+			return c.sorter.compare(c.nodeTotals[a].sortKey, c.nodeTotals[b].sortKey)
+		})
+		return
 	}
-	return st
+	for n := range changed {
+		if nt, ok := c.nodeTotals[n]; ok && nt.visible {
+			nt.sortKey = c.sorter.key(n, scoring)
+			c.insertIntoOrder(n, nt.sortKey)
+		}
+	}
+}
+
+// position returns the index of the first node in the order whose key isn't before the key
+func (c *Cluster) position(key sortKey) int {
+	i, _ := slices.BinarySearchFunc(c.order, key, func(n *Node, key sortKey) int {
+		return c.sorter.compare(c.nodeTotals[n].sortKey, key)
+	})
+	return i
+}
+
+func (c *Cluster) insertIntoOrder(n *Node, key sortKey) {
+	c.order = slices.Insert(c.order, c.position(key), n)
+}
+
+func (c *Cluster) removeFromOrder(n *Node, key sortKey) {
+	for i := c.position(key); i < len(c.order); i++ {
+		if c.order[i] == n {
+			c.order = slices.Delete(c.order, i, i+1)
+			return
+		}
+		if c.sorter.compare(c.nodeTotals[c.order[i]].sortKey, key) != 0 {
+			break
+		}
+	}
+	// the keys of volatile sorters change while the nodes are in order, so the node may not be where its key says
+	if i := slices.Index(c.order, n); i >= 0 {
+		c.order = slices.Delete(c.order, i, i+1)
+	}
+}
+
+// staleNodes returns the number of visible ready nodes that haven't been updated for longer than after, the number of
+// visible ready nodes, and when the most recently updated visible node was updated
+func (c *Cluster) staleNodes(after time.Duration) (stale, ready int, last time.Time) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if n := len(c.totals.updated); n > 0 {
+		last = c.totals.updated[n-1]
+	}
+	return countBefore(c.totals.readyUpdated, time.Now().Add(-after)), len(c.totals.readyUpdated), last
+}
+
+// expiredNodes returns the number of visible nodes that are older than the lifetime
+func (c *Cluster) expiredNodes(lifetime time.Duration) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return countBefore(c.totals.created, serverNow().Add(-lifetime))
 }
 
 // addResources sets lhs = lhs + rhs
@@ -14,10 +14,16 @@ limitations under the License.
 package model_test
 
 import (
+	"fmt"
+	"math"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	karpv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 
 	"github.com/awslabs/eks-node-viewer/pkg/model"
 )
@@ -102,6 +108,35 @@ func TestClusterGetNodeByName(t *testing.T) {
 	if !ok {
 		t.Errorf("expected to find node by name")
 	}
+
+	cluster.DeleteNode(cluster.NodeKey(n))
+	_, ok = cluster.GetNodeByName("mynode")
+	if ok {
+		t.Errorf("expected to not find node by name after deletion")
+	}
+}
+
+func TestClusterStatsSkipsPodsOnHiddenNodes(t *testing.T) {
+	cluster := model.NewCluster()
+	for _, name := range []string{"hidden", "visible"} {
+		n := testNode(name)
+		n.Spec.ProviderID = name + "-id"
+		node := model.NewNode(n)
+		if name == "visible" {
+			node.Show()
+		}
+		cluster.AddNode(node)
+	}
+
+	for _, nodeName := range []string{"hidden", "visible"} {
+		p := testPod("default", "pod-on-"+nodeName)
+		p.Spec.NodeName = nodeName
+		cluster.AddPod(model.NewPod(p))
+	}
+
+	if got := cluster.Stats().TotalPods; got != 1 {
+		t.Errorf("expected 1 pod, got %d", got)
+	}
 }
 
 func TestClusterUpdateNode(t *testing.T) {
@@ -134,6 +169,84 @@ func TestClusterUpdateNode(t *testing.T) {
 
 }
 
+func TestClusterNodeClaimHandoff(t *testing.T) {
+	cluster := model.NewCluster()
+
+	created := time.Now().Add(-5 * time.Minute)
+	nc := &karpv1.NodeClaim{}
+	nc.CreationTimestamp = metav1.NewTime(created)
+	nc.Status.ProviderID = "aws:///us-west-2a/i-1234"
+	nc.Status.Allocatable = v1.ResourceList{
+		"cpu": resource.MustParse("4"),
+	}
+	placeholder := cluster.AddNode(model.NewNodeFromNodeClaim(nc))
+	placeholder.Show()
+
+	// a pod is bound to the node before the node itself is seen
+	p := testPod("default", "mypod")
+	p.Spec.NodeName = "mynode"
+	cluster.AddPod(model.NewPod(p))
+
+	// the node registers, but the kubelet hasn't reported its resources yet
+	n := testNode("mynode")
+	n.CreationTimestamp = metav1.NewTime(time.Now())
+	n.Spec.ProviderID = nc.Status.ProviderID
+	n.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	node := cluster.AddNode(model.NewNode(n))
+	if node != placeholder {
+		t.Fatalf("expected the node to be merged into the nodeclaim's node")
+	}
+	if got, ok := cluster.GetNodeByName("mynode"); !ok || got != placeholder {
+		t.Errorf("expected the node to be found by its registered name")
+	}
+	if _, ok := cluster.GetNodeByName(""); ok {
+		t.Errorf("expected no node to be indexed without a name")
+	}
+	if exp, got := 1, node.NumPods(); exp != got {
+		t.Errorf("expected %d pods on the node, got %d", exp, got)
+	}
+	if got := cluster.Stats().AllocatableResources["cpu"]; got.Cmp(resource.MustParse("4")) != 0 {
+		t.Errorf("expected the nodeclaim's allocatable CPU = 4, got %s", got.String())
+	}
+
+	// the age doesn't jump once the node is ready
+	if !node.Ready() {
+		t.Fatalf("expected the node to be ready")
+	}
+	if got := node.Created(); !got.Equal(created) {
+		t.Errorf("expected the node to be created at %s, got %s", created, got)
+	}
+}
+
+func TestClusterBindsPodsScheduledBeforeNode(t *testing.T) {
+	cluster := model.NewCluster()
+
+	// a pending pod that's scheduled to the node by updating it in place, as the pod watch does
+	pending := testPod("default", "pending")
+	pod := model.NewPod(pending)
+	cluster.AddPod(pod)
+	scheduled := pending.DeepCopy()
+	scheduled.Spec.NodeName = "mynode"
+	pod.Update(scheduled)
+	cluster.AddPod(pod)
+
+	// pods that were deleted or scheduled elsewhere aren't bound
+	deleted := testPod("default", "deleted")
+	deleted.Spec.NodeName = "mynode"
+	cluster.AddPod(model.NewPod(deleted))
+	cluster.DeletePod("default", "deleted")
+	other := testPod("default", "other")
+	other.Spec.NodeName = "othernode"
+	cluster.AddPod(model.NewPod(other))
+
+	n := testNode("mynode")
+	n.Spec.ProviderID = "mynode-id"
+	node := cluster.AddNode(model.NewNode(n))
+	if exp, got := 1, node.NumPods(); exp != got {
+		t.Errorf("expected %d pods on the node, got %d", exp, got)
+	}
+}
+
 func TestClusterAddPod(t *testing.T) {
 	cluster := model.NewCluster()
 
@@ -198,14 +311,328 @@ func TestClusterDeleteNodeDeletesPods(t *testing.T) {
 		t.Errorf("expected 2 CPU used, got %s", got.String())
 	}
 
+	// a pending pod isn't bound to any node
+	cluster.AddPod(model.NewPod(testPod("default", "pending")))
+
 	// deleting the node should clear all of the usage of pods that were bound to the node
 	cluster.DeleteNode("mynode-id")
 
-	if got := cluster.Stats().TotalPods; got != 0 {
-		t.Errorf("expected 0 pods, got %d", got)
+	if got := cluster.Stats().TotalPods; got != 1 {
+		t.Errorf("expected 1 pod, got %d", got)
+	}
+	if _, ok := cluster.GetPod("default", "pending"); !ok {
+		t.Errorf("expected the pending pod to be kept")
 	}
 	if got := cluster.Stats().UsedResources["cpu"]; got.Cmp(resource.MustParse("0")) != 0 {
 		t.Errorf("expected 0 CPU used, got %s", got.String())
 	}
 
 }
+
+func TestClusterInterruptions(t *testing.T) {
+	cluster := model.NewCluster()
+
+	n := testNode("mynode")
+	n.Spec.ProviderID = "mynode-id"
+	node := model.NewNode(n)
+	node.Show()
+	cluster.AddNode(node)
+
+	p := testPod("default", "mypod")
+	p.Spec.NodeName = n.Name
+	cluster.AddPod(model.NewPod(p))
+
+	if got := len(cluster.Interruptions()); got != 0 {
+		t.Errorf("expected 0 interruptions, got %d", got)
+	}
+
+	notice := time.Now().Add(-time.Minute)
+	node.SetInterrupted(notice)
+	// later notices shouldn't change the notice time
+	node.SetInterrupted(time.Now())
+	cluster.DeletePod("default", "mypod")
+
+	interruptions := cluster.Interruptions()
+	if got := len(interruptions); got != 1 {
+		t.Fatalf("expected 1 interruption, got %d", got)
+	}
+	if !interruptions[0].Gone.IsZero() {
+		t.Errorf("expected node to still be present")
+	}
+
+	cluster.DeleteNode("mynode-id")
+	interruptions = cluster.Interruptions()
+	if got := len(interruptions); got != 1 {
+		t.Fatalf("expected 1 interruption, got %d", got)
+	}
+	if got := interruptions[0].Notice; !got.Equal(notice) {
+		t.Errorf("expected notice at %s, got %s", notice, got)
+	}
+	if got := interruptions[0].Duration(); got < time.Minute {
+		t.Errorf("expected notice→gone of at least 1m, got %s", got)
+	}
+	if got := interruptions[0].PodsEvicted; got != 1 {
+		t.Errorf("expected 1 pod evicted, got %d", got)
+	}
+}
+
+func TestMergeStats(t *testing.T) {
+	var stats []model.Stats
+	for _, name := range []string{"cluster-a", "cluster-b"} {
+		cluster := model.NewCluster()
+		cluster.SetName(name)
+		n := testNode(name + "-node")
+		n.Spec.ProviderID = name + "-node-id"
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}
+		node := model.NewNode(n)
+		node.Show()
+		cluster.AddNode(node)
+
+		p := testPod("default", name+"-pod")
+		p.Spec.NodeName = n.Name
+		cluster.AddPod(model.NewPod(p))
+		stats = append(stats, cluster.Stats())
+	}
+
+	merged := model.MergeStats(stats...)
+	if got := merged.NumNodes; got != 2 {
+		t.Errorf("expected 2 nodes, got %d", got)
+	}
+	if got := merged.TotalPods; got != 2 {
+		t.Errorf("expected 2 pods, got %d", got)
+	}
+	if got := merged.AllocatableResources[v1.ResourceCPU]; got.Cmp(resource.MustParse("8")) != 0 {
+		t.Errorf("expected 8 CPU allocatable, got %s", got.String())
+	}
+	if got := merged.UsedResources[v1.ResourceCPU]; got.Cmp(resource.MustParse("4")) != 0 {
+		t.Errorf("expected 4 CPU used, got %s", got.String())
+	}
+	if got := merged.Nodes[0].Name(); got != "cluster-a-node" {
+		t.Errorf("expected nodes to retain their order, got %s first", got)
+	}
+}
+
+func TestClusterActualUsage(t *testing.T) {
+	cluster := model.NewCluster()
+	n := testNode("mynode")
+	n.Spec.ProviderID = "mynode-id"
+	node := model.NewNode(n)
+	node.Show()
+	cluster.AddNode(node)
+
+	if got := node.ActualUsage(); got != nil {
+		t.Errorf("expected no actual usage, got %v", got)
+	}
+	node.SetActualUsage(v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")})
+	if got := cluster.Stats().ActualUsedResources[v1.ResourceCPU]; got.Cmp(resource.MustParse("500m")) != 0 {
+		t.Errorf("expected 500m CPU actually used, got %s", got.String())
+	}
+}
+
+func TestClusterBreakdown(t *testing.T) {
+	cluster := model.NewCluster()
+	for i, tc := range []struct {
+		zone         string
+		capacityType string
+		price        float64
+	}{
+		{"us-west-2a", "spot", 1},
+		{"us-west-2a", "on-demand", 2},
+		{"us-west-2b", "spot", math.NaN()},
+	} {
+		n := testNode(fmt.Sprintf("node-%d", i))
+		n.Spec.ProviderID = fmt.Sprintf("node-%d-id", i)
+		n.Labels = map[string]string{
+			v1.LabelTopologyZone:         tc.zone,
+			"karpenter.sh/capacity-type": tc.capacityType,
+		}
+		node := model.NewNode(n)
+		node.SetPrice(tc.price)
+		node.Show()
+		cluster.AddNode(node)
+	}
+
+	stats := cluster.Stats()
+	if got := stats.Zones["us-west-2a"]; got.Nodes != 2 || got.Price != 3 {
+		t.Errorf("expected 2 nodes costing $3 in us-west-2a, got %+v", got)
+	}
+	if got := stats.Zones["us-west-2b"]; got.Nodes != 1 || got.Price != 0 {
+		t.Errorf("expected 1 node without a price in us-west-2b, got %+v", got)
+	}
+	if spot, onDemand := stats.CapacityTypes["spot"], stats.CapacityTypes["on-demand"]; spot.Nodes != 2 || onDemand.Nodes != 1 {
+		t.Errorf("expected 2 spot and 1 on-demand nodes, got %+v and %+v", spot, onDemand)
+	}
+
+	merged := model.MergeStats(stats, stats)
+	if got := merged.Zones["us-west-2a"]; got.Nodes != 4 || got.Price != 6 {
+		t.Errorf("expected the merged breakdown to combine both clusters, got %+v", got)
+	}
+}
+
+func TestClusterProviderIDProblems(t *testing.T) {
+	cluster := model.NewCluster()
+	add := func(name, uid, providerID string) *model.Node {
+		n := testNode(name)
+		n.UID = types.UID(uid)
+		n.Spec.ProviderID = providerID
+		return cluster.AddNode(model.NewNode(n))
+	}
+	// self-managed nodes without provider IDs don't overwrite each other
+	a := add("node-a", "uid-a", "")
+	b := add("node-b", "uid-b", "")
+	// nodes with the same provider ID are both kept and flagged
+	c := add("node-c", "uid-c", "aws:///us-west-2a/i-c")
+	d := add("node-d", "uid-d", "aws:///us-west-2a/i-c")
+	e := add("node-e", "uid-e", "aws:///us-west-2a/i-e")
+
+	count := 0
+	cluster.ForEachNode(func(*model.Node) { count++ })
+	if count != 5 {
+		t.Fatalf("expected 5 nodes, got %d", count)
+	}
+	for _, n := range []*model.Node{a, b, c, d, e} {
+		if !cluster.HasNode(n) {
+			t.Errorf("expected %s to be in the cluster", n.Name())
+		}
+	}
+	if !a.MissingProviderID() || !b.MissingProviderID() || c.MissingProviderID() {
+		t.Errorf("expected only node-a and node-b to be missing a provider ID")
+	}
+	if !c.DuplicateProviderID() || !d.DuplicateProviderID() || e.DuplicateProviderID() {
+		t.Errorf("expected only node-c and node-d to have a duplicate provider ID")
+	}
+
+	// updates find the node they were stored under
+	updated := testNode("node-d")
+	updated.UID = "uid-d"
+	updated.Spec.ProviderID = "aws:///us-west-2a/i-c"
+	if n, ok := cluster.GetNode(cluster.NodeKey(updated)); !ok || n != d {
+		t.Errorf("expected to find node-d by its key")
+	}
+
+	// once one of the duplicates is deleted the other is no longer flagged
+	deleted := testNode("node-c")
+	deleted.UID = "uid-c"
+	deleted.Spec.ProviderID = "aws:///us-west-2a/i-c"
+	cluster.DeleteNode(cluster.NodeKey(deleted))
+	if cluster.HasNode(c) || !cluster.HasNode(d) {
+		t.Errorf("expected only node-c to be deleted")
+	}
+	if d.DuplicateProviderID() {
+		t.Errorf("expected node-d to no longer have a duplicate provider ID")
+	}
+	if n, ok := cluster.GetNode(cluster.NodeKey(updated)); !ok || n != d {
+		t.Errorf("expected to still find node-d by its key")
+	}
+
+	var empty v1.Node
+	empty.Name, empty.UID = "node-a", "uid-a"
+	cluster.DeleteNode(cluster.NodeKey(&empty))
+	if cluster.HasNode(a) || !cluster.HasNode(b) {
+		t.Errorf("expected only node-a to be deleted")
+	}
+}
+
+func TestClusterStatsFollowChanges(t *testing.T) {
+	cluster := model.NewCluster()
+	nodes := map[string]*model.Node{}
+	objects := map[string]*v1.Node{}
+	for i, name := range []string{"node-a", "node-b", "node-c"} {
+		n := testNode(name)
+		n.Spec.ProviderID = fmt.Sprintf("aws:///us-west-2a/i-%d", i)
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+		node := cluster.AddNode(model.NewNode(n))
+		node.SetPrice(float64(i + 1))
+		node.Show()
+		nodes[name] = node
+		objects[name] = n
+	}
+
+	stats := cluster.Stats()
+	if stats.NumNodes != 3 || stats.TotalPrice != 6 {
+		t.Errorf("expected 3 nodes costing 6, got %d costing %f", stats.NumNodes, stats.TotalPrice)
+	}
+	if got := stats.AllocatableResources[v1.ResourceCPU]; got.Cmp(resource.MustParse("6")) != 0 {
+		t.Errorf("expected 6 allocatable CPUs, got %s", got.String())
+	}
+
+	// changing a node replaces its contribution
+	nodes["node-b"].SetPrice(10)
+	nodes["node-c"].SetInterrupted(time.Now())
+	stats = cluster.Stats()
+	if stats.TotalPrice != 14 || stats.Interrupted != 1 {
+		t.Errorf("expected a price of 14 with 1 interrupted node, got %f and %d", stats.TotalPrice, stats.Interrupted)
+	}
+
+	// hiding and deleting nodes removes theirs
+	nodes["node-a"].Hide()
+	cluster.DeleteNode(cluster.NodeKey(objects["node-c"]))
+	stats = cluster.Stats()
+	if stats.NumNodes != 1 || stats.TotalPrice != 10 || stats.Interrupted != 0 {
+		t.Errorf("expected only node-b to be counted, got %d nodes costing %f with %d interrupted", stats.NumNodes,
+			stats.TotalPrice, stats.Interrupted)
+	}
+	if got := stats.AllocatableResources[v1.ResourceCPU]; got.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("expected 2 allocatable CPUs, got %s", got.String())
+	}
+	if len(stats.Nodes) != 1 || stats.Nodes[0] != nodes["node-b"] {
+		t.Errorf("expected only node-b to be listed, got %v", stats.Nodes)
+	}
+}
+
+func TestClusterStatsFollowRenames(t *testing.T) {
+	cluster := model.NewCluster()
+
+	nc := &karpv1.NodeClaim{}
+	nc.Status.ProviderID = "aws:///us-west-2a/i-1234"
+	nc.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}
+	claimed := cluster.AddNode(model.NewNodeFromNodeClaim(nc))
+	claimed.SetPrice(1)
+	claimed.Show()
+
+	p := testPod("default", "mypod")
+	p.Spec.NodeName = "mynode"
+	cluster.AddPod(model.NewPod(p))
+	stats := cluster.Stats()
+	if stats.NumNodes != 1 || stats.TotalPods != 1 {
+		t.Errorf("expected 1 node and 1 pod, got %d and %d", stats.NumNodes, stats.TotalPods)
+	}
+	if got := stats.UsedResources[v1.ResourceCPU]; !got.IsZero() {
+		t.Errorf("expected no CPU to be used before the node is named, got %s", got.String())
+	}
+
+	// once the node registers under its name, the pod scheduled to it is counted in place of the unnamed node's totals
+	n := testNode("mynode")
+	n.Spec.ProviderID = nc.Status.ProviderID
+	n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")}
+	cluster.AddNode(model.NewNode(n))
+	stats = cluster.Stats()
+	if stats.NumNodes != 1 || stats.TotalPrice != 1 {
+		t.Errorf("expected 1 node costing 1, got %d costing %f", stats.NumNodes, stats.TotalPrice)
+	}
+	if got := stats.UsedResources[v1.ResourceCPU]; got.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("expected 2 CPUs to be used, got %s", got.String())
+	}
+	if got := stats.AllocatableResources[v1.ResourceCPU]; got.Cmp(resource.MustParse("8")) != 0 {
+		t.Errorf("expected 8 allocatable CPUs, got %s", got.String())
+	}
+	if len(stats.Nodes) != 1 || stats.Nodes[0] != claimed {
+		t.Errorf("expected only the renamed node to be listed, got %v", stats.Nodes)
+	}
+
+	// deleting the node removes it along with its pods
+	cluster.DeleteNode(nc.Status.ProviderID)
+	stats = cluster.Stats()
+	if stats.NumNodes != 0 || stats.TotalPods != 0 || stats.TotalPrice != 0 || len(stats.Nodes) != 0 {
+		t.Errorf("expected no nodes or pods, got %d nodes with %d pods costing %f", stats.NumNodes, stats.TotalPods,
+			stats.TotalPrice)
+	}
+	for rn, q := range stats.UsedResources {
+		if !q.IsZero() {
+			t.Errorf("expected no %s to be used, got %s", rn, q.String())
+		}
+	}
+	if len(stats.Zones) != 0 || len(stats.CapacityTypes) != 0 {
+		t.Errorf("expected no breakdowns, got %v and %v", stats.Zones, stats.CapacityTypes)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// ScaleDownDisabledAnnotation prevents the cluster autoscaler from scaling down a node
+const ScaleDownDisabledAnnotation = "cluster-autoscaler.kubernetes.io/scale-down-disabled"
+
+// SafeToEvictAnnotation set to false on a pod prevents the cluster autoscaler from scaling down its node
+const SafeToEvictAnnotation = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+
+// The taints that the cluster autoscaler adds to the nodes it's scaling down, their values are the Unix time they
+// were added
+const (
+	// toBeDeletedTaint is added before draining a node that's being scaled down
+	toBeDeletedTaint = "ToBeDeletedByClusterAutoscaler"
+	// deletionCandidateTaint is added once a node is unneeded, it's scaled down if it's still unneeded after the
+	// --scale-down-unneeded-time
+	deletionCandidateTaint = "DeletionCandidateOfClusterAutoscaler"
+)
+
+// DisruptionUnneeded is the disruption state of a node that the cluster autoscaler considers unneeded
+const DisruptionUnneeded = "unneeded"
+
+// asgLabels identify nodes in Auto Scaling groups, which the cluster autoscaler scales
+var asgLabels = []string{"eks.amazonaws.com/nodegroup", "alpha.eksctl.io/nodegroup-name"}
+
+// ClusterAutoscaled returns true if the node is scaled by the cluster autoscaler rather than Karpenter, i.e. it's in
+// an Auto Scaling group or the cluster autoscaler has tainted or been told not to scale it down
+func (n *Node) ClusterAutoscaled() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if _, ok := n.node.Labels[DefaultGroupBy]; ok || len(n.nodeClaimConditions) > 0 {
+		return false
+	}
+	if _, ok := n.node.Annotations[ScaleDownDisabledAnnotation]; ok {
+		return true
+	}
+	for _, taint := range n.node.Spec.Taints {
+		if taint.Key == toBeDeletedTaint || taint.Key == deletionCandidateTaint {
+			return true
+		}
+	}
+	for _, label := range asgLabels {
+		if _, ok := n.node.Labels[label]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ScaleDownBlocked returns why the cluster autoscaler won't scale down the node, or an empty string if it may
+func (n *Node) ScaleDownBlocked() string {
+	n.mu.RLock()
+	disabled := n.node.Annotations[ScaleDownDisabledAnnotation] == "true"
+	n.mu.RUnlock()
+	if disabled {
+		return "node has the " + ScaleDownDisabledAnnotation + " annotation"
+	}
+	for _, p := range n.Pods() {
+		if p.SafeToEvict() == "false" {
+			return "pod " + p.Namespace() + "/" + p.Name() + " has the " + SafeToEvictAnnotation + "=false annotation"
+		}
+	}
+	return ""
+}
+
+// UnneededSince returns when the cluster autoscaler marked the node as unneeded, if it has
+func (n *Node) UnneededSince() (time.Time, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	for _, taint := range n.node.Spec.Taints {
+		if taint.Key != deletionCandidateTaint {
+			continue
+		}
+		if unix, err := strconv.ParseInt(taint.Value, 10, 64); err == nil {
+			return time.Unix(unix, 0), true
+		}
+		if taint.TimeAdded != nil {
+			return taint.TimeAdded.Time, true
+		}
+		return time.Time{}, true
+	}
+	return time.Time{}, false
+}
+
+// clusterAutoscalerDisruption summarizes the cluster autoscaler's scale down of the node in the same terms as
+// Karpenter's disruption. A node being scaled down is "disrupting/ScaleDown", an unneeded node is "unneeded" followed
+// by how long it's been unneeded, e.g. unneeded/7m, and "/blocked" is added if scale down is disabled for the node or
+// one of its pods can't be evicted.
+func (n *Node) clusterAutoscalerDisruption() string {
+	n.mu.RLock()
+	scalingDown := false
+	for _, taint := range n.node.Spec.Taints {
+		if taint.Key == toBeDeletedTaint {
+			scalingDown = true
+		}
+	}
+	n.mu.RUnlock()
+	if scalingDown {
+		return DisruptionDisrupting + "/ScaleDown"
+	}
+
+	var state string
+	if unneeded, ok := n.UnneededSince(); ok {
+		state = DisruptionUnneeded
+		if !unneeded.IsZero() {
+			state += "/" + duration.HumanDuration(since(unneeded))
+		}
+	}
+	if n.ScaleDownBlocked() != "" {
+		if state == "" {
+			return DisruptionBlocked
+		}
+		return state + "/" + DisruptionBlocked
+	}
+	if state == "" {
+		return "-"
+	}
+	return state
+}
+
+// SafeToEvict returns the value of the pod's cluster autoscaler safe-to-evict annotation
+func (p *Pod) SafeToEvict() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.pod.Annotations[SafeToEvictAnnotation]
+}
@@ -0,0 +1,83 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestClusterAutoscalerDisruption(t *testing.T) {
+	n := testNode("node")
+	n.Labels = map[string]string{"eks.amazonaws.com/nodegroup": "workers"}
+	node := model.NewNode(n)
+	if !node.ClusterAutoscaled() {
+		t.Fatal("expected a node group node to be scaled by the cluster autoscaler")
+	}
+	if got := node.Disruption(); got != "-" {
+		t.Errorf("expected -, got %s", got)
+	}
+
+	// the cluster autoscaler records when it found the node unneeded in the taint's value
+	unneeded := time.Now().Add(-7 * time.Minute)
+	n.Spec.Taints = []v1.Taint{{Key: "DeletionCandidateOfClusterAutoscaler", Value: strconv.FormatInt(unneeded.Unix(), 10),
+		Effect: v1.TaintEffectPreferNoSchedule}}
+	node.Update(n)
+	if since, ok := node.UnneededSince(); !ok || since.Unix() != unneeded.Unix() {
+		t.Errorf("expected the node to be unneeded since %s, got %s", unneeded, since)
+	}
+	if exp, got := "unneeded/7m", node.ComputeLabel("eks-node-viewer/node-disruption"); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+
+	// a pod that isn't safe to evict blocks the scale down
+	p := testPod("default", "batch-job")
+	p.Annotations = map[string]string{model.SafeToEvictAnnotation: "false"}
+	p.Spec.NodeName = "node"
+	node.BindPod(model.NewPod(p))
+	if exp, got := "unneeded/7m/blocked", node.Disruption(); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+	node.DeletePod("default", "batch-job")
+
+	n.Spec.Taints = append(n.Spec.Taints, v1.Taint{Key: "ToBeDeletedByClusterAutoscaler", Effect: v1.TaintEffectNoSchedule})
+	node.Update(n)
+	if exp, got := "disrupting/ScaleDown", node.Disruption(); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+
+	// scale down can be disabled for nodes outside of node groups
+	disabled := testNode("disabled")
+	disabled.Annotations = map[string]string{model.ScaleDownDisabledAnnotation: "true"}
+	node = model.NewNode(disabled)
+	if exp, got := model.DisruptionBlocked, node.Disruption(); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+	if exp, got := "node has the cluster-autoscaler.kubernetes.io/scale-down-disabled annotation", node.ScaleDownBlocked(); exp != got {
+		t.Errorf("expected %q, got %q", exp, got)
+	}
+
+	// Karpenter's nodes are never scaled by the cluster autoscaler
+	karpenter := testNode("karpenter")
+	karpenter.Labels = map[string]string{"karpenter.sh/nodepool": "default", "eks.amazonaws.com/nodegroup": "workers"}
+	if model.NewNode(karpenter).ClusterAutoscaled() {
+		t.Error("expected a Karpenter node not to be scaled by the cluster autoscaler")
+	}
+}
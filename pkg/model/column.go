@@ -0,0 +1,64 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Column is a custom column whose value is extracted from the node object with a JSONPath expression
+type Column struct {
+	Name string
+	path *jsonpath.JSONPath
+}
+
+// ParseColumn parses a column definition of the form name={jsonpath}, e.g.
+// kubelet={.status.nodeInfo.kubeletVersion}
+func ParseColumn(def string) (Column, error) {
+	name, expr, ok := strings.Cut(def, "=")
+	if !ok || name == "" || expr == "" {
+		return Column{}, fmt.Errorf("invalid column %q, expected name={jsonpath}", def)
+	}
+	path := jsonpath.New(name).AllowMissingKeys(true)
+	if err := path.Parse(expr); err != nil {
+		return Column{}, fmt.Errorf("parsing column %q, %w", name, err)
+	}
+	return Column{Name: name, path: path}, nil
+}
+
+// Value returns the result of evaluating the column's JSONPath against the node, or "-" if it doesn't match anything
+func (c Column) Value(n *Node) string {
+	n.mu.RLock()
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&n.node)
+	n.mu.RUnlock()
+	if err != nil {
+		return "-"
+	}
+	var buf bytes.Buffer
+	if err := c.path.Execute(&buf, obj); err != nil || buf.Len() == 0 {
+		return "-"
+	}
+	return buf.String()
+}
+
+// SetColumns sets the custom columns that are displayed after the extra labels
+func (u *UIModel) SetColumns(columns []Column) {
+	u.columns = columns
+}
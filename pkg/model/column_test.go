@@ -0,0 +1,181 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestColumn(t *testing.T) {
+	n := testNode("mynode")
+	n.Labels = map[string]string{"nvidia.com/driver-version": "535.104"}
+	n.Status.NodeInfo.KubeletVersion = "v1.31.2"
+	n.Status.Addresses = []v1.NodeAddress{
+		{Type: v1.NodeExternalIP, Address: "54.1.2.3"},
+		{Type: v1.NodeInternalIP, Address: "192.168.1.1"},
+	}
+	node := model.NewNode(n)
+
+	for def, exp := range map[string]string{
+		`kubelet={.status.nodeInfo.kubeletVersion}`:               "v1.31.2",
+		`gpuDriver={.metadata.labels.nvidia\.com/driver-version}`: "535.104",
+		`ip={.status.addresses[?(@.type=="InternalIP")].address}`: "192.168.1.1",
+		`missing={.status.nodeInfo.containerRuntimeVersion}`:      "-",
+		`missingLabel={.metadata.labels.karpenter\.sh/nodepool}`:  "-",
+	} {
+		c, err := model.ParseColumn(def)
+		if err != nil {
+			t.Errorf("parsing %s, %s", def, err)
+			continue
+		}
+		if got := c.Value(node); got != exp {
+			t.Errorf("expected %s to be %q, got %q", def, exp, got)
+		}
+	}
+
+	for _, def := range []string{"kubelet", "={.status}", "kubelet={.status"} {
+		if _, err := model.ParseColumn(def); err == nil {
+			t.Errorf("expected an error parsing %q", def)
+		}
+	}
+}
+
+func TestSetLayout(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "creation", style)
+	ui.SetResources([]string{"cpu", "memory"})
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		v1.LabelInstanceTypeStable: "m5.large",
+		v1.LabelTopologyZone:       "us-west-2a",
+		v1.LabelArchStable:         "arm64",
+	}
+	node := model.NewNode(n)
+	node.Show()
+	ui.Cluster().AddNode(node)
+
+	if err := ui.SetLayout([]string{"zone", "name", "label:kubernetes.io/arch"}); err != nil {
+		t.Fatalf("setting layout, %s", err)
+	}
+	var buf bytes.Buffer
+	if err := ui.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	got := buf.String()
+	header := strings.Index(got, "ZONE")
+	if header < 0 || !strings.Contains(got[header:], "NAME") || !strings.Contains(got[header:], "ARCH") {
+		t.Fatalf("expected the headings of the chosen columns, got\n%s", got)
+	}
+	if strings.Contains(got, "USAGE") || strings.Contains(got, "m5.large") {
+		t.Errorf("expected only the chosen columns, got\n%s", got)
+	}
+	row := got[strings.LastIndex(got, "us-west-2a"):]
+	if !strings.HasPrefix(strings.Join(strings.Fields(row), " "), "us-west-2a mynode arm64") {
+		t.Errorf("expected the columns in the chosen order, got\n%s", got)
+	}
+	// without a per-resource column, each node is displayed on a single row
+	if strings.Count(got, "mynode") != 1 {
+		t.Errorf("expected a single row for the node, got\n%s", got)
+	}
+
+	if err := ui.SetLayout([]string{"name", "bogus"}); err == nil {
+		t.Errorf("expected an error for an unknown column")
+	}
+}
+
+func TestFitColumns(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	n := testNode("node-a")
+	n.Labels = map[string]string{v1.LabelInstanceTypeStable: "m5.large"}
+	node := model.NewNode(n)
+	node.Show()
+	ui.Cluster().AddNode(node)
+
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	if view := ui.View(); strings.Contains(view, "hidden to fit") || !strings.Contains(view, "LIFECYCLE") {
+		t.Errorf("expected every column to fit, got\n%s", view)
+	}
+
+	ui.Update(tea.WindowSizeMsg{Width: 70, Height: 40})
+	view := ui.View()
+	if !strings.Contains(view, "hidden to fit: lifecycle") || strings.Contains(view, "LIFECYCLE") {
+		t.Errorf("expected the lifecycle column to be hidden first, got\n%s", view)
+	}
+	if !strings.Contains(view, "NAME") || !strings.Contains(view, "USAGE") {
+		t.Errorf("expected the most important columns to be kept, got\n%s", view)
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if strings.Contains(line, "node-a") {
+			if width := lipgloss.Width(strings.TrimRight(line, " ")); width > 70 {
+				t.Errorf("expected the node row to fit in 70 columns, got %d in %q", width, line)
+			}
+		}
+	}
+}
+
+func TestFitColumnsTruncatesCells(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	name := "ip-192-168-100-200.us-west-2.compute.internal-with-a-very-long-suffix"
+	node := model.NewNode(testNode(name))
+	node.Show()
+	ui.Cluster().AddNode(node)
+
+	// the name column is never hidden, so it's truncated once the other columns have been
+	ui.Update(tea.WindowSizeMsg{Width: 40, Height: 40})
+	view := ui.View()
+	if strings.Contains(view, name) || !strings.Contains(view, "ip-192-168") {
+		t.Errorf("expected the node name to be truncated, got\n%s", view)
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if strings.Contains(line, "ip-192-168") {
+			if !strings.Contains(line, "…") {
+				t.Errorf("expected the truncated name to end with an ellipsis, got %q", line)
+			}
+			if width := lipgloss.Width(strings.TrimRight(line, " ")); width > 40 {
+				t.Errorf("expected the node row to fit in 40 columns, got %d in %q", width, line)
+			}
+		}
+	}
+
+	// a snapshot isn't fitted to a terminal
+	var b strings.Builder
+	if err := ui.WriteSnapshot(&b); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	if !strings.Contains(b.String(), name) {
+		t.Errorf("expected the full node name in the snapshot, got\n%s", b.String())
+	}
+}
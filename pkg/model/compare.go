@@ -0,0 +1,197 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/facette/natsort"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"github.com/awslabs/eks-node-viewer/pkg/text"
+)
+
+// NodeDiff is a single row of a side-by-side node comparison
+type NodeDiff struct {
+	Field string
+	Left  string
+	Right string
+}
+
+// Differs returns true if the nodes have different values for the field
+func (d NodeDiff) Differs() bool {
+	return d.Left != d.Right
+}
+
+// CompareNodes compares two nodes side-by-side. The summary fields and the capacity, allocatable and used amounts of
+// each resource are always included, followed by only the labels and the number of pods of each workload that differ.
+// Byte quantities such as memory are displayed in binary units unless raw is set.
+func CompareNodes(lhs, rhs *Node, resources []v1.ResourceName, raw bool) []NodeDiff {
+	field := func(name string, f func(n *Node) string) NodeDiff {
+		return NodeDiff{Field: name, Left: f(lhs), Right: f(rhs)}
+	}
+	diffs := []NodeDiff{
+		field("name", (*Node).Name),
+		field("instance type", func(n *Node) string { return string(n.InstanceType()) }),
+		field("capacity type", capacityType),
+		field("zone", (*Node).Zone),
+		field("price", func(n *Node) string {
+			if !n.HasPrice() {
+				return "-"
+			}
+			return fmt.Sprintf("$%0.4f", n.Price())
+		}),
+		field("price source", func(n *Node) string {
+			if source := n.PriceSource(); source != "" {
+				return source
+			}
+			return "-"
+		}),
+		field("age", func(n *Node) string { return duration.HumanDuration(since(n.Created())) }),
+		field("ready", func(n *Node) string { return strconv.FormatBool(n.Ready()) }),
+		field("cordoned", func(n *Node) string { return strconv.FormatBool(n.Cordoned()) }),
+		field("pods", func(n *Node) string { return strconv.Itoa(n.NumPods()) }),
+	}
+	for _, res := range resources {
+		diffs = append(diffs,
+			field(string(res)+" capacity", func(n *Node) string { return quantity(n.Capacity(), res, raw) }),
+			field(string(res)+" allocatable", func(n *Node) string { return quantity(n.Allocatable(), res, raw) }),
+			field(string(res)+" used", func(n *Node) string {
+				return fmt.Sprintf("%s (%s)", quantity(n.Used(), res, raw), pctUsage(n.Allocatable(), n.Used(), string(res)))
+			}))
+	}
+
+	lhsLabels, rhsLabels := lhs.Labels(), rhs.Labels()
+	for _, key := range unionKeys(lhsLabels, rhsLabels) {
+		if d := (NodeDiff{Field: key, Left: labelValue(lhsLabels, key), Right: labelValue(rhsLabels, key)}); d.Differs() {
+			diffs = append(diffs, d)
+		}
+	}
+
+	lhsPods, rhsPods := workloadCounts(lhs), workloadCounts(rhs)
+	for _, workload := range unionKeys(lhsPods, rhsPods) {
+		if lhsPods[workload] != rhsPods[workload] {
+			diffs = append(diffs, NodeDiff{Field: workload, Left: podCount(lhsPods[workload]), Right: podCount(rhsPods[workload])})
+		}
+	}
+	return diffs
+}
+
+func quantity(resources v1.ResourceList, res v1.ResourceName, raw bool) string {
+	q, ok := resources[res]
+	if !ok {
+		return "-"
+	}
+	if raw {
+		return q.String()
+	}
+	return humanQuantity(res, q)
+}
+
+func labelValue(labels map[string]string, key string) string {
+	if v, ok := labels[key]; ok {
+		return v
+	}
+	return "-"
+}
+
+func podCount(count int) string {
+	switch count {
+	case 0:
+		return "-"
+	case 1:
+		return "1 pod"
+	}
+	return fmt.Sprintf("%d pods", count)
+}
+
+// workloadCounts returns the number of pods on the node for each workload
+func workloadCounts(n *Node) map[string]int {
+	counts := map[string]int{}
+	for _, p := range n.Pods() {
+		counts[p.WorkloadName()]++
+	}
+	return counts
+}
+
+// unionKeys returns the sorted keys that are in either map
+func unionKeys[V any](lhs, rhs map[string]V) []string {
+	var keys []string
+	for k := range lhs {
+		keys = append(keys, k)
+	}
+	for k := range rhs {
+		if _, ok := lhs[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(a, b int) bool { return natsort.Compare(keys[a], keys[b]) })
+	return keys
+}
+
+// writeComparison writes the comparison of the two marked nodes, highlighting the fields that differ
+func (u *UIModel) writeComparison(w io.Writer) {
+	if len(u.marked) != 2 {
+		fmt.Fprintln(w, "Mark two nodes with 'm' to compare them...")
+		return
+	}
+	ctw := text.NewColorTabWriter(w, 0, 8, 2)
+	for _, d := range CompareNodes(u.marked[0], u.marked[1], u.Cluster().resources, u.RawQuantities) {
+		if d.Differs() {
+			fmt.Fprintf(ctw, "%s\t%s\t%s\n", d.Field, u.style.yellow(d.Left), u.style.yellow(d.Right))
+		} else {
+			fmt.Fprintf(ctw, "%s\t%s\t%s\n", d.Field, d.Left, d.Right)
+		}
+	}
+	ctw.Flush()
+}
+
+// toggleMark marks or unmarks a node for comparison, marking a third node unmarks the oldest
+func (u *UIModel) toggleMark(n *Node) {
+	for i, m := range u.marked {
+		if m == n {
+			u.marked = append(u.marked[:i], u.marked[i+1:]...)
+			return
+		}
+	}
+	u.marked = append(u.marked, n)
+	if len(u.marked) > 2 {
+		u.marked = u.marked[1:]
+	}
+}
+
+// unmarkDeleted unmarks any nodes that have been removed from the cluster
+func (u *UIModel) unmarkDeleted() {
+	var marked []*Node
+	for _, m := range u.marked {
+		if m.cluster.Load() != nil && m.Visible() {
+			marked = append(marked, m)
+		}
+	}
+	u.marked = marked
+}
+
+func (u *UIModel) isMarked(n *Node) bool {
+	for _, m := range u.marked {
+		if m == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestCompareNodes(t *testing.T) {
+	newNode := func(name, zone string) *model.Node {
+		n := testNode(name)
+		n.Labels = map[string]string{
+			v1.LabelInstanceTypeStable: "m5.large",
+			v1.LabelTopologyZone:       zone,
+		}
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+		n.Status.Capacity = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+		return model.NewNode(n)
+	}
+	lhs := newNode("lhs", "us-west-2a")
+	rhs := newNode("rhs", "us-west-2b")
+
+	for i, node := range []*model.Node{lhs, lhs, rhs} {
+		p := testPod("default", "web-abc"+string(rune('0'+i)))
+		p.GenerateName = "web-"
+		node.BindPod(model.NewPod(p))
+	}
+
+	diffs := map[string]model.NodeDiff{}
+	for _, d := range model.CompareNodes(lhs, rhs, []v1.ResourceName{v1.ResourceCPU}, false) {
+		diffs[d.Field] = d
+	}
+	for field, exp := range map[string]model.NodeDiff{
+		"name":               {Field: "name", Left: "lhs", Right: "rhs"},
+		"instance type":      {Field: "instance type", Left: "m5.large", Right: "m5.large"},
+		"cpu allocatable":    {Field: "cpu allocatable", Left: "2", Right: "2"},
+		v1.LabelTopologyZone: {Field: v1.LabelTopologyZone, Left: "us-west-2a", Right: "us-west-2b"},
+		"default/web":        {Field: "default/web", Left: "2 pods", Right: "1 pod"},
+	} {
+		if got, ok := diffs[field]; !ok || got != exp {
+			t.Errorf("expected %v, got %v", exp, got)
+		}
+	}
+	if _, ok := diffs[v1.LabelInstanceTypeStable]; ok {
+		t.Errorf("expected labels with the same value to be omitted")
+	}
+}
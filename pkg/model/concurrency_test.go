@@ -0,0 +1,113 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// TestConcurrentAccess updates the cluster from several goroutines the way the informers do while it's rendered, it's
+// only meaningful when run with -race
+func TestConcurrentAccess(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	m := model.NewUIModel([]string{"eks-node-viewer/node-price", "eks-node-viewer/cost-per-pod"}, "", style)
+	m.SetResources([]string{"cpu", "memory"})
+	m.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	cluster := m.Cluster()
+
+	const nodes, iterations = 4, 200
+	for i := 0; i < nodes; i++ {
+		n := cluster.AddNode(model.NewNode(testNode(fmt.Sprintf("node-%d", i))))
+		n.Show()
+	}
+
+	var wg sync.WaitGroup
+	// node updates, as from the node informer
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < iterations; i++ {
+			n := testNode(fmt.Sprintf("node-%d", i%nodes))
+			n.Labels = map[string]string{"karpenter.sh/capacity-type": "spot", "iteration": fmt.Sprint(i)}
+			if i%10 == 0 {
+				n.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			}
+			if existing, ok := cluster.GetNodeByName(n.Name); ok {
+				existing.Update(n)
+			}
+		}
+	}()
+	// pods being scheduled and deleted, as from the pod informer
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < iterations; i++ {
+			p := testPod("default", fmt.Sprintf("pod-%d", i))
+			p.Spec.NodeName = fmt.Sprintf("node-%d", i%nodes)
+			cluster.AddPod(model.NewPod(p))
+			if i >= nodes {
+				cluster.DeletePod("default", fmt.Sprintf("pod-%d", i-nodes))
+			}
+		}
+	}()
+	// pricing, as from the pricing queue
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < iterations; i++ {
+			if n, ok := cluster.GetNodeByName(fmt.Sprintf("node-%d", i%nodes)); ok {
+				n.SetPrice(float64(i) / 100)
+			}
+		}
+	}()
+	// rendering and reading, as from the display and the API
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < iterations/10; i++ {
+			_ = m.View()
+			cluster.ForEachNode(func(n *model.Node) {
+				_ = n.Pods()
+				for range n.Labels() {
+				}
+				_, _ = n.Label("iteration")
+				_ = n.Deleting()
+				_ = n.Price()
+				_ = n.IsSpot()
+				_ = n.Name()
+			})
+		}
+	}()
+	wg.Wait()
+
+	var pods int
+	cluster.ForEachNode(func(n *model.Node) {
+		pods += len(n.Pods())
+	})
+	if pods != nodes {
+		t.Errorf("expected the last %d pods to be bound, got %d", nodes, pods)
+	}
+}
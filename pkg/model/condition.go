@@ -0,0 +1,279 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// conditionVariables are the variables that can be used in a condition, computed from the stats of the clusters
+var conditionVariables = map[string]func(st Stats) float64{
+	"nodes_total":     func(st Stats) float64 { return float64(st.NumNodes) },
+	"nodes_ready":     func(st Stats) float64 { return countNodes(st.Nodes, (*Node).Ready) },
+	"nodes_not_ready": func(st Stats) float64 { return float64(st.NumNodes) - countNodes(st.Nodes, (*Node).Ready) },
+	"nodes_cordoned":  func(st Stats) float64 { return countNodes(st.Nodes, (*Node).Cordoned) },
+	"nodes_deleting":  func(st Stats) float64 { return countNodes(st.Nodes, (*Node).Deleting) },
+	"pods_total":      func(st Stats) float64 { return float64(st.TotalPods) },
+	"pending_pods":    func(st Stats) float64 { return float64(st.PodsByPhase[v1.PodPending]) },
+	"running_pods":    func(st Stats) float64 { return float64(st.PodsByPhase[v1.PodRunning]) },
+	"bound_pods":      func(st Stats) float64 { return float64(st.BoundPodCount) },
+	"price_per_hour":  func(st Stats) float64 { return st.TotalPrice },
+	"cpu_utilization": cpuUtilization,
+}
+
+// cpuUtilization returns the percentage of the allocatable CPU of the nodes that's requested
+func cpuUtilization(st Stats) float64 {
+	allocatable, used := st.AllocatableResources[v1.ResourceCPU], st.UsedResources[v1.ResourceCPU]
+	if allocatable.IsZero() {
+		return 0
+	}
+	return 100 * used.AsApproximateFloat64() / allocatable.AsApproximateFloat64()
+}
+
+func countNodes(nodes []*Node, f func(n *Node) bool) float64 {
+	count := 0.0
+	for _, n := range nodes {
+		if f(n) {
+			count++
+		}
+	}
+	return count
+}
+
+// ConditionVariables returns the names of the variables that can be used in a condition
+func ConditionVariables() []string {
+	var names []string
+	for name := range conditionVariables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Condition is a boolean expression over the state of the clusters, e.g.
+// nodes_ready==nodes_total && pending_pods==0
+type Condition struct {
+	expr      string
+	variables []string
+	eval      func(vars map[string]float64) bool
+}
+
+// ParseCondition parses a condition made up of comparisons (==, !=, <, <=, >, >=) between variables and numbers,
+// combined with &&, || and ! and grouped with parentheses
+func ParseCondition(expr string) (*Condition, error) {
+	tokens, err := tokenizeCondition(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing condition %q, %w", expr, err)
+	}
+	p := &conditionParser{tokens: tokens}
+	eval, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing condition %q, %w", expr, err)
+	}
+	return &Condition{expr: expr, variables: p.variables, eval: eval}, nil
+}
+
+func (c *Condition) String() string {
+	return c.expr
+}
+
+// Evaluate returns true if the condition holds for the stats
+func (c *Condition) Evaluate(st Stats) bool {
+	return c.eval(c.values(st))
+}
+
+// Values returns the values of the variables used by the condition, e.g. "nodes_ready=3 nodes_total=4"
+func (c *Condition) Values(st Stats) string {
+	vars := c.values(st)
+	var values []string
+	for _, name := range c.variables {
+		values = append(values, fmt.Sprintf("%s=%s", name, strconv.FormatFloat(vars[name], 'f', -1, 64)))
+	}
+	return strings.Join(values, " ")
+}
+
+func (c *Condition) values(st Stats) map[string]float64 {
+	vars := map[string]float64{}
+	for _, name := range c.variables {
+		vars[name] = conditionVariables[name](st)
+	}
+	return vars
+}
+
+func tokenizeCondition(expr string) ([]string, error) {
+	var tokens []string
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsLetter(r) || r == '_' || unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || runes[i] == '_' || unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		case strings.ContainsRune("()", r):
+			tokens = append(tokens, string(r))
+			i++
+		default:
+			// operators, the two character operators are checked first so that <= isn't split into < and =
+			if i+1 < len(runes) {
+				if op := string(runes[i : i+2]); op == "==" || op == "!=" || op == "<=" || op == ">=" || op == "&&" || op == "||" {
+					tokens = append(tokens, op)
+					i += 2
+					continue
+				}
+			}
+			if r == '<' || r == '>' || r == '!' {
+				tokens = append(tokens, string(r))
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("unexpected %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+type conditionParser struct {
+	tokens    []string
+	pos       int
+	variables []string
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *conditionParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *conditionParser) parseOr() (func(map[string]float64) bool, error) {
+	lhs, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.next()
+		rhs, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := lhs
+		lhs = func(vars map[string]float64) bool { return l(vars) || rhs(vars) }
+	}
+	return lhs, nil
+}
+
+func (p *conditionParser) parseAnd() (func(map[string]float64) bool, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.next()
+		rhs, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := lhs
+		lhs = func(vars map[string]float64) bool { return l(vars) && rhs(vars) }
+	}
+	return lhs, nil
+}
+
+func (p *conditionParser) parseUnary() (func(map[string]float64) bool, error) {
+	switch p.peek() {
+	case "!":
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(vars map[string]float64) bool { return !operand(vars) }, nil
+	case "(":
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok != ")" {
+			return nil, fmt.Errorf("expected ), found %q", tok)
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+var comparisons = map[string]func(lhs, rhs float64) bool{
+	"==": func(lhs, rhs float64) bool { return lhs == rhs },
+	"!=": func(lhs, rhs float64) bool { return lhs != rhs },
+	"<":  func(lhs, rhs float64) bool { return lhs < rhs },
+	"<=": func(lhs, rhs float64) bool { return lhs <= rhs },
+	">":  func(lhs, rhs float64) bool { return lhs > rhs },
+	">=": func(lhs, rhs float64) bool { return lhs >= rhs },
+}
+
+func (p *conditionParser) parseComparison() (func(map[string]float64) bool, error) {
+	lhs, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op := p.next()
+	compare, ok := comparisons[op]
+	if !ok {
+		return nil, fmt.Errorf("expected a comparison, found %q", op)
+	}
+	rhs, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return func(vars map[string]float64) bool { return compare(lhs(vars), rhs(vars)) }, nil
+}
+
+func (p *conditionParser) parseOperand() (func(map[string]float64) float64, error) {
+	tok := p.next()
+	if tok == "" {
+		return nil, fmt.Errorf("unexpected end of condition")
+	}
+	if value, err := strconv.ParseFloat(tok, 64); err == nil {
+		return func(map[string]float64) float64 { return value }, nil
+	}
+	if _, ok := conditionVariables[tok]; !ok {
+		return nil, fmt.Errorf("unknown variable %q, must be one of %s", tok, strings.Join(ConditionVariables(), ", "))
+	}
+	if !slices.Contains(p.variables, tok) {
+		p.variables = append(p.variables, tok)
+	}
+	return func(vars map[string]float64) float64 { return vars[tok] }, nil
+}
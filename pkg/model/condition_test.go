@@ -0,0 +1,91 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func testConditionStats() model.Stats {
+	cluster := model.NewCluster()
+	for _, name := range []string{"ready-1", "ready-2", "not-ready"} {
+		n := testNode(name)
+		n.Spec.ProviderID = name
+		if name != "not-ready" {
+			n.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+		}
+		node := model.NewNode(n)
+		node.Show()
+		cluster.AddNode(node)
+	}
+	cluster.AddPod(model.NewPod(testPod("default", "pending")))
+	return cluster.Stats()
+}
+
+func TestConditionEvaluate(t *testing.T) {
+	stats := testConditionStats()
+	for _, tc := range []struct {
+		expr string
+		met  bool
+	}{
+		{"nodes_total==3", true},
+		{"nodes_ready==nodes_total", false},
+		{"nodes_ready>=2 && nodes_not_ready<=1", true},
+		{"nodes_ready==nodes_total || pending_pods>0", true},
+		{"!(pending_pods==0)", true},
+		{"(nodes_ready==3 || nodes_ready==2) && pending_pods!=1", false},
+		{"nodes_cordoned < 1", true},
+	} {
+		c, err := model.ParseCondition(tc.expr)
+		if err != nil {
+			t.Errorf("parsing %q, %s", tc.expr, err)
+			continue
+		}
+		if met := c.Evaluate(stats); met != tc.met {
+			t.Errorf("expected %q to be %v, got %v (%s)", tc.expr, tc.met, met, c.Values(stats))
+		}
+	}
+}
+
+func TestConditionValues(t *testing.T) {
+	c, err := model.ParseCondition("nodes_ready==nodes_total && pending_pods==0")
+	if err != nil {
+		t.Fatalf("parsing condition, %s", err)
+	}
+	if exp, got := "nodes_ready=2 nodes_total=3 pending_pods=1", c.Values(testConditionStats()); exp != got {
+		t.Errorf("expected values %q, got %q", exp, got)
+	}
+}
+
+func TestConditionInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"nodes_ready",
+		"nodes_ready==",
+		"node_ready==1",
+		"nodes_ready==1 &&",
+		"(nodes_ready==1",
+		"nodes_ready==1)",
+		"nodes_ready=1",
+	} {
+		if _, err := model.ParseCondition(expr); err == nil {
+			t.Errorf("expected %q to be invalid", expr)
+		}
+	}
+}
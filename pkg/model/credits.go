@@ -0,0 +1,102 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lowCPUCreditFraction is the fraction of the maximum CPU credit balance below which a burstable node is considered to
+// be low on credits
+const lowCPUCreditFraction = 0.1
+
+// maxCPUCredits is the maximum CPU credit balance that each size of burstable instance can accrue, t3a and t4g
+// instances accrue the same credits as t3 instances
+var maxCPUCredits = map[string]map[string]float64{
+	"t2": {"nano": 72, "micro": 144, "small": 288, "medium": 576, "large": 864, "xlarge": 1296, "2xlarge": 1958.4},
+	"t3": {"nano": 144, "micro": 288, "small": 576, "medium": 576, "large": 864, "xlarge": 2304, "2xlarge": 4608},
+}
+
+// burstableFamily returns the family whose credits a burstable instance type accrues, or false if it isn't burstable
+func burstableFamily(instanceType string) (family, size string, ok bool) {
+	family, size, _ = strings.Cut(instanceType, ".")
+	switch family {
+	case "t2", "t3":
+		return family, size, true
+	case "t3a", "t4g":
+		return "t3", size, true
+	}
+	return "", "", false
+}
+
+// Burstable returns true if the node is a burstable performance instance that earns and spends CPU credits
+func (n *Node) Burstable() bool {
+	_, _, ok := burstableFamily(string(n.InstanceType()))
+	return ok
+}
+
+// SetCPUCreditBalance records the CPU credit balance of a burstable node as reported by CloudWatch
+func (n *Node) SetCPUCreditBalance(balance float64) {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cpuCredits = balance
+	n.hasCPUCredits = true
+}
+
+// CPUCreditBalance returns the CPU credit balance of a burstable node, or false if it isn't known
+func (n *Node) CPUCreditBalance() (float64, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.cpuCredits, n.hasCPUCredits
+}
+
+// CPUCreditFraction returns the CPU credit balance of a burstable node as a fraction of the maximum balance that it can
+// accrue, or false if either isn't known
+func (n *Node) CPUCreditFraction() (float64, bool) {
+	balance, ok := n.CPUCreditBalance()
+	if !ok {
+		return 0, false
+	}
+	family, size, ok := burstableFamily(string(n.InstanceType()))
+	if !ok {
+		return 0, false
+	}
+	maxCredits, ok := maxCPUCredits[family][size]
+	if !ok {
+		return 0, false
+	}
+	return min(balance/maxCredits, 1), true
+}
+
+// LowCPUCredits returns true if a burstable node is close to running out of CPU credits, at which point its CPU is
+// throttled to the baseline or surplus credits are charged for
+func (n *Node) LowCPUCredits() bool {
+	fraction, ok := n.CPUCreditFraction()
+	return ok && fraction < lowCPUCreditFraction
+}
+
+// cpuCredits returns the CPU credit balance of a node for display, e.g. "42%", or "-" if it isn't burstable or the
+// balance isn't known
+func cpuCredits(n *Node) string {
+	if fraction, ok := n.CPUCreditFraction(); ok {
+		return fmt.Sprintf("%0.0f%%", 100*fraction)
+	}
+	if balance, ok := n.CPUCreditBalance(); ok {
+		return fmt.Sprintf("%0.0f", balance)
+	}
+	return "-"
+}
@@ -0,0 +1,56 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestCPUCredits(t *testing.T) {
+	for _, tc := range []struct {
+		instanceType string
+		balance      float64
+		burstable    bool
+		fraction     float64
+		low          bool
+	}{
+		{instanceType: "t3.large", balance: 432, burstable: true, fraction: 0.5},
+		{instanceType: "t4g.medium", balance: 28.8, burstable: true, fraction: 0.05, low: true},
+		{instanceType: "t2.micro", balance: 144, burstable: true, fraction: 1},
+		{instanceType: "m5.large", balance: 100},
+	} {
+		n := testNode(tc.instanceType)
+		n.Labels = map[string]string{v1.LabelInstanceTypeStable: tc.instanceType}
+		node := model.NewNode(n)
+		if node.Burstable() != tc.burstable {
+			t.Errorf("expected %s burstable to be %t", tc.instanceType, tc.burstable)
+		}
+		if _, ok := node.CPUCreditFraction(); ok {
+			t.Errorf("expected no credits for %s before the balance is known", tc.instanceType)
+		}
+		node.SetCPUCreditBalance(tc.balance)
+		fraction, ok := node.CPUCreditFraction()
+		if ok != tc.burstable || fraction != tc.fraction {
+			t.Errorf("expected %s credit fraction to be %v, got %v", tc.instanceType, tc.fraction, fraction)
+		}
+		if node.LowCPUCredits() != tc.low {
+			t.Errorf("expected %s low credits to be %t", tc.instanceType, tc.low)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// DebugSnapshot is an anonymized summary of the clusters and of the viewer's own timings, written to debug bundles so
+// that they can be attached to public issues. Node names, pods and label values are left out, the nodes are numbered
+// in display order instead.
+type DebugSnapshot struct {
+	Time     time.Time      `json:"time"`
+	Clusters []DebugCluster `json:"clusters"`
+	Timings  DebugTimings   `json:"timings"`
+}
+
+// DebugCluster is the anonymized state of a single cluster
+type DebugCluster struct {
+	Resources   []v1.ResourceName   `json:"resources"`
+	CachedNodes int                 `json:"cachedNodes"`
+	CachedPods  int                 `json:"cachedPods"`
+	PodsByPhase map[v1.PodPhase]int `json:"podsByPhase"`
+	Nodes       []DebugNode         `json:"nodes"`
+}
+
+// DebugNode is the anonymized state of a node
+type DebugNode struct {
+	Name         string `json:"name"`
+	InstanceType string `json:"instanceType"`
+	CapacityType string `json:"capacityType"`
+	Zone         string `json:"zone"`
+	OS           string `json:"os"`
+	Manager      string `json:"manager"`
+	Age          string `json:"age"`
+	Ready        bool   `json:"ready"`
+	Cordoned     bool   `json:"cordoned,omitempty"`
+	Deleting     bool   `json:"deleting,omitempty"`
+	Interrupted  bool   `json:"interrupted,omitempty"`
+	Lifecycle    string `json:"lifecycle"`
+	Disruption   string `json:"disruption"`
+	Priced       bool   `json:"priced"`
+	PriceSource  string `json:"priceSource,omitempty"`
+	Pods         int    `json:"pods"`
+	// Used and Allocatable are the quantities of each displayed resource
+	Used        map[v1.ResourceName]string `json:"used"`
+	Allocatable map[v1.ResourceName]string `json:"allocatable"`
+	// LabelKeys are the keys of the node's labels, their values may identify the cluster
+	LabelKeys []string `json:"labelKeys"`
+}
+
+// DebugTimings are the viewer's rendering times and resource usage
+type DebugTimings struct {
+	RenderDuration string `json:"renderDuration"`
+	FrameInterval  string `json:"frameInterval"`
+	ClockSkew      string `json:"clockSkew"`
+	CPUTime        string `json:"cpuTime,omitempty"`
+	MemoryBytes    uint64 `json:"memoryBytes"`
+	HeapBytes      uint64 `json:"heapBytes"`
+	Goroutines     uint64 `json:"goroutines"`
+}
+
+// DebugSnapshot returns an anonymized snapshot of the clusters and the viewer's timings
+func (u *UIModel) DebugSnapshot(now time.Time) DebugSnapshot {
+	u.sampleFootprint(now)
+	snap := DebugSnapshot{
+		Time: now,
+		Timings: DebugTimings{
+			RenderDuration: u.renderDuration.String(),
+			FrameInterval:  u.frameInterval().String(),
+			ClockSkew:      ClockSkew().String(),
+			MemoryBytes:    u.footprint.memory,
+			HeapBytes:      u.footprint.heap,
+			Goroutines:     u.footprint.goroutines,
+		},
+	}
+	if u.footprint.cpuTime > 0 {
+		snap.Timings.CPUTime = u.footprint.cpuTime.String()
+	}
+	_, clusterStats := u.stats()
+	index := 0
+	for i, st := range clusterStats {
+		c := u.clusters[i]
+		dc := DebugCluster{Resources: c.resources, PodsByPhase: st.PodsByPhase}
+		dc.CachedNodes, dc.CachedPods = c.CacheSizes()
+		for _, n := range st.Nodes {
+			index++
+			dc.Nodes = append(dc.Nodes, debugNode(n, fmt.Sprintf("node-%d", index), c.resources))
+		}
+		snap.Clusters = append(snap.Clusters, dc)
+	}
+	return snap
+}
+
+func debugNode(n *Node, name string, resources []v1.ResourceName) DebugNode {
+	dn := DebugNode{
+		Name:         name,
+		InstanceType: string(n.InstanceType()),
+		CapacityType: capacityType(n),
+		Zone:         n.Zone(),
+		OS:           n.OperatingSystem(),
+		Manager:      n.Manager(),
+		Age:          duration.HumanDuration(since(n.Created())),
+		Ready:        n.Ready(),
+		Cordoned:     n.Cordoned(),
+		Deleting:     n.Deleting(),
+		Interrupted:  n.Interrupted(),
+		Lifecycle:    n.NodeClaimLifecycle(),
+		Disruption:   n.Disruption(),
+		Priced:       n.HasPrice(),
+		PriceSource:  n.PriceSource(),
+		Pods:         n.NumPods(),
+		Used:         map[v1.ResourceName]string{},
+		Allocatable:  map[v1.ResourceName]string{},
+	}
+	used, allocatable := n.Used(), n.Allocatable()
+	for _, res := range resources {
+		usedRes, allocatableRes := used[res], allocatable[res]
+		dn.Used[res] = usedRes.String()
+		dn.Allocatable[res] = allocatableRes.String()
+	}
+	for key := range n.Labels() {
+		dn.LabelKeys = append(dn.LabelKeys, key)
+	}
+	sort.Strings(dn.LabelKeys)
+	return dn
+}
+
+// WriteDebugSnapshot writes the snapshot as indented JSON
+func WriteDebugSnapshot(w io.Writer, snap DebugSnapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(snap)
+}
@@ -0,0 +1,64 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestDebugSnapshot(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("creating style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	n := testNode("ip-10-0-1-2.secret-project.internal")
+	n.Spec.ProviderID = "aws:///us-west-2a/i-0123456789abcdef0"
+	n.Labels = map[string]string{v1.LabelInstanceTypeStable: "m5.large", "team": "secret-team"}
+	n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+	node := model.NewNode(n)
+	node.Show()
+	ui.Cluster().AddNode(node)
+
+	snap := ui.DebugSnapshot(time.Now())
+	if len(snap.Clusters) != 1 || len(snap.Clusters[0].Nodes) != 1 {
+		t.Fatalf("expected a cluster with a node, got %+v", snap.Clusters)
+	}
+	dn := snap.Clusters[0].Nodes[0]
+	if dn.Name != "node-1" || dn.InstanceType != "m5.large" || dn.Allocatable[v1.ResourceCPU] != "2" {
+		t.Errorf("unexpected node %+v", dn)
+	}
+	var buf bytes.Buffer
+	if err := model.WriteDebugSnapshot(&buf, snap); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	for _, secret := range []string{"secret-project", "secret-team", "i-0123456789abcdef0"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("expected %s to be left out of the snapshot, got\n%s", secret, buf.String())
+		}
+	}
+	if !strings.Contains(buf.String(), `"team"`) {
+		t.Errorf("expected the label keys in the snapshot, got\n%s", buf.String())
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DoNotDisruptAnnotation prevents Karpenter from voluntarily disrupting a node, or the node a pod is running on
+const DoNotDisruptAnnotation = "karpenter.sh/do-not-disrupt"
+
+// disruptionTaint is added by Karpenter to a node that it has decided to disrupt before draining it
+const disruptionTaint = "karpenter.sh/disruption"
+
+// The disruption states of a node
+const (
+	DisruptionDisrupting = "disrupting"
+	DisruptionDrifted    = "drifted"
+	DisruptionCandidate  = "candidate"
+	DisruptionBlocked    = "blocked"
+)
+
+// SetDisruptionBlocked records the message of the latest event explaining why Karpenter can't disrupt the node, an
+// empty message clears it
+func (n *Node) SetDisruptionBlocked(message string) {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.disruptionBlocked = message
+}
+
+// DisruptionBlockedEvent returns the message of the latest event explaining why Karpenter can't disrupt the node
+func (n *Node) DisruptionBlockedEvent() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.disruptionBlocked
+}
+
+// disruptionBlockedMessage returns why Karpenter can't disrupt the node, or an empty string if it's not blocked
+func (n *Node) disruptionBlockedMessage() string {
+	if n.doNotDisrupt() {
+		return "node has the " + DoNotDisruptAnnotation + " annotation"
+	}
+	for _, p := range n.Pods() {
+		if p.DoNotDisrupt() {
+			return "pod " + p.Namespace() + "/" + p.Name() + " has the " + DoNotDisruptAnnotation + " annotation"
+		}
+	}
+	return n.DisruptionBlockedEvent()
+}
+
+func (n *Node) doNotDisrupt() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.node.Annotations[DoNotDisruptAnnotation] == "true"
+}
+
+// Disruption summarizes Karpenter's disruption of the node. A node that Karpenter is disrupting is "disrupting",
+// followed by the reason if the NodeClaim records it, e.g. disrupting/Underutilized. Otherwise a drifted node is
+// "drifted" and a node that can be consolidated is a "candidate", followed by "/blocked" if a do-not-disrupt annotation
+// or a DisruptionBlocked event prevents it. Nodes that Karpenter isn't disrupting return "-". Nodes scaled by the
+// cluster autoscaler are summarized by clusterAutoscalerDisruption instead.
+func (n *Node) Disruption() string {
+	if n.ClusterAutoscaled() {
+		return n.clusterAutoscalerDisruption()
+	}
+	n.mu.RLock()
+	disrupting := false
+	for _, taint := range n.node.Spec.Taints {
+		if taint.Key == disruptionTaint {
+			disrupting = true
+		}
+	}
+	reason := n.nodeClaimConditions["DisruptionReason"]
+	drifted := n.nodeClaimConditions["Drifted"].status == metav1.ConditionTrue
+	consolidatable := n.nodeClaimConditions["Consolidatable"].status == metav1.ConditionTrue
+	n.mu.RUnlock()
+
+	if disrupting {
+		if reason.status == metav1.ConditionTrue && reason.reason != "" {
+			return DisruptionDisrupting + "/" + reason.reason
+		}
+		return DisruptionDisrupting
+	}
+	var state string
+	switch {
+	case drifted:
+		state = DisruptionDrifted
+	case consolidatable:
+		state = DisruptionCandidate
+	}
+	if n.disruptionBlockedMessage() != "" {
+		if state == "" {
+			return DisruptionBlocked
+		}
+		return state + "/" + DisruptionBlocked
+	}
+	if state == "" {
+		return "-"
+	}
+	return state
+}
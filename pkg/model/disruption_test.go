@@ -0,0 +1,87 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/awslabs/operatorpkg/status"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	karpv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestDisruption(t *testing.T) {
+	if got := model.NewNode(testNode("node")).Disruption(); got != "-" {
+		t.Errorf("expected a node without a NodeClaim to be -, got %s", got)
+	}
+
+	nc := &karpv1.NodeClaim{}
+	nc.Status.NodeName = "node"
+	nc.Status.ProviderID = "aws:///us-west-2a/i-1234"
+	nc.Status.Conditions = []status.Condition{
+		{Type: "Initialized", Status: metav1.ConditionTrue},
+		{Type: "Consolidatable", Status: metav1.ConditionTrue},
+	}
+	node := model.NewNodeFromNodeClaim(nc)
+	if exp, got := model.DisruptionCandidate, node.ComputeLabel("eks-node-viewer/node-disruption"); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+
+	// a do-not-disrupt pod prevents the candidate from being consolidated
+	p := testPod("default", "batch-job")
+	p.Annotations = map[string]string{model.DoNotDisruptAnnotation: "true"}
+	p.Spec.NodeName = "node"
+	node.BindPod(model.NewPod(p))
+	if exp, got := "candidate/blocked", node.Disruption(); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+	node.DeletePod("default", "batch-job")
+
+	node.SetDisruptionBlocked(`Cannot disrupt Node: pdb "default/web" prevents pod evictions`)
+	nc.Status.Conditions = []status.Condition{
+		{Type: "Initialized", Status: metav1.ConditionTrue},
+		{Type: "Drifted", Status: metav1.ConditionTrue},
+	}
+	node.UpdateNodeClaim(nc)
+	if exp, got := "drifted/blocked", node.Disruption(); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+	node.SetDisruptionBlocked("")
+	if exp, got := model.DisruptionDrifted, node.Disruption(); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+
+	// once Karpenter taints the node it's being disrupted, regardless of anything that blocked it earlier
+	n := testNode("node")
+	n.Spec.Taints = []v1.Taint{{Key: "karpenter.sh/disruption", Value: "disrupting", Effect: v1.TaintEffectNoSchedule}}
+	node.Update(n)
+	nc.Status.Conditions = append(nc.Status.Conditions,
+		status.Condition{Type: "DisruptionReason", Status: metav1.ConditionTrue, Reason: "Underutilized"})
+	node.UpdateNodeClaim(nc)
+	if exp, got := "disrupting/Underutilized", node.Disruption(); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+}
+
+func TestDisruptionDoNotDisruptNode(t *testing.T) {
+	n := testNode("node")
+	n.Annotations = map[string]string{model.DoNotDisruptAnnotation: "true"}
+	if exp, got := model.DisruptionBlocked, model.NewNode(n).Disruption(); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+}
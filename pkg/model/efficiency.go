@@ -0,0 +1,61 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// CostPerPod returns the hourly price of the node divided by the number of pods on it, or false if the node isn't
+// priced or has no pods
+func (n *Node) CostPerPod() (float64, bool) {
+	pods := n.NumPods()
+	if !n.HasPrice() || pods == 0 {
+		return 0, false
+	}
+	return n.Price() / float64(pods), true
+}
+
+// CostEfficiency returns the hourly price of the node divided by the utilization of its dominant resource, the higher
+// of the share of its allocatable CPU and memory that's requested. It's what the requested capacity would cost if the
+// node were fully used, so the lower it is the more efficient the node. It returns false if the node isn't priced or
+// nothing is requested.
+func (n *Node) CostEfficiency() (float64, bool) {
+	if !n.HasPrice() {
+		return 0, false
+	}
+	used, allocatable := n.Used(), n.Allocatable()
+	utilization := 0.0
+	for _, rn := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		usedRes, allocatableRes := used[rn], allocatable[rn]
+		if allocatableRes.AsApproximateFloat64() > 0 {
+			utilization = max(utilization, usedRes.AsApproximateFloat64()/allocatableRes.AsApproximateFloat64())
+		}
+	}
+	if utilization == 0 {
+		return 0, false
+	}
+	return n.Price() / utilization, true
+}
+
+// costLabel formats an hourly cost for a computed label, or "-" if it isn't known
+func costLabel(cost float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%0.4f", cost)
+}
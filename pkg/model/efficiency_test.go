@@ -0,0 +1,57 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestCostEfficiency(t *testing.T) {
+	n := testNode("node")
+	n.Status.Allocatable = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("16Gi"),
+	}
+	node := model.NewNode(n)
+	if exp, got := "-", node.ComputeLabel("eks-node-viewer/cost-per-pod"); exp != got {
+		t.Errorf("expected %s for an unpriced node, got %s", exp, got)
+	}
+	node.SetPrice(0.2)
+	if exp, got := "-", node.ComputeLabel("eks-node-viewer/cost-efficiency"); exp != got {
+		t.Errorf("expected %s for an empty node, got %s", exp, got)
+	}
+
+	// memory is the dominant resource at 50% utilization, the CPU is only 25% utilized
+	for _, name := range []string{"a", "b"} {
+		p := testPod("default", name)
+		p.Spec.InitContainers = nil
+		p.Spec.Containers = []v1.Container{{Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("500m"),
+			v1.ResourceMemory: resource.MustParse("4Gi"),
+		}}}}
+		node.BindPod(model.NewPod(p))
+	}
+	if exp, got := "0.1000", node.ComputeLabel("eks-node-viewer/cost-per-pod"); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+	if exp, got := "0.4000", node.ComputeLabel("eks-node-viewer/cost-efficiency"); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// SetExcludedNamespaces sets the namespaces whose pods don't count toward the usage of the nodes, such as kube-system,
+// so that the usage is that of the applications alone. Their requests are counted separately. This must be called
+// before pods are added.
+func (u *UIModel) SetExcludedNamespaces(namespaces []string) {
+	for _, c := range u.clusters {
+		c.excludedNamespaces = namespaces
+	}
+}
+
+// excluded returns true if the pod's namespace is excluded from the usage of the nodes
+func (c *Cluster) excluded(p *Pod) bool {
+	return slices.Contains(c.excludedNamespaces, p.Namespace())
+}
+
+// SetExcluded sets whether the pod's requests count toward the usage of its node, pods are excluded by namespace
+func (p *Pod) SetExcluded(excluded bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.excluded = excluded
+}
+
+// Excluded returns true if the pod's requests don't count toward the usage of its node
+func (p *Pod) Excluded() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.excluded
+}
+
+// ExcludedUsed returns the resources requested by the pods on the node in excluded namespaces, these aren't part of
+// the node's used resources
+func (n *Node) ExcludedUsed() v1.ResourceList {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	used := v1.ResourceList{}
+	for rn, q := range n.excludedUsed {
+		used[rn] = q.DeepCopy()
+	}
+	return used
+}
+
+// writeExcludedUsage writes the requests of the pods in excluded namespaces, as a share of the same base as the
+// displayed usage, so that the usage of the applications and of the excluded namespaces add up to the total
+func (u *UIModel) writeExcludedUsage(w io.Writer, resources []v1.ResourceName, stats Stats) {
+	namespaces := u.Cluster().excludedNamespaces
+	if len(namespaces) == 0 {
+		return
+	}
+	base := u.usageBase(stats.AllocatableResources, stats.CapacityResources)
+	var usage []string
+	for _, res := range resources {
+		used := stats.ExcludedResources[res]
+		allocatable := base[res]
+		pctUsed := 0.0
+		if allocatable.AsApproximateFloat64() != 0 {
+			pctUsed = 100 * (used.AsApproximateFloat64() / allocatable.AsApproximateFloat64())
+		}
+		q := used.String()
+		if !u.RawQuantities {
+			q = humanQuantity(res, used)
+		}
+		usage = append(usage, fmt.Sprintf("%s %s (%0.1f%%)", res, q, pctUsed))
+	}
+	fmt.Fprintf(w, "excluded %s: %s\n", strings.Join(namespaces, ","), strings.Join(usage, " "))
+}
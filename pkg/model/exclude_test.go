@@ -0,0 +1,72 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestExcludedNamespaces(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("creating style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	ui.SetExcludedNamespaces([]string{"kube-system", "monitoring"})
+
+	n := testNode("node")
+	n.Spec.ProviderID = "node-id"
+	n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")}
+	node := model.NewNode(n)
+	node.Show()
+	cluster := ui.Cluster()
+	cluster.AddNode(node)
+	for _, ns := range []string{"default", "kube-system"} {
+		p := testPod(ns, "pod")
+		p.Spec.NodeName = "node"
+		cluster.AddPod(model.NewPod(p))
+	}
+
+	// each test pod requests 2 CPUs
+	used, excluded := node.Used()[v1.ResourceCPU], node.ExcludedUsed()[v1.ResourceCPU]
+	if used.Value() != 2 || excluded.Value() != 2 {
+		t.Errorf("expected 2 CPUs used and 2 excluded, got %s and %s", used.String(), excluded.String())
+	}
+	if exp, got := 2, node.NumPods(); exp != got {
+		t.Errorf("expected excluded pods to still be counted, expected %d pods, got %d", exp, got)
+	}
+
+	var buf bytes.Buffer
+	if err := ui.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	if exp := "excluded kube-system,monitoring: cpu 2 (25.0%)"; !strings.Contains(buf.String(), exp) {
+		t.Errorf("expected %q in\n%s", exp, buf.String())
+	}
+
+	cluster.DeletePod("kube-system", "pod")
+	used, excluded = node.Used()[v1.ResourceCPU], node.ExcludedUsed()[v1.ResourceCPU]
+	if used.Value() != 2 || !excluded.IsZero() {
+		t.Errorf("expected deleting the excluded pod to only change the excluded usage, got %s and %s", used.String(), excluded.String())
+	}
+}
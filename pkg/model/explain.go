@@ -0,0 +1,269 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// Rejection is a reason that a pod can't be scheduled along with the number of nodes that reject it for that reason
+type Rejection struct {
+	Reason string
+	Nodes  int
+}
+
+// ExplainPending checks a pending pod against the nodes the same way the scheduler filters them, using only taints,
+// node selectors, required node affinity and the resources remaining on each node. It returns the number of nodes
+// that the pod fits on and the reasons the remaining nodes reject it, most common first.
+func ExplainPending(pod *Pod, nodes []*Node) (int, []Rejection) {
+	pod.mu.RLock()
+	spec := pod.pod.Spec
+	pod.mu.RUnlock()
+	requested := pod.Requested()
+
+	fits := 0
+	counts := map[string]int{}
+	for _, n := range nodes {
+		reasons := n.rejections(spec, requested)
+		if len(reasons) == 0 {
+			fits++
+		}
+		for _, r := range reasons {
+			counts[r]++
+		}
+	}
+
+	var rejections []Rejection
+	for reason, count := range counts {
+		rejections = append(rejections, Rejection{Reason: reason, Nodes: count})
+	}
+	sort.Slice(rejections, func(a, b int) bool {
+		if rejections[a].Nodes == rejections[b].Nodes {
+			return rejections[a].Reason < rejections[b].Reason
+		}
+		return rejections[a].Nodes > rejections[b].Nodes
+	})
+	return fits, rejections
+}
+
+// rejections returns the reasons that the node can't run a pod with the given spec and resource requests
+func (n *Node) rejections(spec v1.PodSpec, requested v1.ResourceList) []string {
+	var reasons []string
+	if n.Cordoned() {
+		reasons = append(reasons, "node is cordoned")
+	}
+
+	n.mu.RLock()
+	node := n.node
+	n.mu.RUnlock()
+
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == v1.TaintEffectPreferNoSchedule || tolerated(spec.Tolerations, taint) {
+			continue
+		}
+		reasons = append(reasons, fmt.Sprintf("untolerated taint %s", taint.ToString()))
+	}
+
+	for key, value := range spec.NodeSelector {
+		if node.Labels[key] != value {
+			reasons = append(reasons, fmt.Sprintf("node selector %s=%s", key, value))
+		}
+	}
+
+	if affinity := spec.Affinity; affinity != nil && affinity.NodeAffinity != nil &&
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		if !matchesNodeSelectorTerms(&node, affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms) {
+			reasons = append(reasons, "node affinity")
+		}
+	}
+
+	allocatable := n.Allocatable()
+	used := n.Used()
+	var resourceNames []string
+	for rn := range requested {
+		resourceNames = append(resourceNames, string(rn))
+	}
+	sort.Strings(resourceNames)
+	for _, rn := range resourceNames {
+		available := allocatable[v1.ResourceName(rn)]
+		available.Sub(used[v1.ResourceName(rn)])
+		if req := requested[v1.ResourceName(rn)]; req.Cmp(available) > 0 {
+			reasons = append(reasons, fmt.Sprintf("insufficient %s", rn))
+		}
+	}
+	return reasons
+}
+
+func tolerated(tolerations []v1.Toleration, taint *v1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesNodeSelectorTerms returns true if the node matches any of the terms, the terms are ORed together while the
+// requirements within a term are ANDed
+func matchesNodeSelectorTerms(node *v1.Node, terms []v1.NodeSelectorTerm) bool {
+	for _, term := range terms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		if matchesNodeSelectorTerm(node, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesNodeSelectorTerm(node *v1.Node, term v1.NodeSelectorTerm) bool {
+	for _, req := range term.MatchExpressions {
+		if !matchesNodeSelectorRequirement(labels.Set(node.Labels), req) {
+			return false
+		}
+	}
+	for _, req := range term.MatchFields {
+		// metadata.name is the only supported field
+		if req.Key != "metadata.name" || !matchesNodeSelectorRequirement(labels.Set{req.Key: node.Name}, req) {
+			return false
+		}
+	}
+	return true
+}
+
+var nodeSelectorOperators = map[v1.NodeSelectorOperator]selection.Operator{
+	v1.NodeSelectorOpIn:           selection.In,
+	v1.NodeSelectorOpNotIn:        selection.NotIn,
+	v1.NodeSelectorOpExists:       selection.Exists,
+	v1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	v1.NodeSelectorOpGt:           selection.GreaterThan,
+	v1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+func matchesNodeSelectorRequirement(set labels.Set, req v1.NodeSelectorRequirement) bool {
+	op, ok := nodeSelectorOperators[req.Operator]
+	if !ok {
+		return false
+	}
+	r, err := labels.NewRequirement(req.Key, op, req.Values)
+	if err != nil {
+		return false
+	}
+	return r.Matches(set)
+}
+
+// PendingPods returns the pods in the Pending phase, both those waiting to be scheduled and those that have been
+// scheduled but haven't started, sorted by namespace and name
+func (c *Cluster) PendingPods() []*Pod {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var pending []*Pod
+	for _, p := range c.pods {
+		if p.Phase() == v1.PodPending {
+			pending = append(pending, p)
+		}
+	}
+	sort.Slice(pending, func(a, b int) bool {
+		return strings.Compare(pending[a].Namespace()+"/"+pending[a].Name(), pending[b].Namespace()+"/"+pending[b].Name()) < 0
+	})
+	return pending
+}
+
+// VisibleNodes returns the nodes that are displayed
+func (c *Cluster) VisibleNodes() []*Node {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var nodes []*Node
+	for _, n := range c.nodes {
+		if n.Visible() {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// PendingCategory is why a pod is pending, so that the pods that won't be scheduled without changes are told apart
+// from those waiting for capacity that an autoscaler is expected to add
+type PendingCategory string
+
+const (
+	// PendingGated pods have scheduling gates, the scheduler ignores them until the gates are removed
+	PendingGated PendingCategory = "gated"
+	// PendingUnschedulable pods have a node selector or required node affinity that none of the nodes match
+	PendingUnschedulable PendingCategory = "unschedulable"
+	// PendingCapacity pods match some of the nodes but haven't been scheduled, usually as they're full
+	PendingCapacity PendingCategory = "waiting for capacity"
+	// PendingStarting pods have been scheduled but their containers haven't started
+	PendingStarting PendingCategory = "starting"
+)
+
+// CategorizePending returns why a pending pod is pending. A pod is only unschedulable if there are nodes and none of
+// them have the labels it selects, an autoscaler may still be able to launch a node that does.
+func CategorizePending(pod *Pod, nodes []*Node) PendingCategory {
+	if pod.IsScheduled() {
+		return PendingStarting
+	}
+	pod.mu.RLock()
+	spec := pod.pod.Spec
+	pod.mu.RUnlock()
+	if len(spec.SchedulingGates) > 0 {
+		return PendingGated
+	}
+	if len(nodes) == 0 {
+		return PendingCapacity
+	}
+	for _, n := range nodes {
+		if n.matchesPlacement(spec) {
+			return PendingCapacity
+		}
+	}
+	return PendingUnschedulable
+}
+
+// matchesPlacement returns true if the node has the labels required by the pod's node selector and node affinity
+func (n *Node) matchesPlacement(spec v1.PodSpec) bool {
+	n.mu.RLock()
+	node := n.node
+	n.mu.RUnlock()
+	for key, value := range spec.NodeSelector {
+		if node.Labels[key] != value {
+			return false
+		}
+	}
+	if affinity := spec.Affinity; affinity != nil && affinity.NodeAffinity != nil &&
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		return matchesNodeSelectorTerms(&node, affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms)
+	}
+	return true
+}
+
+// SchedulingGates returns the names of the pod's scheduling gates
+func (p *Pod) SchedulingGates() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var gates []string
+	for _, g := range p.pod.Spec.SchedulingGates {
+		gates = append(gates, g.Name)
+	}
+	return gates
+}
@@ -0,0 +1,158 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestExplainPending(t *testing.T) {
+	allocatable := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("16Gi"),
+		v1.ResourcePods:   resource.MustParse("110"),
+	}
+
+	fits := testNode("fits")
+	fits.Status.Allocatable = allocatable
+
+	tainted := testNode("tainted")
+	tainted.Status.Allocatable = allocatable
+	tainted.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule}}
+
+	small := testNode("small")
+	small.Status.Allocatable = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("1"),
+		v1.ResourceMemory: resource.MustParse("16Gi"),
+		v1.ResourcePods:   resource.MustParse("110"),
+	}
+	small.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule}}
+
+	wrongZone := testNode("wrong-zone")
+	wrongZone.Status.Allocatable = allocatable
+	wrongZone.Labels = map[string]string{v1.LabelTopologyZone: "us-west-2b"}
+
+	var nodes []*model.Node
+	for _, n := range []*v1.Node{fits, tainted, small, wrongZone} {
+		nodes = append(nodes, model.NewNode(n))
+	}
+
+	pod := testPod("default", "mypod")
+	pod.Spec.Affinity = &v1.Affinity{
+		NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{
+					{
+						MatchExpressions: []v1.NodeSelectorRequirement{
+							{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpNotIn, Values: []string{"us-west-2b"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	gotFits, rejections := model.ExplainPending(model.NewPod(pod), nodes)
+	if gotFits != 1 {
+		t.Errorf("expected the pod to fit on 1 node, got %d", gotFits)
+	}
+	expected := []model.Rejection{
+		{Reason: "untolerated taint dedicated=gpu:NoSchedule", Nodes: 2},
+		{Reason: "insufficient cpu", Nodes: 1},
+		{Reason: "node affinity", Nodes: 1},
+	}
+	if len(rejections) != len(expected) {
+		t.Fatalf("expected %d rejections, got %v", len(expected), rejections)
+	}
+	for i := range expected {
+		if rejections[i] != expected[i] {
+			t.Errorf("expected rejection %d to be %v, got %v", i, expected[i], rejections[i])
+		}
+	}
+
+	// tolerating the taint leaves only the resource shortage on the small node
+	pod.Spec.Tolerations = []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists}}
+	gotFits, rejections = model.ExplainPending(model.NewPod(pod), nodes)
+	if gotFits != 2 {
+		t.Errorf("expected the pod to fit on 2 nodes, got %d", gotFits)
+	}
+	if len(rejections) != 2 || rejections[0].Reason != "insufficient cpu" {
+		t.Errorf("expected insufficient cpu and node affinity, got %v", rejections)
+	}
+}
+
+func TestClusterPendingPods(t *testing.T) {
+	cluster := model.NewCluster()
+	running := testPod("default", "running")
+	running.Spec.NodeName = "mynode"
+	running.Status.Phase = v1.PodRunning
+	cluster.AddPod(model.NewPod(running))
+	// pods that are scheduled but haven't started are still pending
+	creating := testPod("default", "c")
+	creating.Spec.NodeName = "mynode"
+	cluster.AddPod(model.NewPod(creating))
+	cluster.AddPod(model.NewPod(testPod("default", "b")))
+	cluster.AddPod(model.NewPod(testPod("default", "a")))
+
+	pending := cluster.PendingPods()
+	if len(pending) != 3 {
+		t.Fatalf("expected 3 pending pods, got %d", len(pending))
+	}
+	for i, exp := range []string{"a", "b", "c"} {
+		if got := pending[i].Name(); got != exp {
+			t.Errorf("expected pending pod %d to be %s, got %s", i, exp, got)
+		}
+	}
+}
+
+func TestCategorizePending(t *testing.T) {
+	node := testNode("mynode")
+	node.Labels = map[string]string{"arch": "amd64"}
+	nodes := []*model.Node{model.NewNode(node)}
+
+	gated := testPod("default", "gated")
+	gated.Spec.SchedulingGates = []v1.PodSchedulingGate{{Name: "example.com/quota"}}
+	unschedulable := testPod("default", "unschedulable")
+	unschedulable.Spec.NodeSelector = map[string]string{"arch": "arm64"}
+	capacity := testPod("default", "capacity")
+	capacity.Spec.NodeSelector = map[string]string{"arch": "amd64"}
+	starting := testPod("default", "starting")
+	starting.Spec.NodeName = "mynode"
+
+	for _, tc := range []struct {
+		pod   *v1.Pod
+		nodes []*model.Node
+		exp   model.PendingCategory
+	}{
+		{pod: gated, nodes: nodes, exp: model.PendingGated},
+		{pod: unschedulable, nodes: nodes, exp: model.PendingUnschedulable},
+		// with no nodes an autoscaler may still launch one that matches
+		{pod: unschedulable, nodes: nil, exp: model.PendingCapacity},
+		{pod: capacity, nodes: nodes, exp: model.PendingCapacity},
+		{pod: starting, nodes: nodes, exp: model.PendingStarting},
+	} {
+		if got := model.CategorizePending(model.NewPod(tc.pod), tc.nodes); got != tc.exp {
+			t.Errorf("expected %s to be %s, got %s", tc.pod.Name, tc.exp, got)
+		}
+	}
+	if got := model.NewPod(gated).SchedulingGates(); len(got) != 1 || got[0] != "example.com/quota" {
+		t.Errorf("expected scheduling gate example.com/quota, got %v", got)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// WriteCSV writes the nodes that are currently displayed, across all pages and in display order, as CSV
+func (u *UIModel) WriteCSV(w io.Writer) error {
+	_, clusterStats := u.stats()
+	resources := u.Cluster().resources
+	multiCluster := len(u.clusters) > 1
+
+	var extraLabels []string
+	for _, label := range u.extraLabels {
+		if label != "" {
+			extraLabels = append(extraLabels, label)
+		}
+	}
+
+	var header []string
+	if multiCluster {
+		header = append(header, "cluster")
+	}
+	header = append(header, "name", "instance type", "capacity type", "price")
+	for _, res := range resources {
+		header = append(header, fmt.Sprintf("%s used", res), fmt.Sprintf("%s allocatable", res))
+	}
+	header = append(header, extraLabels...)
+	for _, c := range u.columns {
+		header = append(header, c.Name)
+	}
+	notes := u.Notes.Len() > 0
+	if notes {
+		header = append(header, "note")
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for i, st := range clusterStats {
+		for _, n := range u.filterNodes(st.Nodes) {
+			var row []string
+			if multiCluster {
+				row = append(row, u.clusters[i].Name())
+			}
+			price := ""
+			if n.HasPrice() && !u.DisablePricing {
+				price = fmt.Sprintf("%0.4f", n.Price())
+			}
+			row = append(row, n.Name(), string(n.InstanceType()), capacityType(n), price)
+
+			used, allocatable := n.Used(), n.Allocatable()
+			for _, res := range resources {
+				usedRes, allocatableRes := used[res], allocatable[res]
+				row = append(row, usedRes.String(), allocatableRes.String())
+			}
+			for _, label := range extraLabels {
+				labelValue, ok := n.Label(label)
+				if !ok {
+					labelValue = n.ComputeLabel(label)
+				}
+				row = append(row, labelValue)
+			}
+			for _, c := range u.columns {
+				row = append(row, c.Value(n))
+			}
+			if notes {
+				note, _ := u.Notes.Get(n.Name())
+				row = append(row, note.Text)
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportCSV writes the displayed nodes to the export path, or a timestamped file in the current directory if no
+// path was specified, and returns a status message describing the result
+func (u *UIModel) exportCSV() string {
+	path := u.ExportPath
+	if path == "" {
+		path = fmt.Sprintf("eks-node-viewer-%s.csv", time.Now().Format("20060102-150405"))
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Sprintf("export failed, %s", err)
+	}
+	if err := u.WriteCSV(f); err != nil {
+		f.Close()
+		return fmt.Sprintf("export failed, %s", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Sprintf("export failed, %s", err)
+	}
+	return fmt.Sprintf("exported to %s", path)
+}
@@ -0,0 +1,57 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package model_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestWriteCSV(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel([]string{"topology.kubernetes.io/zone"}, "creation", style)
+	ui.SetResources([]string{"cpu"})
+
+	n := testNode("mynode")
+	n.Spec.ProviderID = "mynode-id"
+	n.Labels = map[string]string{
+		v1.LabelInstanceTypeStable:    "m5.large",
+		"karpenter.sh/capacity-type":  "spot",
+		"topology.kubernetes.io/zone": "us-west-2a",
+	}
+	n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+	node := model.NewNode(n)
+	node.SetPrice(0.0345)
+	node.Show()
+	ui.Cluster().AddNode(node)
+
+	var buf bytes.Buffer
+	if err := ui.WriteCSV(&buf); err != nil {
+		t.Fatalf("writing csv, %s", err)
+	}
+	exp := "name,instance type,capacity type,price,cpu used,cpu allocatable,topology.kubernetes.io/zone\n" +
+		"mynode,m5.large,Spot,0.0345,0,2,us-west-2a\n"
+	if got := buf.String(); got != exp {
+		t.Errorf("expected csv\n%s\ngot\n%s", exp, strings.TrimSpace(got))
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// fargateFreeEphemeralStorage is the ephemeral storage in GB that Fargate provides to each pod at no extra charge
+const fargateFreeEphemeralStorage = 20
+
+// fargateOverheadMemory is the memory in GB that Fargate adds to each pod's requests for the Kubernetes components
+const fargateOverheadMemory = 0.25
+
+// FargateCapacity is the capacity that Fargate provisions for a pod, with memory and ephemeral storage in GB
+type FargateCapacity struct {
+	CPU              float64
+	Memory           float64
+	EphemeralStorage float64
+	// Estimated is true if the capacity was estimated from the pod's requests as the CapacityProvisioned annotation
+	// was missing or couldn't be parsed
+	Estimated bool
+}
+
+// BillableEphemeralStorage returns the ephemeral storage in GB that is charged for beyond what Fargate provides for free
+func (c FargateCapacity) BillableEphemeralStorage() float64 {
+	return math.Max(0, c.EphemeralStorage-fargateFreeEphemeralStorage)
+}
+
+func (c FargateCapacity) String() string {
+	s := fmt.Sprintf("%gvCPU-%gGB", c.CPU, c.Memory)
+	if c.EphemeralStorage > fargateFreeEphemeralStorage {
+		s += fmt.Sprintf("-%gGB", c.EphemeralStorage)
+	}
+	if c.Estimated {
+		s = "~" + s
+	}
+	return s
+}
+
+// fargateSize is a vCPU and memory combination that Fargate supports
+type fargateSize struct {
+	cpu    float64
+	memory []float64
+}
+
+var fargateSizes = []fargateSize{
+	{cpu: 0.25, memory: []float64{0.5, 1, 2}},
+	{cpu: 0.5, memory: memoryRange(1, 4, 1)},
+	{cpu: 1, memory: memoryRange(2, 8, 1)},
+	{cpu: 2, memory: memoryRange(4, 16, 1)},
+	{cpu: 4, memory: memoryRange(8, 30, 1)},
+	{cpu: 8, memory: memoryRange(16, 60, 4)},
+	{cpu: 16, memory: memoryRange(32, 120, 8)},
+}
+
+func memoryRange(min, max, step float64) []float64 {
+	var memory []float64
+	for m := min; m <= max; m += step {
+		memory = append(memory, m)
+	}
+	return memory
+}
+
+// estimateFargateCapacity estimates the capacity that Fargate provisions for the requested resources by rounding up to
+// the smallest supported vCPU and memory combination, see
+// https://docs.aws.amazon.com/eks/latest/userguide/fargate-pod-configuration.html
+func estimateFargateCapacity(requested v1.ResourceList) FargateCapacity {
+	cpu := requested.Cpu().AsApproximateFloat64()
+	memory := requested.Memory().AsApproximateFloat64()/(1024*1024*1024) + fargateOverheadMemory
+	capacity := FargateCapacity{
+		EphemeralStorage: fargateEphemeralStorage(requested),
+		Estimated:        true,
+	}
+	for _, size := range fargateSizes {
+		if size.cpu < cpu {
+			continue
+		}
+		for _, m := range size.memory {
+			if m >= memory {
+				capacity.CPU, capacity.Memory = size.cpu, m
+				return capacity
+			}
+		}
+	}
+	// larger than any supported size, so the pod wouldn't be scheduled but we can still estimate its price
+	largest := fargateSizes[len(fargateSizes)-1]
+	capacity.CPU, capacity.Memory = math.Max(cpu, largest.cpu), math.Max(memory, largest.memory[len(largest.memory)-1])
+	return capacity
+}
+
+// fargateEphemeralStorage returns the ephemeral storage in GB that Fargate provisions for the requested resources
+func fargateEphemeralStorage(requested v1.ResourceList) float64 {
+	storage := requested.StorageEphemeral().AsApproximateFloat64() / (1024 * 1024 * 1024)
+	return math.Max(fargateFreeEphemeralStorage, math.Ceil(storage))
+}
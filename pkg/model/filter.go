@@ -0,0 +1,65 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// NewNodeFilter returns a function that reports whether a node matches the filter. Filters that look like a label
+// selector (e.g. "karpenter.sh/capacity-type=spot") are matched against the node labels, anything else is a case
+// insensitive substring match against the node name, instance ID, instance type, manager and label values.
+func NewNodeFilter(filter string) func(n *Node) bool {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return func(n *Node) bool { return true }
+	}
+
+	if selector, ok := labelSelector(filter); ok {
+		return func(n *Node) bool {
+			return n.MatchesSelector(selector)
+		}
+	}
+
+	filter = strings.ToLower(filter)
+	return func(n *Node) bool {
+		if strings.Contains(strings.ToLower(n.Name()), filter) ||
+			strings.Contains(strings.ToLower(n.InstanceID()), filter) ||
+			strings.Contains(strings.ToLower(string(n.InstanceType())), filter) ||
+			strings.Contains(n.Manager(), filter) {
+			return true
+		}
+		for _, v := range n.Labels() {
+			if strings.Contains(strings.ToLower(v), filter) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// labelSelector returns the filter as a label selector, or false if it doesn't look like one
+func labelSelector(filter string) (labels.Selector, bool) {
+	if !strings.ContainsAny(filter, "=!()") {
+		return nil, false
+	}
+	selector, err := labels.Parse(filter)
+	if err != nil {
+		return nil, false
+	}
+	return selector, true
+}
@@ -0,0 +1,49 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package model_test
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestNodeFilter(t *testing.T) {
+	n := testNode("ip-192-168-1-1.us-west-2.compute.internal")
+	n.Spec.ProviderID = "aws:///us-west-2a/i-0123456789abcdef0"
+	n.Labels = map[string]string{
+		v1.LabelInstanceTypeStable:   "m5.large",
+		"karpenter.sh/capacity-type": "spot",
+	}
+	node := model.NewNode(n)
+
+	for filter, exp := range map[string]bool{
+		"":                                 true,
+		"ip-192-168-1-1":                   true,
+		"IP-192":                           true,
+		"i-0123456789":                     true,
+		"m5.large":                         true,
+		"c5":                               false,
+		"karpenter.sh/capacity-type=spot":  true,
+		"karpenter.sh/capacity-type!=spot": false,
+		"karpenter.sh/capacity-type in (spot,on-demand)": true,
+		"karpenter.sh/capacity-type=on-demand":           false,
+	} {
+		if got := model.NewNodeFilter(filter)(node); got != exp {
+			t.Errorf("expected filter %q to be %v, got %v", filter, exp, got)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"runtime/metrics"
+	"time"
+)
+
+// footprintSampleInterval is how often the viewer's own resource usage is sampled while it's displayed
+const footprintSampleInterval = 2 * time.Second
+
+// footprintMetrics are the runtime metrics read for the viewer's memory usage, in the order they're sampled
+var footprintMetrics = []string{
+	"/memory/classes/total:bytes",
+	"/memory/classes/heap/released:bytes",
+	"/memory/classes/heap/objects:bytes",
+	"/sched/goroutines:goroutines",
+}
+
+// footprint is the viewer's own resource usage, so that users can confirm its footprint, e.g. on a shared jump host,
+// and report it when it grows on large clusters
+type footprint struct {
+	sampled time.Time
+	// cpuTime is the user and system CPU time used by the process, it's zero if it can't be read on this platform
+	cpuTime time.Duration
+	// cpuPercent is the percentage of a CPU that was used between the last two samples, if cpuMeasured
+	cpuPercent  float64
+	cpuMeasured bool
+	// memory is the memory obtained from the OS and not returned to it, which approximates the resident set
+	memory     uint64
+	heap       uint64
+	goroutines uint64
+}
+
+// sampleFootprint samples the viewer's resource usage, the CPU percentage is measured from the previous sample
+func (u *UIModel) sampleFootprint(now time.Time) {
+	samples := make([]metrics.Sample, len(footprintMetrics))
+	for i, name := range footprintMetrics {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+
+	prev := u.footprint
+	u.footprint = footprint{
+		sampled:    now,
+		memory:     samples[0].Value.Uint64() - samples[1].Value.Uint64(),
+		heap:       samples[2].Value.Uint64(),
+		goroutines: samples[3].Value.Uint64(),
+	}
+	if cpuTime, ok := processCPUTime(); ok {
+		u.footprint.cpuTime = cpuTime
+		if elapsed := now.Sub(prev.sampled); !prev.sampled.IsZero() && elapsed > 0 {
+			u.footprint.cpuPercent = 100 * float64(cpuTime-prev.cpuTime) / float64(elapsed)
+			u.footprint.cpuMeasured = true
+		}
+	}
+	u.dirty = true
+}
+
+// toggleFootprint shows or hides the viewer's resource usage, it's sampled straight away so that it's not blank
+func (u *UIModel) toggleFootprint() {
+	u.showFootprint = !u.showFootprint
+	if u.showFootprint {
+		u.sampleFootprint(time.Now())
+	}
+}
+
+// footprintView summarizes the viewer's resource usage along with the number of nodes and pods that it caches from its
+// watches, which is what it grows with on large clusters
+func (u *UIModel) footprintView() string {
+	nodes, pods := 0, 0
+	for _, c := range u.clusters {
+		n, p := c.CacheSizes()
+		nodes += n
+		pods += p
+	}
+	cpu := "-"
+	if u.footprint.cpuMeasured {
+		cpu = fmt.Sprintf("%0.1f%%", u.footprint.cpuPercent)
+	}
+	memUnit, memSize := byteUnit(float64(u.footprint.memory))
+	heapUnit, heapSize := byteUnit(float64(u.footprint.heap))
+	return fmt.Sprintf("viewer: cpu %s, mem %0.1f %s (heap %0.1f %s), %d goroutines, caching %d nodes/%d pods",
+		cpu, float64(u.footprint.memory)/memSize, memUnit, float64(u.footprint.heap)/heapSize, heapUnit,
+		u.footprint.goroutines, nodes, pods)
+}
+
+// CacheSizes returns the number of nodes and pods that are held for the cluster
+func (c *Cluster) CacheSizes() (nodes, pods int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.nodes), len(c.pods)
+}
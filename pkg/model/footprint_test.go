@@ -0,0 +1,62 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestFootprint(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	n := testNode("mynode")
+	n.Spec.ProviderID = "mynode-id"
+	node := model.NewNode(n)
+	node.Show()
+	ui.Cluster().AddNode(node)
+	ui.Cluster().AddPod(model.NewPod(testPod("default", "a")))
+	ui.Cluster().AddPod(model.NewPod(testPod("default", "b")))
+
+	if nodes, pods := ui.Cluster().CacheSizes(); nodes != 1 || pods != 2 {
+		t.Errorf("expected 1 node and 2 pods to be cached, got %d and %d", nodes, pods)
+	}
+	if view := ui.View(); strings.Contains(view, "viewer:") {
+		t.Fatalf("expected the footprint to be hidden by default, got\n%s", view)
+	}
+
+	press := func() { ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")}) }
+	press()
+	view := ui.View()
+	// the CPU usage is measured between samples, so it's unknown until the next one
+	for _, exp := range []string{"viewer: cpu -, mem ", "goroutines", "caching 1 nodes/2 pods"} {
+		if !strings.Contains(view, exp) {
+			t.Errorf("expected the footprint to contain %q, got\n%s", exp, view)
+		}
+	}
+	press()
+	if view := ui.View(); strings.Contains(view, "viewer:") {
+		t.Errorf("expected f to hide the footprint, got\n%s", view)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// gpuResources are the extended resources that are counted as GPUs
+var gpuResources = []v1.ResourceName{"nvidia.com/gpu", "amd.com/gpu"}
+
+// GPUs returns the number of GPUs on the node
+func (n *Node) GPUs() int64 {
+	capacity := n.Capacity()
+	var gpus int64
+	for _, rn := range gpuResources {
+		if q, ok := capacity[rn]; ok {
+			gpus += q.Value()
+		}
+	}
+	return gpus
+}
+
+// GPUUsage is the GPU hours and cost of the GPU nodes accumulated during the session, for chargeback that's based on
+// GPU hours rather than vCPU hours
+type GPUUsage struct {
+	// GPUs and Nodes are the number of GPUs and GPU nodes when last accumulated
+	GPUs     int64
+	Nodes    int
+	GPUHours float64
+	// Cost is the accumulated price of the GPU nodes, nodes without a known price are excluded
+	Cost float64
+	last time.Time
+}
+
+// Accumulate adds the GPU hours and cost of the nodes since the last time usage was accumulated
+func (g *GPUUsage) Accumulate(now time.Time, nodes []*Node) {
+	hours := 0.0
+	if !g.last.IsZero() {
+		hours = now.Sub(g.last).Hours()
+	}
+	g.last = now
+	g.GPUs, g.Nodes = 0, 0
+	for _, n := range nodes {
+		gpus := n.GPUs()
+		if gpus == 0 {
+			continue
+		}
+		g.GPUs += gpus
+		g.Nodes++
+		g.GPUHours += float64(gpus) * hours
+		if n.HasPrice() {
+			g.Cost += n.Price() * hours
+		}
+	}
+}
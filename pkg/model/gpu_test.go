@@ -0,0 +1,63 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestGPUUsage(t *testing.T) {
+	gpuNode := testNode("gpu")
+	gpuNode.Status.Capacity = v1.ResourceList{
+		v1.ResourceCPU:   resource.MustParse("48"),
+		"nvidia.com/gpu": resource.MustParse("4"),
+	}
+	gpu := model.NewNode(gpuNode)
+	gpu.SetPrice(16)
+	cpuNode := testNode("cpu")
+	cpuNode.Status.Capacity = v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}
+	cpu := model.NewNode(cpuNode)
+	cpu.SetPrice(0.2)
+	nodes := []*model.Node{gpu, cpu}
+
+	if gpus := gpu.GPUs(); gpus != 4 {
+		t.Errorf("expected 4 GPUs, got %d", gpus)
+	}
+
+	var usage model.GPUUsage
+	start := time.Now()
+	usage.Accumulate(start, nodes)
+	if usage.GPUHours != 0 || usage.Cost != 0 {
+		t.Errorf("expected nothing to be accumulated by the first sample, got %+v", usage)
+	}
+	usage.Accumulate(start.Add(30*time.Minute), nodes)
+	usage.Accumulate(start.Add(90*time.Minute), nodes)
+	if usage.GPUs != 4 || usage.Nodes != 1 {
+		t.Errorf("expected 4 GPUs on 1 node, got %d on %d", usage.GPUs, usage.Nodes)
+	}
+	if math.Abs(usage.GPUHours-6) > 1e-9 {
+		t.Errorf("expected 6 GPU hours, got %f", usage.GPUHours)
+	}
+	if math.Abs(usage.Cost-24) > 1e-9 {
+		t.Errorf("expected the GPU node to cost $24, got %f", usage.Cost)
+	}
+}
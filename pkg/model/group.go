@@ -0,0 +1,161 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/facette/natsort"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/text"
+)
+
+// DefaultGroupBy is the label nodes are grouped by when grouping is toggled on without a label being configured
+const DefaultGroupBy = "karpenter.sh/nodepool"
+
+// noGroup is the name of the group for nodes that don't have the grouping label
+const noGroup = "<none>"
+
+// NodeGroup is a set of nodes that share the same value of the grouping label along with their combined stats
+type NodeGroup struct {
+	Name  string
+	Nodes []*Node
+	Stats Stats
+}
+
+// GroupNodes groups the nodes by the value of a label, or a computed label, preserving the order of the nodes within
+// each group. Groups are sorted by name with nodes missing the label last.
+func GroupNodes(nodes []*Node, label string) []NodeGroup {
+	index := map[string]int{}
+	var groups []NodeGroup
+	for _, n := range nodes {
+		name, ok := n.Label(label)
+		if !ok {
+			name = n.ComputeLabel(label)
+		}
+		if name == "" || name == "-" {
+			name = noGroup
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, NodeGroup{
+				Name: name,
+				Stats: Stats{
+					AllocatableResources: v1.ResourceList{},
+					UsedResources:        v1.ResourceList{},
+					CapacityResources:    v1.ResourceList{},
+					ActualUsedResources:  v1.ResourceList{},
+					PodsByPhase:          map[v1.PodPhase]int{},
+				},
+			})
+		}
+		g := &groups[i]
+		g.Nodes = append(g.Nodes, n)
+		g.Stats.Nodes = append(g.Stats.Nodes, n)
+		g.Stats.NumNodes++
+		g.Stats.TotalPods += n.NumPods()
+		g.Stats.BoundPodCount += n.NumPods()
+		if n.HasPrice() {
+			g.Stats.TotalPrice += n.Price()
+		}
+		addResources(g.Stats.AllocatableResources, limitPods(n, n.Allocatable()))
+		addResources(g.Stats.UsedResources, n.Used())
+		addResources(g.Stats.CapacityResources, limitPods(n, n.Capacity()))
+		addResources(g.Stats.ActualUsedResources, n.ActualUsage())
+	}
+	sort.SliceStable(groups, func(a, b int) bool {
+		if (groups[a].Name == noGroup) != (groups[b].Name == noGroup) {
+			return groups[b].Name == noGroup
+		}
+		return natsort.Compare(groups[a].Name, groups[b].Name)
+	})
+	return groups
+}
+
+// SetGroupBy groups the displayed nodes by the value of the label, grouping can still be toggled off with 'g'
+func (u *UIModel) SetGroupBy(label string) {
+	u.groupLabel = label
+	u.grouping = true
+}
+
+// writeGroups writes a summary line for each group of nodes followed by the nodes of any expanded groups. Only the
+// lines around the selected group are written if they don't all fit in the available height.
+func (u *UIModel) writeGroups(nodes []*Node, resources []v1.ResourceName, b *strings.Builder) {
+	groups := GroupNodes(nodes, u.groupLabel)
+	if u.groupIndex >= len(groups) {
+		u.groupIndex = len(groups) - 1
+	}
+	u.selectedGroup = groups[u.groupIndex].Name
+
+	var gb strings.Builder
+	ctw := text.NewColorTabWriter(&gb, 0, 8, 1)
+	line, selectedLine := 0, 0
+	for i, g := range groups {
+		marker := "▸"
+		if u.expanded[g.Name] {
+			marker = "▾"
+		}
+		name := fmt.Sprintf("  %s %s", marker, g.Name)
+		if i == u.groupIndex {
+			selectedLine = line
+			name = u.style.yellow(fmt.Sprintf("> %s %s", marker, g.Name))
+		}
+		u.writeContextSummary(name, resources, g.Stats, ctw)
+		line++
+		if u.expanded[g.Name] {
+			// the node columns don't line up with the group summaries
+			ctw.Flush()
+			for _, n := range g.Nodes {
+				u.writeNodeInfo(n, ctw, resources)
+				line += u.rowsPerNode(resources)
+			}
+			ctw.Flush()
+		}
+	}
+	ctw.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(gb.String(), "\n"), "\n")
+	// leave room for the help
+	available := u.height - strings.Count(b.String(), "\n") - 2
+	if available > 0 && len(lines) > available {
+		start := selectedLine - available/2
+		if start < 0 {
+			start = 0
+		}
+		if start+available > len(lines) {
+			start = len(lines) - available
+		}
+		lines = lines[start : start+available]
+	}
+	for _, line := range lines {
+		fmt.Fprintln(b, line)
+	}
+}
+
+// toggleGroup expands or collapses the selected group
+func (u *UIModel) toggleGroup() {
+	if u.selectedGroup == "" {
+		return
+	}
+	if u.expanded == nil {
+		u.expanded = map[string]bool{}
+	}
+	u.expanded[u.selectedGroup] = !u.expanded[u.selectedGroup]
+}
@@ -0,0 +1,69 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestGroupNodes(t *testing.T) {
+	var nodes []*model.Node
+	for name, nodePool := range map[string]string{
+		"a": "general",
+		"b": "gpu",
+		"c": "general",
+		"d": "",
+	} {
+		n := testNode(name)
+		if nodePool != "" {
+			n.Labels = map[string]string{model.DefaultGroupBy: nodePool}
+		}
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+		node := model.NewNode(n)
+		node.SetPrice(1.5)
+		nodes = append(nodes, node)
+	}
+
+	groups := model.GroupNodes(nodes, model.DefaultGroupBy)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(groups))
+	}
+	for i, exp := range []struct {
+		name  string
+		nodes int
+	}{
+		{"general", 2},
+		{"gpu", 1},
+		{"<none>", 1},
+	} {
+		if groups[i].Name != exp.name {
+			t.Errorf("expected group %d to be %s, got %s", i, exp.name, groups[i].Name)
+		}
+		if groups[i].Stats.NumNodes != exp.nodes || len(groups[i].Nodes) != exp.nodes {
+			t.Errorf("expected %d nodes in %s, got %d", exp.nodes, exp.name, groups[i].Stats.NumNodes)
+		}
+		if exp, got := 1.5*float64(exp.nodes), groups[i].Stats.TotalPrice; exp != got {
+			t.Errorf("expected total price of %f, got %f", exp, got)
+		}
+	}
+	if got := groups[0].Stats.AllocatableResources[v1.ResourceCPU]; got.Cmp(resource.MustParse("4")) != 0 {
+		t.Errorf("expected 4 CPUs allocatable in general, got %s", got.String())
+	}
+}
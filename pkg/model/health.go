@@ -0,0 +1,87 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// HealthOK is the health of a node that isn't reporting any pressure conditions
+const HealthOK = "OK"
+
+// healthConditions are the node conditions that mean the kubelet is evicting pods or the node can't run new ones, in the
+// order they're displayed. Disk pressure is less severe as the kubelet frees space by removing unused images first.
+var healthConditions = []struct {
+	condition v1.NodeConditionType
+	severity  int
+}{
+	{v1.NodeMemoryPressure, 2},
+	{v1.NodePIDPressure, 2},
+	{v1.NodeNetworkUnavailable, 2},
+	{v1.NodeDiskPressure, 1},
+}
+
+// PressureConditions returns the pressure conditions that the node is reporting, along with the combined severity of
+// them. Ready is displayed in its own column so isn't included.
+func (n *Node) PressureConditions() ([]v1.NodeConditionType, int) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	var conditions []v1.NodeConditionType
+	severity := 0
+	for _, hc := range healthConditions {
+		for _, c := range n.node.Status.Conditions {
+			if c.Type == hc.condition && c.Status == v1.ConditionTrue {
+				conditions = append(conditions, c.Type)
+				severity += hc.severity
+			}
+		}
+	}
+	return conditions, severity
+}
+
+// Health returns the pressure conditions that the node is reporting separated by slashes, e.g.
+// MemoryPressure/DiskPressure, or OK if it isn't reporting any
+func (n *Node) Health() string {
+	conditions, _ := n.PressureConditions()
+	if len(conditions) == 0 {
+		return HealthOK
+	}
+	names := make([]string, len(conditions))
+	for i, c := range conditions {
+		names[i] = string(c)
+	}
+	return strings.Join(names, "/")
+}
+
+// HealthSeverity returns how severe the node's pressure conditions are, zero if it isn't reporting any
+func (n *Node) HealthSeverity() int {
+	_, severity := n.PressureConditions()
+	return severity
+}
+
+// health returns the health of a node for display, colored by the severity of its conditions
+func (u *UIModel) health(n *Node) string {
+	conditions, severity := n.PressureConditions()
+	switch {
+	case len(conditions) == 0:
+		return HealthOK
+	case severity > 1:
+		return u.style.red(n.Health())
+	default:
+		return u.style.yellow(n.Health())
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestNodeHealth(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("creating style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "eks-node-viewer/node-health=dsc", style)
+	ui.SetResources([]string{"cpu"})
+	if err := ui.SetLayout([]string{"name", "health"}); err != nil {
+		t.Fatalf("setting layout, %s", err)
+	}
+	for name, conditions := range map[string][]v1.NodeCondition{
+		"a-healthy": {{Type: v1.NodeReady, Status: v1.ConditionTrue}, {Type: v1.NodeMemoryPressure, Status: v1.ConditionFalse}},
+		"b-disk":    {{Type: v1.NodeDiskPressure, Status: v1.ConditionTrue}},
+		"c-memory":  {{Type: v1.NodeDiskPressure, Status: v1.ConditionTrue}, {Type: v1.NodeMemoryPressure, Status: v1.ConditionTrue}},
+	} {
+		n := testNode(name)
+		n.Spec.ProviderID = name + "-id"
+		n.Status.Conditions = conditions
+		node := model.NewNode(n)
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+
+	for name, exp := range map[string]string{
+		"a-healthy": model.HealthOK,
+		"b-disk":    "DiskPressure",
+		// conditions are displayed in a fixed order regardless of the order the kubelet reports them in
+		"c-memory": "MemoryPressure/DiskPressure",
+	} {
+		node, _ := ui.Cluster().GetNodeByName(name)
+		if got := node.ComputeLabel("eks-node-viewer/node-health"); got != exp {
+			t.Errorf("expected %s to have health %s, got %s", name, exp, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ui.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	if !strings.Contains(buf.String(), "2 nodes are reporting memory, disk or PID pressure") {
+		t.Errorf("expected a pressure warning, got\n%s", buf.String())
+	}
+	// the nodes under the most pressure are first
+	var order []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		for _, name := range []string{"a-healthy", "b-disk", "c-memory"} {
+			if strings.HasPrefix(line, name) {
+				order = append(order, name)
+			}
+		}
+	}
+	if exp, got := "c-memory,b-disk,a-healthy", strings.Join(order, ","); exp != got {
+		t.Errorf("expected nodes sorted by health %s, got %s", exp, got)
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"github.com/awslabs/eks-node-viewer/pkg/text"
+)
+
+// inspectEntry is a line of the node inspector, e.g. one of the node's labels and its value
+type inspectEntry struct {
+	section string
+	key     string
+	value   string
+}
+
+// Annotations returns the annotations of the node
+func (n *Node) Annotations() map[string]string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.node.Annotations
+}
+
+// inspectEntries returns the labels, annotations, taints and NodeClaim details of the node, each sorted by key
+func inspectEntries(n *Node) []inspectEntry {
+	var entries []inspectEntry
+	for _, section := range []struct {
+		name   string
+		values map[string]string
+	}{{"labels", n.Labels()}, {"annotations", n.Annotations()}} {
+		keys := make([]string, 0, len(section.values))
+		for k := range section.values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			entries = append(entries, inspectEntry{section: section.name, key: k, value: section.values[k]})
+		}
+	}
+	for _, t := range n.Taints() {
+		value := string(t.Effect)
+		if t.Value != "" {
+			value = t.Value + ":" + value
+		}
+		entries = append(entries, inspectEntry{section: "taints", key: t.Key, value: value})
+	}
+	return append(entries, nodeClaimEntries(n)...)
+}
+
+// nodeClaimEntries returns when the node's NodeClaim was created, its lifecycle and its status conditions
+func nodeClaimEntries(n *Node) []inspectEntry {
+	lifecycle := n.NodeClaimLifecycle()
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if len(n.nodeClaimConditions) == 0 {
+		return nil
+	}
+	var entries []inspectEntry
+	if !n.nodeclaimCreationTime.IsZero() {
+		entries = append(entries, inspectEntry{section: "nodeclaim", key: "Age",
+			value: duration.HumanDuration(since(n.nodeclaimCreationTime))})
+	}
+	entries = append(entries, inspectEntry{section: "nodeclaim", key: "Lifecycle", value: lifecycle})
+	var types []string
+	for t := range n.nodeClaimConditions {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		c := n.nodeClaimConditions[t]
+		value := string(c.status)
+		if c.reason != "" {
+			value += "/" + c.reason
+		}
+		entries = append(entries, inspectEntry{section: "nodeclaim", key: t, value: value})
+	}
+	return entries
+}
+
+// fuzzyMatch returns true if the characters of the query appear in s in the same order, ignoring case
+func fuzzyMatch(s, query string) bool {
+	q := []rune(strings.ToLower(query))
+	for _, r := range strings.ToLower(s) {
+		if len(q) == 0 {
+			break
+		}
+		if r == q[0] {
+			q = q[1:]
+		}
+	}
+	return len(q) == 0
+}
+
+// inspectMatches returns the entries of the inspected node whose key or value match the search
+func (u *UIModel) inspectMatches() []inspectEntry {
+	var matches []inspectEntry
+	for _, e := range inspectEntries(u.inspectNode) {
+		if fuzzyMatch(e.key+"="+e.value, u.inspectQuery) {
+			matches = append(matches, e)
+		}
+	}
+	return matches
+}
+
+// writeInspector writes the labels, annotations, taints and NodeClaim details of the inspected node that match the
+// search, starting from the scroll offset and limited to the number of lines if it's positive
+func (u *UIModel) writeInspector(w io.Writer, lines int) {
+	if u.inspectNode == nil {
+		fmt.Fprintln(w, "Select a node to inspect...")
+		return
+	}
+	matches := u.inspectMatches()
+	fmt.Fprintf(w, "%s: %d of %d entries match\n\n", u.inspectNode.Name(), len(matches), len(inspectEntries(u.inspectNode)))
+	u.inspectOffset = max(0, min(u.inspectOffset, len(matches)-1))
+	matches = matches[min(u.inspectOffset, len(matches)):]
+
+	ctw := text.NewColorTabWriter(w, 0, 8, 2)
+	keyWidth := 0
+	for _, e := range matches {
+		keyWidth = max(keyWidth, len([]rune(e.key)))
+	}
+	if u.width > 0 {
+		ctw.SetMaxCellWidth(max(u.width-keyWidth-2, minCellWidth))
+	}
+	section := ""
+	written := 0
+	for _, e := range matches {
+		// each section starts with its name, which isn't written without room for an entry after it
+		if e.section != section {
+			if lines > 0 && written+2 > lines {
+				break
+			}
+			section = e.section
+			fmt.Fprintln(ctw, headerStyle(strings.ToUpper(section)))
+			written++
+		}
+		if lines > 0 && written >= lines {
+			break
+		}
+		// multi-line values, such as the JSON of some annotations, are displayed on a single line
+		fmt.Fprintf(ctw, "%s\t%s\n", e.key, strings.Join(strings.Fields(e.value), " "))
+		written++
+	}
+	ctw.Flush()
+}
+
+// updateInspector handles key presses while a node is inspected, typing searches its entries
+func (u *UIModel) updateInspector(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyEsc:
+		// the first escape clears the search
+		if u.inspectQuery != "" {
+			u.inspectQuery = ""
+		} else {
+			u.inspecting = false
+		}
+		u.inspectOffset = 0
+	case tea.KeyUp:
+		u.inspectOffset = max(0, u.inspectOffset-1)
+	case tea.KeyDown:
+		// clamped to the last entry when displayed
+		u.inspectOffset++
+	case tea.KeyBackspace:
+		if r := []rune(u.inspectQuery); len(r) > 0 {
+			u.inspectQuery = string(r[:len(r)-1])
+			u.inspectOffset = 0
+		}
+	case tea.KeySpace:
+		u.inspectQuery += " "
+		u.inspectOffset = 0
+	case tea.KeyRunes:
+		u.inspectQuery += string(msg.Runes)
+		u.inspectOffset = 0
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestInspector(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		v1.LabelTopologyZone:       "us-west-2a",
+		v1.LabelInstanceTypeStable: "m5.large",
+	}
+	n.Annotations = map[string]string{"example.com/config": "{\n  \"a\": 1\n}"}
+	n.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule}}
+	node := model.NewNode(n)
+	node.Show()
+	ui.Cluster().AddNode(node)
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	ui.View()
+
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	view := ui.View()
+	for _, exp := range []string{"mynode: 4 of 4 entries match", "us-west-2a", "m5.large", `{ "a": 1 }`, "gpu:NoSchedule"} {
+		if !strings.Contains(view, exp) {
+			t.Errorf("expected %q in the inspector, got\n%s", exp, view)
+		}
+	}
+
+	// searching is a fuzzy match, so keys typed in the inspector don't act on the nodes
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("tpz")})
+	view = ui.View()
+	if !strings.Contains(view, "1 of 4 entries match") || !strings.Contains(view, "us-west-2a") || strings.Contains(view, "m5.large") {
+		t.Errorf("expected only the zone label to match, got\n%s", view)
+	}
+
+	// the first escape clears the search and the second returns to the nodes
+	ui.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view := ui.View(); !strings.Contains(view, "4 of 4 entries match") {
+		t.Errorf("expected the search to be cleared, got\n%s", view)
+	}
+	ui.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view := ui.View(); strings.Contains(view, "entries match") || !strings.Contains(view, "mynode") {
+		t.Errorf("expected the nodes to be displayed, got\n%s", view)
+	}
+}
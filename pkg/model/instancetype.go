@@ -0,0 +1,125 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// InstanceTypeInfo is the hardware of an instance type
+type InstanceTypeInfo struct {
+	VCPUs     int64 `json:"vcpus"`
+	MemoryMiB int64 `json:"memoryMiB"`
+	// NetworkPerformance is the network bandwidth as described by EC2, e.g. "Up to 12.5 Gigabit", it's empty if unknown
+	NetworkPerformance string `json:"networkPerformance,omitempty"`
+	GPUs               int64  `json:"gpus,omitempty"`
+	// MaxENIs and IPv4PerENI are the network interfaces the instance type supports and the IPv4 addresses of each,
+	// they're zero if unknown
+	MaxENIs    int64 `json:"maxENIs,omitempty"`
+	IPv4PerENI int64 `json:"ipv4PerENI,omitempty"`
+}
+
+// MemoryGiB returns the memory of the instance type in GiB
+func (i InstanceTypeInfo) MemoryGiB() float64 {
+	return float64(i.MemoryMiB) / 1024
+}
+
+// SetInstanceTypeInfo records the hardware of the node's instance type
+func (n *Node) SetInstanceTypeInfo(info InstanceTypeInfo) {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.instanceTypeInfo = &info
+}
+
+// InstanceTypeInfo returns the hardware of the node's instance type, or false if it isn't known
+func (n *Node) InstanceTypeInfo() (InstanceTypeInfo, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.instanceTypeInfo == nil {
+		return InstanceTypeInfo{}, false
+	}
+	return *n.instanceTypeInfo, true
+}
+
+// PricePerVCPU returns the hourly price of each of the node's vCPUs, or false if either the price or the vCPUs aren't
+// known
+func (n *Node) PricePerVCPU() (float64, bool) {
+	info, ok := n.InstanceTypeInfo()
+	if !ok || info.VCPUs == 0 || !n.HasPrice() {
+		return 0, false
+	}
+	return n.Price() / float64(info.VCPUs), true
+}
+
+// PricePerGB returns the hourly price of each GiB of the node's memory, or false if either the price or the memory
+// aren't known
+func (n *Node) PricePerGB() (float64, bool) {
+	info, ok := n.InstanceTypeInfo()
+	if !ok || info.MemoryMiB == 0 || !n.HasPrice() {
+		return 0, false
+	}
+	return n.Price() / info.MemoryGiB(), true
+}
+
+// hardware returns the vCPUs, memory and GPUs of a node's instance type for display, e.g. "4vCPU 16GiB 1GPU", or "-" if
+// they aren't known
+func hardware(n *Node) string {
+	info, ok := n.InstanceTypeInfo()
+	if !ok {
+		return "-"
+	}
+	s := fmt.Sprintf("%dvCPU %sGiB", info.VCPUs, formatGiB(info))
+	if info.GPUs > 0 {
+		s += fmt.Sprintf(" %dGPU", info.GPUs)
+	}
+	return s
+}
+
+// formatGiB formats the memory of an instance type without trailing zeros, e.g. 0.5 or 16
+func formatGiB(info InstanceTypeInfo) string {
+	return strconv.FormatFloat(info.MemoryGiB(), 'f', -1, 64)
+}
+
+// instanceTypeLabel returns the value of the computed instance type label with the given name for display, or "-" if
+// it isn't known
+func instanceTypeLabel(n *Node, name string) string {
+	info, ok := n.InstanceTypeInfo()
+	if !ok {
+		return "-"
+	}
+	switch name {
+	case "vcpus":
+		return strconv.FormatInt(info.VCPUs, 10)
+	case "memory":
+		return formatGiB(info) + "Gi"
+	case "network":
+		if info.NetworkPerformance != "" {
+			return info.NetworkPerformance
+		}
+	case "gpus":
+		return strconv.FormatInt(info.GPUs, 10)
+	case "price-per-vcpu":
+		if price, ok := n.PricePerVCPU(); ok {
+			return fmt.Sprintf("%0.4f", price)
+		}
+	case "price-per-gb":
+		if price, ok := n.PricePerGB(); ok {
+			return fmt.Sprintf("%0.4f", price)
+		}
+	}
+	return "-"
+}
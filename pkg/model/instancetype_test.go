@@ -0,0 +1,58 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestInstanceTypeInfo(t *testing.T) {
+	node := model.NewNode(testNode("node"))
+	node.SetPrice(0.192)
+	if _, ok := node.PricePerVCPU(); ok {
+		t.Errorf("expected no price per vCPU before the instance type is known")
+	}
+	if got := node.ComputeLabel("eks-node-viewer/node-vcpus"); got != "-" {
+		t.Errorf("expected unknown vCPUs to be -, got %q", got)
+	}
+
+	node.SetInstanceTypeInfo(model.InstanceTypeInfo{VCPUs: 4, MemoryMiB: 16384, NetworkPerformance: "Up to 10 Gigabit"})
+	if price, ok := node.PricePerVCPU(); !ok || price != 0.048 {
+		t.Errorf("expected a price per vCPU of 0.048, got %v", price)
+	}
+	if price, ok := node.PricePerGB(); !ok || price != 0.012 {
+		t.Errorf("expected a price per GB of 0.012, got %v", price)
+	}
+	for label, expected := range map[string]string{
+		"eks-node-viewer/node-vcpus":          "4",
+		"eks-node-viewer/node-memory":         "16Gi",
+		"eks-node-viewer/node-network":        "Up to 10 Gigabit",
+		"eks-node-viewer/node-gpus":           "0",
+		"eks-node-viewer/node-price-per-vcpu": "0.0480",
+		"eks-node-viewer/node-price-per-gb":   "0.0120",
+	} {
+		if got := node.ComputeLabel(label); got != expected {
+			t.Errorf("expected %s to be %q, got %q", label, expected, got)
+		}
+	}
+
+	node.SetPrice(math.NaN())
+	if got := node.ComputeLabel("eks-node-viewer/node-price-per-vcpu"); got != "-" {
+		t.Errorf("expected an unknown price per vCPU to be -, got %q", got)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// SpotInterruptionWarning is how long EC2 gives a spot instance between the interruption notice and termination
+const SpotInterruptionWarning = 2 * time.Minute
+
+// Interruption records how long a node took to drain and disappear after receiving a spot interruption notice
+type Interruption struct {
+	Name         string
+	InstanceType ec2types.InstanceType
+	Notice       time.Time
+	// Gone is when the node was removed from the cluster, or zero if it was still present at exit
+	Gone        time.Time
+	PodsEvicted int
+}
+
+// Duration returns the time between the interruption notice and the node being removed
+func (i Interruption) Duration() time.Duration {
+	if i.Gone.IsZero() {
+		return 0
+	}
+	return i.Gone.Sub(i.Notice)
+}
+
+func newInterruption(n *Node, gone time.Time) Interruption {
+	notice, evicted := n.interruption()
+	return Interruption{
+		Name:         n.Name(),
+		InstanceType: n.InstanceType(),
+		Notice:       notice,
+		Gone:         gone,
+		PodsEvicted:  evicted,
+	}
+}
+
+// Interruptions returns the spot interruptions observed during the session, including nodes that have received an
+// interruption notice but haven't been removed yet
+func (c *Cluster) Interruptions() []Interruption {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	interruptions := append([]Interruption{}, c.interruptions...)
+	for _, n := range c.nodes {
+		if n.Interrupted() {
+			interruptions = append(interruptions, newInterruption(n, time.Time{}))
+		}
+	}
+	sort.Slice(interruptions, func(a, b int) bool {
+		return interruptions[a].Notice.Before(interruptions[b].Notice)
+	})
+	return interruptions
+}
+
+// WriteInterruptionSummary writes a table of the spot interruptions along with the average and maximum time taken to
+// drain the interrupted nodes
+func WriteInterruptionSummary(w io.Writer, interruptions []Interruption) {
+	if len(interruptions) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%d spot interruptions\n", len(interruptions))
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NODE\tINSTANCE TYPE\tNOTICE\tNOTICE→GONE\tPODS EVICTED")
+	var total, longest time.Duration
+	completed, exceeded := 0, 0
+	for _, i := range interruptions {
+		drain := "still present"
+		if d := i.Duration(); !i.Gone.IsZero() {
+			drain = d.Round(time.Second).String()
+			total += d
+			completed++
+			if d > longest {
+				longest = d
+			}
+			if d > SpotInterruptionWarning {
+				exceeded++
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", i.Name, i.InstanceType, i.Notice.Format(time.TimeOnly), drain, i.PodsEvicted)
+	}
+	tw.Flush()
+	if completed > 0 {
+		fmt.Fprintf(w, "average notice→gone %s, max %s, %d of %d exceeded %s\n",
+			(total / time.Duration(completed)).Round(time.Second), longest.Round(time.Second), exceeded, completed, SpotInterruptionWarning)
+	}
+}
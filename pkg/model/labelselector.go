@@ -0,0 +1,97 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"io"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// SetSelector restricts the displayed nodes to those whose labels match the selector, in addition to the filter. Unlike
+// --node-selector it's applied to the nodes already being watched, so it can be changed while running with 'L'.
+func (u *UIModel) SetSelector(selector string) error {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return fmt.Errorf("parsing selector, %w", err)
+	}
+	u.selectorText = selector
+	u.applySelector(parsed)
+	return nil
+}
+
+func (u *UIModel) applySelector(selector labels.Selector) {
+	u.selector = selector
+	u.paginator.Page = 0
+	u.cursor = 0
+	u.dirty = true
+}
+
+// editSelector opens the selector editor with the selector that's currently applied
+func (u *UIModel) editSelector() {
+	u.editingSelector = true
+	u.selectorBefore = u.selector
+	u.selectorText = u.selector.String()
+	u.selectorErr = nil
+}
+
+// setSelectorText applies the selector as it's typed, while it's incomplete or invalid the last valid selector stays
+// applied and the error is displayed instead
+func (u *UIModel) setSelectorText(text string) {
+	u.selectorText = text
+	selector, err := labels.Parse(text)
+	u.selectorErr = err
+	if err == nil {
+		u.applySelector(selector)
+	}
+}
+
+// updateSelector handles key presses while the selector editor is open
+func (u *UIModel) updateSelector(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyEnter:
+		u.editingSelector = false
+	case tea.KeyEsc:
+		u.editingSelector = false
+		u.applySelector(u.selectorBefore)
+	case tea.KeyBackspace:
+		if r := []rune(u.selectorText); len(r) > 0 {
+			u.setSelectorText(string(r[:len(r)-1]))
+		}
+	case tea.KeySpace:
+		u.setSelectorText(u.selectorText + " ")
+	case tea.KeyRunes:
+		u.setSelectorText(u.selectorText + string(msg.Runes))
+	}
+	return nil
+}
+
+// writeSelector writes the selector line of the header, which is the selector being typed and why it's invalid while
+// it's edited
+func (u *UIModel) writeSelector(w io.Writer, matched, total int) {
+	if u.editingSelector {
+		fmt.Fprintf(w, "selector: %s█", u.selectorText)
+		if u.selectorErr != nil {
+			fmt.Fprintf(w, " %s", u.style.red(u.selectorErr.Error()))
+		}
+	} else {
+		fmt.Fprintf(w, "selector: %s", u.selector.String())
+	}
+	fmt.Fprintf(w, " (%d of %d nodes)\n", matched, total)
+}
@@ -0,0 +1,124 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func selectorTestModel(t *testing.T) *model.UIModel {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	for i, zone := range []string{"us-west-2a", "us-west-2b", "us-west-2c"} {
+		n := testNode(fmt.Sprintf("node-%d", i))
+		n.Spec.ProviderID = fmt.Sprintf("node-%d-id", i)
+		n.Labels = map[string]string{
+			v1.LabelHostname:     fmt.Sprintf("node-%d", i),
+			v1.LabelTopologyZone: zone,
+		}
+		if i == 0 {
+			n.Labels["example.com/gpu"] = "true"
+		}
+		node := model.NewNode(n)
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+	return ui
+}
+
+func TestSetSelector(t *testing.T) {
+	ui := selectorTestModel(t)
+	for selector, exp := range map[string]int{
+		"topology.kubernetes.io/zone in (us-west-2a,us-west-2b)": 2,
+		"topology.kubernetes.io/zone notin (us-west-2a)":         2,
+		"example.com/gpu":  1,
+		"!example.com/gpu": 2,
+		"":                 3,
+	} {
+		if err := ui.SetSelector(selector); err != nil {
+			t.Fatalf("setting selector %q, %s", selector, err)
+		}
+		_, nodes, err := ui.NodeSelector()
+		if err != nil {
+			t.Fatalf("building selector, %s", err)
+		}
+		if nodes != exp {
+			t.Errorf("expected %q to match %d nodes, got %d", selector, exp, nodes)
+		}
+	}
+	if err := ui.SetSelector("zone in (a"); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+
+	// the selector is combined with a filter that's a label selector
+	if err := ui.SetSelector("!example.com/gpu"); err != nil {
+		t.Fatalf("setting selector, %s", err)
+	}
+	ui.SetFilter("topology.kubernetes.io/zone=us-west-2b")
+	selector, nodes, err := ui.NodeSelector()
+	if err != nil {
+		t.Fatalf("building selector, %s", err)
+	}
+	if exp := "!example.com/gpu,topology.kubernetes.io/zone=us-west-2b"; selector != exp || nodes != 1 {
+		t.Errorf("expected %s matching 1 node, got %s matching %d", exp, selector, nodes)
+	}
+}
+
+func TestSelectorEditor(t *testing.T) {
+	ui := selectorTestModel(t)
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	// the selector is applied as it's typed, while it's incomplete the last valid selector stays applied
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("topology.kubernetes.io/zone")})
+	ui.Update(tea.KeyMsg{Type: tea.KeySpace})
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("in (us-west-2c")})
+	view := ui.View()
+	if !strings.Contains(view, "selector: topology.kubernetes.io/zone in (us-west-2c█") || !strings.Contains(view, "(3 of 3 nodes)") {
+		t.Errorf("expected the incomplete selector to be displayed with every node, got\n%s", view)
+	}
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(")")})
+	ui.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	view = ui.View()
+	if !strings.Contains(view, "selector: topology.kubernetes.io/zone in (us-west-2c) (1 of 3 nodes)") ||
+		strings.Contains(view, "node-0") || !strings.Contains(view, "node-2") {
+		t.Errorf("expected the selector to be displayed in the header and only node-2 to be listed, got\n%s", view)
+	}
+
+	// cancelling an edit restores the selector from before it
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	ui.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b)")})
+	ui.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, nodes, _ := ui.NodeSelector(); nodes != 1 || !strings.Contains(ui.View(), "us-west-2c)") {
+		t.Errorf("expected the selector to be restored, got\n%s", ui.View())
+	}
+
+	// escape clears the selector
+	ui.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view := ui.View(); strings.Contains(view, "selector:") {
+		t.Errorf("expected the selector to be cleared, got\n%s", view)
+	}
+}
@@ -0,0 +1,425 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// DefaultColumnPriority is the order that the columns are kept in when the terminal is too narrow to display them all,
+// from the most to the least important
+var DefaultColumnPriority = []string{"name", "usage", "resource", "instance-type", "price", "capacity-type", "status",
+	"readiness", "pods", "lifecycle"}
+
+// DefaultLayout is the columns of the node table that are displayed when no columns are chosen
+var DefaultLayout = []string{"name", "resource", "usage", "pods", "instance-type", "price", "capacity-type", "status",
+	"readiness", "lifecycle"}
+
+// labelColumnPrefix is the prefix of a column that displays a node label, e.g. label:kubernetes.io/arch
+const labelColumnPrefix = "label:"
+
+// nodeRow is a row of the node table, nodes have a row for each resource that's displayed
+type nodeRow struct {
+	node          *Node
+	first         bool
+	resource      v1.ResourceName
+	used          v1.ResourceList
+	allocatable   v1.ResourceList
+	daemonSetUsed v1.ResourceList
+	reserved      []v1.ResourceName
+	limits        v1.ResourceList
+}
+
+// nodeColumn describes a column of the node table
+type nodeColumn struct {
+	name  string
+	title string
+	// key is the sort key that the column's heading selects
+	key string
+	// perResource columns are displayed on each of a node's rows, the others are only displayed on the first
+	perResource bool
+	// joinAfter is the column that this column's value is displayed in the same cell as, separated by a slash, when
+	// it directly follows it
+	joinAfter string
+	value     func(u *UIModel, r *nodeRow) string
+}
+
+// nodeColumns are the columns that can be chosen for the node table by name
+var nodeColumns = []nodeColumn{
+	{name: "name", title: "NAME", key: "name", value: (*UIModel).nameValue},
+	{name: "resource", perResource: true, value: (*UIModel).resourceValue},
+	{name: "usage", title: "USAGE", perResource: true, value: (*UIModel).usageValue},
+	// the ratio of the limits of the node's pods to its resources, see Node.Overcommit
+	{name: "overcommit", title: "OVERCOMMIT", perResource: true, value: (*UIModel).overcommitValue},
+	{name: "pods", title: "PODS", key: "eks-node-viewer/node-pods", value: func(_ *UIModel, r *nodeRow) string {
+		return fmt.Sprintf("(%d pods)", r.node.NumPods())
+	}},
+	{name: "instance-type", title: "TYPE", key: v1.LabelInstanceTypeStable, value: func(_ *UIModel, r *nodeRow) string {
+		return string(r.node.InstanceType())
+	}},
+	{name: "price", title: "PRICE", key: "eks-node-viewer/node-price", joinAfter: "instance-type", value: func(u *UIModel, r *nodeRow) string {
+		if r.node.HasPrice() {
+			return u.nodePrice(r.node.Price())
+		}
+		if r.node.HostBilled() {
+			return "host-billed"
+		}
+		return ""
+	}},
+	{name: "capacity-type", title: "CAPACITY", key: "eks-node-viewer/node-capacity-type", value: func(u *UIModel, r *nodeRow) string {
+		if r.node.CapacityTypeMismatch() {
+			return u.style.red(capacityType(r.node))
+		}
+		return capacityType(r.node)
+	}},
+	{name: "status", title: "STATUS", value: func(u *UIModel, r *nodeRow) string {
+		return u.nodeStatus(r.node)
+	}},
+	// node readiness or time we've been waiting for it to be ready
+	{name: "readiness", title: "READY", value: func(u *UIModel, r *nodeRow) string {
+		if r.node.Ready() {
+			return u.statusWord("Ready", nil)
+		}
+		word := "NotReady"
+		if r.node.Provisioning() {
+			word = "Provisioning"
+		}
+		return fmt.Sprintf("%s/%s", u.statusWord(word, nil), duration.HumanDuration(since(r.node.NotReadyTime())))
+	}},
+	// pressure conditions beyond readiness, these often explain why pods are pending or being evicted
+	{name: "health", title: "HEALTH", key: "eks-node-viewer/node-health", value: func(u *UIModel, r *nodeRow) string {
+		return u.health(r.node)
+	}},
+	// the lifecycle of the NodeClaim, to explain why a node that isn't ready yet is stuck
+	{name: "lifecycle", title: "LIFECYCLE", key: "eks-node-viewer/node-lifecycle", value: func(_ *UIModel, r *nodeRow) string {
+		return r.node.NodeClaimLifecycle()
+	}},
+	// whether Karpenter is disrupting the node, or would like to, see Node.Disruption
+	{name: "disruption", title: "DISRUPTION", key: "eks-node-viewer/node-disruption", value: func(u *UIModel, r *nodeRow) string {
+		disruption := r.node.Disruption()
+		switch {
+		case strings.HasPrefix(disruption, DisruptionDisrupting):
+			return u.style.red(disruption)
+		case strings.HasSuffix(disruption, DisruptionBlocked):
+			return u.style.yellow(disruption)
+		}
+		return disruption
+	}},
+	labelColumn(v1.LabelTopologyZone),
+	labelColumn(DefaultGroupBy),
+	// the managed node group of nodes that weren't launched by Karpenter
+	{name: "nodegroup", title: "NODEGROUP", key: "eks-node-viewer/node-group", value: func(_ *UIModel, r *nodeRow) string {
+		return managedNodeGroupLabel(r.node)
+	}},
+	// the system that manages the node, to tell Karpenter, node group and Fargate nodes apart in mixed clusters
+	{name: "manager", title: "MANAGER", key: "eks-node-viewer/node-manager", value: func(_ *UIModel, r *nodeRow) string {
+		return r.node.Manager()
+	}},
+	{name: "cpu-credits", title: "CREDITS", key: "eks-node-viewer/node-cpu-credits", value: func(u *UIModel, r *nodeRow) string {
+		if r.node.LowCPUCredits() {
+			return u.style.red(cpuCredits(r.node))
+		}
+		return cpuCredits(r.node)
+	}},
+	{name: "taints", title: "TAINTS", key: "eks-node-viewer/node-taints", value: func(_ *UIModel, r *nodeRow) string {
+		return taintSummary(r.node)
+	}},
+	{name: "hardware", title: "HARDWARE", key: "eks-node-viewer/node-vcpus", value: func(_ *UIModel, r *nodeRow) string {
+		return hardware(r.node)
+	}},
+	{name: "network", title: "NETWORK", key: "eks-node-viewer/node-network", value: func(_ *UIModel, r *nodeRow) string {
+		return instanceTypeLabel(r.node, "network")
+	}},
+	// the price of each vCPU and GiB of memory, to compare the efficiency of nodes across instance families
+	{name: "price-per-vcpu", title: "$/VCPU", key: "eks-node-viewer/node-price-per-vcpu", value: func(_ *UIModel, r *nodeRow) string {
+		return instanceTypeLabel(r.node, "price-per-vcpu")
+	}},
+	{name: "price-per-gb", title: "$/GB", key: "eks-node-viewer/node-price-per-gb", value: func(_ *UIModel, r *nodeRow) string {
+		return instanceTypeLabel(r.node, "price-per-gb")
+	}},
+	// the percentage that each spot node saves compared to the on-demand price of its instance type
+	{name: "savings", title: "SAVINGS", key: "eks-node-viewer/node-spot-savings", value: func(_ *UIModel, r *nodeRow) string {
+		return spotSavingsLabel(r.node)
+	}},
+	// a triage aid ranking the nodes that most need attention, sort by it descending to see them first
+	{name: "score", title: "SCORE", key: "eks-node-viewer/node-score", value: func(_ *UIModel, r *nodeRow) string {
+		return r.node.ComputeLabel("eks-node-viewer/node-score")
+	}},
+	{name: "age", title: "AGE", key: "creation", value: func(_ *UIModel, r *nodeRow) string {
+		return duration.HumanDuration(since(r.node.Created()))
+	}},
+}
+
+// labelColumn returns a column that displays a node label or computed label, only the name of prefixed labels is used
+// as the heading, e.g. ZONE for topology.kubernetes.io/zone
+func labelColumn(label string) nodeColumn {
+	name := strings.TrimPrefix(label, AnnotationPrefix)
+	name = name[strings.LastIndex(name, "/")+1:]
+	return nodeColumn{name: name, title: strings.ToUpper(name), key: label, value: func(_ *UIModel, r *nodeRow) string {
+		labelValue, ok := r.node.Label(label)
+		if !ok {
+			// support computed label values
+			labelValue = r.node.ComputeLabel(label)
+		}
+		return labelValue
+	}}
+}
+
+// customColumn returns a column that displays the value of a custom JSONPath column
+func customColumn(c Column) nodeColumn {
+	return nodeColumn{name: c.Name, title: strings.ToUpper(c.Name), value: func(_ *UIModel, r *nodeRow) string {
+		return c.Value(r.node)
+	}}
+}
+
+// SetLayout sets the columns of the node table and their order, columns are chosen by name or as label:<label> to
+// display a node label. The default layout is used if no columns are given.
+func (u *UIModel) SetLayout(names []string) error {
+	if len(names) == 0 {
+		names = DefaultLayout
+	}
+	var layout []nodeColumn
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if label, ok := strings.CutPrefix(name, labelColumnPrefix); ok && label != "" {
+			layout = append(layout, labelColumn(label))
+			continue
+		}
+		c, ok := lookupNodeColumn(name)
+		if !ok {
+			return fmt.Errorf("unknown column %q, expected one of %s or %s<label>", name, strings.Join(NodeColumnNames(), ", "), labelColumnPrefix)
+		}
+		layout = append(layout, c)
+	}
+	u.layout = layout
+	return nil
+}
+
+// NodeColumnNames returns the names of the columns that can be chosen for the node table
+func NodeColumnNames() []string {
+	var names []string
+	for _, c := range nodeColumns {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+func lookupNodeColumn(name string) (nodeColumn, bool) {
+	for _, c := range nodeColumns {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return nodeColumn{}, false
+}
+
+// tableLayout returns the columns of the node table, the chosen columns are followed by the extra labels and the
+// custom columns. Columns hidden to fit the terminal are left out.
+func (u *UIModel) tableLayout() []nodeColumn {
+	var layout []nodeColumn
+	for _, c := range u.fullLayout() {
+		if !slices.Contains(u.hiddenColumns, c.name) {
+			layout = append(layout, c)
+		}
+	}
+	return layout
+}
+
+// fullLayout returns the columns of the node table including any that are hidden to fit the terminal
+func (u *UIModel) fullLayout() []nodeColumn {
+	var layout []nodeColumn
+	for _, c := range u.layout {
+		if u.DisablePricing && (c.name == "price" || c.name == "price-per-vcpu" || c.name == "price-per-gb" || c.name == "savings") {
+			continue
+		}
+		layout = append(layout, c)
+	}
+	for _, label := range u.extraLabels {
+		layout = append(layout, labelColumn(label))
+	}
+	for _, c := range u.columns {
+		layout = append(layout, customColumn(c))
+	}
+	return layout
+}
+
+// fitColumns hides the least important columns until the node table fits the width of the terminal, rather than
+// letting its rows wrap. The name column is never hidden, so if the table is still too wide, e.g. due to long node
+// names, the widest cells are truncated until it fits.
+func (u *UIModel) fitColumns(nodes []*Node, resources []v1.ResourceName) {
+	u.hiddenColumns = nil
+	u.maxCellWidth = 0
+	if u.width <= 0 {
+		return
+	}
+	hideOrder := u.hideOrder()
+	for {
+		widths := u.cellWidths(nodes, resources)
+		if tableWidth(widths, 0) <= u.width {
+			return
+		}
+		if len(u.hiddenColumns) == len(hideOrder) {
+			u.maxCellWidth = fitCellWidth(widths, u.width)
+			return
+		}
+		u.hiddenColumns = append(u.hiddenColumns, hideOrder[len(u.hiddenColumns)])
+	}
+}
+
+// hideOrder returns the order that columns are hidden in to fit the terminal, columns without a priority are hidden
+// first starting from the right, followed by the columns with a priority from the least important
+func (u *UIModel) hideOrder() []string {
+	layout := u.fullLayout()
+	var order []string
+	for i := len(layout) - 1; i >= 0; i-- {
+		if name := layout[i].name; name != "name" && !slices.Contains(u.ColumnPriority, name) {
+			order = append(order, name)
+		}
+	}
+	for i := len(u.ColumnPriority) - 1; i >= 0; i-- {
+		name := u.ColumnPriority[i]
+		if name != "name" && slices.ContainsFunc(layout, func(c nodeColumn) bool { return c.name == name }) {
+			order = append(order, name)
+		}
+	}
+	return order
+}
+
+// cellWidths returns the width of each cell of the node table when displaying the nodes
+func (u *UIModel) cellWidths(nodes []*Node, resources []v1.ResourceName) []int {
+	var b strings.Builder
+	u.writeTableHeader(&b)
+	for _, n := range nodes {
+		u.writeNodeInfo(n, &b, resources)
+	}
+	var widths []int
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		for i, cell := range strings.Split(line, "\t") {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+	return widths
+}
+
+// tableWidth returns the width of a table with the cell widths when its cells are truncated to maxCellWidth, or not
+// truncated if it's zero. Cells are separated by a space.
+func tableWidth(widths []int, maxCellWidth int) int {
+	width := 0
+	for _, w := range widths {
+		if maxCellWidth > 0 {
+			w = min(w, maxCellWidth)
+		}
+		width += w + 1
+	}
+	return width - 1
+}
+
+// fitCellWidth returns the widest that cells can be for a table with the cell widths to fit within width, cells are
+// never truncated to less than minCellWidth
+func fitCellWidth(widths []int, width int) int {
+	for cellWidth := slices.Max(widths); cellWidth > minCellWidth; cellWidth-- {
+		if tableWidth(widths, cellWidth) <= width {
+			return cellWidth
+		}
+	}
+	return minCellWidth
+}
+
+// tableCells groups the columns of the node table into the cells they're displayed in
+func (u *UIModel) tableCells() [][]nodeColumn {
+	var cells [][]nodeColumn
+	for _, c := range u.tableLayout() {
+		if n := len(cells); n > 0 && c.joinAfter != "" && cells[n-1][len(cells[n-1])-1].name == c.joinAfter {
+			cells[n-1] = append(cells[n-1], c)
+			continue
+		}
+		cells = append(cells, []nodeColumn{c})
+	}
+	return cells
+}
+
+// rowsPerNode returns the number of rows of the node table that each node is displayed on
+func (u *UIModel) rowsPerNode(resources []v1.ResourceName) int {
+	for _, c := range u.tableLayout() {
+		if c.perResource && len(resources) > 0 {
+			return len(resources)
+		}
+	}
+	return 1
+}
+
+func (u *UIModel) nameValue(r *nodeRow) string {
+	n := r.node
+	name := n.Name()
+	if n.Tainted() {
+		name = "!" + name
+	}
+	if _, ok := u.Notes.Get(n.Name()); ok {
+		name = "#" + name
+	}
+	if u.isMarked(n) {
+		name = "*" + name
+	}
+	if u.expired(n) {
+		name = u.style.red(name)
+	}
+	if n == u.cursorNode {
+		name = cursorStyle(name)
+	}
+	return name
+}
+
+func (u *UIModel) resourceValue(r *nodeRow) string {
+	for _, rn := range r.reserved {
+		if rn == r.resource {
+			return u.style.red(u.resourceName(r.resource))
+		}
+	}
+	// pods limited by the IP addresses of the node's ENIs rather than the kubelet's max pods are marked
+	if _, ok := r.node.ENIPodLimit(); ok && r.resource == v1.ResourcePods {
+		return u.style.yellow("pods (eni)")
+	}
+	return u.resourceName(r.resource)
+}
+
+func (u *UIModel) usageValue(r *nodeRow) string {
+	usedRes := r.used[r.resource]
+	allocatableRes := r.allocatable[r.resource]
+	pct := usedRes.AsApproximateFloat64() / allocatableRes.AsApproximateFloat64()
+	if allocatableRes.AsApproximateFloat64() == 0 {
+		pct = 0
+	}
+	bar := u.progress.ViewAs(pct)
+	if u.showDaemonSets {
+		dsPct := 0.0
+		if dsRes := r.daemonSetUsed[r.resource]; allocatableRes.AsApproximateFloat64() != 0 {
+			dsPct = 100 * dsRes.AsApproximateFloat64() / allocatableRes.AsApproximateFloat64()
+		}
+		bar += u.style.yellow(fmt.Sprintf(" ds %3.0f%%", dsPct))
+	}
+	if u.showLimits {
+		bar = u.limitsView(bar, r)
+	}
+	return bar
+}
@@ -0,0 +1,121 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// limitMarker is drawn on a node's usage bar at the share of its resources that its pods' limits add up to
+const limitMarker = '┃'
+
+var overcommitLabelRe = regexp.MustCompile("eks-node-viewer/node-(.*?)-overcommit")
+
+// Limits returns the sum of the resource limits of the pods on the node
+func (n *Node) Limits() v1.ResourceList {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	limits := v1.ResourceList{}
+	for rn, q := range n.limits {
+		limits[rn] = q.DeepCopy()
+	}
+	return limits
+}
+
+// Overcommit returns the ratio of the limits of the pods on the node to its allocatable resources, a ratio above one
+// means the pods may use more than the node has if they all reach their limits. It returns false if the node has no
+// allocatable resources of the given type or no limits are set.
+func (n *Node) Overcommit(rn v1.ResourceName) (float64, bool) {
+	return overcommit(n.Limits(), limitPods(n, n.Allocatable()), rn)
+}
+
+func overcommit(limits, allocatable v1.ResourceList, rn v1.ResourceName) (float64, bool) {
+	limit, hasLimit := limits[rn]
+	alloc := allocatable[rn]
+	if !hasLimit || limit.IsZero() || alloc.AsApproximateFloat64() == 0 {
+		return 0, false
+	}
+	return limit.AsApproximateFloat64() / alloc.AsApproximateFloat64(), true
+}
+
+func overcommitLabel(n *Node, rn v1.ResourceName) string {
+	ratio, ok := n.Overcommit(rn)
+	if !ok {
+		return "-"
+	}
+	return strconv.FormatFloat(ratio, 'f', 2, 64)
+}
+
+// overcommitValue is the overcommit column of a node's row, overcommitted resources are highlighted in red
+func (u *UIModel) overcommitValue(r *nodeRow) string {
+	ratio, ok := overcommit(r.limits, r.allocatable, r.resource)
+	if !ok {
+		return "-"
+	}
+	value := fmt.Sprintf("%.2fx", ratio)
+	if ratio > 1 {
+		return u.style.red(value)
+	}
+	return value
+}
+
+// limitsView marks the share of the row's resource that its pods' limits add up to on the usage bar, and follows it
+// with the percentage
+func (u *UIModel) limitsView(bar string, r *nodeRow) string {
+	allocatableRes := r.allocatable[r.resource]
+	limitRes := r.limits[r.resource]
+	pct := 0.0
+	if allocatableRes.AsApproximateFloat64() != 0 {
+		pct = limitRes.AsApproximateFloat64() / allocatableRes.AsApproximateFloat64()
+	}
+	if pct > 0 {
+		bar = markBar(bar, u.progress.Full, u.progress.Empty, pct)
+	}
+	limits := fmt.Sprintf(" lim %3.0f%%", 100*pct)
+	if pct > 1 {
+		return bar + u.style.red(limits)
+	}
+	return bar + limits
+}
+
+// markBar replaces the cell of a rendered progress bar at the given share of its width with the limit marker, keeping
+// the cell's color. Limits beyond the width of the bar are marked on its last cell.
+func markBar(bar string, full, empty rune, pct float64) string {
+	cells := strings.Count(bar, string(full)) + strings.Count(bar, string(empty))
+	if cells == 0 {
+		return bar
+	}
+	mark := min(int(pct*float64(cells)), cells-1)
+	var b strings.Builder
+	cell := 0
+	for i := 0; i < len(bar); {
+		r, size := utf8.DecodeRuneInString(bar[i:])
+		if (r == full || r == empty) && cell <= mark {
+			if cell == mark {
+				r = limitMarker
+			}
+			cell++
+		}
+		b.WriteRune(r)
+		i += size
+	}
+	return b.String()
+}
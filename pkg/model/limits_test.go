@@ -0,0 +1,82 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestLimits(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("creating style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu", "memory"})
+	if err := ui.SetLayout([]string{"name", "resource", "usage", "overcommit"}); err != nil {
+		t.Fatalf("setting layout, %s", err)
+	}
+	n := testNode("node-a")
+	n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("8Gi")}
+	node := model.NewNode(n)
+	node.Show()
+	ui.Cluster().AddNode(node)
+
+	// only the cpu is limited, and to more than the node has
+	p := testPod("default", "web")
+	p.Spec.NodeName = "node-a"
+	p.Spec.Containers = []v1.Container{{Resources: v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+		Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("6")},
+	}}}
+	pod := model.NewPod(p)
+	if limit := pod.Limits()[v1.ResourceCPU]; limit.String() != "6" {
+		t.Errorf("expected a cpu limit of 6, got %s", limit.String())
+	}
+	ui.Cluster().AddPod(pod)
+
+	if ratio, ok := node.Overcommit(v1.ResourceCPU); !ok || ratio != 1.5 {
+		t.Errorf("expected a cpu overcommit of 1.5, got %v", ratio)
+	}
+	if _, ok := node.Overcommit(v1.ResourceMemory); ok {
+		t.Errorf("expected no memory overcommit without memory limits")
+	}
+	if exp, got := "1.50", node.ComputeLabel("eks-node-viewer/node-cpu-overcommit"); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	view := ui.View()
+	if !strings.Contains(view, "1.50x") || strings.Contains(view, " lim ") {
+		t.Errorf("expected the overcommit column without limit markers, got\n%s", view)
+	}
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	view = ui.View()
+	if !strings.Contains(view, "lim 150%") || !strings.Contains(view, "lim   0%") || !strings.Contains(view, "┃") {
+		t.Errorf("expected limit markers, got\n%s", view)
+	}
+
+	ui.Cluster().DeletePod("default", "web")
+	if _, ok := node.Overcommit(v1.ResourceCPU); ok {
+		t.Errorf("expected the limits to be removed with the pod")
+	}
+}
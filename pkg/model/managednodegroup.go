@@ -0,0 +1,108 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"io"
+	"sort"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/awslabs/eks-node-viewer/pkg/text"
+)
+
+const (
+	// LabelManagedNodeGroup is the label EKS adds to the nodes of managed node groups
+	LabelManagedNodeGroup = "eks.amazonaws.com/nodegroup"
+	// labelEksctlNodeGroup is the label eksctl adds to the nodes of the node groups it creates, including self-managed
+	// node groups
+	labelEksctlNodeGroup = "alpha.eksctl.io/nodegroup-name"
+)
+
+// ManagedNodeGroup is the capacity of an EKS managed node group as reported by the EKS and Auto Scaling APIs
+type ManagedNodeGroup struct {
+	Name string
+	// Status is the status of the node group in EKS, e.g. ACTIVE or DEGRADED
+	Status  string
+	Desired int
+	Min     int
+	Max     int
+	// Instances is the number of instances in the node group's Auto Scaling groups that are in service
+	Instances int
+}
+
+// ManagedNodeGroup returns the name of the managed or eksctl node group that launched the node, or an empty string if it
+// wasn't launched by one
+func (n *Node) ManagedNodeGroup() string {
+	if name, _ := n.Label(LabelManagedNodeGroup); name != "" {
+		return name
+	}
+	name, _ := n.Label(labelEksctlNodeGroup)
+	return name
+}
+
+// SetManagedNodeGroups sets the managed node groups of the cluster, replacing those that were previously set
+func (c *Cluster) SetManagedNodeGroups(groups []ManagedNodeGroup) {
+	sorted := append([]ManagedNodeGroup{}, groups...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].Name < sorted[b].Name })
+	c.mu.Lock()
+	c.managedNodeGroups = sorted
+	c.mu.Unlock()
+	c.Invalidate()
+}
+
+// ManagedNodeGroups returns the managed node groups of the cluster sorted by name, they're only known when the EKS API
+// is polled
+func (c *Cluster) ManagedNodeGroups() []ManagedNodeGroup {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]ManagedNodeGroup{}, c.managedNodeGroups...)
+}
+
+// writeManagedNodeGroups writes the desired capacity of each managed node group alongside the number of instances it
+// has launched and the number of those that have joined the cluster as nodes. Groups that haven't reached their desired
+// capacity are highlighted, as are groups that EKS reports aren't healthy.
+func (u *UIModel) writeManagedNodeGroups(w io.Writer, clusterStats []Stats) {
+	enPrinter := message.NewPrinter(language.English)
+	ctw := text.NewColorTabWriter(w, 0, 8, 1)
+	for i, c := range u.clusters {
+		counts := clusterStats[i].ManagedNodeGroupNodes
+		for _, g := range c.ManagedNodeGroups() {
+			name := g.Name
+			if len(u.clusters) > 1 {
+				name = c.Name() + "/" + name
+			}
+			nodes := enPrinter.Sprintf("%d/%d nodes", counts[g.Name], g.Desired)
+			if counts[g.Name] != g.Desired || g.Instances != g.Desired {
+				nodes = u.style.yellow(nodes)
+			}
+			status := g.Status
+			if status != "ACTIVE" {
+				status = u.style.red(status)
+			}
+			enPrinter.Fprintf(ctw, "%s\t%s\t%d instances\tmin %d max %d\t%s\n", name, nodes, g.Instances, g.Min, g.Max, status)
+		}
+	}
+	ctw.Flush()
+}
+
+// managedNodeGroupLabel returns the node group of a node for display, or "-" if it isn't in one
+func managedNodeGroupLabel(n *Node) string {
+	if name := n.ManagedNodeGroup(); name != "" {
+		return name
+	}
+	return "-"
+}
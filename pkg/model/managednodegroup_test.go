@@ -0,0 +1,93 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestManagedNodeGroups(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("creating style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	if err := ui.SetLayout([]string{"name", "nodegroup"}); err != nil {
+		t.Fatalf("setting layout, %s", err)
+	}
+	labels := []map[string]string{
+		{model.LabelManagedNodeGroup: "system"},
+		{model.LabelManagedNodeGroup: "system"},
+		{"alpha.eksctl.io/nodegroup-name": "workers"},
+		{"karpenter.sh/nodepool": "default"},
+	}
+	for i, l := range labels {
+		n := testNode(fmt.Sprintf("node-%d", i))
+		n.Spec.ProviderID = fmt.Sprintf("node-%d-id", i)
+		n.Labels = l
+		node := model.NewNode(n)
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+
+	node, _ := ui.Cluster().GetNodeByName("node-2")
+	if exp, got := "workers", node.ComputeLabel("eks-node-viewer/node-group"); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+	node, _ = ui.Cluster().GetNodeByName("node-3")
+	if exp, got := "-", node.ComputeLabel("eks-node-viewer/node-group"); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+
+	// without polling the EKS API there's no node group summary
+	var buf bytes.Buffer
+	if err := ui.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	if strings.Contains(buf.String(), "instances") {
+		t.Errorf("expected no node group summary, got\n%s", buf.String())
+	}
+
+	ui.Cluster().SetManagedNodeGroups([]model.ManagedNodeGroup{
+		{Name: "workers", Status: "DEGRADED", Desired: 3, Min: 1, Max: 5, Instances: 2},
+		{Name: "system", Status: "ACTIVE", Desired: 2, Min: 2, Max: 2, Instances: 2},
+	})
+	if groups := ui.Cluster().ManagedNodeGroups(); len(groups) != 2 || groups[0].Name != "system" {
+		t.Errorf("expected the node groups sorted by name, got %v", groups)
+	}
+	buf.Reset()
+	if err := ui.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	for _, exp := range []string{
+		"system  2/2 nodes 2 instances min 2 max 2 ACTIVE",
+		"workers 1/3 nodes 2 instances min 1 max 5 DEGRADED",
+	} {
+		if !strings.Contains(buf.String(), exp) {
+			t.Errorf("expected %q in\n%s", exp, buf.String())
+		}
+	}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "node-0") && !strings.Contains(line, "system") {
+			t.Errorf("expected the node group column, got %q", line)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+// The systems that can manage a node, as returned by Node.Manager
+const (
+	ManagerKarpenter   = "karpenter"
+	ManagerAutoMode    = "eks-auto"
+	ManagerFargate     = "fargate"
+	ManagerNodeGroup   = "managed-nodegroup"
+	ManagerSelfManaged = "self-managed"
+	ManagerNone        = "none"
+)
+
+// labelEKSComputeType distinguishes auto mode and Fargate nodes from EC2 nodes that EKS doesn't manage
+const labelEKSComputeType = "eks.amazonaws.com/compute-type"
+
+// Manager returns the system that launched and manages the node: EKS auto mode, Fargate, Karpenter if the node is
+// owned by a NodeClaim or labeled with a node pool, an EKS managed node group, a self-managed node group created by
+// eksctl, or none if it can't be told
+func (n *Node) Manager() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	// auto mode nodes also carry Karpenter's labels, so they're checked first
+	switch n.node.Labels[labelEKSComputeType] {
+	case "auto":
+		return ManagerAutoMode
+	case "fargate":
+		return ManagerFargate
+	}
+	if _, ok := n.node.Labels[DefaultGroupBy]; ok || len(n.nodeClaimConditions) > 0 {
+		return ManagerKarpenter
+	}
+	for _, owner := range n.node.OwnerReferences {
+		if owner.Kind == "NodeClaim" {
+			return ManagerKarpenter
+		}
+	}
+	if _, ok := n.node.Labels[LabelManagedNodeGroup]; ok {
+		return ManagerNodeGroup
+	}
+	if _, ok := n.node.Labels[labelEksctlNodeGroup]; ok {
+		return ManagerSelfManaged
+	}
+	return ManagerNone
+}
@@ -0,0 +1,51 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestNodeManager(t *testing.T) {
+	for exp, labels := range map[string]map[string]string{
+		model.ManagerAutoMode:    {"eks.amazonaws.com/compute-type": "auto", "karpenter.sh/nodepool": "general-purpose"},
+		model.ManagerFargate:     {"eks.amazonaws.com/compute-type": "fargate"},
+		model.ManagerKarpenter:   {"karpenter.sh/nodepool": "default"},
+		model.ManagerNodeGroup:   {model.LabelManagedNodeGroup: "system", "alpha.eksctl.io/nodegroup-name": "system"},
+		model.ManagerSelfManaged: {"alpha.eksctl.io/nodegroup-name": "workers"},
+		model.ManagerNone:        {},
+	} {
+		n := testNode("node")
+		n.Labels = labels
+		node := model.NewNode(n)
+		if got := node.ComputeLabel("eks-node-viewer/node-manager"); got != exp {
+			t.Errorf("expected %s for labels %v, got %s", exp, labels, got)
+		}
+		if !model.NewNodeFilter(exp)(node) {
+			t.Errorf("expected filter %q to match the node", exp)
+		}
+	}
+
+	// a node owned by a NodeClaim is Karpenter's before its labels are synced
+	n := testNode("node")
+	n.OwnerReferences = []metav1.OwnerReference{{APIVersion: "karpenter.sh/v1", Kind: "NodeClaim", Name: "default-abcde"}}
+	if got := model.NewNode(n).Manager(); got != model.ManagerKarpenter {
+		t.Errorf("expected %s, got %s", model.ManagerKarpenter, got)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// maxPodsTolerance is how far a node's allocatable pods can be from the ENI-derived maximum pods of its instance type,
+// as a fraction of the maximum, before its max pods is considered misconfigured
+const maxPodsTolerance = 0.1
+
+// ENIMaxPods returns the maximum pods that the VPC CNI can assign IP addresses to on the instance type, either from the
+// secondary IP addresses of its ENIs or with prefix delegation. It returns false if the ENI limits aren't known.
+func (i InstanceTypeInfo) ENIMaxPods(prefixDelegation bool) (int64, bool) {
+	if i.MaxENIs == 0 || i.IPv4PerENI == 0 {
+		return 0, false
+	}
+	// the primary address of each ENI isn't available to pods, the extra two are for the host network pods of the VPC
+	// CNI and kube-proxy
+	if !prefixDelegation {
+		return i.MaxENIs*(i.IPv4PerENI-1) + 2, true
+	}
+	// each address is replaced by a /28 prefix of 16 addresses, limited to the max pods that EKS recommends
+	limit := int64(110)
+	if i.VCPUs > 30 {
+		limit = 250
+	}
+	return min(i.MaxENIs*(i.IPv4PerENI-1)*16+2, limit), true
+}
+
+// MaxPodsMismatch returns true if the node's allocatable pods is far from the ENI-derived maximum pods of its instance
+// type both with and without prefix delegation. This usually means the kubelet's max pods wasn't updated when prefix
+// delegation was enabled, leaving pods unschedulable, or it was raised without enabling prefix delegation, leaving pods
+// without an IP address.
+func (n *Node) MaxPodsMismatch() bool {
+	// Fargate and EKS Auto Mode nodes don't run the VPC CNI that users configure
+	info, ok := n.InstanceTypeInfo()
+	if !ok || n.IsFargate() || n.IsAuto() {
+		return false
+	}
+	pods, ok := n.Allocatable()[v1.ResourcePods]
+	if !ok {
+		return false
+	}
+	for _, prefixDelegation := range []bool{false, true} {
+		maxPods, ok := info.ENIMaxPods(prefixDelegation)
+		if !ok {
+			return false
+		}
+		if math.Abs(float64(pods.Value()-maxPods)) <= maxPodsTolerance*float64(maxPods) {
+			return false
+		}
+	}
+	return true
+}
+
+// eniMaxPods returns the ENI-derived maximum pods of the node's instance type without and with prefix delegation for
+// display, e.g. "29/110", or "-" if they aren't known
+func eniMaxPods(n *Node) string {
+	info, ok := n.InstanceTypeInfo()
+	if !ok {
+		return "-"
+	}
+	secondaryIPs, ok := info.ENIMaxPods(false)
+	if !ok {
+		return "-"
+	}
+	prefixes, _ := info.ENIMaxPods(true)
+	return fmt.Sprintf("%d/%d", secondaryIPs, prefixes)
+}
+
+// ENIPodLimit returns the ENI-derived maximum pods of the node's instance type without prefix delegation when the
+// node's allocatable pods has been raised beyond it, see MaxPodsMismatch. Pods scheduled beyond the limit wait for an
+// IP address that the VPC CNI can't assign, so it's the number of pods that the node can actually run.
+func (n *Node) ENIPodLimit() (int64, bool) {
+	if !n.MaxPodsMismatch() {
+		return 0, false
+	}
+	info, _ := n.InstanceTypeInfo()
+	maxPods, _ := info.ENIMaxPods(false)
+	if pods := n.Allocatable()[v1.ResourcePods]; pods.Value() <= maxPods {
+		return 0, false
+	}
+	return maxPods, true
+}
+
+// limitPods returns the node's allocatable resources or capacity with the pods limited to its ENIPodLimit, so that pod
+// density is measured against the pods that can get an IP address
+func limitPods(n *Node, resources v1.ResourceList) v1.ResourceList {
+	limit, ok := n.ENIPodLimit()
+	if _, hasPods := resources[v1.ResourcePods]; !ok || !hasPods {
+		return resources
+	}
+	limited := resources.DeepCopy()
+	limited[v1.ResourcePods] = *resource.NewQuantity(limit, resource.DecimalSI)
+	return limited
+}
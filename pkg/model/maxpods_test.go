@@ -0,0 +1,146 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestENIMaxPods(t *testing.T) {
+	for _, tc := range []struct {
+		info             model.InstanceTypeInfo
+		secondaryIPs     int64
+		prefixDelegation int64
+	}{
+		// m5.large
+		{model.InstanceTypeInfo{VCPUs: 2, MaxENIs: 3, IPv4PerENI: 10}, 29, 110},
+		// m5.24xlarge
+		{model.InstanceTypeInfo{VCPUs: 96, MaxENIs: 15, IPv4PerENI: 50}, 737, 250},
+		// t3.nano
+		{model.InstanceTypeInfo{VCPUs: 2, MaxENIs: 2, IPv4PerENI: 2}, 4, 34},
+	} {
+		if got, ok := tc.info.ENIMaxPods(false); !ok || got != tc.secondaryIPs {
+			t.Errorf("expected %d max pods for %+v, got %d", tc.secondaryIPs, tc.info, got)
+		}
+		if got, ok := tc.info.ENIMaxPods(true); !ok || got != tc.prefixDelegation {
+			t.Errorf("expected %d max pods with prefix delegation for %+v, got %d", tc.prefixDelegation, tc.info, got)
+		}
+	}
+	if _, ok := (model.InstanceTypeInfo{VCPUs: 2}).ENIMaxPods(false); ok {
+		t.Errorf("expected max pods to be unknown without the ENI limits")
+	}
+}
+
+func TestMaxPodsMismatch(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	// an m5.large with the secondary IP, prefix delegation and a misconfigured max pods
+	for i, maxPods := range []string{"29", "110", "58"} {
+		n := testNode(fmt.Sprintf("node-%d", i))
+		n.Spec.ProviderID = fmt.Sprintf("node-%d-id", i)
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("1930m"), v1.ResourcePods: resource.MustParse(maxPods)}
+		node := model.NewNode(n)
+		node.SetInstanceTypeInfo(model.InstanceTypeInfo{VCPUs: 2, MemoryMiB: 8192, MaxENIs: 3, IPv4PerENI: 10})
+		node.Show()
+		ui.Cluster().AddNode(node)
+		if exp, got := i == 2, node.MaxPodsMismatch(); exp != got {
+			t.Errorf("expected a mismatch of %v with %s max pods, got %v", exp, maxPods, got)
+		}
+		if exp, got := "29/110", node.ComputeLabel("eks-node-viewer/node-eni-max-pods"); exp != got {
+			t.Errorf("expected %s, got %s", exp, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ui.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	if !strings.Contains(buf.String(), "1 nodes have a max pods that doesn't match the ENI limit of their instance type") {
+		t.Errorf("expected the mismatched node to be counted, got\n%s", buf.String())
+	}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "node-") && strings.Contains(line, "MaxPods") != strings.HasPrefix(line, "node-2 ") {
+			t.Errorf("expected only node-2 to have a MaxPods status, got %q", line)
+		}
+	}
+}
+
+func TestENIPodLimit(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"pods"})
+	// an m5.large with the secondary IP max pods, max pods raised without prefix delegation and max pods lowered
+	for i, tc := range []struct {
+		maxPods string
+		limit   int64
+		limited bool
+		usage   string
+	}{
+		{"29", 0, false, "34%"},
+		{"58", 29, true, "34%"},
+		{"20", 0, false, "50%"},
+	} {
+		n := testNode(fmt.Sprintf("node-%d", i))
+		n.Spec.ProviderID = fmt.Sprintf("node-%d-id", i)
+		n.Status.Allocatable = v1.ResourceList{v1.ResourcePods: resource.MustParse(tc.maxPods)}
+		n.Status.Capacity = n.Status.Allocatable
+		node := model.NewNode(n)
+		node.SetInstanceTypeInfo(model.InstanceTypeInfo{VCPUs: 2, MemoryMiB: 8192, MaxENIs: 3, IPv4PerENI: 10})
+		node.Show()
+		ui.Cluster().AddNode(node)
+		for j := range 10 {
+			p := testPod("default", fmt.Sprintf("pod-%d-%d", i, j))
+			p.Spec.NodeName = n.Name
+			p.Status.Phase = v1.PodRunning
+			ui.Cluster().AddPod(model.NewPod(p))
+		}
+
+		if limit, ok := node.ENIPodLimit(); ok != tc.limited || limit != tc.limit {
+			t.Errorf("expected a limit of %d (%v) with %s max pods, got %d (%v)", tc.limit, tc.limited, tc.maxPods, limit, ok)
+		}
+		if got := node.ComputeLabel("eks-node-viewer/node-pods-usage"); got != tc.usage {
+			t.Errorf("expected %s of the pods to be used with %s max pods, got %s", tc.usage, tc.maxPods, got)
+		}
+	}
+
+	// the summary measures the pods against the pods that can get an IP address
+	if pods := ui.Cluster().Stats().AllocatableResources[v1.ResourcePods]; pods.Value() != 29+29+20 {
+		t.Errorf("expected 78 allocatable pods, got %s", pods.String())
+	}
+	var buf bytes.Buffer
+	if err := ui.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "node-") && strings.Contains(line, "pods (eni)") != strings.HasPrefix(line, "node-1 ") {
+			t.Errorf("expected only node-1 to be marked as limited by its ENIs, got %q", line)
+		}
+	}
+}
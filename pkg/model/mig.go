@@ -0,0 +1,93 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ResourceGPUMemory is the GPU memory of the MIG slices of NVIDIA GPUs, the memory of each slice that's allocatable on
+// a node or requested by a pod added up. With MIG a GPU is shared by pods of different sizes, so its memory is a
+// better measure of how full it is than the number of whole GPUs. GPUs that aren't sliced don't add to it.
+const ResourceGPUMemory v1.ResourceName = "gpu-memory"
+
+// migResourceRe matches the extended resources of MIG slices advertised with the mixed strategy, e.g.
+// nvidia.com/mig-1g.5gb or nvidia.com/mig-1g.10gb+me, capturing the memory of the slice in GB
+var migResourceRe = regexp.MustCompile(`^nvidia\.com/mig-\d+g\.(\d+)gb(\+me)?$`)
+
+// withGPUMemory returns the resources with the GPU memory of their MIG slices added, the resources are returned as is
+// if they don't have any. It's used for resources that may be shared with the informer's cache, which are copied rather
+// than modified.
+func withGPUMemory(resources v1.ResourceList) v1.ResourceList {
+	memory, ok := gpuMemory(resources)
+	if !ok {
+		return resources
+	}
+	withMemory := maps.Clone(resources)
+	withMemory[ResourceGPUMemory] = memory
+	return withMemory
+}
+
+// addGPUMemory adds the GPU memory of the MIG slices to resources that aren't shared
+func addGPUMemory(resources v1.ResourceList) {
+	if memory, ok := gpuMemory(resources); ok {
+		resources[ResourceGPUMemory] = memory
+	}
+}
+
+// gpuMemory returns the GPU memory of the MIG slices in the resources, or false if there aren't any
+func gpuMemory(resources v1.ResourceList) (resource.Quantity, bool) {
+	var gb int64
+	for rn, q := range resources {
+		if !strings.HasPrefix(string(rn), "nvidia.com/mig-") {
+			continue
+		}
+		if match := migResourceRe.FindStringSubmatch(string(rn)); match != nil {
+			size, _ := strconv.ParseInt(match[1], 10, 64)
+			gb += size * q.Value()
+		}
+	}
+	if gb == 0 {
+		return resource.Quantity{}, false
+	}
+	// MIG profiles are named by GiB, e.g. the 40GB A100's seven 1g.5gb slices
+	return *resource.NewQuantity(gb<<30, resource.BinarySI), true
+}
+
+// resourceName returns the name of the resource as it's displayed. Extended resources are displayed without their
+// vendor's domain, e.g. nvidia.com/mig-1g.5gb is displayed as mig-1g.5gb, unless that's ambiguous with another of the
+// displayed resources.
+func (u *UIModel) resourceName(rn v1.ResourceName) string {
+	short := shortResourceName(rn)
+	for _, other := range u.Cluster().resources {
+		if other != rn && shortResourceName(other) == short {
+			return string(rn)
+		}
+	}
+	return short
+}
+
+func shortResourceName(rn v1.ResourceName) string {
+	if _, name, ok := strings.Cut(string(rn), "/"); ok && name != "" {
+		return name
+	}
+	return string(rn)
+}
@@ -0,0 +1,84 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestGPUMemory(t *testing.T) {
+	n := testNode("a100")
+	n.Status.Allocatable = v1.ResourceList{
+		v1.ResourceCPU:           resource.MustParse("96"),
+		"nvidia.com/mig-1g.5gb":  resource.MustParse("7"),
+		"nvidia.com/mig-3g.20gb": resource.MustParse("2"),
+	}
+	n.Status.Capacity = n.Status.Allocatable
+	node := model.NewNode(n)
+	node.Show()
+	if got, exp := node.Allocatable()[model.ResourceGPUMemory], resource.MustParse("75Gi"); got.Cmp(exp) != 0 {
+		t.Errorf("expected %s of GPU memory from the MIG slices, got %s", exp.String(), got.String())
+	}
+	if _, ok := n.Status.Allocatable[model.ResourceGPUMemory]; ok {
+		t.Errorf("expected the node's resources not to be modified")
+	}
+
+	p := testPod("default", "inference")
+	p.Spec.InitContainers = nil
+	p.Spec.Containers = []v1.Container{{Resources: v1.ResourceRequirements{
+		Requests: v1.ResourceList{"nvidia.com/mig-1g.5gb": resource.MustParse("2")},
+	}}}
+	node.BindPod(model.NewPod(p))
+	if got, exp := node.Used()[model.ResourceGPUMemory], resource.MustParse("10Gi"); got.Cmp(exp) != 0 {
+		t.Errorf("expected %s of GPU memory to be requested, got %s", exp.String(), got.String())
+	}
+	if got := node.ComputeLabel("eks-node-viewer/node-gpu-memory-usage"); got != "13%" {
+		t.Errorf("expected 13%% of the GPU memory to be used, got %s", got)
+	}
+
+	// nodes without MIG slices don't have any GPU memory, until they're updated with them, e.g. once the device plugin
+	// advertises the slices after the node registers
+	cpu := model.NewNode(testNode("cpu"))
+	if _, ok := cpu.Allocatable()[model.ResourceGPUMemory]; ok {
+		t.Errorf("expected no GPU memory without MIG slices")
+	}
+	cpu.Update(n)
+	if got, exp := cpu.Capacity()[model.ResourceGPUMemory], resource.MustParse("75Gi"); got.Cmp(exp) != 0 {
+		t.Errorf("expected %s of GPU memory once the node is updated, got %s", exp.String(), got.String())
+	}
+
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	m := model.NewUIModel(nil, "", style)
+	m.SetResources([]string{"gpu-memory", "nvidia.com/mig-1g.5gb"})
+	m.Cluster().AddNode(node)
+	m.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	view := m.View()
+	if !strings.Contains(view, "mig-1g.5gb") || strings.Contains(view, "nvidia.com/mig-1g.5gb") {
+		t.Errorf("expected the MIG resource to be displayed without its domain, got %s", view)
+	}
+	if !strings.Contains(view, "10.0/75.0 GiB") {
+		t.Errorf("expected the GPU memory to be displayed in GiB, got %s", view)
+	}
+}
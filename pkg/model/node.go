@@ -16,13 +16,19 @@ package model
 
 import (
 	"fmt"
+	"maps"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/duration"
 	karpv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 )
@@ -35,26 +41,114 @@ type objectKey struct {
 	namespace string
 	name      string
 }
+
+// TenancyLabel can be applied to nodes to set their tenancy when it isn't looked up from EC2, e.g. for nodes launched
+// on dedicated hosts
+const TenancyLabel = "eks-node-viewer/tenancy"
+
+// The tenancies of EC2 instances
+const (
+	TenancyDefault   = "default"
+	TenancyDedicated = "dedicated"
+	TenancyHost      = "host"
+)
+
 type Node struct {
-	mu                    sync.RWMutex
-	visible               bool
-	node                  v1.Node
-	pods                  map[objectKey]*Pod
-	used                  v1.ResourceList
-	Price                 float64
+	mu            sync.RWMutex
+	visible       bool
+	node          v1.Node
+	pods          map[objectKey]*Pod
+	used          v1.ResourceList
+	daemonSetUsed v1.ResourceList
+	// price is the hourly price of the node, NaN if it isn't known
+	price float64
+	// priceSource is the name of the pricing provider that priced the node
+	priceSource           string
 	nodeclaimCreationTime time.Time
+	instanceLifecycle     string
+	instanceTenancy       string
+	cpuCredits            float64
+	hasCPUCredits         bool
+	instanceTypeInfo      *InstanceTypeInfo
+	interruptionTime      time.Time
+	rebalanceTime         time.Time
+	actualUsage           v1.ResourceList
+	podsEvicted           int
+	// terminating are the pods on the node that are being deleted, along with the time their grace period ends. They
+	// are no longer part of the node's pods, but are tracked so that pods stuck terminating can be counted.
+	terminating map[objectKey]time.Time
+	// nodeClaimConditions are the status conditions of the NodeClaim that launched the node, keyed by type
+	nodeClaimConditions map[string]nodeClaimCondition
+	// disruptionBlocked is the message of the latest event explaining why Karpenter can't disrupt the node
+	disruptionBlocked string
+	// excludedUsed are the resources requested by pods in excluded namespaces, they aren't included in used
+	excludedUsed v1.ResourceList
+	// wasReady is set once the node has been seen Ready
+	wasReady bool
+	// onDemandPrice is the on-demand price of the node's instance type, zero if it isn't known
+	onDemandPrice float64
+	// duplicateProviderID is set when another node in the cluster has the same provider ID
+	duplicateProviderID bool
+	// limits are the resource limits of the pods on the node, excluding those in excluded namespaces
+	limits v1.ResourceList
+	// lastUpdate is the local time that the node was last added or updated, to detect a watch that's stopped
+	lastUpdate time.Time
+	// registered is set once the node's Kubernetes object has been seen, nodes from NodeClaims aren't until then
+	registered bool
+	// allocatable and capacity are those of the node with the GPU memory of its MIG slices added, they're computed
+	// when the node is set rather than each time they're displayed
+	allocatable v1.ResourceList
+	capacity    v1.ResourceList
+	// cluster is the cluster that the node has been added to, which is told when the node changes so that it can keep
+	// its totals up to date
+	cluster atomic.Pointer[Cluster]
+}
+
+// nodeClaimCondition is a status condition of a NodeClaim
+type nodeClaimCondition struct {
+	status metav1.ConditionStatus
+	reason string
 }
 
 func NewNode(n *v1.Node) *Node {
 	node := &Node{
-		node: *n,
-		pods: map[objectKey]*Pod{},
-		used: v1.ResourceList{},
+		node:          *n,
+		pods:          map[objectKey]*Pod{},
+		used:          v1.ResourceList{},
+		daemonSetUsed: v1.ResourceList{},
+		terminating:   map[objectKey]time.Time{},
+		excludedUsed:  v1.ResourceList{},
+		limits:        v1.ResourceList{},
+		lastUpdate:    time.Now(),
+		registered:    true,
 	}
-
+	node.wasReady = node.ready()
+	node.setResources()
 	return node
 }
 
+// changed tells the node's cluster that the node has changed, mutators defer it before locking the node so that the
+// cluster is told once the node is unlocked
+func (n *Node) changed() {
+	if c := n.cluster.Load(); c != nil {
+		c.nodeChanged(n)
+	}
+}
+
+// clusterName returns the name of the cluster that the node has been added to
+func (n *Node) clusterName() string {
+	if c := n.cluster.Load(); c != nil {
+		return c.Name()
+	}
+	return ""
+}
+
+// setResources computes the allocatable resources and capacity of the node from its Kubernetes object
+func (n *Node) setResources() {
+	n.allocatable = withGPUMemory(n.node.Status.Allocatable)
+	n.capacity = withGPUMemory(n.node.Status.Capacity)
+}
+
 func NewNodeFromNodeClaim(nc *karpv1.NodeClaim) *Node {
 	node := NewNode(&v1.Node{
 		ObjectMeta: metav1.ObjectMeta{
@@ -72,43 +166,278 @@ func NewNodeFromNodeClaim(nc *karpv1.NodeClaim) *Node {
 			Allocatable: nc.Status.Allocatable,
 		},
 	})
-	node.nodeclaimCreationTime = nc.CreationTimestamp.Time
+	node.registered = false
+	node.UpdateNodeClaim(nc)
 	return node
 }
 
+// UpdateNodeClaim updates the lifecycle conditions of the NodeClaim that launched the node, and records when the
+// NodeClaim was created if the node was seen before its NodeClaim
+func (n *Node) UpdateNodeClaim(nc *karpv1.NodeClaim) {
+	conditions := map[string]nodeClaimCondition{}
+	for _, c := range nc.Status.Conditions {
+		conditions[c.Type] = nodeClaimCondition{status: c.Status, reason: c.Reason}
+	}
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.nodeClaimConditions = conditions
+	if n.nodeclaimCreationTime.IsZero() {
+		n.nodeclaimCreationTime = nc.CreationTimestamp.Time
+	}
+}
+
+// ClearNodeClaim forgets the lifecycle conditions of the node's NodeClaim once it's deleted, the node itself may
+// outlive it, e.g. while it's being finalized or if the NodeClaim was orphaned
+func (n *Node) ClearNodeClaim() {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.nodeClaimConditions = nil
+}
+
+// Registered returns true if the node's Kubernetes object has been seen, rather than only the NodeClaim that launched it
+func (n *Node) Registered() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.registered
+}
+
+// Provisioning returns true if the node was launched by a NodeClaim and hasn't registered yet
+func (n *Node) Provisioning() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return len(n.nodeClaimConditions) > 0 && n.nodeClaimConditions["Registered"].status != metav1.ConditionTrue
+}
+
+// NodeClaimLifecycle summarizes the lifecycle conditions of the node's NodeClaim. It's the first of Launched, Registered
+// and Initialized that isn't true, e.g. NotRegistered/<reason>, or Initialized once they all are, followed by Drifted or
+// Expired if the NodeClaim is. Nodes that weren't launched by a NodeClaim return "-".
+func (n *Node) NodeClaimLifecycle() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if len(n.nodeClaimConditions) == 0 {
+		return "-"
+	}
+	lifecycle := "Initialized"
+	for _, conditionType := range []string{"Launched", "Registered", "Initialized"} {
+		c := n.nodeClaimConditions[conditionType]
+		if c.status == metav1.ConditionTrue {
+			continue
+		}
+		lifecycle = "Not" + conditionType
+		// the reason is only useful when the condition has failed, an unknown condition is usually still in progress
+		if c.status == metav1.ConditionFalse && c.reason != "" {
+			lifecycle += "/" + c.reason
+		}
+		break
+	}
+	for _, conditionType := range []string{"Drifted", "Expired"} {
+		if n.nodeClaimConditions[conditionType].status == metav1.ConditionTrue {
+			lifecycle += "/" + conditionType
+		}
+	}
+	return lifecycle
+}
+
+// IsOnDemand returns true if the node is labeled as on-demand by Karpenter, EKS, GKE or AKS
 func (n *Node) IsOnDemand() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
 	return n.node.Labels["karpenter.sh/capacity-type"] == "on-demand" ||
-		n.node.Labels["eks.amazonaws.com/capacityType"] == "ON_DEMAND"
+		n.node.Labels["eks.amazonaws.com/capacityType"] == "ON_DEMAND" ||
+		n.node.Labels["cloud.google.com/gke-provisioning"] == "standard" ||
+		n.node.Labels["kubernetes.azure.com/scalesetpriority"] == "regular"
 }
 
+// IsSpot returns true if the node is labeled as spot by Karpenter, EKS, GKE or AKS. GKE preemptible VMs are
+// considered to be spot.
 func (n *Node) IsSpot() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
 	return n.node.Labels["karpenter.sh/capacity-type"] == "spot" ||
-		n.node.Labels["eks.amazonaws.com/capacityType"] == "SPOT"
+		n.node.Labels["eks.amazonaws.com/capacityType"] == "SPOT" ||
+		n.node.Labels["cloud.google.com/gke-spot"] == "true" ||
+		n.node.Labels["cloud.google.com/gke-preemptible"] == "true" ||
+		n.node.Labels["cloud.google.com/gke-provisioning"] == "spot" ||
+		n.node.Labels["kubernetes.azure.com/scalesetpriority"] == "spot"
+}
+
+// SetInstanceLifecycle records the purchase option reported by EC2 for the node's instance, either
+// "spot" or "on-demand"
+func (n *Node) SetInstanceLifecycle(lifecycle string) {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.instanceLifecycle = lifecycle
+}
+
+// SetInstanceTenancy records the tenancy reported by EC2 for the node's instance, one of "default", "dedicated" or
+// "host"
+func (n *Node) SetInstanceTenancy(tenancy string) {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.instanceTenancy = tenancy
+}
+
+// Tenancy returns whether the node runs on shared, dedicated or host tenancy hardware, either from the tenancy label or
+// as reported by EC2. Nodes are assumed to have shared tenancy if neither is known.
+func (n *Node) Tenancy() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if tenancy, ok := n.node.Labels[TenancyLabel]; ok {
+		return strings.ToLower(tenancy)
+	}
+	if n.instanceTenancy != "" {
+		return n.instanceTenancy
+	}
+	return TenancyDefault
+}
+
+// HostBilled returns true if the node runs on a dedicated host, which is billed for the whole host rather than for
+// each instance
+func (n *Node) HostBilled() bool {
+	return n.Tenancy() == TenancyHost
+}
+
+// SetPriceSource records the name of the pricing provider that priced the node
+func (n *Node) SetPriceSource(source string) {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.priceSource = source
+}
+
+// PriceSource returns the name of the pricing provider that priced the node, or an empty string if it has no price
+func (n *Node) PriceSource() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.priceSource
+}
+
+// InstanceLifecycle returns the purchase option reported by EC2, or an empty string if it is unknown
+func (n *Node) InstanceLifecycle() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.instanceLifecycle
+}
+
+// CapacityTypeMismatch returns true if the capacity type label on the node disagrees with the purchase option
+// reported by EC2, e.g. a node labeled as spot that was launched as on-demand.
+func (n *Node) CapacityTypeMismatch() bool {
+	switch n.InstanceLifecycle() {
+	case "spot":
+		return n.IsOnDemand()
+	case "on-demand":
+		return n.IsSpot()
+	}
+	return false
 }
 
 func (n *Node) IsFargate() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.isFargate()
+}
+
+func (n *Node) isFargate() bool {
 	return n.node.Labels["eks.amazonaws.com/compute-type"] == "fargate"
 }
 
+// The operating systems of nodes, Bottlerocket is distinguished from other Linux distributions but priced as Linux
+const (
+	OSLinux        = "linux"
+	OSWindows      = "windows"
+	OSBottlerocket = "bottlerocket"
+)
+
+// OperatingSystem returns the operating system of the node from its kubernetes.io/os label, or Bottlerocket if the
+// node's OS image is Bottlerocket. Nodes without the label are assumed to be Linux.
+func (n *Node) OperatingSystem() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.node.Labels[v1.LabelOSStable] == OSWindows {
+		return OSWindows
+	}
+	if strings.HasPrefix(n.node.Status.NodeInfo.OSImage, "Bottlerocket") {
+		return OSBottlerocket
+	}
+	return OSLinux
+}
+
 func (n *Node) IsAuto() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
 	return n.node.Labels["eks.amazonaws.com/compute-type"] == "auto"
 }
 
+// Labels returns a copy of the node's labels, which can be read after the node is updated. Label and MatchesSelector
+// read a single label without copying them.
 func (n *Node) Labels() map[string]string {
-	return n.node.Labels
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return maps.Clone(n.node.Labels)
 }
 
+// Label returns the value of the node's label and whether it's set
+func (n *Node) Label(key string) (string, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	value, ok := n.node.Labels[key]
+	return value, ok
+}
+
+// MatchesSelector returns true if the node's labels match the selector
+func (n *Node) MatchesSelector(selector labels.Selector) bool {
+	return selector.Matches(nodeLabels{n})
+}
+
+// nodeLabels looks up the labels of a node for a selector, each under the node's lock
+type nodeLabels struct {
+	n *Node
+}
+
+func (l nodeLabels) Has(key string) bool {
+	_, ok := l.n.Label(key)
+	return ok
+}
+
+func (l nodeLabels) Get(key string) string {
+	value, _ := l.n.Label(key)
+	return value
+}
+
+// Update replaces the node's Kubernetes object. The resources reported by a NodeClaim are kept until the kubelet
+// reports its own, so a node that's registering doesn't briefly lose its capacity.
 func (n *Node) Update(node *v1.Node) {
+	defer n.changed()
 	n.mu.Lock()
 	defer n.mu.Unlock()
+	capacity, allocatable := n.node.Status.Capacity, n.node.Status.Allocatable
 	n.node = *node
+	n.lastUpdate = time.Now()
+	n.registered = true
+	if len(n.node.Status.Capacity) == 0 {
+		n.node.Status.Capacity = capacity
+	}
+	if len(n.node.Status.Allocatable) == 0 {
+		n.node.Status.Allocatable = allocatable
+	}
+	// remember that the node has been ready, so a later NotReady is timed from the transition and not the nodeclaim
+	if n.ready() {
+		n.wasReady = true
+	}
+	n.setResources()
 }
 
 func (n *Node) Name() string {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
+	// nodes from NodeClaims aren't named until they register, InstanceID isn't called as it takes the lock again which
+	// deadlocks if a writer is waiting in between
 	if n.node.Name == "" {
-		return n.InstanceID()
+		return instanceID(n.node.Spec.ProviderID)
 	}
 	return n.node.Name
 }
@@ -120,7 +449,11 @@ func (n *Node) ProviderID() string {
 }
 
 func (n *Node) InstanceID() string {
-	providerID := n.ProviderID()
+	return instanceID(n.ProviderID())
+}
+
+// instanceID returns the instance ID from an AWS provider ID, or the provider ID itself for other cloud providers
+func instanceID(providerID string) string {
 	matches := instanceIDRegex.FindStringSubmatch(providerID)
 	if matches == nil {
 		return providerID
@@ -134,6 +467,7 @@ func (n *Node) InstanceID() string {
 }
 
 func (n *Node) BindPod(pod *Pod) {
+	defer n.changed()
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	key := objectKey{
@@ -145,33 +479,177 @@ func (n *Node) BindPod(pod *Pod) {
 
 	if !alreadyBound {
 		for rn, q := range pod.Requested() {
+			if pod.Excluded() {
+				existing := n.excludedUsed[rn]
+				existing.Add(q)
+				n.excludedUsed[rn] = existing
+				continue
+			}
 			existing := n.used[rn]
 			existing.Add(q)
 			n.used[rn] = existing
+			if pod.IsDaemonSetPod() {
+				dsExisting := n.daemonSetUsed[rn]
+				dsExisting.Add(q)
+				n.daemonSetUsed[rn] = dsExisting
+			}
+		}
+		if !pod.Excluded() {
+			for rn, q := range pod.Limits() {
+				existing := n.limits[rn]
+				existing.Add(q)
+				n.limits[rn] = existing
+			}
+		}
+	}
+}
+
+// SetTerminating records that a pod on the node is being deleted and should be gone by the deadline
+func (n *Node) SetTerminating(namespace, name string, deadline time.Time) {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.terminating[objectKey{namespace: namespace, name: name}] = deadline
+}
+
+// ClearTerminating stops tracking a pod once it has been removed
+func (n *Node) ClearTerminating(namespace, name string) {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.terminating, objectKey{namespace: namespace, name: name})
+}
+
+// UnhealthyPods returns the number of pods on the node that are in CrashLoopBackOff or that are still terminating after
+// their deletion grace period has ended
+func (n *Node) UnhealthyPods() int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	unhealthy := 0
+	for _, p := range n.pods {
+		if p.IsCrashLooping() {
+			unhealthy++
 		}
 	}
+	now := serverNow()
+	for _, deadline := range n.terminating {
+		if now.After(deadline) {
+			unhealthy++
+		}
+	}
+	return unhealthy
 }
 
 func (n *Node) DeletePod(namespace string, name string) {
+	defer n.changed()
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	key := objectKey{namespace: namespace, name: name}
 	if p, ok := n.pods[key]; ok {
 		// subtract the pod requests
 		for rn, q := range p.Requested() {
+			if p.Excluded() {
+				existing := n.excludedUsed[rn]
+				existing.Sub(q)
+				n.excludedUsed[rn] = existing
+				continue
+			}
 			existing := n.used[rn]
 			existing.Sub(q)
 			n.used[rn] = existing
+			if p.IsDaemonSetPod() {
+				dsExisting := n.daemonSetUsed[rn]
+				dsExisting.Sub(q)
+				n.daemonSetUsed[rn] = dsExisting
+			}
+		}
+		if !p.Excluded() {
+			for rn, q := range p.Limits() {
+				existing := n.limits[rn]
+				existing.Sub(q)
+				n.limits[rn] = existing
+			}
 		}
 		delete(n.pods, key)
+		if !n.interruptionTime.IsZero() {
+			n.podsEvicted++
+		}
+	}
+}
+
+// SetInterrupted records the time that the node received a spot interruption notice, later notices are ignored
+func (n *Node) SetInterrupted(t time.Time) {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.interruptionTime.IsZero() {
+		n.interruptionTime = t
+	}
+}
+
+// Interrupted returns true if the node has received a spot interruption notice
+func (n *Node) Interrupted() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return !n.interruptionTime.IsZero()
+}
+
+// SetRebalanceRecommended records the time that the node received a rebalance recommendation, a signal from EC2 that
+// the spot instance is at an elevated risk of interruption, later recommendations are ignored
+func (n *Node) SetRebalanceRecommended(t time.Time) {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.rebalanceTime.IsZero() {
+		n.rebalanceTime = t
 	}
 }
 
+// RebalanceRecommended returns true if the node has received a rebalance recommendation
+func (n *Node) RebalanceRecommended() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return !n.rebalanceTime.IsZero()
+}
+
+func (n *Node) interruption() (notice time.Time, podsEvicted int) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.interruptionTime, n.podsEvicted
+}
+
 func (n *Node) Allocatable() v1.ResourceList {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
 	// shouldn't be modified so it's safe to return
-	return n.node.Status.Allocatable
+	return n.allocatable
+}
+
+// Capacity returns the total resources of the node, before any are reserved for the system
+func (n *Node) Capacity() v1.ResourceList {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	// shouldn't be modified so it's safe to return
+	return n.capacity
+}
+
+// ReservedResources returns the resources whose allocatable amount is more than maxReserved, a fraction between 0 and
+// 1, below the node's capacity. This usually indicates misconfigured eviction thresholds or system reservations, or a
+// device plugin that has failed and reduced the allocatable devices to zero.
+func (n *Node) ReservedResources(maxReserved float64) []v1.ResourceName {
+	capacity, allocatable := n.Capacity(), n.Allocatable()
+	var reserved []v1.ResourceName
+	for rn, c := range capacity {
+		if c.IsZero() {
+			continue
+		}
+		a := allocatable[rn]
+		if (c.AsApproximateFloat64()-a.AsApproximateFloat64())/c.AsApproximateFloat64() > maxReserved {
+			reserved = append(reserved, rn)
+		}
+	}
+	sort.Slice(reserved, func(a, b int) bool { return reserved[a] < reserved[b] })
+	return reserved
 }
 
 func (n *Node) Used() v1.ResourceList {
@@ -184,6 +662,40 @@ func (n *Node) Used() v1.ResourceList {
 	return used
 }
 
+// DaemonSetUsed returns the resources requested by the daemonset pods on the node, which are a fixed overhead of every
+// node regardless of its workload
+func (n *Node) DaemonSetUsed() v1.ResourceList {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	used := v1.ResourceList{}
+	for rn, q := range n.daemonSetUsed {
+		used[rn] = q.DeepCopy()
+	}
+	return used
+}
+
+// SetActualUsage records the resources actually being consumed on the node as reported by the metrics API
+func (n *Node) SetActualUsage(usage v1.ResourceList) {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.actualUsage = usage
+}
+
+// ActualUsage returns the resources actually being consumed on the node, or nil if it isn't known
+func (n *Node) ActualUsage() v1.ResourceList {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.actualUsage == nil {
+		return nil
+	}
+	usage := v1.ResourceList{}
+	for rn, q := range n.actualUsage {
+		usage[rn] = q.DeepCopy()
+	}
+	return usage
+}
+
 func (n *Node) Cordoned() bool {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
@@ -199,22 +711,19 @@ func (n *Node) Cordoned() bool {
 }
 
 func (n *Node) Ready() bool {
-	ready := false
 	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.ready()
+}
+
+// ready returns true if the node's Ready condition is true, it must be called with the lock held
+func (n *Node) ready() bool {
 	for _, c := range n.node.Status.Conditions {
 		if c.Status == v1.ConditionTrue && c.Type == v1.NodeReady {
-			ready = true
-			break
+			return true
 		}
 	}
-	n.mu.RUnlock()
-	// when the node goes ready, remove the nodeclaim creation ts, if any
-	if ready {
-		n.mu.Lock()
-		n.nodeclaimCreationTime = time.Time{}
-		n.mu.Unlock()
-	}
-	return ready
+	return false
 }
 
 func (n *Node) Created() time.Time {
@@ -229,18 +738,38 @@ func (n *Node) Created() time.Time {
 func (n *Node) InstanceType() ec2types.InstanceType {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
-	if n.IsFargate() {
-		if len(n.Pods()) == 1 {
-			cpu, mem, ok := n.Pods()[0].FargateCapacityProvisioned()
-			if ok {
-				return ec2types.InstanceType(fmt.Sprintf("%gvCPU-%gGB", cpu, mem))
-			}
+	if n.isFargate() {
+		if capacity, ok := n.fargateCapacity(); ok {
+			return ec2types.InstanceType(capacity.String())
 		}
 		return "Fargate"
 	}
 	return ec2types.InstanceType(n.node.Labels[v1.LabelInstanceTypeStable])
 }
 
+// FargateCapacity returns the capacity that Fargate provisioned for the pod running on a Fargate node, or false if
+// the pod hasn't been seen yet
+func (n *Node) FargateCapacity() (FargateCapacity, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.fargateCapacity()
+}
+
+func (n *Node) fargateCapacity() (FargateCapacity, bool) {
+	// Fargate runs one pod per node, but a replaced pod may briefly share it so prefer the one whose capacity is known
+	pods := n.podList()
+	if len(pods) == 0 {
+		return FargateCapacity{}, false
+	}
+	for _, p := range pods {
+		if capacity, ok := p.FargateCapacityProvisioned(); ok {
+			return capacity, true
+		}
+	}
+	sort.Slice(pods, func(a, b int) bool { return pods[a].Name() < pods[b].Name() })
+	return pods[0].FargateCapacity(), true
+}
+
 func (n *Node) Zone() string {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
@@ -254,6 +783,7 @@ func (n *Node) NumPods() int {
 }
 
 func (n *Node) Hide() {
+	defer n.changed()
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	n.visible = false
@@ -265,41 +795,117 @@ func (n *Node) Visible() bool {
 }
 
 func (n *Node) Show() {
+	defer n.changed()
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	n.visible = true
 }
 
 func (n *Node) Deleting() bool {
-	n.mu.Lock()
-	defer n.mu.Unlock()
+	n.mu.RLock()
+	defer n.mu.RUnlock()
 	return !n.node.DeletionTimestamp.IsZero()
 }
 
+// Pods returns the pods bound to the node
 func (n *Node) Pods() []*Pod {
-	var pods []*Pod
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.podList()
+}
+
+// podList returns the pods bound to the node, it must be called with the lock held
+func (n *Node) podList() []*Pod {
+	pods := make([]*Pod, 0, len(n.pods))
 	for _, p := range n.pods {
 		pods = append(pods, p)
 	}
 	return pods
 }
 
+// Price returns the hourly price of the node, NaN if it isn't known
+func (n *Node) Price() float64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.price
+}
+
 func (n *Node) HasPrice() bool {
 	// we use NaN for an unknown price, so if this is true the price is known
-	return n.Price == n.Price
+	price := n.Price()
+	return price == price
 }
 
 var resourceLabelRe = regexp.MustCompile("eks-node-viewer/node-(.*?)-usage")
 
+// AnnotationPrefix selects a node annotation in place of a label, e.g. annotation:karpenter.sh/do-not-disrupt
+const AnnotationPrefix = "annotation:"
+
 // ComputeLabel computes dynamic labels
 func (n *Node) ComputeLabel(labelName string) string {
+	if annotation, ok := strings.CutPrefix(labelName, AnnotationPrefix); ok {
+		if value, ok := n.Annotations()[annotation]; ok {
+			return value
+		}
+		return "-"
+	}
 	switch labelName {
 	case "eks-node-viewer/node-age":
-		return duration.HumanDuration(time.Since(n.Created()))
+		return duration.HumanDuration(since(n.Created()))
+	case "eks-node-viewer/node-pods":
+		return strconv.Itoa(n.NumPods())
+	case "eks-node-viewer/node-price":
+		if !n.HasPrice() {
+			return "-"
+		}
+		return fmt.Sprintf("%0.4f", n.Price())
+	case "eks-node-viewer/node-price-source":
+		if source := n.PriceSource(); source != "" {
+			return source
+		}
+		return "-"
+	case "eks-node-viewer/node-capacity-type":
+		return capacityType(n)
+	case "eks-node-viewer/node-tenancy":
+		return n.Tenancy()
+	case "eks-node-viewer/node-cpu-credits":
+		return cpuCredits(n)
+	case "eks-node-viewer/node-vcpus", "eks-node-viewer/node-memory", "eks-node-viewer/node-network",
+		"eks-node-viewer/node-gpus", "eks-node-viewer/node-price-per-vcpu", "eks-node-viewer/node-price-per-gb":
+		return instanceTypeLabel(n, strings.TrimPrefix(labelName, "eks-node-viewer/node-"))
+	case "eks-node-viewer/node-score":
+		return strconv.FormatFloat(n.Score(), 'f', 0, 64)
+	case "eks-node-viewer/node-taints":
+		return taintSummary(n)
+	case "eks-node-viewer/node-lifecycle":
+		return n.NodeClaimLifecycle()
+	case "eks-node-viewer/node-disruption":
+		return n.Disruption()
+	case "eks-node-viewer/node-eni-max-pods":
+		return eniMaxPods(n)
+	case "eks-node-viewer/node-os":
+		return n.OperatingSystem()
+	case "eks-node-viewer/node-health":
+		return n.Health()
+	case "eks-node-viewer/node-group":
+		return managedNodeGroupLabel(n)
+	case "eks-node-viewer/node-manager":
+		return n.Manager()
+	case "eks-node-viewer/node-unhealthy-pods":
+		return strconv.Itoa(n.UnhealthyPods())
+	case "eks-node-viewer/node-spot-savings":
+		return spotSavingsLabel(n)
+	case "eks-node-viewer/cost-per-pod":
+		return costLabel(n.CostPerPod())
+	case "eks-node-viewer/cost-efficiency":
+		return costLabel(n.CostEfficiency())
 	}
 	// resource based custom labels
 	if match := resourceLabelRe.FindStringSubmatch(labelName); len(match) > 0 {
-		return pctUsage(n.Allocatable(), n.Used(), match[1])
+		return pctUsage(limitPods(n, n.Allocatable()), n.Used(), match[1])
+	}
+	if match := overcommitLabelRe.FindStringSubmatch(labelName); len(match) > 0 {
+		return overcommitLabel(n, v1.ResourceName(match[1]))
 	}
 	return "-"
 }
@@ -314,11 +920,13 @@ func (n *Node) NotReadyTime() time.Time {
 			break
 		}
 	}
+	nodeclaimCreationTime := n.nodeclaimCreationTime
+	wasReady := n.wasReady
 	n.mu.RUnlock()
 	if !notReadyTransitionTime.IsZero() {
 		// if there's a nodeclaim creation ts, use it if the node has never been Ready before
-		if !n.nodeclaimCreationTime.IsZero() {
-			return n.nodeclaimCreationTime
+		if !nodeclaimCreationTime.IsZero() && !wasReady {
+			return nodeclaimCreationTime
 		}
 		return notReadyTransitionTime
 	}
@@ -326,7 +934,10 @@ func (n *Node) NotReadyTime() time.Time {
 }
 
 func (n *Node) SetPrice(price float64) {
-	n.Price = price
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.price = price
 }
 
 func pctUsage(allocatable v1.ResourceList, used v1.ResourceList, resource string) string {
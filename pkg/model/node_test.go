@@ -14,11 +14,17 @@ limitations under the License.
 package model_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/awslabs/operatorpkg/status"
+	tea "github.com/charmbracelet/bubbletea"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	karpv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 
 	"github.com/awslabs/eks-node-viewer/pkg/model"
 )
@@ -56,8 +62,10 @@ func TestNodeTypeUnknown(t *testing.T) {
 
 func TestNodeTypeOnDemand(t *testing.T) {
 	for label, value := range map[string]string{
-		"karpenter.sh/capacity-type":     "on-demand",
-		"eks.amazonaws.com/capacityType": "ON_DEMAND",
+		"karpenter.sh/capacity-type":            "on-demand",
+		"eks.amazonaws.com/capacityType":        "ON_DEMAND",
+		"cloud.google.com/gke-provisioning":     "standard",
+		"kubernetes.azure.com/scalesetpriority": "regular",
 	} {
 		n := testNode("mynode")
 		n.Labels = map[string]string{
@@ -78,8 +86,12 @@ func TestNodeTypeOnDemand(t *testing.T) {
 
 func TestNodeTypeSpot(t *testing.T) {
 	for label, value := range map[string]string{
-		"karpenter.sh/capacity-type":     "spot",
-		"eks.amazonaws.com/capacityType": "SPOT",
+		"karpenter.sh/capacity-type":            "spot",
+		"eks.amazonaws.com/capacityType":        "SPOT",
+		"cloud.google.com/gke-spot":             "true",
+		"cloud.google.com/gke-preemptible":      "true",
+		"cloud.google.com/gke-provisioning":     "spot",
+		"kubernetes.azure.com/scalesetpriority": "spot",
 	} {
 		n := testNode("mynode")
 		n.Labels = map[string]string{
@@ -188,3 +200,249 @@ func TestNodeNotReadyNoCondition(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeNotReadyAfterReady(t *testing.T) {
+	nc := &karpv1.NodeClaim{}
+	nc.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	nc.Status.ProviderID = "aws:///us-west-2a/i-1234"
+	node := model.NewNodeFromNodeClaim(nc)
+
+	// the node is remembered as having been ready when it's updated, even if nothing asks whether it's ready
+	n := testNode("mynode")
+	n.Spec.ProviderID = nc.Status.ProviderID
+	n.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	node.Update(n)
+
+	notReadyTime := time.Now().Add(-1 * time.Hour)
+	n = n.DeepCopy()
+	n.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionFalse,
+		LastTransitionTime: metav1.NewTime(notReadyTime)}}
+	node.Update(n)
+	if node.Ready() {
+		t.Fatalf("expected node to be not ready")
+	}
+	if got := node.NotReadyTime(); !got.Equal(notReadyTime) {
+		t.Errorf("expected not ready time = %s, got %s", notReadyTime, got)
+	}
+}
+
+func TestNodeCapacityTypeMismatch(t *testing.T) {
+	n := testNode("mynode")
+	n.Labels = map[string]string{
+		"karpenter.sh/capacity-type": "spot",
+	}
+	node := model.NewNode(n)
+	if node.CapacityTypeMismatch() {
+		t.Errorf("expected no mismatch with an unknown lifecycle")
+	}
+	node.SetInstanceLifecycle("spot")
+	if node.CapacityTypeMismatch() {
+		t.Errorf("expected no mismatch for a spot instance labeled as spot")
+	}
+	node.SetInstanceLifecycle("on-demand")
+	if !node.CapacityTypeMismatch() {
+		t.Errorf("expected mismatch for an on-demand instance labeled as spot")
+	}
+}
+
+func TestNodeTenancy(t *testing.T) {
+	n := testNode("mynode")
+	node := model.NewNode(n)
+	if got := node.Tenancy(); got != model.TenancyDefault {
+		t.Errorf("expected default tenancy when it's unknown, got %s", got)
+	}
+	node.SetInstanceTenancy(model.TenancyHost)
+	if !node.HostBilled() {
+		t.Errorf("expected a host tenancy instance to be host billed")
+	}
+	if got := node.ComputeLabel("eks-node-viewer/node-capacity-type"); got != "-/Host" {
+		t.Errorf("expected the tenancy in the capacity type, got %s", got)
+	}
+
+	// the label takes precedence over EC2
+	n.Labels = map[string]string{model.TenancyLabel: "Dedicated"}
+	node.Update(n)
+	if got := node.Tenancy(); got != model.TenancyDedicated {
+		t.Errorf("expected the labeled tenancy, got %s", got)
+	}
+	if node.HostBilled() {
+		t.Errorf("expected a dedicated instance not to be host billed")
+	}
+}
+
+func TestNodeReservedResources(t *testing.T) {
+	n := testNode("mynode")
+	n.Status.Capacity = v1.ResourceList{
+		v1.ResourceCPU:                   resource.MustParse("4"),
+		v1.ResourceMemory:                resource.MustParse("16Gi"),
+		"nvidia.com/gpu":                 resource.MustParse("1"),
+		v1.ResourceName("hugepages-1Gi"): resource.MustParse("0"),
+	}
+	n.Status.Allocatable = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("3920m"),
+		v1.ResourceMemory: resource.MustParse("6Gi"),
+		"nvidia.com/gpu":  resource.MustParse("0"),
+	}
+	node := model.NewNode(n)
+
+	got := node.ReservedResources(0.5)
+	if len(got) != 2 || got[0] != v1.ResourceMemory || got[1] != "nvidia.com/gpu" {
+		t.Errorf("expected memory and nvidia.com/gpu to be reserved, got %v", got)
+	}
+	if got := node.ReservedResources(0.9); len(got) != 1 || got[0] != "nvidia.com/gpu" {
+		t.Errorf("expected only nvidia.com/gpu to be reserved, got %v", got)
+	}
+}
+
+func TestNodeDaemonSetUsed(t *testing.T) {
+	node := model.NewNode(testNode("mynode"))
+	ds := testPod("kube-system", "aws-node-abcde")
+	ds.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "aws-node"}}
+	node.BindPod(model.NewPod(ds))
+	node.BindPod(model.NewPod(testPod("default", "web")))
+
+	// each test pod requests 2 CPUs, including its sidecar
+	if got := node.Used()[v1.ResourceCPU]; got.Cmp(resource.MustParse("4")) != 0 {
+		t.Errorf("expected 4 CPUs used, got %s", got.String())
+	}
+	if got := node.DaemonSetUsed()[v1.ResourceCPU]; got.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("expected 2 CPUs used by daemonsets, got %s", got.String())
+	}
+	node.DeletePod("kube-system", "aws-node-abcde")
+	if got := node.DaemonSetUsed()[v1.ResourceCPU]; !got.IsZero() {
+		t.Errorf("expected no CPU used by daemonsets, got %s", got.String())
+	}
+}
+
+func TestNodeClaimLifecycle(t *testing.T) {
+	if got := model.NewNode(testNode("mynode")).NodeClaimLifecycle(); got != "-" {
+		t.Errorf("expected a node without a nodeclaim to have no lifecycle, got %s", got)
+	}
+
+	nc := &karpv1.NodeClaim{}
+	nc.Status.ProviderID = "aws:///us-west-2a/i-1234"
+	nc.Status.Conditions = []status.Condition{
+		{Type: "Launched", Status: metav1.ConditionTrue},
+		{Type: "Registered", Status: metav1.ConditionUnknown, Reason: "AwaitingReconciliation"},
+	}
+	node := model.NewNodeFromNodeClaim(nc)
+	if exp, got := "NotRegistered", node.NodeClaimLifecycle(); exp != got {
+		t.Errorf("expected lifecycle %s, got %s", exp, got)
+	}
+
+	nc.Status.Conditions = []status.Condition{
+		{Type: "Launched", Status: metav1.ConditionFalse, Reason: "InsufficientCapacity"},
+	}
+	node.UpdateNodeClaim(nc)
+	if exp, got := "NotLaunched/InsufficientCapacity", node.ComputeLabel("eks-node-viewer/node-lifecycle"); exp != got {
+		t.Errorf("expected lifecycle %s, got %s", exp, got)
+	}
+
+	nc.Status.Conditions = []status.Condition{
+		{Type: "Launched", Status: metav1.ConditionTrue},
+		{Type: "Registered", Status: metav1.ConditionTrue},
+		{Type: "Initialized", Status: metav1.ConditionTrue},
+		{Type: "Drifted", Status: metav1.ConditionTrue},
+	}
+	node.UpdateNodeClaim(nc)
+	if exp, got := "Initialized/Drifted", node.NodeClaimLifecycle(); exp != got {
+		t.Errorf("expected lifecycle %s, got %s", exp, got)
+	}
+}
+
+func TestNodeUnhealthyPods(t *testing.T) {
+	node := model.NewNode(testNode("mynode"))
+	crashing := testPod("default", "crashing")
+	crashing.Status.ContainerStatuses = []v1.ContainerStatus{{
+		Name:  "container",
+		State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+	}}
+	node.BindPod(model.NewPod(crashing))
+	node.BindPod(model.NewPod(testPod("default", "web")))
+	if exp, got := 1, node.UnhealthyPods(); exp != got {
+		t.Errorf("expected %d unhealthy pods, got %d", exp, got)
+	}
+
+	// only pods that are still terminating after their grace period are unhealthy
+	node.SetTerminating("default", "stuck", time.Now().Add(-time.Minute))
+	node.SetTerminating("default", "stopping", time.Now().Add(time.Minute))
+	if exp, got := "2", node.ComputeLabel("eks-node-viewer/node-unhealthy-pods"); exp != got {
+		t.Errorf("expected %s unhealthy pods, got %s", exp, got)
+	}
+	node.ClearTerminating("default", "stuck")
+	if exp, got := 1, node.UnhealthyPods(); exp != got {
+		t.Errorf("expected %d unhealthy pods, got %d", exp, got)
+	}
+}
+
+func TestNodeOperatingSystem(t *testing.T) {
+	n := testNode("mynode")
+	if exp, got := model.OSLinux, model.NewNode(n).OperatingSystem(); exp != got {
+		t.Errorf("expected a node without an os label to be %s, got %s", exp, got)
+	}
+
+	n.Status.NodeInfo.OSImage = "Bottlerocket OS 1.20.0 (aws-k8s-1.30)"
+	n.Labels = map[string]string{v1.LabelOSStable: "linux"}
+	if exp, got := model.OSBottlerocket, model.NewNode(n).ComputeLabel("eks-node-viewer/node-os"); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+
+	n = testNode("mywindowsnode")
+	n.Labels = map[string]string{v1.LabelOSStable: "windows"}
+	if exp, got := model.OSWindows, model.NewNode(n).OperatingSystem(); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+}
+
+func TestNodeAnnotationLabel(t *testing.T) {
+	n := testNode("mynode")
+	n.Annotations = map[string]string{"karpenter.sh/do-not-disrupt": "true", "cost-center": "1234"}
+	node := model.NewNode(n)
+	if exp, got := "true", node.ComputeLabel("annotation:karpenter.sh/do-not-disrupt"); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+	if exp, got := "-", node.ComputeLabel("annotation:missing"); exp != got {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+	if groups := model.GroupNodes([]*model.Node{node}, "annotation:cost-center"); len(groups) != 1 || groups[0].Name != "1234" {
+		t.Errorf("expected a 1234 group, got %v", groups)
+	}
+
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("creating style, %s", err)
+	}
+	ui := model.NewUIModel([]string{"annotation:karpenter.sh/do-not-disrupt", "annotation:cost-center"}, "name", style)
+	ui.SetResources([]string{"cpu"})
+	node.Show()
+	ui.Cluster().AddNode(node)
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	view := ui.View()
+	for _, exp := range []string{"DO-NOT-DISRUPT", "COST-CENTER", "1234"} {
+		if !strings.Contains(view, exp) {
+			t.Errorf("expected %s in the view, got\n%s", exp, view)
+		}
+	}
+}
+
+func TestNodeLabels(t *testing.T) {
+	n := testNode("mynode")
+	n.Labels = map[string]string{"zone": "a"}
+	node := model.NewNode(n)
+
+	// the labels are a copy, so changes to them don't affect the node
+	nodeLabels := node.Labels()
+	nodeLabels["zone"] = "b"
+	if got, ok := node.Label("zone"); !ok || got != "a" {
+		t.Errorf("expected the zone label to be %q, got %q", "a", got)
+	}
+	if _, ok := node.Label("missing"); ok {
+		t.Errorf("expected the missing label not to be set")
+	}
+	if !node.MatchesSelector(labels.SelectorFromSet(labels.Set{"zone": "a"})) {
+		t.Errorf("expected the node to match its zone")
+	}
+	if node.MatchesSelector(labels.SelectorFromSet(labels.Set{"zone": "b"})) {
+		t.Errorf("expected the node not to match another zone")
+	}
+}
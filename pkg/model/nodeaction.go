@@ -0,0 +1,186 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"text/template"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v1 "k8s.io/api/core/v1"
+)
+
+// maxNodeActions is the number of node actions that can be chosen from, they're selected with the keys 1-9
+const maxNodeActions = 9
+
+// NodeActionPresets are the node actions that are available unless replaced by an action with the same name
+var NodeActionPresets = []string{
+	"ssm=aws ssm start-session --target {{.InstanceID}}{{with .Region}} --region {{.}}{{end}}",
+	"ssm-ssh=aws ssm start-session --target {{.InstanceID}}{{with .Region}} --region {{.}}{{end}} --document-name AWS-StartPortForwardingSession --parameters portNumber=22,localPortNumber=2222",
+	"debug=kubectl debug node/{{.Name}} -it --image=busybox --profile=sysadmin{{with .Context}} --context {{.}}{{end}}",
+}
+
+// NodeAction is a named command that's run against the selected node, its arguments are templated with the node's
+// fields
+type NodeAction struct {
+	Name    string
+	command *template.Template
+}
+
+// NodeActionFields are the fields of the node available to a node action's template
+type NodeActionFields struct {
+	Name         string
+	InstanceID   string
+	ProviderID   string
+	InstanceType string
+	Zone         string
+	Region       string
+	// Context is the kubeconfig context of the node's cluster, it's empty for the current context
+	Context string
+}
+
+// ParseNodeAction parses a node action of the form name=command, where the command is a Go template of the node's
+// fields, e.g. ssm=aws ssm start-session --target {{.InstanceID}}
+func ParseNodeAction(def string) (NodeAction, error) {
+	name, text, ok := strings.Cut(def, "=")
+	if !ok || name == "" || strings.TrimSpace(text) == "" {
+		return NodeAction{}, fmt.Errorf("invalid node action %q, expected name=command", def)
+	}
+	command, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return NodeAction{}, fmt.Errorf("parsing node action %q, %w", name, err)
+	}
+	return NodeAction{Name: name, command: command}, nil
+}
+
+// ParseNodeActions parses the presets followed by the node actions, an action with the same name as a preset replaces
+// it
+func ParseNodeActions(defs []string) ([]NodeAction, error) {
+	var actions []NodeAction
+	for _, def := range append(append([]string{}, NodeActionPresets...), defs...) {
+		action, err := ParseNodeAction(def)
+		if err != nil {
+			return nil, err
+		}
+		replaced := false
+		for i := range actions {
+			if actions[i].Name == action.Name {
+				actions[i] = action
+				replaced = true
+			}
+		}
+		if !replaced {
+			actions = append(actions, action)
+		}
+	}
+	if len(actions) > maxNodeActions {
+		return nil, fmt.Errorf("at most %d node actions are supported, got %d", maxNodeActions, len(actions))
+	}
+	return actions, nil
+}
+
+// Args returns the command line of the action for the node, the templated command is split on whitespace so its
+// arguments can't contain spaces
+func (a NodeAction) Args(fields NodeActionFields) ([]string, error) {
+	var buf bytes.Buffer
+	if err := a.command.Execute(&buf, fields); err != nil {
+		return nil, fmt.Errorf("templating node action %s, %w", a.Name, err)
+	}
+	args := strings.Fields(buf.String())
+	if len(args) == 0 {
+		return nil, fmt.Errorf("node action %s is empty for the node", a.Name)
+	}
+	return args, nil
+}
+
+// nodeActionFields returns the fields of the node that are available to node actions
+func (u *UIModel) nodeActionFields(n *Node) NodeActionFields {
+	fields := NodeActionFields{
+		Name:         n.Name(),
+		InstanceID:   n.InstanceID(),
+		ProviderID:   n.ProviderID(),
+		InstanceType: string(n.InstanceType()),
+		Zone:         n.Zone(),
+		Region:       n.Labels()[v1.LabelTopologyRegion],
+	}
+	for _, c := range u.clusters {
+		if c.HasNode(n) {
+			fields.Context = c.Name()
+			break
+		}
+	}
+	return fields
+}
+
+// nodeActionMsg is sent when a node action's command exits
+type nodeActionMsg struct {
+	action string
+	node   string
+	err    error
+}
+
+// SetNodeActions sets the actions that can be run against the selected node
+func (u *UIModel) SetNodeActions(actions []NodeAction) {
+	u.nodeActions = actions
+}
+
+// chooseNodeAction starts choosing an action to run against the selected node
+func (u *UIModel) chooseNodeAction() {
+	if u.cursorNode == nil || u.grouping || len(u.nodeActions) == 0 {
+		return
+	}
+	u.actionNode = u.cursorNode
+}
+
+// updateNodeAction handles key presses while choosing a node action, running the chosen action's command in place of
+// the display until it exits
+func (u *UIModel) updateNodeAction(msg tea.KeyMsg) tea.Cmd {
+	node := u.actionNode
+	u.actionNode = nil
+	key := msg.String()
+	if len(key) != 1 || key[0] < '1' || int(key[0]-'1') >= len(u.nodeActions) {
+		return nil
+	}
+	action := u.nodeActions[key[0]-'1']
+	args, err := action.Args(u.nodeActionFields(node))
+	if err != nil {
+		u.status = err.Error()
+		return nil
+	}
+	// kubectl and the AWS CLI are interactive, so the display is released to them until they exit
+	return tea.ExecProcess(exec.Command(args[0], args[1:]...), func(err error) tea.Msg {
+		return nodeActionMsg{action: action.Name, node: node.Name(), err: err}
+	})
+}
+
+// nodeActionHelp lists the actions that can be chosen for the node
+func (u *UIModel) nodeActionHelp() string {
+	var choices []string
+	for i, a := range u.nodeActions {
+		choices = append(choices, fmt.Sprintf("%d: %s", i+1, a.Name))
+	}
+	return fmt.Sprintf("run on %s • %s • esc: cancel", u.actionNode.Name(), strings.Join(choices, " • "))
+}
+
+// nodeActionStatus describes how a node action's command exited
+func nodeActionStatus(msg nodeActionMsg) string {
+	if msg.err != nil {
+		return fmt.Sprintf("%s on %s failed, %s", msg.action, msg.node, msg.err)
+	}
+	return fmt.Sprintf("%s on %s finished", msg.action, msg.node)
+}
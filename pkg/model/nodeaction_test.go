@@ -0,0 +1,132 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestNodeActionArgs(t *testing.T) {
+	actions, err := model.ParseNodeActions([]string{"debug=kubectl debug node/{{.Name}} -it --image=alpine", "zone=echo {{.Zone}}"})
+	if err != nil {
+		t.Fatalf("parsing node actions, %s", err)
+	}
+	var names []string
+	for _, a := range actions {
+		names = append(names, a.Name)
+	}
+	// the debug preset is replaced in place and the new action is added after the presets
+	if got := strings.Join(names, ","); got != "ssm,ssm-ssh,debug,zone" {
+		t.Fatalf("expected the actions ssm,ssm-ssh,debug,zone, got %s", got)
+	}
+
+	fields := model.NodeActionFields{Name: "mynode", InstanceID: "i-0123456789abcdef0", Zone: "us-west-2a", Region: "us-west-2"}
+	for _, tc := range []struct {
+		action model.NodeAction
+		fields model.NodeActionFields
+		exp    string
+	}{
+		{actions[0], fields, "aws ssm start-session --target i-0123456789abcdef0 --region us-west-2"},
+		{actions[2], fields, "kubectl debug node/mynode -it --image=alpine"},
+		{actions[3], fields, "echo us-west-2a"},
+	} {
+		args, err := tc.action.Args(tc.fields)
+		if err != nil {
+			t.Errorf("templating %s, %s", tc.action.Name, err)
+			continue
+		}
+		if got := strings.Join(args, " "); got != tc.exp {
+			t.Errorf("expected %s to run %q, got %q", tc.action.Name, tc.exp, got)
+		}
+	}
+
+	// the preset doesn't pass an empty context or region
+	preset, err := model.ParseNodeActions(nil)
+	if err != nil {
+		t.Fatalf("parsing presets, %s", err)
+	}
+	args, err := preset[2].Args(model.NodeActionFields{Name: "mynode"})
+	if err != nil {
+		t.Fatalf("templating debug, %s", err)
+	}
+	if got := strings.Join(args, " "); strings.Contains(got, "--context") {
+		t.Errorf("expected no context, got %q", got)
+	}
+	if args, err := preset[2].Args(model.NodeActionFields{Name: "mynode", Context: "prod"}); err != nil ||
+		!strings.HasSuffix(strings.Join(args, " "), "--context prod") {
+		t.Errorf("expected the context to be passed, got %q, %v", args, err)
+	}
+}
+
+func TestParseNodeActionErrors(t *testing.T) {
+	for _, def := range []string{"ssm", "=aws ssm", "empty=  ", "bad=echo {{.Name"} {
+		if _, err := model.ParseNodeAction(def); err == nil {
+			t.Errorf("expected %q to be invalid", def)
+		}
+	}
+	if _, err := model.ParseNodeActions([]string{"a=a", "b=b", "c=c", "d=d", "e=e", "f=f", "g=g"}); err == nil {
+		t.Error("expected more than 9 actions to be rejected")
+	}
+}
+
+func TestChooseNodeAction(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	actions, err := model.ParseNodeActions([]string{"missing=echo {{.Missing}}"})
+	if err != nil {
+		t.Fatalf("parsing node actions, %s", err)
+	}
+	ui.SetNodeActions(actions)
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	n := testNode("mynode")
+	n.Spec.ProviderID = "aws:///us-west-2a/i-0123456789abcdef0"
+	node := model.NewNode(n)
+	node.Show()
+	ui.Cluster().AddNode(node)
+	ui.View()
+
+	press := func(key string) tea.Cmd {
+		_, cmd := ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		return cmd
+	}
+	press("x")
+	if view := ui.View(); !strings.Contains(view, "run on mynode • 1: ssm • 2: ssm-ssh • 3: debug • 4: missing") {
+		t.Fatalf("expected the actions to be listed, got\n%s", view)
+	}
+	if cmd := press("1"); cmd == nil {
+		t.Error("expected choosing an action to run its command")
+	}
+	if view := ui.View(); strings.Contains(view, "run on mynode") {
+		t.Errorf("expected choosing an action to return to the nodes, got\n%s", view)
+	}
+
+	// a template that can't be executed is reported rather than run
+	press("x")
+	if cmd := press("4"); cmd != nil {
+		t.Error("expected the action not to run")
+	}
+	if view := ui.View(); !strings.Contains(view, "templating node action missing") {
+		t.Errorf("expected the template error to be displayed, got\n%s", view)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxClipboardBytes is the longest node list that's copied to the clipboard, terminals drop OSC 52 sequences that are
+// much longer so larger lists are written to a file instead
+const maxClipboardBytes = 64 * 1024
+
+// The fields of the nodes that can be listed with 'Y'
+const (
+	NodeListName       = "name"
+	NodeListInstanceID = "instance-id"
+)
+
+// WriteNodeList writes the name, or instance ID, of every node that passes the filter, selector and quick filters, one
+// per line in the displayed order. Every page is written, not only the one being displayed.
+func (u *UIModel) WriteNodeList(w io.Writer) (int, error) {
+	stats, _ := u.stats()
+	nodes := u.filterNodes(stats.Nodes)
+	for _, n := range nodes {
+		value := n.Name()
+		if u.NodeListField == NodeListInstanceID {
+			value = n.InstanceID()
+		}
+		if _, err := fmt.Fprintln(w, value); err != nil {
+			return 0, err
+		}
+	}
+	return len(nodes), nil
+}
+
+// copyNodeList copies the list of the filtered nodes to the clipboard, or writes it to NodeListPath if it's set or the
+// list is too long for the clipboard, returning a status message for the help line
+func (u *UIModel) copyNodeList() string {
+	var b strings.Builder
+	nodes, err := u.WriteNodeList(&b)
+	if err != nil {
+		return fmt.Sprintf("listing nodes failed, %s", err)
+	}
+	if nodes == 0 {
+		return "listing nodes failed, no nodes match the filter"
+	}
+	if u.NodeListPath == "" && b.Len() <= maxClipboardBytes {
+		if err := copyToClipboard(b.String()); err != nil {
+			return fmt.Sprintf("copying nodes failed, %s", err)
+		}
+		return fmt.Sprintf("copied %d nodes", nodes)
+	}
+	path := u.NodeListPath
+	if path == "" {
+		path = fmt.Sprintf("eks-node-viewer-nodes-%s.txt", time.Now().Format("20060102-150405"))
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return fmt.Sprintf("listing nodes failed, %s", err)
+	}
+	return fmt.Sprintf("wrote %d nodes to %s", nodes, path)
+}
@@ -0,0 +1,67 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestWriteNodeList(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	// more nodes match than fit on a page
+	ui.PageSize = 2
+	for i := 0; i < 5; i++ {
+		n := testNode(fmt.Sprintf("spot-%d", i))
+		n.Spec.ProviderID = fmt.Sprintf("aws:///us-west-2a/i-%d", i)
+		node := model.NewNode(n)
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+	other := model.NewNode(testNode("other"))
+	other.Show()
+	ui.Cluster().AddNode(other)
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+
+	ui.SetFilter("spot")
+	var b strings.Builder
+	nodes, err := ui.WriteNodeList(&b)
+	if err != nil {
+		t.Fatalf("listing nodes, %s", err)
+	}
+	if exp := "spot-0\nspot-1\nspot-2\nspot-3\nspot-4\n"; nodes != 5 || b.String() != exp {
+		t.Errorf("expected every page of the filtered nodes %q, got %d nodes %q", exp, nodes, b.String())
+	}
+
+	ui.NodeListField = model.NodeListInstanceID
+	ui.SetFilter("spot-3")
+	b.Reset()
+	if _, err := ui.WriteNodeList(&b); err != nil {
+		t.Fatalf("listing nodes, %s", err)
+	}
+	if exp := "i-3\n"; b.String() != exp {
+		t.Errorf("expected %q, got %q", exp, b.String())
+	}
+}
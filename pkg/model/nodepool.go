@@ -0,0 +1,186 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/text"
+)
+
+// NodePoolUsage is the cost and resource usage of the nodes of a Karpenter NodePool accumulated during the session
+type NodePoolUsage struct {
+	// Nodes is the number of nodes in the pool when last accumulated
+	Nodes int
+	// Cost is the accumulated price of the pool's priced nodes, and PricedVCPUHours the vCPU hours requested on them
+	Cost            float64
+	PricedVCPUHours float64
+	// Requested and Allocatable are the resource hours requested on and allocatable by the pool's nodes
+	Requested   map[v1.ResourceName]float64
+	Allocatable map[v1.ResourceName]float64
+}
+
+// CostPerVCPU returns the hourly cost of each requested vCPU, or false if none of the pool's nodes were priced
+func (p *NodePoolUsage) CostPerVCPU() (float64, bool) {
+	if p.PricedVCPUHours == 0 {
+		return 0, false
+	}
+	return p.Cost / p.PricedVCPUHours, true
+}
+
+// Utilization returns the average share of the pool's allocatable resource that was requested
+func (p *NodePoolUsage) Utilization(rn v1.ResourceName) (float64, bool) {
+	if p.Allocatable[rn] == 0 {
+		return 0, false
+	}
+	return p.Requested[rn] / p.Allocatable[rn], true
+}
+
+// NodePoolUsages are the usage of each NodePool accumulated during the session, to rank the pools by their efficiency
+type NodePoolUsages struct {
+	Pools map[string]*NodePoolUsage
+	last  time.Time
+}
+
+// Accumulate adds the cost and usage of the NodePools' nodes since the last time they were accumulated
+func (u *NodePoolUsages) Accumulate(now time.Time, nodes []*Node) {
+	hours := 0.0
+	if !u.last.IsZero() {
+		hours = now.Sub(u.last).Hours()
+	}
+	u.last = now
+	if u.Pools == nil {
+		u.Pools = map[string]*NodePoolUsage{}
+	}
+	for _, p := range u.Pools {
+		p.Nodes = 0
+	}
+	for _, n := range nodes {
+		name, ok := n.Label(DefaultGroupBy)
+		if !ok {
+			continue
+		}
+		p, ok := u.Pools[name]
+		if !ok {
+			p = &NodePoolUsage{Requested: map[v1.ResourceName]float64{}, Allocatable: map[v1.ResourceName]float64{}}
+			u.Pools[name] = p
+		}
+		p.Nodes++
+		used, allocatable := n.Used(), n.Allocatable()
+		for _, rn := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+			usedRes, allocatableRes := used[rn], allocatable[rn]
+			p.Requested[rn] += usedRes.AsApproximateFloat64() * hours
+			p.Allocatable[rn] += allocatableRes.AsApproximateFloat64() * hours
+		}
+		if n.HasPrice() {
+			cpu := used[v1.ResourceCPU]
+			p.Cost += n.Price() * hours
+			p.PricedVCPUHours += cpu.AsApproximateFloat64() * hours
+		}
+	}
+}
+
+// Ranked returns the names of the NodePools with accumulated usage from the most to the least efficient, which is the
+// lowest cost per requested vCPU, or the highest CPU utilization if the pools aren't priced
+func (u *NodePoolUsages) Ranked(priced bool) []string {
+	var names []string
+	for name, p := range u.Pools {
+		if _, ok := p.Utilization(v1.ResourceCPU); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(a, b int) bool {
+		lhs, rhs := u.Pools[names[a]], u.Pools[names[b]]
+		if priced {
+			lhsCost, lhsOK := lhs.CostPerVCPU()
+			rhsCost, rhsOK := rhs.CostPerVCPU()
+			if lhsOK != rhsOK {
+				return lhsOK
+			}
+			if lhsOK && lhsCost != rhsCost {
+				return lhsCost < rhsCost
+			}
+		}
+		lhsUtil, _ := lhs.Utilization(v1.ResourceCPU)
+		rhsUtil, _ := rhs.Utilization(v1.ResourceCPU)
+		if lhsUtil != rhsUtil {
+			return lhsUtil > rhsUtil
+		}
+		return names[a] < names[b]
+	})
+	return names
+}
+
+// writeNodePools writes the leaderboard of the NodePools ranked by their efficiency over the session, highlighting the
+// most efficient pool in green and the least efficient in red
+func (u *UIModel) writeNodePools(w io.Writer) {
+	priced := !u.DisablePricing
+	names := u.nodePools.Ranked(priced)
+	if len(names) == 0 {
+		fmt.Fprintln(w, "Collecting the usage of the NodePools, the leaderboard is ready after the first samples...")
+		return
+	}
+	unit := priceUnits[u.priceUnit]
+	if priced {
+		fmt.Fprintf(w, "NodePools from the most to the least efficient this session, by price per requested vCPU per %s\n", unit.per)
+	} else {
+		fmt.Fprintln(w, "NodePools from the most to the least efficient this session, by CPU utilization")
+	}
+	ctw := text.NewColorTabWriter(w, 0, 8, 2)
+	fmt.Fprint(ctw, "RANK\tNODEPOOL\tNODES\tCPU UTIL\tMEMORY UTIL")
+	if priced {
+		fmt.Fprint(ctw, "\tPER VCPU\tSESSION COST")
+	}
+	fmt.Fprintln(ctw)
+	for i, name := range names {
+		p := u.nodePools.Pools[name]
+		cells := []string{fmt.Sprintf("%d", i+1), name, fmt.Sprintf("%d", p.Nodes), utilizationCell(p, v1.ResourceCPU),
+			utilizationCell(p, v1.ResourceMemory)}
+		if priced {
+			perVCPU := "-"
+			if c, ok := p.CostPerVCPU(); ok {
+				perVCPU = u.nodePrice(c)
+			}
+			cells = append(cells, perVCPU, fmt.Sprintf("$%0.2f", p.Cost))
+		}
+		for j, cell := range cells {
+			switch {
+			case len(names) > 1 && i == 0:
+				cell = u.style.green(cell)
+			case len(names) > 1 && i == len(names)-1:
+				cell = u.style.red(cell)
+			}
+			if j > 0 {
+				fmt.Fprint(ctw, "\t")
+			}
+			fmt.Fprint(ctw, cell)
+		}
+		fmt.Fprintln(ctw)
+	}
+	ctw.Flush()
+}
+
+func utilizationCell(p *NodePoolUsage, rn v1.ResourceName) string {
+	util, ok := p.Utilization(rn)
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%0.0f%%", 100*util)
+}
@@ -0,0 +1,76 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestNodePoolUsages(t *testing.T) {
+	// a pool's node with the given price and vCPUs, half of which are requested
+	poolNode := func(name, pool string, price float64, vcpus string) *model.Node {
+		n := testNode(name)
+		n.Spec.ProviderID = name + "-id"
+		n.Labels = map[string]string{"karpenter.sh/nodepool": pool}
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse(vcpus)}
+		node := model.NewNode(n)
+		node.SetPrice(price)
+		p := testPod("default", name+"-pod")
+		p.Spec.InitContainers = nil
+		half := resource.MustParse(vcpus)
+		half.Set(half.Value() / 2)
+		p.Spec.Containers = []v1.Container{{Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: half},
+		}}}
+		node.BindPod(model.NewPod(p))
+		return node
+	}
+	nodes := []*model.Node{
+		poolNode("a", "general", 0.4, "4"),
+		poolNode("b", "gpu", 16, "48"),
+		poolNode("c", "spot", 0.1, "4"),
+		model.NewNode(testNode("unmanaged")),
+	}
+
+	var usages model.NodePoolUsages
+	start := time.Now()
+	usages.Accumulate(start, nodes)
+	if ranked := usages.Ranked(true); len(ranked) != 0 {
+		t.Errorf("expected nothing to be ranked after the first sample, got %v", ranked)
+	}
+	usages.Accumulate(start.Add(time.Hour), nodes)
+
+	general := usages.Pools["general"]
+	if cost, ok := general.CostPerVCPU(); !ok || math.Abs(cost-0.2) > 1e-9 {
+		t.Errorf("expected $0.20 per requested vCPU, got %f", cost)
+	}
+	if util, ok := general.Utilization(v1.ResourceCPU); !ok || math.Abs(util-0.5) > 1e-9 {
+		t.Errorf("expected 50%% CPU utilization, got %f", util)
+	}
+	if exp, got := []string{"spot", "general", "gpu"}, usages.Ranked(true); !slices.Equal(exp, got) {
+		t.Errorf("expected %v, got %v", exp, got)
+	}
+	if _, ok := usages.Pools[""]; ok {
+		t.Error("expected nodes without a NodePool to be left out")
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Note is a note attached to a node while investigating it
+type Note struct {
+	Text    string    `json:"text"`
+	Updated time.Time `json:"updated"`
+}
+
+// Notes are the notes attached to nodes by name. They're saved to a session file as they change so that an
+// investigation can continue across runs.
+type Notes struct {
+	mu    sync.RWMutex
+	path  string
+	notes map[string]Note
+}
+
+// DefaultNotesPath returns the session file in the user cache directory, or an empty string if there is no cache
+// directory
+func DefaultNotesPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "eks-node-viewer", "notes.json")
+}
+
+// LoadNotes loads the notes from a session file, a missing file has no notes. The notes are only kept in memory if
+// the path is empty.
+func LoadNotes(path string) (*Notes, error) {
+	n := &Notes{path: path, notes: map[string]Note{}}
+	if path == "" {
+		return n, nil
+	}
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return n, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(contents, &n.notes); err != nil {
+		return nil, fmt.Errorf("parsing %s, %w", path, err)
+	}
+	return n, nil
+}
+
+// Get returns the note attached to a node
+func (n *Notes) Get(node string) (Note, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	note, ok := n.notes[node]
+	return note, ok
+}
+
+// Len returns the number of nodes with a note
+func (n *Notes) Len() int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return len(n.notes)
+}
+
+// Set attaches a note to a node, replacing any existing note, and saves the session file. An empty note removes the
+// node's note.
+func (n *Notes) Set(node, text string, now time.Time) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if text == "" {
+		delete(n.notes, node)
+	} else {
+		n.notes[node] = Note{Text: text, Updated: now}
+	}
+	return n.save()
+}
+
+func (n *Notes) save() error {
+	if n.path == "" {
+		return nil
+	}
+	contents, err := json.MarshalIndent(n.notes, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(n.path), 0o700); err != nil {
+		return err
+	}
+	// write to a temporary file first so that the notes aren't lost if the viewer is interrupted while saving
+	tmp, err := os.CreateTemp(filepath.Dir(n.path), filepath.Base(n.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(contents); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), n.path)
+}
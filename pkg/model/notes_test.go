@@ -0,0 +1,114 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestNotes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.json")
+	notes, err := model.LoadNotes(path)
+	if err != nil {
+		t.Fatalf("loading missing notes, %s", err)
+	}
+	if notes.Len() != 0 {
+		t.Errorf("expected no notes, got %d", notes.Len())
+	}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := notes.Set("node-a", "suspected bad NIC", now); err != nil {
+		t.Fatalf("setting note, %s", err)
+	}
+	if err := notes.Set("node-b", "draining", now); err != nil {
+		t.Fatalf("setting note, %s", err)
+	}
+	if err := notes.Set("node-b", "", now); err != nil {
+		t.Fatalf("removing note, %s", err)
+	}
+
+	loaded, err := model.LoadNotes(path)
+	if err != nil {
+		t.Fatalf("loading notes, %s", err)
+	}
+	if loaded.Len() != 1 {
+		t.Errorf("expected 1 note, got %d", loaded.Len())
+	}
+	note, ok := loaded.Get("node-a")
+	if !ok || note.Text != "suspected bad NIC" || !note.Updated.Equal(now) {
+		t.Errorf("expected the note to be saved, got %v", note)
+	}
+	if _, ok := loaded.Get("node-b"); ok {
+		t.Errorf("expected the empty note to be removed")
+	}
+}
+
+func TestNodeNotes(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "notes.json")
+	ui := model.NewUIModel(nil, "creation", style)
+	ui.SetResources([]string{"cpu"})
+	if ui.Notes, err = model.LoadNotes(path); err != nil {
+		t.Fatalf("loading notes, %s", err)
+	}
+	n := testNode("node-0")
+	n.Spec.ProviderID = "node-0-id"
+	node := model.NewNode(n)
+	node.Show()
+	ui.Cluster().AddNode(node)
+
+	ui.Update(tea.WindowSizeMsg{Height: 40})
+	ui.View()
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("bad")})
+	ui.Update(tea.KeyMsg{Type: tea.KeySpace})
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("NIC")})
+	if view := ui.View(); !strings.Contains(view, "note for node-0: bad NIC") {
+		t.Errorf("expected the note to be edited, got\n%s", view)
+	}
+	ui.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	view := ui.View()
+	for _, exp := range []string{"#node-0", "note: bad NIC"} {
+		if !strings.Contains(view, exp) {
+			t.Errorf("expected the view to contain %q, got\n%s", exp, view)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ui.WriteCSV(&buf); err != nil {
+		t.Fatalf("writing csv, %s", err)
+	}
+	if got := buf.String(); !strings.Contains(got, ",note\n") || !strings.Contains(got, ",bad NIC\n") {
+		t.Errorf("expected the note to be exported, got\n%s", got)
+	}
+
+	loaded, err := model.LoadNotes(path)
+	if err != nil {
+		t.Fatalf("loading notes, %s", err)
+	}
+	if note, ok := loaded.Get("node-0"); !ok || note.Text != "bad NIC" {
+		t.Errorf("expected the note to be saved to the session file, got %v", note)
+	}
+}
@@ -15,9 +15,10 @@ limitations under the License.
 package model
 
 import (
-	"log"
+	"log/slog"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 
 	v1 "k8s.io/api/core/v1"
@@ -28,6 +29,8 @@ import (
 type Pod struct {
 	mu  sync.RWMutex
 	pod v1.Pod
+	// excluded is true if the pod's namespace is excluded from the usage of its node
+	excluded bool
 }
 
 // NewPod constructs a pod model based off of the K8s pod object
@@ -72,6 +75,37 @@ func (p *Pod) Name() string {
 	return p.pod.Name
 }
 
+// WorkloadName returns the namespace and name of the pod without the random suffix added by its controller, so that
+// pods of the same workload have the same name
+func (p *Pod) WorkloadName() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.pod.GenerateName != "" {
+		return p.pod.Namespace + "/" + strings.TrimSuffix(p.pod.GenerateName, "-")
+	}
+	return p.pod.Namespace + "/" + p.pod.Name
+}
+
+// DoNotDisrupt returns true if the pod has the karpenter.sh/do-not-disrupt annotation, which blocks Karpenter from
+// voluntarily disrupting its node
+func (p *Pod) DoNotDisrupt() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.pod.Annotations[DoNotDisruptAnnotation] == "true"
+}
+
+// IsDaemonSetPod returns true if the pod is managed by a daemonset
+func (p *Pod) IsDaemonSetPod() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, ref := range p.pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
 // Phase returns the pod phase
 func (p *Pod) Phase() v1.PodPhase {
 	p.mu.RLock()
@@ -79,6 +113,47 @@ func (p *Pod) Phase() v1.PodPhase {
 	return p.pod.Status.Phase
 }
 
+// IsCrashLooping returns true if any of the pod's containers are waiting to be restarted after repeatedly crashing
+func (p *Pod) IsCrashLooping() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, statuses := range [][]v1.ContainerStatus{p.pod.Status.InitContainerStatuses, p.pod.Status.ContainerStatuses} {
+		for _, cs := range statuses {
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PendingReason returns why a pending pod isn't running yet. For pods that haven't been scheduled, this is the reason
+// and message of the PodScheduled condition set by the scheduler. For pods that have been scheduled, it's the reason
+// the first waiting container is waiting, e.g. ContainerCreating or ImagePullBackOff.
+func (p *Pod) PendingReason() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.pod.Spec.NodeName == "" {
+		for _, c := range p.pod.Status.Conditions {
+			if c.Type == v1.PodScheduled && c.Status == v1.ConditionFalse {
+				if c.Message == "" {
+					return c.Reason
+				}
+				return c.Reason + ": " + c.Message
+			}
+		}
+		return "waiting to be scheduled"
+	}
+	for _, statuses := range [][]v1.ContainerStatus{p.pod.Status.InitContainerStatuses, p.pod.Status.ContainerStatuses} {
+		for _, cs := range statuses {
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+				return cs.State.Waiting.Reason
+			}
+		}
+	}
+	return "waiting to start"
+}
+
 // Requested returns the sum of the resources requested by the pod.
 // Also include resources for init containers that are sidecars as described in
 // https://kubernetes.io/blog/2023/08/25/native-sidecar-containers .
@@ -104,30 +179,76 @@ func (p *Pod) Requested() v1.ResourceList {
 		}
 	}
 	requested[v1.ResourcePods] = resource.MustParse("1")
+	addGPUMemory(requested)
 	return requested
 }
 
+// Limits returns the sum of the resource limits of the pod's containers, including sidecar init containers. Like
+// kubectl describe node, containers without a limit for a resource don't add to it.
+func (p *Pod) Limits() v1.ResourceList {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	limits := v1.ResourceList{}
+	for _, c := range p.pod.Spec.InitContainers {
+		if c.RestartPolicy == nil || *c.RestartPolicy != v1.ContainerRestartPolicyAlways {
+			continue
+		}
+		for rn, q := range c.Resources.Limits {
+			existing := limits[rn]
+			existing.Add(q)
+			limits[rn] = existing
+		}
+	}
+	for _, c := range p.pod.Spec.Containers {
+		for rn, q := range c.Resources.Limits {
+			existing := limits[rn]
+			existing.Add(q)
+			limits[rn] = existing
+		}
+	}
+	addGPUMemory(limits)
+	return limits
+}
+
 var fargateCapacityRe = regexp.MustCompile("(.*?)vCPU (.*?)GB")
 
-func (p *Pod) FargateCapacityProvisioned() (float64, float64, bool) {
+// FargateCapacityProvisioned returns the capacity that Fargate provisioned for the pod from its CapacityProvisioned
+// annotation, e.g. "0.25vCPU 0.5GB"
+func (p *Pod) FargateCapacityProvisioned() (FargateCapacity, bool) {
+	p.mu.RLock()
 	provisioned, ok := p.pod.Annotations["CapacityProvisioned"]
+	p.mu.RUnlock()
 	if !ok {
-		return 0, 0, false
+		return FargateCapacity{}, false
 	}
 
 	match := fargateCapacityRe.FindStringSubmatch(provisioned)
 	if len(match) != 3 {
-		log.Printf("unable to parse %q for fargate provisioner capacity", provisioned)
+		slog.Warn("unable to parse fargate provisioner capacity", "capacity", provisioned)
+		return FargateCapacity{}, false
 	}
 	cpu, err := strconv.ParseFloat(match[1], 64)
 	if err != nil {
-		log.Printf("unable to parse CPU from fargate capacity, %q, %s", provisioned, err)
-		return 0, 0, false
+		slog.Warn("unable to parse CPU from fargate capacity", "capacity", provisioned, "error", err)
+		return FargateCapacity{}, false
 	}
 	mem, err := strconv.ParseFloat(match[2], 64)
 	if err != nil {
-		log.Printf("unable to parse memory from fargate capacity, %q, %s", provisioned, err)
-		return 0, 0, false
+		slog.Warn("unable to parse memory from fargate capacity", "capacity", provisioned, "error", err)
+		return FargateCapacity{}, false
+	}
+	return FargateCapacity{
+		CPU:              cpu,
+		Memory:           mem,
+		EphemeralStorage: fargateEphemeralStorage(p.Requested()),
+	}, true
+}
+
+// FargateCapacity returns the capacity that Fargate provisioned for the pod, estimating it from the pod's requests if
+// the CapacityProvisioned annotation is missing or can't be parsed
+func (p *Pod) FargateCapacity() FargateCapacity {
+	if capacity, ok := p.FargateCapacityProvisioned(); ok {
+		return capacity
 	}
-	return cpu, mem, true
+	return estimateFargateCapacity(p.Requested())
 }
@@ -121,14 +121,110 @@ func TestFargateCapacity(t *testing.T) {
 		"CapacityProvisioned": "0.25vCPU 0.5GB",
 	}
 	p := model.NewPod(tp)
-	cpu, mem, ok := p.FargateCapacityProvisioned()
+	capacity, ok := p.FargateCapacityProvisioned()
 	if !ok {
 		t.Errorf("expected to have a fargate capacity")
 	}
-	if cpu != 0.25 {
-		t.Errorf("expected to have a cpu capacity of 0.25, got %f", cpu)
+	if capacity.CPU != 0.25 {
+		t.Errorf("expected to have a cpu capacity of 0.25, got %f", capacity.CPU)
 	}
-	if mem != 0.5 {
-		t.Errorf("expected to have a mem capacity of 0.5, got %f", mem)
+	if capacity.Memory != 0.5 {
+		t.Errorf("expected to have a mem capacity of 0.5, got %f", capacity.Memory)
+	}
+	if capacity.EphemeralStorage != 20 {
+		t.Errorf("expected to have a storage capacity of 20, got %f", capacity.EphemeralStorage)
+	}
+	if capacity.Estimated {
+		t.Errorf("expected the capacity to not be estimated")
+	}
+}
+
+func TestFargateCapacityEstimate(t *testing.T) {
+	for _, tc := range []struct {
+		annotation string
+		requests   v1.ResourceList
+		exp        string
+		billable   float64
+	}{
+		{
+			annotation: "",
+			requests:   v1.ResourceList{},
+			exp:        "~0.25vCPU-0.5GB",
+		},
+		{
+			annotation: "garbage",
+			requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("300m"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+			exp: "~0.5vCPU-2GB",
+		},
+		{
+			annotation: "?vCPU 1GB",
+			requests: v1.ResourceList{
+				v1.ResourceCPU:              resource.MustParse("2"),
+				v1.ResourceMemory:           resource.MustParse("3Gi"),
+				v1.ResourceEphemeralStorage: resource.MustParse("30Gi"),
+			},
+			exp:      "~2vCPU-4GB-30GB",
+			billable: 10,
+		},
+		{
+			annotation: "",
+			requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("6"),
+				v1.ResourceMemory: resource.MustParse("40Gi"),
+			},
+			exp: "~8vCPU-44GB",
+		},
+	} {
+		tp := testPod("default", "mypod")
+		if tc.annotation != "" {
+			tp.Annotations = map[string]string{"CapacityProvisioned": tc.annotation}
+		}
+		tp.Spec.InitContainers = nil
+		tp.Spec.Containers = []v1.Container{{Resources: v1.ResourceRequirements{Requests: tc.requests}}}
+		p := model.NewPod(tp)
+		if _, ok := p.FargateCapacityProvisioned(); ok {
+			t.Errorf("expected %q to not be parsed", tc.annotation)
+		}
+		capacity := p.FargateCapacity()
+		if got := capacity.String(); got != tc.exp {
+			t.Errorf("expected capacity of %s, got %s", tc.exp, got)
+		}
+		if got := capacity.BillableEphemeralStorage(); got != tc.billable {
+			t.Errorf("expected %g GB of billable storage, got %g", tc.billable, got)
+		}
+	}
+}
+
+func TestPodPendingReason(t *testing.T) {
+	unschedulable := testPod("default", "unschedulable")
+	unschedulable.Status.Conditions = []v1.PodCondition{
+		{
+			Type:    v1.PodScheduled,
+			Status:  v1.ConditionFalse,
+			Reason:  v1.PodReasonUnschedulable,
+			Message: "0/3 nodes are available: 3 Insufficient cpu.",
+		},
+	}
+	if exp, got := "Unschedulable: 0/3 nodes are available: 3 Insufficient cpu.", model.NewPod(unschedulable).PendingReason(); got != exp {
+		t.Errorf("expected reason %q, got %q", exp, got)
+	}
+
+	creating := testPod("default", "creating")
+	creating.Spec.NodeName = "mynode"
+	creating.Status.ContainerStatuses = []v1.ContainerStatus{
+		{
+			Name:  "container",
+			State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+		},
+	}
+	if exp, got := "ImagePullBackOff", model.NewPod(creating).PendingReason(); got != exp {
+		t.Errorf("expected reason %q, got %q", exp, got)
+	}
+
+	if exp, got := "waiting to be scheduled", model.NewPod(testPod("default", "new")).PendingReason(); got != exp {
+		t.Errorf("expected reason %q, got %q", exp, got)
 	}
 }
@@ -0,0 +1,88 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// priceUnit is a unit of time that prices are displayed in
+type priceUnit struct {
+	name  string
+	per   string
+	hours float64
+}
+
+// priceUnits are the units that prices can be displayed in, pressing '$' cycles through them in order
+var priceUnits = []priceUnit{
+	{name: "hourly", per: "hour", hours: 1},
+	{name: "daily", per: "day", hours: 24},
+	{name: "monthly", per: "month", hours: 365 * 24 / 12}, // average hours per month
+	{name: "yearly", per: "year", hours: 365 * 24},
+}
+
+// PriceUnitNames returns the names of the units that prices can be displayed in
+func PriceUnitNames() []string {
+	var names []string
+	for _, p := range priceUnits {
+		names = append(names, p.name)
+	}
+	return names
+}
+
+// SetPriceUnit sets the unit of time that node prices and the cost of the clusters are displayed in, one of hourly,
+// daily, monthly or yearly
+func (u *UIModel) SetPriceUnit(name string) error {
+	for i, p := range priceUnits {
+		if p.name == name {
+			u.priceUnit = i
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown price unit %q, expected one of %s", name, strings.Join(PriceUnitNames(), ", "))
+}
+
+// nextPriceUnit cycles the unit that prices are displayed in
+func (u *UIModel) nextPriceUnit() {
+	u.priceUnit = (u.priceUnit + 1) % len(priceUnits)
+}
+
+// nodePrice formats the hourly price of a node in the displayed unit, hourly prices are fractions of a cent so they
+// keep more digits
+func (u *UIModel) nodePrice(price float64) string {
+	unit := priceUnits[u.priceUnit]
+	if unit.hours == 1 {
+		return fmt.Sprintf("$%0.4f", price)
+	}
+	return message.NewPrinter(language.English).Sprintf("$%0.2f", price*unit.hours)
+}
+
+// cost formats an hourly cost, e.g. of a cluster or zone, in the unit, e.g. "$1.234/hour"
+func cost(price float64, unit priceUnit) string {
+	return message.NewPrinter(language.English).Sprintf("$%0.3f/%s", price*unit.hours, unit.per)
+}
+
+// clusterCost formats the hourly cost of a cluster in the displayed unit, the hourly cost is followed by the monthly
+// cost as that's what budgets are usually set in
+func (u *UIModel) clusterCost(price float64) string {
+	if u.priceUnit == 0 {
+		return cost(price, priceUnits[0]) + " | " + cost(price, priceUnits[2])
+	}
+	return cost(price, priceUnits[u.priceUnit])
+}
@@ -0,0 +1,65 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestPriceUnits(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	n := testNode("mynode")
+	n.Spec.ProviderID = "aws:///us-west-2a/i-1234"
+	node := model.NewNode(n)
+	node.Show()
+	node.SetPrice(0.1)
+	ui.Cluster().AddNode(node)
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+
+	if view := ui.View(); !strings.Contains(view, "$0.100/hour | $73.000/month") || !strings.Contains(view, "$0.1000") {
+		t.Errorf("expected hourly prices, got\n%s", view)
+	}
+
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("$")})
+	view := ui.View()
+	if !strings.Contains(view, "$2.400/day") || strings.Contains(view, "/hour") || !strings.Contains(view, "$2.40 ") {
+		t.Errorf("expected daily prices, got\n%s", view)
+	}
+	if !strings.Contains(view, "prices per day") {
+		t.Errorf("expected the price unit in the help, got\n%s", view)
+	}
+
+	if err := ui.SetPriceUnit("yearly"); err != nil {
+		t.Fatalf("setting price unit, %s", err)
+	}
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	if view := ui.View(); !strings.Contains(view, "$876.000/year") || !strings.Contains(view, "$876.00 ") {
+		t.Errorf("expected yearly prices, got\n%s", view)
+	}
+
+	if err := ui.SetPriceUnit("weekly"); err == nil {
+		t.Errorf("expected an error for an unknown price unit")
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// identity returns the fields of the node that it's keyed by in the cluster
+func (n *Node) identity() (providerID string, uid types.UID, name string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.node.Spec.ProviderID, n.node.UID, n.node.Name
+}
+
+// MissingProviderID returns true if the node doesn't have a provider ID, which is usually a self-managed node whose
+// kubelet wasn't started with a cloud provider. It can't be priced and is keyed by its UID instead.
+func (n *Node) MissingProviderID() bool {
+	providerID, _, _ := n.identity()
+	return providerID == ""
+}
+
+// DuplicateProviderID returns true if another node in the cluster has the same provider ID, which happens when nodes
+// are registered with a hard-coded or copied provider ID
+func (n *Node) DuplicateProviderID() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.duplicateProviderID
+}
+
+func (n *Node) setDuplicateProviderID(duplicate bool) {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.duplicateProviderID = duplicate
+}
+
+// NodeKey returns the key that the node is stored under, for looking it up with GetNode and deleting it with
+// DeleteNode. It's the node's provider ID unless that's missing or another node already has it.
+func (c *Cluster) NodeKey(node *v1.Node) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.nodeKey(node.Spec.ProviderID, node.UID, node.Name)
+}
+
+// HasNode returns true if the node is in the cluster, rather than having been deleted or replaced
+func (c *Cluster) HasNode(n *Node) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.nodes[c.nodeKey(n.identity())] == n
+}
+
+// nodeKey returns the key that a node is stored under, it must be called with the lock held. Nodes are keyed by their
+// provider ID so that a NodeClaim and its node share an entry, but nodes without one or with the provider ID of a
+// different node are keyed by their UID so that they don't overwrite each other. The UID is only empty for nodes built
+// from NodeClaims, which always have a provider ID, and in tests where the name is used instead.
+func (c *Cluster) nodeKey(providerID string, uid types.UID, name string) string {
+	fallback := "uid://" + string(uid)
+	if uid == "" {
+		fallback = "name://" + name
+	}
+	if _, ok := c.nodes[fallback]; ok || providerID == "" {
+		return fallback
+	}
+	if existing, ok := c.nodes[providerID]; ok && uid != "" {
+		if _, existingUID, _ := existing.identity(); existingUID != "" && existingUID != uid {
+			return fallback
+		}
+	}
+	return providerID
+}
+
+// clearDuplicateProviderID clears the flag of the last node left with a provider ID once the others are deleted, it
+// must be called with the lock held
+func (c *Cluster) clearDuplicateProviderID(providerID string) {
+	var remaining []*Node
+	for _, n := range c.nodes {
+		if id, _, _ := n.identity(); id == providerID {
+			remaining = append(remaining, n)
+		}
+	}
+	if len(remaining) == 1 {
+		remaining[0].setDuplicateProviderID(false)
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// byteUnits are the binary units that byte quantities are displayed in
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+
+// byteResource returns true if the quantities of the resource are bytes
+func byteResource(res v1.ResourceName) bool {
+	return res == v1.ResourceMemory || res == v1.ResourceEphemeralStorage || res == ResourceGPUMemory ||
+		strings.HasPrefix(string(res), v1.ResourceHugePagesPrefix)
+}
+
+// byteUnit returns the largest binary unit that is no larger than the bytes, along with its size in bytes
+func byteUnit(bytes float64) (string, float64) {
+	unit, size := byteUnits[0], 1.0
+	for _, u := range byteUnits[1:] {
+		if bytes < size*1024 {
+			break
+		}
+		unit, size = u, size*1024
+	}
+	return unit, size
+}
+
+// humanQuantity formats a quantity of a resource for display, byte quantities are displayed in binary units, e.g.
+// 15.5 GiB rather than 16252928Ki
+func humanQuantity(res v1.ResourceName, q resource.Quantity) string {
+	if !byteResource(res) {
+		return q.String()
+	}
+	unit, size := byteUnit(q.AsApproximateFloat64())
+	return fmt.Sprintf("%0.1f %s", q.AsApproximateFloat64()/size, unit)
+}
+
+// humanUsage formats the used and allocatable quantities of a resource for display, byte quantities are displayed in
+// the binary unit of the allocatable quantity, e.g. 12.3/15.5 GiB
+func humanUsage(res v1.ResourceName, used, allocatable resource.Quantity) string {
+	if !byteResource(res) {
+		return fmt.Sprintf("%s/%s", used.String(), allocatable.String())
+	}
+	unit, size := byteUnit(allocatable.AsApproximateFloat64())
+	return fmt.Sprintf("%0.1f/%0.1f %s", used.AsApproximateFloat64()/size, allocatable.AsApproximateFloat64()/size, unit)
+}
@@ -0,0 +1,91 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"strings"
+)
+
+// quickFilter is a filter of the nodes that's toggled with a single key, so that the problem subset of the nodes can be
+// kept in view during an incident
+type quickFilter struct {
+	key  string
+	name string
+	// capacityType filters are alternatives, nodes of any of the toggled capacity types are displayed
+	capacityType bool
+	match        func(n *Node) bool
+}
+
+var quickFilters = []quickFilter{
+	{key: "1", name: "spot", capacityType: true, match: (*Node).IsSpot},
+	{key: "2", name: "on-demand", capacityType: true, match: (*Node).IsOnDemand},
+	{key: "3", name: "fargate", capacityType: true, match: (*Node).IsFargate},
+	{key: "4", name: "not ready/cordoned", match: func(n *Node) bool { return !n.Ready() || n.Cordoned() }},
+}
+
+// toggleQuickFilter toggles the quick filter bound to the key, returning false if there isn't one
+func (u *UIModel) toggleQuickFilter(key string) bool {
+	for _, f := range quickFilters {
+		if f.key != key {
+			continue
+		}
+		if u.quickFilters == nil {
+			u.quickFilters = map[string]bool{}
+		}
+		if u.quickFilters[f.name] {
+			delete(u.quickFilters, f.name)
+		} else {
+			u.quickFilters[f.name] = true
+		}
+		u.paginator.Page = 0
+		u.cursor = 0
+		return true
+	}
+	return false
+}
+
+// quickFiltering returns true if any of the quick filters are on
+func (u *UIModel) quickFiltering() bool {
+	return len(u.quickFilters) > 0
+}
+
+// matchesQuickFilters returns true if the node is one of the toggled capacity types, or any capacity type if none are
+// toggled, and matches the other toggled filters
+func (u *UIModel) matchesQuickFilters(n *Node) bool {
+	capacityFiltered, capacityMatched := false, false
+	for _, f := range quickFilters {
+		if !u.quickFilters[f.name] {
+			continue
+		}
+		if f.capacityType {
+			capacityFiltered = true
+			capacityMatched = capacityMatched || f.match(n)
+		} else if !f.match(n) {
+			return false
+		}
+	}
+	return !capacityFiltered || capacityMatched
+}
+
+// activeQuickFilters returns the names of the toggled quick filters for the help line, e.g. "spot, on-demand"
+func (u *UIModel) activeQuickFilters() string {
+	var active []string
+	for _, f := range quickFilters {
+		if u.quickFilters[f.name] {
+			active = append(active, f.name)
+		}
+	}
+	return strings.Join(active, ", ")
+}
@@ -0,0 +1,104 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestQuickFilters(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	for i, tc := range []struct {
+		labels map[string]string
+		ready  bool
+	}{
+		{map[string]string{"karpenter.sh/capacity-type": "spot"}, true},
+		{map[string]string{"karpenter.sh/capacity-type": "spot"}, false},
+		{map[string]string{"karpenter.sh/capacity-type": "on-demand"}, true},
+		{map[string]string{"eks.amazonaws.com/compute-type": "fargate"}, true},
+	} {
+		n := testNode(fmt.Sprintf("node-%d", i))
+		n.Spec.ProviderID = fmt.Sprintf("node-%d-id", i)
+		n.Labels = tc.labels
+		status := v1.ConditionFalse
+		if tc.ready {
+			status = v1.ConditionTrue
+		}
+		n.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: status}}
+		node := model.NewNode(n)
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+
+	press := func(key string) {
+		ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+	expectNodes := func(exp ...string) {
+		t.Helper()
+		view := ui.View()
+		for i := range 4 {
+			name := fmt.Sprintf("node-%d", i)
+			shown := false
+			for _, e := range exp {
+				shown = shown || e == name
+			}
+			if strings.Contains(view, name) != shown {
+				t.Errorf("expected %s to be shown %v, got\n%s", name, shown, view)
+			}
+		}
+	}
+
+	press("1")
+	expectNodes("node-0", "node-1")
+	if view := ui.View(); !strings.Contains(view, "only spot •") {
+		t.Errorf("expected the active filter in the help line, got\n%s", view)
+	}
+	// capacity types are alternatives
+	press("3")
+	expectNodes("node-0", "node-1", "node-3")
+	// other filters narrow the capacity types
+	press("4")
+	expectNodes("node-1")
+	if view := ui.View(); !strings.Contains(view, "only spot, fargate, not ready/cordoned •") {
+		t.Errorf("expected the active filters in the help line, got\n%s", view)
+	}
+	// toggling a filter again removes it
+	press("1")
+	press("3")
+	expectNodes("node-1")
+	press("2")
+	if view := ui.View(); !strings.Contains(view, "No nodes match the filter") {
+		t.Errorf("expected no not ready on-demand nodes to match, got\n%s", view)
+	}
+
+	// escape clears the filters rather than quitting
+	if _, cmd := ui.Update(tea.KeyMsg{Type: tea.KeyEsc}); cmd != nil {
+		t.Errorf("expected escape to clear the filters")
+	}
+	expectNodes("node-0", "node-1", "node-2", "node-3")
+}
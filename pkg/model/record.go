@@ -0,0 +1,289 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Frame is the state of the displayed clusters at a point in time. Frames are recorded with --record as JSON lines and
+// played back with --replay.
+type Frame struct {
+	Time     time.Time      `json:"time"`
+	Clusters []ClusterFrame `json:"clusters"`
+}
+
+// ClusterFrame is the state of a single cluster in a frame
+type ClusterFrame struct {
+	Name  string      `json:"name,omitempty"`
+	Nodes []NodeFrame `json:"nodes"`
+	Pods  []v1.Pod    `json:"pods"`
+}
+
+// NodeFrame is a node along with the state that is computed by eks-node-viewer rather than read from the node
+type NodeFrame struct {
+	Node v1.Node `json:"node"`
+	// Price is omitted when the price is unknown as NaN can't be encoded as JSON
+	Price               *float64             `json:"price,omitempty"`
+	PriceSource         string               `json:"priceSource,omitempty"`
+	InstanceLifecycle   string               `json:"instanceLifecycle,omitempty"`
+	InstanceTenancy     string               `json:"instanceTenancy,omitempty"`
+	CPUCreditBalance    *float64             `json:"cpuCreditBalance,omitempty"`
+	InstanceTypeInfo    *InstanceTypeInfo    `json:"instanceTypeInfo,omitempty"`
+	ActualUsage         v1.ResourceList      `json:"actualUsage,omitempty"`
+	InterruptionTime    *metav1.Time         `json:"interruptionTime,omitempty"`
+	RebalanceTime       *metav1.Time         `json:"rebalanceTime,omitempty"`
+	NodeClaimCreated    *metav1.Time         `json:"nodeClaimCreated,omitempty"`
+	NodeClaimConditions []NodeClaimCondition `json:"nodeClaimConditions,omitempty"`
+	DisruptionBlocked   string               `json:"disruptionBlocked,omitempty"`
+	OnDemandPrice       float64              `json:"onDemandPrice,omitempty"`
+}
+
+// NodeClaimCondition is a recorded status condition of a NodeClaim
+type NodeClaimCondition struct {
+	Type   string                 `json:"type"`
+	Status metav1.ConditionStatus `json:"status"`
+	Reason string                 `json:"reason,omitempty"`
+}
+
+// Frame returns the current state of the clusters
+func (u *UIModel) Frame(now time.Time) Frame {
+	f := Frame{Time: now}
+	for _, c := range u.clusters {
+		f.Clusters = append(f.Clusters, c.frame())
+	}
+	return f
+}
+
+func (c *Cluster) frame() ClusterFrame {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	cf := ClusterFrame{Name: c.name, Nodes: []NodeFrame{}, Pods: []v1.Pod{}}
+	for _, n := range c.nodes {
+		if n.Visible() {
+			cf.Nodes = append(cf.Nodes, n.frame())
+		}
+	}
+	for _, p := range c.pods {
+		p.mu.RLock()
+		pod := *p.pod.DeepCopy()
+		p.mu.RUnlock()
+		// managed fields are large and aren't displayed
+		pod.ManagedFields = nil
+		cf.Pods = append(cf.Pods, pod)
+	}
+	// sorted so that recordings of an unchanged cluster are identical
+	sort.Slice(cf.Nodes, func(a, b int) bool { return cf.Nodes[a].Node.Spec.ProviderID < cf.Nodes[b].Node.Spec.ProviderID })
+	sort.Slice(cf.Pods, func(a, b int) bool {
+		if cf.Pods[a].Namespace != cf.Pods[b].Namespace {
+			return cf.Pods[a].Namespace < cf.Pods[b].Namespace
+		}
+		return cf.Pods[a].Name < cf.Pods[b].Name
+	})
+	return cf
+}
+
+func (n *Node) frame() NodeFrame {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	nf := NodeFrame{
+		Node:              *n.node.DeepCopy(),
+		PriceSource:       n.priceSource,
+		InstanceLifecycle: n.instanceLifecycle,
+		InstanceTenancy:   n.instanceTenancy,
+		ActualUsage:       n.actualUsage,
+		DisruptionBlocked: n.disruptionBlocked,
+		OnDemandPrice:     n.onDemandPrice,
+	}
+	nf.Node.ManagedFields = nil
+	// the lock is already held, so the price is read directly rather than with HasPrice
+	if n.price == n.price {
+		price := n.price
+		nf.Price = &price
+	}
+	if n.hasCPUCredits {
+		credits := n.cpuCredits
+		nf.CPUCreditBalance = &credits
+	}
+	if n.instanceTypeInfo != nil {
+		info := *n.instanceTypeInfo
+		nf.InstanceTypeInfo = &info
+	}
+	if !n.interruptionTime.IsZero() {
+		nf.InterruptionTime = &metav1.Time{Time: n.interruptionTime}
+	}
+	if !n.rebalanceTime.IsZero() {
+		nf.RebalanceTime = &metav1.Time{Time: n.rebalanceTime}
+	}
+	if !n.nodeclaimCreationTime.IsZero() {
+		nf.NodeClaimCreated = &metav1.Time{Time: n.nodeclaimCreationTime}
+	}
+	for conditionType, c := range n.nodeClaimConditions {
+		nf.NodeClaimConditions = append(nf.NodeClaimConditions, NodeClaimCondition{Type: conditionType, Status: c.status, Reason: c.reason})
+	}
+	sort.Slice(nf.NodeClaimConditions, func(a, b int) bool { return nf.NodeClaimConditions[a].Type < nf.NodeClaimConditions[b].Type })
+	return nf
+}
+
+// newClusterFromFrame returns a cluster with the nodes and pods of the frame
+func newClusterFromFrame(cf ClusterFrame, resources []v1.ResourceName) *Cluster {
+	c := NewCluster()
+	c.name = cf.Name
+	c.resources = resources
+	for i := range cf.Nodes {
+		nf := &cf.Nodes[i]
+		n := NewNode(&nf.Node)
+		n.price = math.NaN()
+		if nf.Price != nil {
+			n.price = *nf.Price
+		}
+		n.priceSource = nf.PriceSource
+		n.instanceLifecycle = nf.InstanceLifecycle
+		n.instanceTenancy = nf.InstanceTenancy
+		n.actualUsage = nf.ActualUsage
+		n.disruptionBlocked = nf.DisruptionBlocked
+		n.onDemandPrice = nf.OnDemandPrice
+		if nf.CPUCreditBalance != nil {
+			n.cpuCredits, n.hasCPUCredits = *nf.CPUCreditBalance, true
+		}
+		n.instanceTypeInfo = nf.InstanceTypeInfo
+		if nf.InterruptionTime != nil {
+			n.interruptionTime = nf.InterruptionTime.Time
+		}
+		if nf.RebalanceTime != nil {
+			n.rebalanceTime = nf.RebalanceTime.Time
+		}
+		if nf.NodeClaimCreated != nil {
+			n.nodeclaimCreationTime = nf.NodeClaimCreated.Time
+		}
+		if len(nf.NodeClaimConditions) > 0 {
+			n.nodeClaimConditions = map[string]nodeClaimCondition{}
+			for _, cond := range nf.NodeClaimConditions {
+				n.nodeClaimConditions[cond.Type] = nodeClaimCondition{status: cond.Status, reason: cond.Reason}
+			}
+		}
+		n.Show()
+		c.AddNode(n)
+	}
+	for i := range cf.Pods {
+		c.AddPod(NewPod(&cf.Pods[i]))
+	}
+	return c
+}
+
+// WriteFrame appends a frame to a recording as a single line of JSON
+func WriteFrame(w io.Writer, f Frame) error {
+	return json.NewEncoder(w).Encode(f)
+}
+
+// ReadFrames reads the frames of a recording
+func ReadFrames(r io.Reader) ([]Frame, error) {
+	var frames []Frame
+	scanner := bufio.NewScanner(r)
+	// frames of large clusters are much longer than the default maximum line length
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var f Frame
+		if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			return nil, fmt.Errorf("decoding frame on line %d, %w", line, err)
+		}
+		frames = append(frames, f)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no frames found")
+	}
+	return frames, nil
+}
+
+// Replay displays the frames of a recording instead of the live clusters, starting with the first frame
+func (u *UIModel) Replay(frames []Frame) {
+	u.frames = frames
+	u.showFrame(0)
+}
+
+// showFrame replaces the displayed clusters with those of a frame of the recording being replayed
+func (u *UIModel) showFrame(i int) {
+	i = max(0, min(i, len(u.frames)-1))
+	resources := u.Cluster().resources
+	var clusters []*Cluster
+	for _, cf := range u.frames[i].Clusters {
+		clusters = append(clusters, newClusterFromFrame(cf, resources))
+	}
+	if len(clusters) == 0 {
+		clusters = append(clusters, newClusterFromFrame(ClusterFrame{}, resources))
+	}
+	u.clusters = clusters
+	u.frameIndex = i
+	u.dirty = true
+}
+
+// playFrames advances to the next frame once as much time has passed as passed between the frames when they were
+// recorded
+func (u *UIModel) playFrames(now time.Time) {
+	if u.frameIndex+1 >= len(u.frames) {
+		u.playing = false
+		return
+	}
+	if now.Sub(u.lastPlayed) >= u.frames[u.frameIndex+1].Time.Sub(u.frames[u.frameIndex].Time) {
+		u.showFrame(u.frameIndex + 1)
+		u.lastPlayed = now
+	}
+}
+
+// updateReplay handles the keys that move through the recording being replayed, returning false if the key isn't one
+// of them
+func (u *UIModel) updateReplay(key string) bool {
+	switch key {
+	case "[":
+		u.showFrame(u.frameIndex - 1)
+	case "]":
+		u.showFrame(u.frameIndex + 1)
+	case "{":
+		u.showFrame(u.frameIndex - replaySkipFrames)
+	case "}":
+		u.showFrame(u.frameIndex + replaySkipFrames)
+	case " ":
+		u.playing = !u.playing
+		u.lastPlayed = time.Now()
+	default:
+		return false
+	}
+	return true
+}
+
+// replayHelp describes the frame being replayed and the keys to move through the recording
+func (u *UIModel) replayHelp() string {
+	state := "paused"
+	if u.playing {
+		state = "playing"
+	}
+	return fmt.Sprintf("replaying %s (%d/%d, %s) • [/]: step • {/}: skip %d • space: play/pause • ",
+		u.frames[u.frameIndex].Time.Format(time.DateTime), u.frameIndex+1, len(u.frames), state, replaySkipFrames)
+}
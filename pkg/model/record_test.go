@@ -0,0 +1,95 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestRecordReplay(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "creation", style)
+	ui.SetResources([]string{"cpu"})
+	addNode := func(i int) {
+		n := testNode(fmt.Sprintf("node-%d", i))
+		n.Spec.ProviderID = fmt.Sprintf("node-%d-id", i)
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}
+		node := model.NewNode(n)
+		node.SetPrice(0.5)
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+
+	var recording bytes.Buffer
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	addNode(0)
+	pod := testPod("default", "web")
+	pod.Spec.NodeName = "node-0"
+	ui.Cluster().AddPod(model.NewPod(pod))
+	if err := model.WriteFrame(&recording, ui.Frame(start)); err != nil {
+		t.Fatalf("writing frame, %s", err)
+	}
+	addNode(1)
+	if err := model.WriteFrame(&recording, ui.Frame(start.Add(time.Minute))); err != nil {
+		t.Fatalf("writing frame, %s", err)
+	}
+
+	frames, err := model.ReadFrames(&recording)
+	if err != nil {
+		t.Fatalf("reading frames, %s", err)
+	}
+	if len(frames) != 2 || !frames[1].Time.Equal(start.Add(time.Minute)) {
+		t.Fatalf("expected 2 frames, got %d", len(frames))
+	}
+
+	replay := model.NewUIModel(nil, "creation", style)
+	replay.SetResources([]string{"cpu"})
+	replay.Replay(frames)
+	replay.Update(tea.WindowSizeMsg{Height: 40})
+	view := replay.View()
+	if !strings.Contains(view, "1 nodes") || !strings.Contains(view, "$0.500/hour") || !strings.Contains(view, "(1 pods)") {
+		t.Errorf("expected the first frame, got\n%s", view)
+	}
+	if !strings.Contains(view, "replaying 2024-01-02 03:04:05 (1/2, paused)") {
+		t.Errorf("expected the replay position, got\n%s", view)
+	}
+
+	replay.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")})
+	if view = replay.View(); !strings.Contains(view, "2 nodes") || !strings.Contains(view, "node-1") {
+		t.Errorf("expected the second frame, got\n%s", view)
+	}
+	// stepping is clamped to the recording
+	replay.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("}")})
+	if view = replay.View(); !strings.Contains(view, "(2/2, paused)") {
+		t.Errorf("expected to remain on the last frame, got\n%s", view)
+	}
+	replay.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("{")})
+	if view = replay.View(); !strings.Contains(view, "(1/2, paused)") {
+		t.Errorf("expected to skip back to the first frame, got\n%s", view)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+)
+
+// SetOnDemandPrice sets the on-demand price of the node's instance type, which spot nodes are compared against
+func (n *Node) SetOnDemandPrice(price float64) {
+	defer n.changed()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.onDemandPrice = price
+}
+
+// SpotSavings returns how much less a spot node costs per hour than the on-demand price of its instance type, and
+// the on-demand price it's compared against. It returns false for nodes that aren't spot or without both prices.
+func (n *Node) SpotSavings() (savings float64, onDemand float64, ok bool) {
+	n.mu.RLock()
+	onDemand = n.onDemandPrice
+	n.mu.RUnlock()
+	if !n.IsSpot() || !n.HasPrice() || !(onDemand > 0) {
+		return 0, 0, false
+	}
+	return onDemand - n.Price(), onDemand, true
+}
+
+// spotSavingsLabel returns the percentage that a spot node saves compared to on-demand, e.g. "62%", or "-"
+func spotSavingsLabel(n *Node) string {
+	savings, onDemand, ok := n.SpotSavings()
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%0.0f%%", 100*savings/onDemand)
+}
+
+// spotSavingsSummary summarizes how much the spot nodes save compared to running them on-demand, e.g.
+// "spot saving $1.234/hour (62%) vs on-demand", it's empty if there aren't any priced spot nodes
+func (u *UIModel) spotSavingsSummary(stats Stats) string {
+	if u.DisablePricing || !(stats.SpotOnDemandPrice > 0) {
+		return ""
+	}
+	return fmt.Sprintf("spot saving %s (%0.0f%%) vs on-demand", cost(stats.SpotSavings, priceUnits[u.priceUnit]),
+		100*stats.SpotSavings/stats.SpotOnDemandPrice)
+}
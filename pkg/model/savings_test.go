@@ -0,0 +1,72 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestSpotSavings(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	if err := ui.SetLayout([]string{"name", "price", "savings"}); err != nil {
+		t.Fatalf("setting layout, %s", err)
+	}
+
+	n := testNode("spot-node")
+	n.Spec.ProviderID = "spot-node-id"
+	n.Labels = map[string]string{"karpenter.sh/capacity-type": "spot"}
+	spot := model.NewNode(n)
+	spot.Show()
+	spot.SetPrice(0.25)
+	spot.SetOnDemandPrice(1)
+	ui.Cluster().AddNode(spot)
+
+	// on-demand nodes don't have savings
+	n = testNode("od-node")
+	n.Spec.ProviderID = "od-node-id"
+	n.Labels = map[string]string{"karpenter.sh/capacity-type": "on-demand"}
+	onDemand := model.NewNode(n)
+	onDemand.Show()
+	onDemand.SetPrice(1)
+	onDemand.SetOnDemandPrice(1)
+	ui.Cluster().AddNode(onDemand)
+
+	if exp, got := "75%", spot.ComputeLabel("eks-node-viewer/node-spot-savings"); exp != got {
+		t.Errorf("expected savings %s, got %s", exp, got)
+	}
+	if exp, got := "-", onDemand.ComputeLabel("eks-node-viewer/node-spot-savings"); exp != got {
+		t.Errorf("expected savings %s, got %s", exp, got)
+	}
+	stats := ui.Cluster().Stats()
+	if stats.SpotSavings != 0.75 || stats.SpotOnDemandPrice != 1 {
+		t.Errorf("expected $0.75 saved of $1, got %f of %f", stats.SpotSavings, stats.SpotOnDemandPrice)
+	}
+
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	view := ui.View()
+	if !strings.Contains(view, "spot saving $0.750/hour (75%) vs on-demand") || !strings.Contains(view, "SAVINGS") {
+		t.Errorf("expected the spot savings, got\n%s", view)
+	}
+}
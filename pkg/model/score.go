@@ -0,0 +1,141 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// ScoreWeights are the weights of the factors that make up a node's attention score, a triage aid that ranks the nodes
+// that are most likely to need attention first without choosing a single sort key
+type ScoreWeights struct {
+	// Utilization weighs how little of the node's allocatable resources are requested
+	Utilization float64
+	// Price weighs the node's price relative to the most expensive node
+	Price float64
+	// Age weighs the node's age relative to the oldest node
+	Age float64
+	// Status weighs whether the node has a problem, such as not being ready or having received an interruption notice
+	Status float64
+}
+
+// DefaultScoreWeights are the weights used when none are chosen, a node with a problem outranks an idle one
+var DefaultScoreWeights = ScoreWeights{Utilization: 1, Price: 1, Age: 1, Status: 2}
+
+// String formats the weights in the form accepted by ParseScoreWeights
+func (w ScoreWeights) String() string {
+	format := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	return fmt.Sprintf("utilization=%s,price=%s,age=%s,status=%s", format(w.Utilization), format(w.Price),
+		format(w.Age), format(w.Status))
+}
+
+// ParseScoreWeights parses a comma separated list of factor=weight pairs, e.g. utilization=1,status=3, factors that
+// aren't listed keep their default weight
+func ParseScoreWeights(s string) (ScoreWeights, error) {
+	w := DefaultScoreWeights
+	for _, pair := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' }) {
+		factor, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return w, fmt.Errorf("expected factor=weight, got %q", pair)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || weight < 0 {
+			return w, fmt.Errorf("invalid weight %q for %s, must be a non-negative number", value, factor)
+		}
+		switch strings.TrimSpace(factor) {
+		case "utilization":
+			w.Utilization = weight
+		case "price":
+			w.Price = weight
+		case "age":
+			w.Age = weight
+		case "status":
+			w.Status = weight
+		default:
+			return w, fmt.Errorf("unknown factor %q, must be one of utilization, price, age or status", factor)
+		}
+	}
+	if w.Utilization+w.Price+w.Age+w.Status == 0 {
+		return w, fmt.Errorf("at least one weight must be positive")
+	}
+	return w, nil
+}
+
+// scoreContext is what the scores of the nodes in a cluster are relative to, the most expensive and oldest of them
+type scoreContext struct {
+	weights   ScoreWeights
+	resources []v1.ResourceName
+	maxPrice  float64
+	oldest    time.Time
+}
+
+// score returns the attention score of the node from 0 to 100. Price and age are relative to the most expensive and
+// oldest of the nodes, and utilization is averaged over the resources.
+func (s *scoreContext) score(n *Node) float64 {
+	w := s.weights
+	score := w.Utilization * (1 - utilization(n, s.resources))
+	if s.maxPrice > 0 && n.HasPrice() {
+		score += w.Price * n.Price() / s.maxPrice
+	}
+	if maxAge := since(s.oldest).Seconds(); maxAge > 0 {
+		score += w.Age * since(n.Created()).Seconds() / maxAge
+	}
+	if hasProblem(n) {
+		score += w.Status
+	}
+	if total := w.Utilization + w.Price + w.Age + w.Status; total > 0 {
+		score = 100 * score / total
+	}
+	return score
+}
+
+// utilization returns the average fraction of the node's allocatable resources that are requested
+func utilization(n *Node, resources []v1.ResourceName) float64 {
+	allocatable, used := n.Allocatable(), n.Used()
+	sum, count := 0.0, 0
+	for _, res := range resources {
+		a, u := allocatable[res], used[res]
+		if a.IsZero() {
+			continue
+		}
+		sum += math.Min(u.AsApproximateFloat64()/a.AsApproximateFloat64(), 1)
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// hasProblem returns true if the node has a problem that needs attention
+func hasProblem(n *Node) bool {
+	return !n.Ready() || n.Interrupted() || n.RebalanceRecommended() || n.Cordoned() || n.Deleting() ||
+		n.UnhealthyPods() > 0 || n.CapacityTypeMismatch() || n.LowCPUCredits() || n.HealthSeverity() > 0
+}
+
+// Score returns the node's attention score from 0 to 100, relative to the other nodes of its cluster
+func (n *Node) Score() float64 {
+	c := n.cluster.Load()
+	if c == nil {
+		return 0
+	}
+	return c.scoring.Load().score(n)
+}
@@ -0,0 +1,97 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestParseScoreWeights(t *testing.T) {
+	w, err := model.ParseScoreWeights("price=3, age=0")
+	if err != nil {
+		t.Fatalf("parsing weights, %s", err)
+	}
+	if exp := (model.ScoreWeights{Utilization: 1, Price: 3, Age: 0, Status: 2}); w != exp {
+		t.Errorf("expected %v, got %v", exp, w)
+	}
+	if w, err := model.ParseScoreWeights(model.DefaultScoreWeights.String()); err != nil || w != model.DefaultScoreWeights {
+		t.Errorf("expected the default weights to round trip, got %v, %v", w, err)
+	}
+	for _, s := range []string{"price", "price=-1", "cost=1", "utilization=0,price=0,age=0,status=0"} {
+		if _, err := model.ParseScoreWeights(s); err == nil {
+			t.Errorf("expected an error parsing %q", s)
+		}
+	}
+}
+
+func TestScoreColumn(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "eks-node-viewer/node-score=dsc", style)
+	ui.SetResources([]string{"cpu"})
+	if err := ui.SetLayout([]string{"name", "score"}); err != nil {
+		t.Fatalf("setting layout, %s", err)
+	}
+	created := time.Now().Add(-time.Hour)
+	for name, price := range map[string]float64{"busy": 0.1, "idle": 0.1, "not-ready": 0.2} {
+		n := testNode(name)
+		n.Spec.ProviderID = name + "-id"
+		n.CreationTimestamp = metav1.NewTime(created)
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+		if name != "not-ready" {
+			n.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+		}
+		node := model.NewNode(n)
+		node.SetPrice(price)
+		node.Show()
+		ui.Cluster().AddNode(node)
+		if name == "busy" {
+			p := testPod("default", "busy")
+			p.Spec.NodeName = name
+			p.Spec.Containers = []v1.Container{{Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			}}}
+			ui.Cluster().AddPod(model.NewPod(p))
+		}
+	}
+	ui.Update(tea.WindowSizeMsg{Height: 40})
+
+	view := ui.View()
+	notReady, idle, busy := strings.Index(view, "not-ready"), strings.Index(view, "idle"), strings.Index(view, "busy")
+	if notReady < 0 || idle < 0 || busy < 0 || notReady > idle || idle > busy {
+		t.Errorf("expected the nodes in the order not-ready, idle, busy, got\n%s", view)
+	}
+	// the not ready node is the most expensive, the oldest and has a problem, but none of its CPU is requested
+	node, _ := ui.Cluster().GetNodeByName("not-ready")
+	if got := node.ComputeLabel("eks-node-viewer/node-score"); got != "100" {
+		t.Errorf("expected a score of 100, got %s", got)
+	}
+	// the busy node is fully requested and half the price of the most expensive node, but is as old as the oldest
+	node, _ = ui.Cluster().GetNodeByName("busy")
+	if got := node.ComputeLabel("eks-node-viewer/node-score"); got != "30" {
+		t.Errorf("expected a score of 30, got %s", got)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// maxSelectorStatusLen is the longest selector that's displayed in full after it's copied
+const maxSelectorStatusLen = 60
+
+// NodeSelector returns a label selector matching the nodes that pass the filter and selector so that they can be used
+// with kubectl, e.g. kubectl get nodes -l <selector>, along with the number of nodes. A filter that's a label selector
+// is returned as is, combined with any selector, otherwise the nodes are selected by their kubernetes.io/hostname label.
+func (u *UIModel) NodeSelector() (string, int, error) {
+	stats, _ := u.stats()
+	nodes := u.filterNodes(stats.Nodes)
+	// the quick filters can't be expressed as a label selector, so the nodes they match are selected by hostname
+	filter := strings.TrimSpace(u.filter)
+	if selector, ok := labelSelector(filter); ok && len(u.quickFilters) == 0 {
+		requirements, _ := selector.Requirements()
+		return u.selector.Add(requirements...).String(), len(nodes), nil
+	}
+	if filter == "" && !u.selector.Empty() && len(u.quickFilters) == 0 {
+		return u.selector.String(), len(nodes), nil
+	}
+	if len(nodes) == 0 {
+		return "", 0, fmt.Errorf("no nodes match the filter")
+	}
+	var hostnames []string
+	for _, n := range nodes {
+		hostname, ok := n.Label(v1.LabelHostname)
+		if !ok {
+			hostname = n.Name()
+		}
+		hostnames = append(hostnames, hostname)
+	}
+	sort.Strings(hostnames)
+	requirement, err := labels.NewRequirement(v1.LabelHostname, selection.In, hostnames)
+	if err != nil {
+		return "", 0, err
+	}
+	return labels.NewSelector().Add(*requirement).String(), len(nodes), nil
+}
+
+// copySelector copies the label selector of the filtered nodes to the clipboard, returning a status message for the
+// help line
+func (u *UIModel) copySelector() string {
+	selector, nodes, err := u.NodeSelector()
+	if err != nil {
+		return fmt.Sprintf("copying selector failed, %s", err)
+	}
+	if err := copyToClipboard(selector); err != nil {
+		return fmt.Sprintf("copying selector failed, %s", err)
+	}
+	if len(selector) > maxSelectorStatusLen {
+		selector = selector[:maxSelectorStatusLen] + "…"
+	}
+	return fmt.Sprintf("copied selector for %d nodes: %s", nodes, selector)
+}
@@ -0,0 +1,72 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestNodeSelector(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	for i, capacityType := range []string{"spot", "spot", "on-demand"} {
+		n := testNode(fmt.Sprintf("node-%d", i))
+		n.Spec.ProviderID = fmt.Sprintf("node-%d-id", i)
+		n.Labels = map[string]string{
+			v1.LabelHostname:             fmt.Sprintf("node-%d", i),
+			"karpenter.sh/capacity-type": capacityType,
+		}
+		node := model.NewNode(n)
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+
+	ui.SetFilter("karpenter.sh/capacity-type=spot")
+	selector, nodes, err := ui.NodeSelector()
+	if err != nil {
+		t.Fatalf("building selector, %s", err)
+	}
+	if exp := "karpenter.sh/capacity-type=spot"; selector != exp || nodes != 2 {
+		t.Errorf("expected %s matching 2 nodes, got %s matching %d", exp, selector, nodes)
+	}
+
+	// substring filters select the nodes that match by hostname
+	ui.SetFilter("node-1")
+	selector, nodes, err = ui.NodeSelector()
+	if err != nil {
+		t.Fatalf("building selector, %s", err)
+	}
+	if exp := "kubernetes.io/hostname in (node-1)"; selector != exp || nodes != 1 {
+		t.Errorf("expected %s matching 1 node, got %s matching %d", exp, selector, nodes)
+	}
+	if _, err := labels.Parse(selector); err != nil {
+		t.Errorf("expected a valid selector, %s", err)
+	}
+
+	ui.SetFilter("no-such-node")
+	if _, _, err := ui.NodeSelector(); err == nil {
+		t.Errorf("expected an error when no nodes match")
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var headerStyle = lipgloss.NewStyle().Bold(true).Render
+
+// sortOption is a heading in the node table, a non-empty key is the sort key that the heading selects
+type sortOption struct {
+	title string
+	key   string
+}
+
+// parseNodeSort splits a node sort of the form key=asc or key=dsc into the sort key and whether it's descending
+func parseNodeSort(nodeSort string) (string, bool) {
+	if key, ok := strings.CutSuffix(nodeSort, "=dsc"); ok {
+		return key, true
+	}
+	key, _ := strings.CutSuffix(nodeSort, "=asc")
+	return key, false
+}
+
+// setSort sorts the nodes by the key in the given direction
+func (u *UIModel) setSort(key string, descending bool) {
+	u.sortKey, u.sortDescending = key, descending
+	if descending {
+		u.nodeSorter = makeNodeSorter(key + "=dsc")
+	} else {
+		u.nodeSorter = makeNodeSorter(key + "=asc")
+	}
+}
+
+// tableColumns returns the headings of each cell of the node table, cells that display more than one column, such as
+// the instance type and price, have a heading for each column
+func (u *UIModel) tableColumns() [][]sortOption {
+	var columns [][]sortOption
+	for _, cell := range u.tableCells() {
+		var options []sortOption
+		for _, c := range cell {
+			o := sortOption{title: c.title, key: c.key}
+			if c.name == "usage" {
+				o.key = ""
+				if resources := u.Cluster().resources; len(resources) > 0 {
+					o.key = fmt.Sprintf("eks-node-viewer/node-%s-usage", resources[0])
+				}
+			}
+			options = append(options, o)
+		}
+		columns = append(columns, options)
+	}
+	return columns
+}
+
+// sortKeys returns the keys that the nodes can be sorted by in the order of the table's columns, followed by creation
+func (u *UIModel) sortKeys() []string {
+	var keys []string
+	for _, column := range u.tableColumns() {
+		for _, o := range column {
+			if o.key != "" && !slices.Contains(keys, o.key) {
+				keys = append(keys, o.key)
+			}
+		}
+	}
+	if !slices.Contains(keys, "creation") {
+		keys = append(keys, "creation")
+	}
+	return keys
+}
+
+// nextSort sorts the nodes by the next column in ascending order
+func (u *UIModel) nextSort() {
+	keys := u.sortKeys()
+	next := 0
+	if i := slices.Index(keys, u.sortKey); i >= 0 {
+		next = (i + 1) % len(keys)
+	}
+	u.setSort(keys[next], false)
+}
+
+// sortArrow returns the arrow indicating the direction of the sort
+func (u *UIModel) sortArrow() string {
+	if u.sortDescending {
+		return "▼"
+	}
+	return "▲"
+}
+
+// writeTableHeader writes the headings of the node table, indicating the column that the nodes are sorted by
+func (u *UIModel) writeTableHeader(w io.Writer) {
+	sorted := false
+	var cells []string
+	for _, column := range u.tableColumns() {
+		var titles []string
+		for _, o := range column {
+			title := o.title
+			if o.key != "" && o.key == u.sortKey {
+				title += " " + u.sortArrow()
+				sorted = true
+			}
+			titles = append(titles, title)
+		}
+		cells = append(cells, headerStyle(strings.Join(titles, "/")))
+	}
+	if !sorted {
+		cells = append(cells, headerStyle(fmt.Sprintf("sorted by %s %s", u.sortKey, u.sortArrow())))
+	}
+	fmt.Fprintln(w, strings.Join(cells, "\t"))
+}
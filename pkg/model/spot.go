@@ -0,0 +1,103 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/awslabs/eks-node-viewer/pkg/text"
+)
+
+// SpotPriceSource provides the current spot price of an instance type in each availability zone of the region
+type SpotPriceSource interface {
+	ZonalSpotPrices(instanceType ec2types.InstanceType) map[string]float64
+}
+
+// writeSpotPrices writes a table of the spot price of each instance type in use across the zones, highlighting the
+// cheapest zone for each type along with the number of nodes of the type in each zone
+func (u *UIModel) writeSpotPrices(w io.Writer, nodes []*Node) {
+	if u.SpotPrices == nil {
+		fmt.Fprintln(w, "Spot prices aren't available for this cloud provider...")
+		return
+	}
+	counts := map[ec2types.InstanceType]map[string]int{}
+	for _, n := range nodes {
+		if n.IsFargate() {
+			continue
+		}
+		it := n.InstanceType()
+		if counts[it] == nil {
+			counts[it] = map[string]int{}
+		}
+		counts[it][n.Zone()]++
+	}
+	if len(counts) == 0 {
+		fmt.Fprintln(w, "No instance types in use...")
+		return
+	}
+
+	var instanceTypes []ec2types.InstanceType
+	prices := map[ec2types.InstanceType]map[string]float64{}
+	zoneSet := map[string]struct{}{}
+	for it := range counts {
+		instanceTypes = append(instanceTypes, it)
+		prices[it] = u.SpotPrices.ZonalSpotPrices(it)
+		for zone := range prices[it] {
+			zoneSet[zone] = struct{}{}
+		}
+	}
+	sort.Slice(instanceTypes, func(a, b int) bool { return instanceTypes[a] < instanceTypes[b] })
+	var zones []string
+	for zone := range zoneSet {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+
+	fmt.Fprintln(w, "Spot price per hour in each zone, the cheapest zone is highlighted and (n) is the number of nodes in the zone")
+	ctw := text.NewColorTabWriter(w, 0, 8, 2)
+	fmt.Fprint(ctw, "TYPE")
+	for _, zone := range zones {
+		fmt.Fprintf(ctw, "\t%s", zone)
+	}
+	fmt.Fprintln(ctw)
+	for _, it := range instanceTypes {
+		best := math.Inf(1)
+		for _, price := range prices[it] {
+			best = math.Min(best, price)
+		}
+		fmt.Fprint(ctw, it)
+		for _, zone := range zones {
+			price, ok := prices[it][zone]
+			cell := "-"
+			if ok {
+				cell = fmt.Sprintf("$%0.4f", price)
+			}
+			if count := counts[it][zone]; count > 0 {
+				cell += fmt.Sprintf(" (%d)", count)
+			}
+			if ok && price == best {
+				cell = u.style.green(cell)
+			}
+			fmt.Fprintf(ctw, "\t%s", cell)
+		}
+		fmt.Fprintln(ctw)
+	}
+	ctw.Flush()
+}
@@ -0,0 +1,58 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+)
+
+// NodeStatusReportPeriod is how often the kubelet updates its node's status by default even when nothing has changed,
+// so a watch that delivers no updates of a ready node for several periods has probably stopped
+const NodeStatusReportPeriod = 5 * time.Minute
+
+// LastUpdate returns when the node was last added or updated by the node watch
+func (n *Node) LastUpdate() time.Time {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.lastUpdate
+}
+
+// Stale returns true if the node is ready but hasn't been updated for longer than the given duration. Its kubelet
+// should have reported its status in the meantime, so the watch may have silently stopped delivering updates.
+func (n *Node) Stale(after time.Duration) bool {
+	return after > 0 && n.Ready() && time.Since(n.LastUpdate()) > after
+}
+
+// stale returns true if the node is stale, nodes restored from a recording aren't updated and are never stale
+func (u *UIModel) stale(n *Node) bool {
+	return u.frames == nil && n.Stale(u.StaleAfter)
+}
+
+// countStale returns the number of stale nodes, the number of ready nodes that could become stale, and when the most
+// recently updated node was updated
+func (u *UIModel) countStale() (stale, ready int, last time.Time) {
+	if u.StaleAfter <= 0 || u.frames != nil {
+		return 0, 0, last
+	}
+	for _, c := range u.clusters {
+		s, r, l := c.staleNodes(u.StaleAfter)
+		stale += s
+		ready += r
+		if l.After(last) {
+			last = l
+		}
+	}
+	return stale, ready, last
+}
@@ -0,0 +1,72 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestStaleNodes(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("creating style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	ui.StaleAfter = 50 * time.Millisecond
+	readyNode := func(name string) *v1.Node {
+		n := testNode(name)
+		n.Spec.ProviderID = name + "-id"
+		n.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+		return n
+	}
+	a := model.NewNode(readyNode("node-a"))
+	a.Show()
+	ui.Cluster().AddNode(a)
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+
+	// every node has gone without updates, so the watch has probably stopped
+	time.Sleep(100 * time.Millisecond)
+	if !a.Stale(ui.StaleAfter) {
+		t.Fatal("expected the node to be stale")
+	}
+	if view := ui.View(); !strings.Contains(view, "no node updates received") || !strings.Contains(view, "Stale") {
+		t.Errorf("expected a stalled watch, got\n%s", view)
+	}
+
+	// a new node shows the watch is working, the other node is only marked
+	b := model.NewNode(readyNode("node-b"))
+	b.Show()
+	ui.Cluster().AddNode(b)
+	if view := ui.View(); !strings.Contains(view, "1 nodes haven't been updated") || strings.Contains(view, "no node updates received") {
+		t.Errorf("expected a stale node, got\n%s", view)
+	}
+
+	// an update refreshes the node
+	ui.Cluster().AddNode(model.NewNode(readyNode("node-a")))
+	if a.Stale(ui.StaleAfter) {
+		t.Error("expected the updated node not to be stale")
+	}
+	if view := ui.View(); strings.Contains(view, "Stale") {
+		t.Errorf("expected no stale nodes, got\n%s", view)
+	}
+}
@@ -20,10 +20,129 @@ type Stats struct {
 	NumNodes             int
 	AllocatableResources v1.ResourceList
 	UsedResources        v1.ResourceList
+	// CapacityResources is the total capacity of the nodes, which usage is measured against instead of the allocatable
+	// resources when normalizing to capacity
+	CapacityResources v1.ResourceList
+	// ActualUsedResources is the resource usage reported by the metrics API for the nodes that have metrics
+	ActualUsedResources  v1.ResourceList
 	PercentUsedResoruces map[v1.ResourceName]float64
 	Nodes                []*Node
 	TotalPods            int
 	PodsByPhase          map[v1.PodPhase]int
 	BoundPodCount        int
 	TotalPrice           float64
+	// CapacityTypeMismatches is the number of nodes whose capacity type label disagrees with EC2
+	CapacityTypeMismatches int
+	// Zones and CapacityTypes break down the nodes by their zone and by spot, on-demand or fargate
+	Zones         map[string]Breakdown
+	CapacityTypes map[string]Breakdown
+	// ExcludedResources are the resources requested by pods in excluded namespaces, which aren't part of UsedResources
+	ExcludedResources v1.ResourceList
+	// SpotSavings is how much less the priced spot nodes cost per hour than the on-demand price of their instance types,
+	// SpotOnDemandPrice is what they'd cost on-demand
+	SpotSavings       float64
+	SpotOnDemandPrice float64
+	// Interrupted is the number of nodes that have received a spot interruption notice, and RebalanceRecommended the
+	// number that have only received a rebalance recommendation
+	Interrupted          int
+	RebalanceRecommended int
+	// HostBilled is the number of nodes without a price that run on dedicated hosts
+	HostBilled int
+	// Pressure is the number of nodes reporting a pressure condition
+	Pressure int
+	// MaxPodsMismatches is the number of nodes whose max pods is far from the ENI-derived maximum
+	MaxPodsMismatches int
+	// ProviderIDProblems is the number of nodes that are missing a provider ID or share it with another node
+	ProviderIDProblems int
+	// LowCPUCredits is the number of burstable nodes that are close to running out of CPU credits
+	LowCPUCredits int
+	// Reserved is the number of nodes that have resources whose allocatable amount is far below capacity, and
+	// ReservedResources the number of nodes for each of those resources. They're only counted when the UI sets the
+	// maximum amount reserved.
+	Reserved          int
+	ReservedResources map[v1.ResourceName]int
+	// ManagedNodeGroupNodes is the number of nodes in each managed node group, including nodes hidden by the node
+	// selector
+	ManagedNodeGroupNodes map[string]int
+}
+
+// Breakdown is the number of nodes and their total price for a subset of the nodes
+type Breakdown struct {
+	Nodes int
+	Price float64
+}
+
+// breakdownCapacityType returns the capacity type that the node is counted under in the capacity type breakdown
+func breakdownCapacityType(n *Node) string {
+	switch {
+	case n.IsSpot():
+		return "spot"
+	case n.IsOnDemand():
+		return "on-demand"
+	case n.IsFargate():
+		return "fargate"
+	}
+	return "other"
+}
+
+func mergeBreakdowns(dst, src map[string]Breakdown) {
+	for key, b := range src {
+		merged := dst[key]
+		merged.Nodes += b.Nodes
+		merged.Price += b.Price
+		dst[key] = merged
+	}
+}
+
+// MergeStats combines the stats from multiple clusters into a single set of stats, the nodes retain the order they
+// had in each of the individual stats
+func MergeStats(stats ...Stats) Stats {
+	merged := Stats{
+		AllocatableResources:  v1.ResourceList{},
+		UsedResources:         v1.ResourceList{},
+		CapacityResources:     v1.ResourceList{},
+		ActualUsedResources:   v1.ResourceList{},
+		PercentUsedResoruces:  map[v1.ResourceName]float64{},
+		PodsByPhase:           map[v1.PodPhase]int{},
+		Zones:                 map[string]Breakdown{},
+		CapacityTypes:         map[string]Breakdown{},
+		ExcludedResources:     v1.ResourceList{},
+		ReservedResources:     map[v1.ResourceName]int{},
+		ManagedNodeGroupNodes: map[string]int{},
+	}
+	for _, st := range stats {
+		merged.NumNodes += st.NumNodes
+		merged.Nodes = append(merged.Nodes, st.Nodes...)
+		merged.TotalPods += st.TotalPods
+		merged.BoundPodCount += st.BoundPodCount
+		merged.TotalPrice += st.TotalPrice
+		merged.SpotSavings += st.SpotSavings
+		merged.SpotOnDemandPrice += st.SpotOnDemandPrice
+		merged.CapacityTypeMismatches += st.CapacityTypeMismatches
+		merged.Interrupted += st.Interrupted
+		merged.RebalanceRecommended += st.RebalanceRecommended
+		merged.HostBilled += st.HostBilled
+		merged.Pressure += st.Pressure
+		merged.MaxPodsMismatches += st.MaxPodsMismatches
+		merged.ProviderIDProblems += st.ProviderIDProblems
+		merged.LowCPUCredits += st.LowCPUCredits
+		merged.Reserved += st.Reserved
+		for rn, count := range st.ReservedResources {
+			merged.ReservedResources[rn] += count
+		}
+		for name, count := range st.ManagedNodeGroupNodes {
+			merged.ManagedNodeGroupNodes[name] += count
+		}
+		for phase, count := range st.PodsByPhase {
+			merged.PodsByPhase[phase] += count
+		}
+		addResources(merged.AllocatableResources, st.AllocatableResources)
+		addResources(merged.UsedResources, st.UsedResources)
+		addResources(merged.CapacityResources, st.CapacityResources)
+		addResources(merged.ActualUsedResources, st.ActualUsedResources)
+		addResources(merged.ExcludedResources, st.ExcludedResources)
+		mergeBreakdowns(merged.Zones, st.Zones)
+		mergeBreakdowns(merged.CapacityTypes, st.CapacityTypes)
+	}
+	return merged
 }
@@ -0,0 +1,88 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Status is a summary of the state of a cluster that is published for other tools to consume
+type Status struct {
+	Updated           time.Time `json:"updated"`
+	Nodes             int       `json:"nodes"`
+	ReadyNodes        int       `json:"readyNodes"`
+	CordonedNodes     int       `json:"cordonedNodes"`
+	DeletingNodes     int       `json:"deletingNodes"`
+	Pods              int       `json:"pods"`
+	PendingPods       int       `json:"pendingPods"`
+	PricePerHour      float64   `json:"pricePerHour"`
+	PricePerMonth     float64   `json:"pricePerMonth"`
+	UnknownPriceNodes int       `json:"unknownPriceNodes"`
+	// Problems describes nodes and pods that likely need attention, e.g. "node ip-10-0-1-2 is not ready"
+	Problems []string `json:"problems"`
+}
+
+// NewStatus summarizes the stats of a cluster
+func NewStatus(st Stats, now time.Time) Status {
+	status := Status{
+		Updated:       now,
+		Nodes:         st.NumNodes,
+		Pods:          st.TotalPods,
+		PendingPods:   st.PodsByPhase[v1.PodPending],
+		PricePerHour:  math.Round(st.TotalPrice*1000) / 1000,
+		PricePerMonth: math.Round(st.TotalPrice*(365*24)/12*100) / 100,
+		Problems:      []string{},
+	}
+	nodes := append([]*Node{}, st.Nodes...)
+	sort.Slice(nodes, func(a, b int) bool { return nodes[a].Name() < nodes[b].Name() })
+	for _, n := range nodes {
+		if n.Ready() {
+			status.ReadyNodes++
+		} else {
+			status.Problems = append(status.Problems, fmt.Sprintf("node %s is not ready", n.Name()))
+		}
+		if n.Cordoned() {
+			status.CordonedNodes++
+		}
+		if n.Deleting() {
+			status.DeletingNodes++
+		}
+		if !n.HasPrice() {
+			status.UnknownPriceNodes++
+		}
+		if n.Interrupted() {
+			status.Problems = append(status.Problems, fmt.Sprintf("node %s received a spot interruption notice", n.Name()))
+		} else if n.RebalanceRecommended() {
+			status.Problems = append(status.Problems, fmt.Sprintf("node %s received a rebalance recommendation", n.Name()))
+		}
+		if n.CapacityTypeMismatch() {
+			status.Problems = append(status.Problems, fmt.Sprintf("node %s has a capacity type label that doesn't match EC2", n.Name()))
+		}
+		if n.MissingProviderID() {
+			status.Problems = append(status.Problems, fmt.Sprintf("node %s has no provider ID", n.Name()))
+		} else if n.DuplicateProviderID() {
+			status.Problems = append(status.Problems, fmt.Sprintf("node %s has the same provider ID as another node", n.Name()))
+		}
+	}
+	if status.PendingPods > 0 {
+		status.Problems = append(status.Problems, fmt.Sprintf("%d pods are pending", status.PendingPods))
+	}
+	return status
+}
@@ -0,0 +1,63 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestNewStatus(t *testing.T) {
+	cluster := model.NewCluster()
+	for _, name := range []string{"b-ready", "a-not-ready"} {
+		n := testNode(name)
+		n.Spec.ProviderID = name
+		if name == "b-ready" {
+			n.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+			n.Spec.Unschedulable = true
+		}
+		node := model.NewNode(n)
+		node.SetPrice(0.25)
+		node.Show()
+		cluster.AddNode(node)
+	}
+	cluster.AddPod(model.NewPod(testPod("default", "pending")))
+
+	now := time.Now()
+	status := model.NewStatus(cluster.Stats(), now)
+	if !status.Updated.Equal(now) {
+		t.Errorf("expected updated %s, got %s", now, status.Updated)
+	}
+	if status.Nodes != 2 || status.ReadyNodes != 1 || status.CordonedNodes != 1 || status.DeletingNodes != 0 {
+		t.Errorf("expected 2 nodes with 1 ready and 1 cordoned, got %+v", status)
+	}
+	if status.Pods != 1 || status.PendingPods != 1 {
+		t.Errorf("expected 1 pending pod, got %d of %d pending", status.PendingPods, status.Pods)
+	}
+	if exp, got := 0.5, status.PricePerHour; exp != got {
+		t.Errorf("expected price per hour %v, got %v", exp, got)
+	}
+	if exp, got := 365.0, status.PricePerMonth; exp != got {
+		t.Errorf("expected price per month %v, got %v", exp, got)
+	}
+	if exp := []string{"node a-not-ready is not ready", "1 pods are pending"}; !reflect.DeepEqual(exp, status.Problems) {
+		t.Errorf("expected problems %q, got %q", exp, status.Problems)
+	}
+}
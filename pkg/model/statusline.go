@@ -0,0 +1,107 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ContextColor colors the statusline of the clusters whose context or cluster name contains Pattern, e.g. red for
+// production clusters so that they can't be mistaken for another cluster
+type ContextColor struct {
+	Pattern string
+	// Color is green, yellow or red for the colors of the style, or a hex or ANSI color
+	Color string
+}
+
+var colorRe = regexp.MustCompile(`^(#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6}|[0-9]{1,3})$`)
+
+// ParseContextColors parses a comma separated list of pattern=color pairs, e.g. prod=red,staging=yellow, the first
+// pattern that matches a cluster chooses its color
+func ParseContextColors(s string) ([]ContextColor, error) {
+	var colors []ContextColor
+	for _, pair := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' }) {
+		pattern, color, ok := strings.Cut(pair, "=")
+		pattern, color = strings.TrimSpace(pattern), strings.TrimSpace(color)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("expected pattern=color, got %q", pair)
+		}
+		if !validColor(color) {
+			return nil, fmt.Errorf("invalid color %q for %s, must be green, yellow, red or a hex or ANSI color", color, pattern)
+		}
+		colors = append(colors, ContextColor{Pattern: pattern, Color: color})
+	}
+	return colors, nil
+}
+
+// validColor returns true if the color is green, yellow or red for the colors of the style, or a hex or ANSI color
+func validColor(color string) bool {
+	switch color {
+	case "green", "yellow", "red":
+		return true
+	}
+	return colorRe.MatchString(color)
+}
+
+// SetIdentity sets the context and cluster names that are displayed at the top of the header, an empty context hides
+// the statusline
+func (u *UIModel) SetIdentity(context, cluster string) {
+	u.contextName = context
+	u.clusterName = cluster
+}
+
+// contextColor returns the color of the first context color whose pattern is contained in any of the names
+func (u *UIModel) contextColor(names ...string) (lipgloss.Color, bool) {
+	for _, cc := range u.ContextColors {
+		for _, name := range names {
+			if name != "" && strings.Contains(strings.ToLower(name), strings.ToLower(cc.Pattern)) {
+				if color, ok := u.style.colors[cc.Color]; ok {
+					return color, true
+				}
+				return lipgloss.Color(cc.Color), true
+			}
+		}
+	}
+	return "", false
+}
+
+// writeStatusline writes the name of the cluster being viewed, in reverse video with the color of its environment
+func (u *UIModel) writeStatusline(w io.Writer) {
+	if u.contextName == "" {
+		return
+	}
+	line := "cluster " + u.clusterName
+	if u.clusterName != u.contextName {
+		line += " (context " + u.contextName + ")"
+	}
+	style := lipgloss.NewStyle().Bold(true).Padding(0, 1)
+	if color, ok := u.contextColor(u.contextName, u.clusterName); ok {
+		style = style.Reverse(true).Foreground(color)
+	}
+	fmt.Fprintln(w, style.Render(line))
+}
+
+// styleContextName colors the name of one of several clusters being viewed with the color of its environment
+func (u *UIModel) styleContextName(name string) string {
+	if color, ok := u.contextColor(name); ok {
+		return lipgloss.NewStyle().Foreground(color).Render(name)
+	}
+	return name
+}
@@ -0,0 +1,81 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestParseContextColors(t *testing.T) {
+	colors, err := model.ParseContextColors("prod=red, staging = #FFFF00,dev=2")
+	if err != nil {
+		t.Fatalf("parsing context colors, %s", err)
+	}
+	exp := []model.ContextColor{{Pattern: "prod", Color: "red"}, {Pattern: "staging", Color: "#FFFF00"}, {Pattern: "dev", Color: "2"}}
+	if len(colors) != len(exp) {
+		t.Fatalf("expected %v, got %v", exp, colors)
+	}
+	for i := range exp {
+		if colors[i] != exp[i] {
+			t.Errorf("expected %v, got %v", exp[i], colors[i])
+		}
+	}
+	for _, s := range []string{"prod", "=red", "prod=crimson", "prod=#GGGGGG"} {
+		if _, err := model.ParseContextColors(s); err == nil {
+			t.Errorf("expected an error parsing %q", s)
+		}
+	}
+}
+
+func TestStatusline(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("creating style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+
+	// the statusline is hidden until the cluster is identified
+	var buf bytes.Buffer
+	if err := ui.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	if strings.Contains(buf.String(), "cluster ") {
+		t.Errorf("expected no statusline, got\n%s", buf.String())
+	}
+
+	ui.SetIdentity("arn:aws:eks:us-west-2:123456789012:cluster/prod", "prod")
+	buf.Reset()
+	if err := ui.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	first, _, _ := strings.Cut(buf.String(), "\n")
+	if !strings.Contains(first, "cluster prod (context arn:aws:eks:us-west-2:123456789012:cluster/prod)") {
+		t.Errorf("expected the statusline first, got %q", first)
+	}
+
+	ui.SetIdentity("prod", "prod")
+	buf.Reset()
+	if err := ui.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	if first, _, _ := strings.Cut(buf.String(), "\n"); strings.TrimSpace(first) != "cluster prod" {
+		t.Errorf("expected the context to be omitted when it matches the cluster, got %q", first)
+	}
+}
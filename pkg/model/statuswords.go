@@ -0,0 +1,101 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// StatusWords are the words describing a node's status and readiness that can be replaced with StatusWord
+var StatusWords = []string{"Ready", "NotReady", "Provisioning", "Cordoned", "Deleting", "Interrupted", "Rebalance",
+	"MaxPods", "NoProviderID", "DupProviderID", "Stale"}
+
+// StatusWord replaces one of the StatusWords, e.g. with a localized term, and optionally its color
+type StatusWord struct {
+	Word string
+	// Text replaces the word, the word is kept if it's empty
+	Text string
+	// Color is green, yellow or red for the colors of the style, or a hex or ANSI color, the word's usual color is kept
+	// if it's empty
+	Color string
+}
+
+// ParseStatusWords parses a comma separated list of word=text[:color] replacements, e.g.
+// Ready=OK:green,Cordoned=GESPERRT:#FF00FF,NotReady=:red
+func ParseStatusWords(s string) ([]StatusWord, error) {
+	var words []StatusWord
+	for _, pair := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' }) {
+		word, replacement, ok := strings.Cut(pair, "=")
+		word = strings.TrimSpace(word)
+		if !ok {
+			return nil, fmt.Errorf("expected word=text[:color], got %q", pair)
+		}
+		if !slices.Contains(StatusWords, word) {
+			return nil, fmt.Errorf("unknown status word %q, must be one of %s", word, strings.Join(StatusWords, ", "))
+		}
+		sw := StatusWord{Word: word, Text: strings.TrimSpace(replacement)}
+		// the color follows the last colon, so the text can't contain one
+		if text, color, ok := cutLast(sw.Text, ":"); ok {
+			color = strings.TrimSpace(color)
+			if !validColor(color) {
+				return nil, fmt.Errorf("invalid color %q for %s, must be green, yellow, red or a hex or ANSI color", color, word)
+			}
+			sw.Text, sw.Color = strings.TrimSpace(text), color
+		}
+		if sw.Text == "" && sw.Color == "" {
+			return nil, fmt.Errorf("expected a replacement text or color for %s", word)
+		}
+		words = append(words, sw)
+	}
+	return words, nil
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// statusWord returns the word, or its replacement, rendered with its color. The color is the replacement's color if it
+// has one, otherwise the word's usual color which may be nil for no color.
+func (u *UIModel) statusWord(word string, color func(strs ...string) string) string {
+	text := word
+	for _, sw := range u.StatusWords {
+		if sw.Word != word {
+			continue
+		}
+		if sw.Text != "" {
+			text = sw.Text
+		}
+		if sw.Color != "" {
+			c, ok := u.style.colors[sw.Color]
+			if !ok {
+				c = lipgloss.Color(sw.Color)
+			}
+			color = lipgloss.NewStyle().Foreground(c).Render
+		}
+		break
+	}
+	if color == nil {
+		return text
+	}
+	return color(text)
+}
@@ -0,0 +1,102 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/awslabs/operatorpkg/status"
+	tea "github.com/charmbracelet/bubbletea"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	karpv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestParseStatusWords(t *testing.T) {
+	words, err := model.ParseStatusWords("Ready=OK:green, Cordoned=GESPERRT, NotReady=:#FF0000")
+	if err != nil {
+		t.Fatalf("parsing status words, %s", err)
+	}
+	exp := []model.StatusWord{
+		{Word: "Ready", Text: "OK", Color: "green"},
+		{Word: "Cordoned", Text: "GESPERRT"},
+		{Word: "NotReady", Color: "#FF0000"},
+	}
+	for i, e := range exp {
+		if words[i] != e {
+			t.Errorf("expected %+v, got %+v", e, words[i])
+		}
+	}
+	for _, s := range []string{"Ready", "Unknown=x", "Ready=", "Ready=OK:purple"} {
+		if _, err := model.ParseStatusWords(s); err == nil {
+			t.Errorf("expected %q to be invalid", s)
+		}
+	}
+}
+
+func TestStatusWords(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	if ui.StatusWords, err = model.ParseStatusWords("Ready=BEREIT,Cordoned=GESPERRT,Provisioning=STARTET"); err != nil {
+		t.Fatalf("parsing status words, %s", err)
+	}
+	if err := ui.SetLayout([]string{"name", "status", "readiness"}); err != nil {
+		t.Fatalf("setting layout, %s", err)
+	}
+	ui.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+
+	ready := testNode("ready")
+	ready.Spec.ProviderID = "ready-id"
+	ready.Spec.Unschedulable = true
+	ready.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	node := model.NewNode(ready)
+	node.Show()
+	ui.Cluster().AddNode(node)
+
+	// a NodeClaim that has launched an instance that hasn't registered
+	nc := &karpv1.NodeClaim{}
+	nc.Name = "claim"
+	nc.Status.NodeName = "claimed"
+	nc.Status.ProviderID = "claimed-id"
+	nc.Status.Conditions = []status.Condition{
+		{Type: "Launched", Status: metav1.ConditionTrue},
+		{Type: "Registered", Status: metav1.ConditionUnknown},
+	}
+	claimed := model.NewNodeFromNodeClaim(nc)
+	claimed.Show()
+	ui.Cluster().AddNode(claimed)
+	if !claimed.Provisioning() || node.Provisioning() {
+		t.Errorf("expected only the node claim to be provisioning")
+	}
+
+	view := ui.View()
+	for _, exp := range []string{"GESPERRT", "BEREIT", "STARTET/"} {
+		if !strings.Contains(view, exp) {
+			t.Errorf("expected the view to contain %q, got\n%s", exp, view)
+		}
+	}
+	for _, word := range []string{"Cordoned", "Ready", "NotReady"} {
+		if strings.Contains(view, word) {
+			t.Errorf("expected %s to be replaced, got\n%s", word, view)
+		}
+	}
+}
@@ -26,6 +26,8 @@ type Style struct {
 	yellow   func(strs ...string) string
 	red      func(strs ...string) string
 	gradient progress.Option
+	// colors are the good, ok and bad colors by name, so they can be referred to elsewhere as green, yellow and red
+	colors map[string]lipgloss.Color
 }
 
 func ParseStyle(style string) (*Style, error) {
@@ -39,5 +41,10 @@ func ParseStyle(style string) (*Style, error) {
 	s.red = lipgloss.NewStyle().Foreground(lipgloss.Color(colors[2])).Render
 
 	s.gradient = progress.WithGradient(colors[2], colors[0])
+	s.colors = map[string]lipgloss.Color{
+		"green":  lipgloss.Color(colors[0]),
+		"yellow": lipgloss.Color(colors[1]),
+		"red":    lipgloss.Color(colors[2]),
+	}
 	return s, nil
 }
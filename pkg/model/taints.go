@@ -0,0 +1,100 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"github.com/awslabs/eks-node-viewer/pkg/text"
+)
+
+// taintSummaryWidth is the width that the keys of a node's taints are truncated to in the taint summary
+const taintSummaryWidth = 40
+
+// conditionTaintPrefix is the prefix of the taints that Kubernetes applies for node conditions such as NotReady, these
+// are already visible in the status and readiness columns
+const conditionTaintPrefix = "node.kubernetes.io/"
+
+// Taints returns the taints of the node
+func (n *Node) Taints() []v1.Taint {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	taints := make([]v1.Taint, len(n.node.Spec.Taints))
+	copy(taints, n.node.Spec.Taints)
+	return taints
+}
+
+// Tainted returns true if the node has taints other than those applied by Kubernetes for node conditions
+func (n *Node) Tainted() bool {
+	for _, t := range n.Taints() {
+		if !strings.HasPrefix(t.Key, conditionTaintPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// taintSummary summarizes the taints of a node as the number of taints followed by their keys, e.g.
+// "2:nvidia.com/gpu,dedicated", truncated to the taint summary width
+func taintSummary(n *Node) string {
+	taints := n.Taints()
+	if len(taints) == 0 {
+		return "-"
+	}
+	var keys []string
+	for _, t := range taints {
+		keys = append(keys, t.Key)
+	}
+	summary := fmt.Sprintf("%d:%s", len(taints), strings.Join(keys, ","))
+	if r := []rune(summary); len(r) > taintSummaryWidth {
+		summary = string(r[:taintSummaryWidth-1]) + "…"
+	}
+	return summary
+}
+
+// writeTaints writes the full list of taints of the selected node
+func (u *UIModel) writeTaints(w io.Writer) {
+	if u.taintNode == nil {
+		fmt.Fprintln(w, "Select a node to show its taints...")
+		return
+	}
+	taints := u.taintNode.Taints()
+	fmt.Fprintf(w, "%s has %d taints\n\n", u.taintNode.Name(), len(taints))
+	if len(taints) == 0 {
+		return
+	}
+	ctw := text.NewColorTabWriter(w, 0, 8, 2)
+	fmt.Fprintln(ctw, headerStyle("KEY")+"\t"+headerStyle("VALUE")+"\t"+headerStyle("EFFECT")+"\t"+headerStyle("AGE"))
+	for _, t := range taints {
+		value, age := t.Value, "-"
+		if value == "" {
+			value = "-"
+		}
+		if t.TimeAdded != nil {
+			age = duration.HumanDuration(since(t.TimeAdded.Time))
+		}
+		effect := string(t.Effect)
+		if t.Effect == v1.TaintEffectNoExecute {
+			effect = u.style.red(effect)
+		}
+		fmt.Fprintf(ctw, "%s\t%s\t%s\t%s\n", t.Key, value, effect, age)
+	}
+	ctw.Flush()
+}
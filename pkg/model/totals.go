@@ -0,0 +1,343 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"cmp"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// nodeTotals is what a node adds to the totals of its cluster. The cluster keeps it so that it can be subtracted again
+// when the node changes or is deleted, rather than totalling every node each time the totals are displayed.
+type nodeTotals struct {
+	visible bool
+	// managedNodeGroup is counted whether or not the node is visible
+	managedNodeGroup string
+	// hiddenPods are the pods bound to a hidden node, which aren't counted in the totals of its cluster
+	hiddenPods []podTotals
+
+	hasPrice             bool
+	price                float64
+	hasSpotSavings       bool
+	spotSavings          float64
+	spotOnDemandPrice    float64
+	capacityTypeMismatch bool
+	allocatable          v1.ResourceList
+	used                 v1.ResourceList
+	capacity             v1.ResourceList
+	actualUsed           v1.ResourceList
+	excluded             v1.ResourceList
+	zone                 string
+	capacityType         string
+	interrupted          bool
+	rebalance            bool
+	hostBilled           bool
+	pressure             bool
+	maxPodsMismatch      bool
+	providerIDProblem    bool
+	lowCPUCredits        bool
+	reserved             []v1.ResourceName
+	ready                bool
+	created              time.Time
+	updated              time.Time
+	// sortKey is the key that the node is sorted by in its cluster, see nodeSorter
+	sortKey sortKey
+}
+
+// newNodeTotals returns what the node adds to the totals of its cluster, counting the resources whose allocatable
+// amount is more than maxReserved below capacity if it's positive
+func newNodeTotals(n *Node, maxReserved float64) *nodeTotals {
+	nt := &nodeTotals{visible: n.Visible(), managedNodeGroup: n.ManagedNodeGroup()}
+	if !nt.visible {
+		for _, p := range n.Pods() {
+			nt.hiddenPods = append(nt.hiddenPods, newPodTotals(p))
+		}
+		return nt
+	}
+	nt.hasPrice = n.HasPrice()
+	if nt.hasPrice {
+		nt.price = n.Price()
+	}
+	nt.spotSavings, nt.spotOnDemandPrice, nt.hasSpotSavings = n.SpotSavings()
+	nt.capacityTypeMismatch = n.CapacityTypeMismatch()
+	nt.allocatable = limitPods(n, n.Allocatable())
+	nt.used = n.Used()
+	nt.capacity = limitPods(n, n.Capacity())
+	nt.actualUsed = n.ActualUsage()
+	nt.excluded = n.ExcludedUsed()
+	nt.zone = n.Zone()
+	if nt.zone == "" {
+		nt.zone = "-"
+	}
+	nt.capacityType = breakdownCapacityType(n)
+	nt.interrupted = n.Interrupted()
+	nt.rebalance = !nt.interrupted && n.RebalanceRecommended()
+	nt.hostBilled = n.HostBilled() && !nt.hasPrice
+	nt.pressure = n.HealthSeverity() > 0
+	nt.maxPodsMismatch = n.MaxPodsMismatch()
+	nt.providerIDProblem = n.MissingProviderID() || n.DuplicateProviderID()
+	nt.lowCPUCredits = n.LowCPUCredits()
+	if maxReserved > 0 {
+		nt.reserved = n.ReservedResources(maxReserved)
+	}
+	nt.ready = n.Ready()
+	nt.created = n.Created()
+	nt.updated = n.LastUpdate()
+	return nt
+}
+
+// podTotals is what a pod adds to the totals of its cluster
+type podTotals struct {
+	phase v1.PodPhase
+	bound bool
+}
+
+func newPodTotals(p *Pod) podTotals {
+	return podTotals{phase: p.Phase(), bound: p.NodeName() != ""}
+}
+
+// nanodollars is an hourly price in billionths of a dollar, prices are totalled as integers so that subtracting a price
+// exactly undoes adding it
+type nanodollars int64
+
+func toNanodollars(price float64) nanodollars {
+	return nanodollars(math.Round(price * 1e9))
+}
+
+func (d nanodollars) dollars() float64 {
+	return float64(d) / 1e9
+}
+
+type breakdownTotals struct {
+	nodes int
+	price nanodollars
+}
+
+// clusterTotals are the totals of the visible nodes of a cluster and of their pods
+type clusterTotals struct {
+	nodes                  int
+	allocatable            v1.ResourceList
+	used                   v1.ResourceList
+	capacity               v1.ResourceList
+	actualUsed             v1.ResourceList
+	excluded               v1.ResourceList
+	price                  nanodollars
+	spotSavings            nanodollars
+	spotOnDemandPrice      nanodollars
+	capacityTypeMismatches int
+	interrupted            int
+	rebalance              int
+	hostBilled             int
+	pressure               int
+	maxPodsMismatches      int
+	providerIDProblems     int
+	lowCPUCredits          int
+	reserved               int
+	reservedResources      map[v1.ResourceName]int
+	zones                  map[string]breakdownTotals
+	capacityTypes          map[string]breakdownTotals
+	managedNodeGroups      map[string]int
+	pods                   int
+	boundPods              int
+	podsByPhase            map[v1.PodPhase]int
+	// created, updated and readyUpdated are sorted indexes of when the nodes were created, when they were last updated
+	// and when the ready nodes were last updated, and prices is a sorted index of the prices of the priced nodes. They
+	// answer questions such as how many nodes are older than a given age without visiting every node.
+	created      []time.Time
+	updated      []time.Time
+	readyUpdated []time.Time
+	prices       []float64
+}
+
+func newClusterTotals() clusterTotals {
+	return clusterTotals{
+		allocatable:       v1.ResourceList{},
+		used:              v1.ResourceList{},
+		capacity:          v1.ResourceList{},
+		actualUsed:        v1.ResourceList{},
+		excluded:          v1.ResourceList{},
+		reservedResources: map[v1.ResourceName]int{},
+		zones:             map[string]breakdownTotals{},
+		capacityTypes:     map[string]breakdownTotals{},
+		managedNodeGroups: map[string]int{},
+		podsByPhase:       map[v1.PodPhase]int{},
+	}
+}
+
+// addNode adds the node's totals if sign is 1, or subtracts them if it's -1
+func (t *clusterTotals) addNode(nt *nodeTotals, sign int) {
+	if nt.managedNodeGroup != "" {
+		addCount(t.managedNodeGroups, nt.managedNodeGroup, sign)
+	}
+	for _, p := range nt.hiddenPods {
+		t.addPod(p, -sign)
+	}
+	if !nt.visible {
+		return
+	}
+	t.nodes += sign
+	addSignedResources(t.allocatable, nt.allocatable, sign)
+	addSignedResources(t.used, nt.used, sign)
+	addSignedResources(t.capacity, nt.capacity, sign)
+	addSignedResources(t.actualUsed, nt.actualUsed, sign)
+	addSignedResources(t.excluded, nt.excluded, sign)
+	var price nanodollars
+	if nt.hasPrice {
+		price = toNanodollars(nt.price)
+		t.price += nanodollars(sign) * price
+		t.prices = updateIndex(t.prices, nt.price, sign, cmp.Compare[float64])
+	}
+	if nt.hasSpotSavings {
+		t.spotSavings += nanodollars(sign) * toNanodollars(nt.spotSavings)
+		t.spotOnDemandPrice += nanodollars(sign) * toNanodollars(nt.spotOnDemandPrice)
+	}
+	addBreakdownTotals(t.zones, nt.zone, price, sign)
+	addBreakdownTotals(t.capacityTypes, nt.capacityType, price, sign)
+	for _, c := range []struct {
+		count *int
+		ok    bool
+	}{
+		{&t.capacityTypeMismatches, nt.capacityTypeMismatch},
+		{&t.interrupted, nt.interrupted},
+		{&t.rebalance, nt.rebalance},
+		{&t.hostBilled, nt.hostBilled},
+		{&t.pressure, nt.pressure},
+		{&t.maxPodsMismatches, nt.maxPodsMismatch},
+		{&t.providerIDProblems, nt.providerIDProblem},
+		{&t.lowCPUCredits, nt.lowCPUCredits},
+		{&t.reserved, len(nt.reserved) > 0},
+	} {
+		if c.ok {
+			*c.count += sign
+		}
+	}
+	for _, rn := range nt.reserved {
+		addCount(t.reservedResources, rn, sign)
+	}
+	t.created = updateIndex(t.created, nt.created, sign, time.Time.Compare)
+	t.updated = updateIndex(t.updated, nt.updated, sign, time.Time.Compare)
+	if nt.ready {
+		t.readyUpdated = updateIndex(t.readyUpdated, nt.updated, sign, time.Time.Compare)
+	}
+}
+
+// addPod adds the pod's totals if sign is 1, or subtracts them if it's -1
+func (t *clusterTotals) addPod(p podTotals, sign int) {
+	t.pods += sign
+	addCount(t.podsByPhase, p.phase, sign)
+	if p.bound {
+		t.boundPods += sign
+	}
+}
+
+// stats returns the totals as stats of the nodes
+func (t *clusterTotals) stats(nodes []*Node) Stats {
+	return Stats{
+		NumNodes:               t.nodes,
+		Nodes:                  nodes,
+		AllocatableResources:   t.allocatable.DeepCopy(),
+		UsedResources:          t.used.DeepCopy(),
+		CapacityResources:      t.capacity.DeepCopy(),
+		ActualUsedResources:    t.actualUsed.DeepCopy(),
+		ExcludedResources:      t.excluded.DeepCopy(),
+		PercentUsedResoruces:   map[v1.ResourceName]float64{},
+		TotalPods:              t.pods,
+		PodsByPhase:            maps.Clone(t.podsByPhase),
+		BoundPodCount:          t.boundPods,
+		TotalPrice:             t.price.dollars(),
+		SpotSavings:            t.spotSavings.dollars(),
+		SpotOnDemandPrice:      t.spotOnDemandPrice.dollars(),
+		CapacityTypeMismatches: t.capacityTypeMismatches,
+		Interrupted:            t.interrupted,
+		RebalanceRecommended:   t.rebalance,
+		HostBilled:             t.hostBilled,
+		Pressure:               t.pressure,
+		MaxPodsMismatches:      t.maxPodsMismatches,
+		ProviderIDProblems:     t.providerIDProblems,
+		LowCPUCredits:          t.lowCPUCredits,
+		Reserved:               t.reserved,
+		ReservedResources:      maps.Clone(t.reservedResources),
+		ManagedNodeGroupNodes:  maps.Clone(t.managedNodeGroups),
+		Zones:                  breakdowns(t.zones),
+		CapacityTypes:          breakdowns(t.capacityTypes),
+	}
+}
+
+func breakdowns(totals map[string]breakdownTotals) map[string]Breakdown {
+	b := make(map[string]Breakdown, len(totals))
+	for key, bt := range totals {
+		b[key] = Breakdown{Nodes: bt.nodes, Price: bt.price.dollars()}
+	}
+	return b
+}
+
+func addBreakdownTotals(totals map[string]breakdownTotals, key string, price nanodollars, sign int) {
+	bt := totals[key]
+	bt.nodes += sign
+	bt.price += nanodollars(sign) * price
+	if bt.nodes == 0 {
+		delete(totals, key)
+		return
+	}
+	totals[key] = bt
+}
+
+// addCount adds sign to the count of key, removing it once it reaches zero
+func addCount[K comparable](counts map[K]int, key K, sign int) {
+	counts[key] += sign
+	if counts[key] == 0 {
+		delete(counts, key)
+	}
+}
+
+// addSignedResources adds rhs to lhs if sign is 1, or subtracts it if it's -1, removing resources that reach zero
+func addSignedResources(lhs v1.ResourceList, rhs v1.ResourceList, sign int) {
+	if sign > 0 {
+		addResources(lhs, rhs)
+		return
+	}
+	for rn, q := range rhs {
+		existing := lhs[rn]
+		existing.Sub(q)
+		if existing.IsZero() {
+			delete(lhs, rn)
+			continue
+		}
+		lhs[rn] = existing
+	}
+}
+
+// updateIndex inserts v into the sorted index if sign is 1, or removes it if it's -1
+func updateIndex[T any](index []T, v T, sign int, compare func(T, T) int) []T {
+	i, found := slices.BinarySearchFunc(index, v, compare)
+	if sign > 0 {
+		return slices.Insert(index, i, v)
+	}
+	if found {
+		return slices.Delete(index, i, i+1)
+	}
+	return index
+}
+
+// countBefore returns the number of entries in the sorted index that are before t
+func countBefore(index []time.Time, t time.Time) int {
+	i, _ := slices.BinarySearchFunc(index, t, time.Time.Compare)
+	return i
+}
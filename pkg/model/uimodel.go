@@ -16,10 +16,13 @@ package model
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"math"
 	"sort"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/charmbracelet/bubbles/paginator"
@@ -30,28 +33,202 @@ import (
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/duration"
 
 	"github.com/awslabs/eks-node-viewer/pkg/text"
+	"github.com/awslabs/eks-node-viewer/pkg/tracing"
 )
 
 var (
 	helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262")).Render
+	// the node under the cursor is displayed in reverse video
+	cursorStyle = lipgloss.NewStyle().Reverse(true).Render
 	// white / black
 	activeDot = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "235", Dark: "252"}).Render("•")
 	// black / white
 	inactiveDot = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "250", Dark: "238"}).Render("•")
 )
 
+const (
+	// scheduledSampleInterval is how often the percentage of scheduled pods is sampled for the trend sparkline
+	scheduledSampleInterval = 5 * time.Second
+	// scheduledHistoryLen is the number of samples displayed in the trend sparkline
+	scheduledHistoryLen = 60
+	// pendingListLen is the maximum number of pending pods listed at once
+	pendingListLen = 10
+	// pendingReasonsLen is the maximum number of reasons displayed for the selected pending pod
+	pendingReasonsLen = 5
+	// pendingReasonWidth is the width that the reason a pod is pending is truncated to in the list of pending pods
+	pendingReasonWidth = 80
+	// minCellWidth is the narrowest that the cells of the node table are truncated to when fitting the terminal
+	minCellWidth = 8
+	// replaySkipFrames is the number of frames skipped at once when replaying a recording
+	replaySkipFrames = 10
+	// defaultUpdateInterval is how often the display checks whether anything has changed that needs to be rendered
+	defaultUpdateInterval = 100 * time.Millisecond
+	// clockSkewWarning is the skew between the local clock and the API server's clock that's displayed as a warning
+	clockSkewWarning = time.Minute
+	// fallbackRenderInterval is how often the display is rendered when nothing has changed, so that durations such as
+	// node ages stay current
+	fallbackRenderInterval = time.Second
+	// renderBackoff is how many times longer than the last render took to wait before rendering changes to the clusters
+	// again, so that slow renders during a storm of events leave most of the time for handling keys
+	renderBackoff = 4
+)
+
 type UIModel struct {
-	progress       progress.Model
-	cluster        *Cluster
-	extraLabels    []string
-	paginator      paginator.Model
-	height         int
-	nodeSorter     func(lhs, rhs *Node) bool
-	style          *Style
+	progress    progress.Model
+	clusters    []*Cluster
+	extraLabels []string
+	columns     []Column
+	paginator   paginator.Model
+	height      int
+	nodeSorter  *nodeSorter
+	nodeFilter  func(n *Node) bool
+	style       *Style
+	filter      string
+	filtering   bool
+	// layout is the chosen columns of the node table in the order they're displayed
+	layout []nodeColumn
+	// hiddenColumns are the columns of the layout that were hidden so that the node table fits the terminal's width
+	hiddenColumns []string
+	width         int
+	// fullHelp is toggled with '?' to list every key rather than the most common ones
+	fullHelp bool
+	// sortKey is the label or computed label that the nodes are sorted by, it's changed while running with 's' and 'S'
+	sortKey        string
+	sortDescending bool
+	// scheduledHistory is the trend of the percentage of pods that are bound to a node
+	scheduledHistory []float64
+	lastScheduled    time.Time
+	// gpuUsage is the GPU hours and cost accumulated since the viewer started
+	gpuUsage GPUUsage
+	// status is a message about the last action taken, displayed with the help
+	status string
+	// usageMetrics is true if actual usage is available from the metrics API, showActual toggles between displaying
+	// actual usage and resource requests
+	usageMetrics bool
+	showActual   bool
+	// showDaemonSets displays the share of each node's resources requested by daemonset pods
+	showDaemonSets bool
+	// showPending replaces the node list with the pending pods, explaining why the selected pod can't be scheduled
+	showPending  bool
+	pendingIndex int
+	// showSpot replaces the node list with the spot price of the instance types in use in each zone
+	showSpot bool
+	// grouping replaces the node list with a summary of each group of nodes, groups are expanded to show their nodes
+	grouping      bool
+	groupLabel    string
+	groupIndex    int
+	selectedGroup string
+	expanded      map[string]bool
+	// view is the last rendered display, it's only rendered again when dirty, when a cluster's generation changes and
+	// the frame interval has passed or after the fallback render interval
+	view           string
+	dirty          bool
+	lastRender     time.Time
+	lastGeneration uint64
+	// frames are displayed instead of the live clusters when replaying a recording, they're played back in real time
+	// while playing
+	frames     []Frame
+	frameIndex int
+	playing    bool
+	lastPlayed time.Time
+	// cursor is the index of the selected node, nodes are marked to be compared side-by-side
+	cursor     int
+	cursorNode *Node
+	marked     []*Node
+	comparing  bool
+	// showTaints replaces the node list with the full list of taints of taintNode
+	showTaints bool
+	taintNode  *Node
+	// inspecting replaces the node list with the labels, annotations, taints and NodeClaim details of inspectNode that
+	// match inspectQuery, scrolled down by inspectOffset entries
+	inspecting    bool
+	inspectNode   *Node
+	inspectQuery  string
+	inspectOffset int
+	// noting is true while the note for noteNode is being edited
+	noting   bool
+	noteNode *Node
+	noteText string
+	// Notes are the notes attached to nodes, they're displayed for the node under the cursor and exported
+	Notes *Notes
+	// MaxNodeLifetime is the age after which nodes are highlighted, zero disables the check
+	MaxNodeLifetime time.Duration
+	// StaleAfter is how long a ready node can go without updates before it's marked as stale, zero disables the check
+	StaleAfter time.Duration
+	// MaxReserved is the fraction of a resource's capacity that can be unallocatable before the node is highlighted,
+	// zero disables the check
+	MaxReserved float64
+	// PageSize is the number of nodes displayed on each page, zero fits as many nodes as the terminal's height allows
+	PageSize int
+	// FixedLayout keeps the number of nodes on each page the same until the terminal is resized, rather than fitting it
+	// to the space left below the cluster summary each time the display is rendered
+	FixedLayout  bool
+	fixedPerPage int
+	// UpdateInterval is how often the display checks for changes
+	UpdateInterval time.Duration
+	// SpotPrices provides the spot prices displayed when pressing 'z', it's nil if they aren't available
+	SpotPrices SpotPriceSource
+	// ExportPath is the file that the displayed nodes are written to as CSV when pressing 'e'
+	ExportPath     string
 	DisablePricing bool
+	// NormalizeAllocatable measures usage against the nodes' capacity rather than their allocatable resources, so the
+	// resources reserved for the system and kubelet count as unused, it's toggled with 'a'
+	NormalizeAllocatable bool
+	// ColumnPriority is the order that columns are kept in when the terminal is too narrow to display them all, from
+	// the most to the least important
+	ColumnPriority []string
+	// ScoreWeights are the weights of the factors of the nodes' attention scores
+	ScoreWeights ScoreWeights
+	// RawQuantities displays byte quantities such as memory as Kubernetes quantities, e.g. 16252928Ki, rather than in
+	// binary units, it's toggled with 'r'
+	RawQuantities bool
+	// contextName and clusterName identify the cluster in the statusline, they're colored by the first of the
+	// ContextColors that matches them
+	contextName   string
+	clusterName   string
+	ContextColors []ContextColor
+	// StatusWords replace the words, and colors, of the node status and readiness columns
+	StatusWords []StatusWord
+	// maxCellWidth is the width that the cells of the node table are truncated to so that it fits the terminal's width,
+	// zero if they aren't truncated
+	maxCellWidth int
+	// FrameInterval is the least time between renders of changes to the clusters, so that a storm of events, e.g. during
+	// a mass scale-down, is coalesced into fewer renders. Key presses are rendered immediately. Zero renders every change.
+	FrameInterval time.Duration
+	// renderDuration is how long the last render took
+	renderDuration time.Duration
+	// priceUnit is the index of the unit in priceUnits that prices are displayed in
+	priceUnit int
+	// selector restricts the displayed nodes to those with matching labels, it's edited with 'L' and selectorBefore is
+	// restored if the edit is cancelled
+	selector        labels.Selector
+	selectorBefore  labels.Selector
+	selectorText    string
+	selectorErr     error
+	editingSelector bool
+	// quickFilters are the names of the quickFilters that are toggled on
+	quickFilters map[string]bool
+	// showFootprint displays the viewer's own resource usage, which is sampled every footprintSampleInterval
+	showFootprint bool
+	footprint     footprint
+	// nodeActions are the commands that can be run against the selected node, actionNode is the node that one is
+	// being chosen for
+	nodeActions []NodeAction
+	actionNode  *Node
+	// showLimits marks the share of each node's resources that its pods' limits add up to on the usage bars
+	showLimits bool
+	// showNodePools replaces the node list with the NodePools ranked by their efficiency, nodePools is their usage
+	// accumulated over the session
+	showNodePools bool
+	nodePools     NodePoolUsages
+	// NodeListField is the field of the filtered nodes that's listed when pressing 'Y', NodeListName or
+	// NodeListInstanceID, and NodeListPath is the file they're written to in place of the clipboard
+	NodeListField string
+	NodeListPath  string
 }
 
 func NewUIModel(extraLabels []string, nodeSort string, style *Style) *UIModel {
@@ -59,156 +236,728 @@ func NewUIModel(extraLabels []string, nodeSort string, style *Style) *UIModel {
 	pager.Type = paginator.Dots
 	pager.ActiveDot = activeDot
 	pager.InactiveDot = inactiveDot
-	return &UIModel{
-		// red to green
-		progress:    progress.New(style.gradient),
-		cluster:     NewCluster(),
-		extraLabels: extraLabels,
-		paginator:   pager,
-		nodeSorter:  makeNodeSorter(nodeSort),
-		style:       style,
+	u := &UIModel{
+		// red to green, using the same color profile as the other styles so that the bars honor NO_COLOR and
+		// CLICOLOR_FORCE
+		progress:       progress.New(style.gradient, progress.WithColorProfile(lipgloss.ColorProfile())),
+		clusters:       []*Cluster{NewCluster()},
+		extraLabels:    extraLabels,
+		paginator:      pager,
+		nodeFilter:     NewNodeFilter(""),
+		selector:       labels.Everything(),
+		groupLabel:     DefaultGroupBy,
+		style:          style,
+		UpdateInterval: defaultUpdateInterval,
+		ScoreWeights:   DefaultScoreWeights,
+		ColumnPriority: DefaultColumnPriority,
 	}
+	// the default layout only contains known columns
+	_ = u.SetLayout(DefaultLayout)
+	// notes are only kept in memory until a session file is loaded
+	u.Notes, _ = LoadNotes("")
+	u.setSort(parseNodeSort(nodeSort))
+	return u
 }
 
+// Cluster returns the first cluster being displayed
 func (u *UIModel) Cluster() *Cluster {
-	return u.cluster
+	return u.clusters[0]
+}
+
+// Clusters returns all of the clusters being displayed
+func (u *UIModel) Clusters() []*Cluster {
+	return u.clusters
+}
+
+// AddCluster adds an additional cluster to be displayed, nodes are grouped by cluster when displaying more than one
+func (u *UIModel) AddCluster(name string) *Cluster {
+	c := NewCluster()
+	c.name = name
+	c.resources = u.Cluster().resources
+	c.excludedNamespaces = u.Cluster().excludedNamespaces
+	u.clusters = append(u.clusters, c)
+	return c
+}
+
+// stats returns the stats for each cluster along with the combined stats for all clusters. The nodes in each are
+// sorted using the node sorter.
+func (u *UIModel) stats() (Stats, []Stats) {
+	clusterStats := make([]Stats, len(u.clusters))
+	for i, c := range u.clusters {
+		clusterStats[i] = c.sortedStats(u.nodeSorter, u.ScoreWeights, u.MaxReserved)
+	}
+	return MergeStats(clusterStats...), clusterStats
 }
 
 func (u *UIModel) Init() tea.Cmd {
 	return nil
 }
 
+// View returns the display, re-rendering it only if something may have changed since it was last rendered
 func (u *UIModel) View() string {
-	b := strings.Builder{}
+	var generation uint64
+	for _, c := range u.clusters {
+		generation += c.Generation()
+	}
+	if u.view != "" && !u.dirty {
+		sinceRender := time.Since(u.lastRender)
+		if generation == u.lastGeneration && sinceRender < fallbackRenderInterval {
+			return u.view
+		}
+		// changes are coalesced until the next frame
+		if sinceRender < u.frameInterval() {
+			return u.view
+		}
+	}
+	start := time.Now()
+	u.view = u.render()
+	u.renderDuration = time.Since(start)
+	u.dirty = false
+	u.lastGeneration = generation
+	u.lastRender = time.Now()
+	return u.view
+}
 
-	stats := u.cluster.Stats()
+// frameInterval returns the least time between renders of changes to the clusters, it backs off when rendering is
+// slow, e.g. with thousands of nodes
+func (u *UIModel) frameInterval() time.Duration {
+	if u.FrameInterval <= 0 {
+		return 0
+	}
+	return max(u.FrameInterval, renderBackoff*u.renderDuration)
+}
 
-	sort.Slice(stats.Nodes, func(a, b int) bool {
-		return u.nodeSorter(stats.Nodes[a], stats.Nodes[b])
-	})
+func (u *UIModel) render() string {
+	_, span := tracing.StartSpan(context.Background(), "Render")
+	defer span.End()
+	b := strings.Builder{}
+	// columns are fitted to the page of nodes that's displayed, the expanded groups are displayed in full
+	u.hiddenColumns = nil
+	u.maxCellWidth = 0
 
+	stats, clusterStats := u.stats()
+	resources := u.Cluster().resources
+	u.writeHeader(&b, stats, clusterStats)
 	ctw := text.NewColorTabWriter(&b, 0, 8, 1)
-	u.writeClusterSummary(u.cluster.resources, stats, ctw)
-	ctw.Flush()
-	u.progress.ShowPercentage = true
-	// message printer formats numbers nicely with commas
-	enPrinter := message.NewPrinter(language.English)
-	enPrinter.Fprintf(&b, "%d pods (%d pending %d running %d bound)\n", stats.TotalPods,
-		stats.PodsByPhase[v1.PodPending], stats.PodsByPhase[v1.PodRunning], stats.BoundPodCount)
+	u.unmarkDeleted()
+
+	if u.comparing {
+		fmt.Fprintln(&b)
+		u.writeComparison(&b)
+		fmt.Fprintln(&b, u.helpView())
+		return b.String()
+	}
+
+	if u.showPending {
+		fmt.Fprintln(&b)
+		u.writePending(&b)
+		fmt.Fprintln(&b, u.helpView())
+		return b.String()
+	}
+
+	if u.showSpot {
+		fmt.Fprintln(&b)
+		u.writeSpotPrices(&b, stats.Nodes)
+		fmt.Fprintln(&b, u.helpView())
+		return b.String()
+	}
+
+	if u.showNodePools {
+		fmt.Fprintln(&b)
+		u.writeNodePools(&b)
+		fmt.Fprintln(&b, u.helpView())
+		return b.String()
+	}
+
+	if u.showTaints {
+		fmt.Fprintln(&b)
+		u.writeTaints(&b)
+		fmt.Fprintln(&b, u.helpView())
+		return b.String()
+	}
+
+	if u.inspecting {
+		fmt.Fprintln(&b)
+		// the inspector fills the lines left below the header, other than its title and the help
+		lines := 0
+		if u.height > 0 {
+			lines = max(u.height-strings.Count(b.String(), "\n")-3-lipgloss.Height(u.helpView()), 1)
+		}
+		u.writeInspector(&b, lines)
+		fmt.Fprintln(&b, u.helpView())
+		return b.String()
+	}
 
 	if stats.NumNodes == 0 {
 		fmt.Fprintln(&b)
 		fmt.Fprintln(&b, "Waiting for update or no nodes found...")
 		fmt.Fprintln(&b, u.paginator.View())
-		fmt.Fprintln(&b, helpStyle("←/→ page • q: quit"))
+		fmt.Fprintln(&b, u.helpView())
+		return b.String()
+	}
+
+	nodes := u.filterNodes(stats.Nodes)
+	if u.filtering || u.filter != "" {
+		fmt.Fprintf(&b, "filter: %s", u.filter)
+		if u.filtering {
+			fmt.Fprint(&b, "█")
+		}
+		fmt.Fprintf(&b, " (%d of %d nodes)\n", len(nodes), stats.NumNodes)
+	}
+	if u.editingSelector || !u.selector.Empty() {
+		u.writeSelector(&b, len(nodes), stats.NumNodes)
+	}
+
+	if len(nodes) == 0 {
+		fmt.Fprintln(&b)
+		fmt.Fprintln(&b, "No nodes match the filter...")
+		fmt.Fprintln(&b, u.helpView())
 		return b.String()
 	}
 
 	fmt.Fprintln(&b)
-	u.paginator.PerPage = u.computeItemsPerPage(stats.Nodes, &b)
-	u.paginator.SetTotalPages(stats.NumNodes)
-	// check if we're on a page that is outside of the NumNode upper bound
-	if u.paginator.Page*u.paginator.PerPage > stats.NumNodes {
-		// set the page to the last page
-		u.paginator.Page = u.paginator.TotalPages - 1
+	if u.grouping {
+		u.writeGroups(nodes, resources, &b)
+		fmt.Fprintln(&b, u.helpView())
+		return b.String()
 	}
-	start, end := u.paginator.GetSliceBounds(stats.NumNodes)
-	if start >= 0 && end >= start {
-		for _, n := range stats.Nodes[start:end] {
-			u.writeNodeInfo(n, ctw, u.cluster.resources)
-		}
+
+	u.paginator.PerPage = u.itemsPerPage(nodes, &b)
+	u.paginator.SetTotalPages(len(nodes))
+	// display the page with the node under the cursor
+	if u.cursor >= len(nodes) {
+		u.cursor = len(nodes) - 1
+	}
+	if u.paginator.PerPage > 0 {
+		u.paginator.Page = u.cursor / u.paginator.PerPage
+	}
+	start, end := u.paginator.GetSliceBounds(len(nodes))
+	if start >= 0 && end > start {
+		u.cursorNode = nodes[u.cursor]
+		u.fitColumns(nodes[start:end], resources)
+		ctw.SetMaxCellWidth(u.maxCellWidth)
+		u.writeNodes(nodes[start:end], &b, ctw)
 	}
 	ctw.Flush()
 
 	fmt.Fprintln(&b, u.paginator.View())
-	fmt.Fprintln(&b, helpStyle("←/→ page • q: quit"))
+	fmt.Fprintln(&b, u.helpView())
 	return b.String()
 }
 
-func (u *UIModel) writeNodeInfo(n *Node, w io.Writer, resources []v1.ResourceName) {
-	allocatable := n.Allocatable()
-	used := n.Used()
-	firstLine := true
-	resNameLen := 0
-	for _, res := range resources {
-		if len(res) > resNameLen {
-			resNameLen = len(res)
+// writeHeader writes the cluster summary along with any warnings
+func (u *UIModel) writeHeader(w io.Writer, stats Stats, clusterStats []Stats) {
+	resources := u.Cluster().resources
+	ctw := text.NewColorTabWriter(w, 0, 8, 1)
+	u.writeStatusline(w)
+	u.writeClusterSummary(resources, stats, ctw)
+	ctw.Flush()
+	u.writeExcludedUsage(w, resources, stats)
+	u.writeBreakdown(w, stats)
+	// with multiple clusters, the totals above are followed by a summary of each cluster
+	if len(u.clusters) > 1 {
+		for i, c := range u.clusters {
+			u.writeContextSummary(c.Name(), resources, clusterStats[i], ctw)
 		}
+		ctw.Flush()
 	}
-	for _, res := range resources {
-		usedRes := used[res]
-		allocatableRes := allocatable[res]
-		pct := usedRes.AsApproximateFloat64() / allocatableRes.AsApproximateFloat64()
-		if allocatableRes.AsApproximateFloat64() == 0 {
-			pct = 0
+	u.writeManagedNodeGroups(w, clusterStats)
+	u.progress.ShowPercentage = true
+	// message printer formats numbers nicely with commas
+	enPrinter := message.NewPrinter(language.English)
+	pendingStr := enPrinter.Sprintf("%d pending", stats.PodsByPhase[v1.PodPending])
+	if stats.PodsByPhase[v1.PodPending] > 0 {
+		pendingStr = u.style.red(pendingStr)
+	}
+	enPrinter.Fprintf(w, "%d pods (%s %d running %d bound)\n", stats.TotalPods,
+		pendingStr, stats.PodsByPhase[v1.PodRunning], stats.BoundPodCount)
+	if len(u.scheduledHistory) > 0 {
+		pctScheduled := u.scheduledHistory[len(u.scheduledHistory)-1]
+		pctScheduledStr := fmt.Sprintf("%0.1f%%", pctScheduled)
+		if pctScheduled >= 99 {
+			pctScheduledStr = u.style.green(pctScheduledStr)
+		} else if pctScheduled >= 90 {
+			pctScheduledStr = u.style.yellow(pctScheduledStr)
+		} else {
+			pctScheduledStr = u.style.red(pctScheduledStr)
+		}
+		fmt.Fprintf(w, "%s scheduled %s\n", pctScheduledStr, text.Sparkline(u.scheduledHistory, 0, 100))
+	}
+	if u.gpuUsage.Nodes > 0 || u.gpuUsage.GPUHours > 0 {
+		gpuStr := enPrinter.Sprintf("%d GPUs on %d nodes, %0.1f GPU-hours this session", u.gpuUsage.GPUs,
+			u.gpuUsage.Nodes, u.gpuUsage.GPUHours)
+		if !u.DisablePricing {
+			gpuStr += enPrinter.Sprintf(" ($%0.2f)", u.gpuUsage.Cost)
 		}
+		fmt.Fprintln(w, gpuStr)
+	}
+	if stats.CapacityTypeMismatches > 0 {
+		fmt.Fprintln(w, u.style.red(fmt.Sprintf("%d nodes have a capacity type label that doesn't match EC2", stats.CapacityTypeMismatches)))
+	}
+	if stats.Interrupted > 0 || stats.RebalanceRecommended > 0 {
+		fmt.Fprintln(w, u.style.red(fmt.Sprintf("%d nodes received a spot interruption notice, %d a rebalance recommendation",
+			stats.Interrupted, stats.RebalanceRecommended)))
+	}
+	if stats.HostBilled > 0 && !u.DisablePricing {
+		fmt.Fprintln(w, u.style.yellow(fmt.Sprintf("%d nodes run on dedicated hosts which are billed per host, their price isn't included", stats.HostBilled)))
+	}
+	if stats.Pressure > 0 {
+		fmt.Fprintln(w, u.style.red(fmt.Sprintf("%d nodes are reporting memory, disk or PID pressure or an unavailable network, pods may be evicted or fail to schedule", stats.Pressure)))
+	}
+	if stats.MaxPodsMismatches > 0 {
+		fmt.Fprintln(w, u.style.yellow(fmt.Sprintf("%d nodes have a max pods that doesn't match the ENI limit of their instance type", stats.MaxPodsMismatches)))
+	}
+	if stats.ProviderIDProblems > 0 {
+		fmt.Fprintln(w, u.style.yellow(fmt.Sprintf("%d nodes have a missing or duplicated provider ID, they can't be priced or may be confused with each other", stats.ProviderIDProblems)))
+	}
+	if stats.LowCPUCredits > 0 {
+		fmt.Fprintln(w, u.style.red(fmt.Sprintf("%d burstable nodes are low on CPU credits, their CPU may be throttled", stats.LowCPUCredits)))
+	}
+	if stale, ready, last := u.countStale(); stale > 0 && stale == ready {
+		fmt.Fprintln(w, u.style.red(fmt.Sprintf("no node updates received for %s, the watch may have stopped and the nodes may be out of date",
+			duration.HumanDuration(time.Since(last)))))
+	} else if stale > 0 {
+		fmt.Fprintln(w, u.style.yellow(fmt.Sprintf("%d nodes haven't been updated for %s, they may be out of date", stale,
+			duration.HumanDuration(u.StaleAfter))))
+	}
+	if skew := ClockSkew(); skew.Abs() >= clockSkewWarning && u.frames == nil {
+		direction := "behind"
+		if skew < 0 {
+			direction = "ahead of"
+		}
+		fmt.Fprintln(w, u.style.yellow(fmt.Sprintf("the local clock is %s %s the API server, ages are adjusted",
+			duration.HumanDuration(skew.Abs()), direction)))
+	}
+	if expired := u.countExpired(); expired > 0 {
+		fmt.Fprintln(w, u.style.red(fmt.Sprintf("%d nodes are older than the maximum lifetime of %s", expired,
+			duration.HumanDuration(u.MaxNodeLifetime))))
+	}
+	if stats.Reserved > 0 {
+		var reserved []string
+		for rn := range stats.ReservedResources {
+			reserved = append(reserved, string(rn))
+		}
+		sort.Strings(reserved)
+		fmt.Fprintln(w, u.style.red(fmt.Sprintf("%d nodes have allocatable far below capacity (%s)", stats.Reserved,
+			strings.Join(reserved, ", "))))
+	}
+}
 
-		if firstLine {
-			priceLabel := fmt.Sprintf("/$%0.4f", n.Price)
-			if !n.HasPrice() || u.DisablePricing {
-				priceLabel = ""
-			}
-			fmt.Fprintf(w, "%s\t%s\t%s\t(%d pods)\t%s%s", n.Name(), res, u.progress.ViewAs(pct), n.NumPods(), n.InstanceType(), priceLabel)
-
-			// node compute type
-			if n.IsOnDemand() {
-				fmt.Fprintf(w, "\tOn-Demand")
-			} else if n.IsSpot() {
-				fmt.Fprintf(w, "\tSpot")
-			} else if n.IsFargate() {
-				fmt.Fprintf(w, "\tFargate")
-			} else {
-				fmt.Fprintf(w, "\t-")
-			}
+// writeNodes writes the table header and the nodes to the tab writer, preceding the nodes of each cluster with a header
+// when displaying multiple clusters
+func (u *UIModel) writeNodes(nodes []*Node, w io.Writer, ctw *text.ColorTabWriter) {
+	multiCluster := len(u.clusters) > 1
+	if !multiCluster {
+		u.writeTableHeader(ctw)
+	}
+	currentCluster := ""
+	for i, n := range nodes {
+		if multiCluster && (i == 0 || n.clusterName() != currentCluster) {
+			currentCluster = n.clusterName()
+			ctw.Flush()
+			fmt.Fprintln(w, u.style.yellow(fmt.Sprintf("── %s ──", currentCluster)))
+			u.writeTableHeader(ctw)
+		}
+		u.writeNodeInfo(n, ctw, u.Cluster().resources)
+	}
+}
 
-			if n.IsAuto() {
-				fmt.Fprintf(w, "/Auto")
-			}
+// WriteSnapshot writes the cluster summary and all of the nodes matching the filter as plain text, for use when there
+// is no terminal to display the interactive view
+func (u *UIModel) WriteSnapshot(w io.Writer) error {
+	if now := time.Now(); now.Sub(u.lastScheduled) >= scheduledSampleInterval {
+		u.sample(now)
+	}
+	var b strings.Builder
+	// there's no terminal width to fit the columns to
+	u.hiddenColumns = nil
+	u.maxCellWidth = 0
+	stats, clusterStats := u.stats()
+	u.writeHeader(&b, stats, clusterStats)
+	fmt.Fprintln(&b)
+	nodes := u.filterNodes(stats.Nodes)
+	if len(nodes) == 0 {
+		fmt.Fprintln(&b, "Waiting for update or no nodes found...")
+	}
+	ctw := text.NewColorTabWriter(&b, 0, 8, 1)
+	u.writeNodes(nodes, &b, ctw)
+	ctw.Flush()
+	_, err := io.WriteString(w, b.String())
+	return err
+}
 
-			// node status
-			if n.Cordoned() && n.Deleting() {
-				fmt.Fprintf(w, "\tCordoned/Deleting")
-			} else if n.Deleting() {
-				fmt.Fprintf(w, "\tDeleting")
-			} else if n.Cordoned() {
-				fmt.Fprintf(w, "\tCordoned")
-			} else {
-				fmt.Fprintf(w, "\t-")
-			}
+// filterNodes returns the nodes that match the current filter, selector and quick filters
+func (u *UIModel) filterNodes(nodes []*Node) []*Node {
+	if u.filter == "" && u.selector.Empty() && !u.quickFiltering() {
+		return nodes
+	}
+	var filtered []*Node
+	for _, n := range nodes {
+		if u.nodeFilter(n) && n.MatchesSelector(u.selector) && u.matchesQuickFilters(n) {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered
+}
 
-			// node readiness or time we've been waiting for it to be ready
-			if n.Ready() {
-				fmt.Fprintf(w, "\tReady")
-			} else {
-				fmt.Fprintf(w, "\tNotReady/%s", duration.HumanDuration(time.Since(n.NotReadyTime())))
-			}
+// pendingPod is a pending pod along with the cluster that it's waiting to be scheduled in
+type pendingPod struct {
+	pod      *Pod
+	cluster  *Cluster
+	category PendingCategory
+}
 
-			for _, label := range u.extraLabels {
-				labelValue, ok := n.node.Labels[label]
-				if !ok {
-					// support computed label values
-					labelValue = n.ComputeLabel(label)
-				}
-				fmt.Fprintf(w, "\t%s", labelValue)
-			}
+func (u *UIModel) pendingPods() []pendingPod {
+	var pending []pendingPod
+	for _, c := range u.clusters {
+		nodes := c.VisibleNodes()
+		for _, p := range c.PendingPods() {
+			pending = append(pending, pendingPod{pod: p, cluster: c, category: CategorizePending(p, nodes)})
+		}
+	}
+	return pending
+}
 
+// pendingBreakdown summarizes how many of the pending pods are in each category, the categories that won't be
+// scheduled without changes to the pod or the nodes are in red
+func (u *UIModel) pendingBreakdown(pending []pendingPod) string {
+	counts := map[PendingCategory]int{}
+	for _, p := range pending {
+		counts[p.category]++
+	}
+	var parts []string
+	for _, category := range []PendingCategory{PendingGated, PendingUnschedulable, PendingCapacity, PendingStarting} {
+		if counts[category] == 0 {
+			continue
+		}
+		part := fmt.Sprintf("%d %s", counts[category], category)
+		if category == PendingGated || category == PendingUnschedulable {
+			part = u.style.red(part)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writePending lists the pending pods and the top reasons that the nodes reject the selected pod
+func (u *UIModel) writePending(w io.Writer) {
+	pending := u.pendingPods()
+	if len(pending) == 0 {
+		fmt.Fprintln(w, "No pending pods...")
+		return
+	}
+	if u.pendingIndex >= len(pending) {
+		u.pendingIndex = len(pending) - 1
+	}
+
+	// scroll the list to keep the selected pod visible
+	start := 0
+	if u.pendingIndex >= pendingListLen {
+		start = u.pendingIndex - pendingListLen + 1
+	}
+	end := start + pendingListLen
+	if end > len(pending) {
+		end = len(pending)
+	}
+	fmt.Fprintf(w, "%d pending pods: %s\n", len(pending), u.pendingBreakdown(pending))
+	ctw := text.NewColorTabWriter(w, 0, 8, 2)
+	for i := start; i < end; i++ {
+		name := pending[i].pod.Namespace() + "/" + pending[i].pod.Name()
+		if len(u.clusters) > 1 {
+			name = pending[i].cluster.Name() + " " + name
+		}
+		reason := pending[i].pod.PendingReason()
+		if r := []rune(reason); len(r) > pendingReasonWidth {
+			reason = string(r[:pendingReasonWidth-1]) + "…"
+		}
+		if i == u.pendingIndex {
+			fmt.Fprintf(ctw, "> %s\t%s\t%s\n", u.style.yellow(name), pending[i].category, reason)
 		} else {
-			fmt.Fprintf(w, " \t%s\t%s\t\t\t\t\t", res, u.progress.ViewAs(pct))
-			for range u.extraLabels {
-				fmt.Fprintf(w, "\t")
-			}
+			fmt.Fprintf(ctw, "  %s\t%s\t%s\n", name, pending[i].category, reason)
 		}
-		fmt.Fprintln(w)
-		firstLine = false
 	}
+	ctw.Flush()
+	fmt.Fprintln(w)
+
+	selected := pending[u.pendingIndex]
+	if selected.pod.IsScheduled() {
+		fmt.Fprintf(w, "%s/%s is scheduled to %s, %s\n", selected.pod.Namespace(), selected.pod.Name(),
+			selected.pod.NodeName(), selected.pod.PendingReason())
+		return
+	}
+	if gates := selected.pod.SchedulingGates(); len(gates) > 0 {
+		fmt.Fprintf(w, "%s/%s is gated by %s, it won't be scheduled until they're removed\n", selected.pod.Namespace(),
+			selected.pod.Name(), u.style.red(strings.Join(gates, ", ")))
+		return
+	}
+	nodes := selected.cluster.VisibleNodes()
+	fits, rejections := ExplainPending(selected.pod, nodes)
+	fmt.Fprintf(w, "%s/%s fits on %d of %d nodes\n", selected.pod.Namespace(), selected.pod.Name(), fits, len(nodes))
+	if len(rejections) > pendingReasonsLen {
+		rejections = rejections[:pendingReasonsLen]
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	for _, r := range rejections {
+		fmt.Fprintf(tw, "  %d nodes\t%s\n", r.Nodes, u.style.red(r.Reason))
+	}
+	tw.Flush()
+}
+
+func (u *UIModel) helpView() string {
+	if u.filtering {
+		return u.styleHelp("enter: apply filter • esc: clear filter")
+	}
+	if u.editingSelector {
+		return u.styleHelp("e.g. key=value, key in (a,b), key notin (a,b), key, !key • enter: done • esc: cancel")
+	}
+	if u.noting {
+		return fmt.Sprintf("note for %s: %s█ ", u.noteNode.Name(), u.noteText) +
+			u.styleHelp("enter: save (empty removes the note) • esc: cancel")
+	}
+	if u.actionNode != nil {
+		return u.styleHelp(u.nodeActionHelp())
+	}
+	if u.inspecting {
+		return fmt.Sprintf("search: %s█ ", u.inspectQuery) +
+			u.styleHelp("↑/↓ scroll • esc: clear search/show nodes • ctrl+c: quit")
+	}
+	if u.showPending {
+		return u.styleHelp("↑/↓ select pod • p/esc: show nodes • q: quit")
+	}
+	if u.comparing {
+		return u.styleHelp("c/esc: show nodes • r: raw units • q: quit")
+	}
+	if u.showSpot {
+		return u.styleHelp("z/esc: show nodes • q: quit")
+	}
+	if u.showNodePools {
+		return u.styleHelp("P/esc: show nodes • $: price unit • q: quit")
+	}
+	if u.showTaints {
+		return u.styleHelp("t/esc: show nodes • q: quit")
+	}
+	help := "←/→ page • ↑/↓ select • /: filter • L: label selector • ?: more keys • q: quit"
+	if u.fullHelp {
+		help = "←/→ page • ↑/↓ select • home/end: first/last • enter: copy name • " +
+			"m: mark • n: note • x: node action • t: taints • " +
+			"i: inspect • c: compare marked • s/S: sort column/direction • d: daemonsets • o: limits • r: raw units • " +
+			"a: usage of capacity • $: price unit • f: viewer footprint • /: filter • L: label selector • " +
+			"1-4: spot/on-demand/fargate/not ready • y: copy selector • Y: copy nodes • g: group • p: pending pods • " +
+			"z: spot prices • P: nodepools • e: export csv • ?: fewer keys • q: quit"
+	}
+	if u.grouping {
+		help = fmt.Sprintf("grouped by %s • ↑/↓ select group • enter: expand/collapse • g: ungroup • /: filter • ?: more keys • q: quit", u.groupLabel)
+		if u.fullHelp {
+			help = fmt.Sprintf("grouped by %s • ↑/↓ select group • enter: expand/collapse • g: ungroup • /: filter • "+
+				"L: label selector • y: copy selector • Y: copy nodes • p: pending pods • e: export csv • ?: fewer keys • "+
+				"q: quit", u.groupLabel)
+		}
+	}
+	if active := u.activeQuickFilters(); active != "" {
+		help = fmt.Sprintf("only %s • ", active) + help
+	}
+	if len(u.hiddenColumns) > 0 {
+		help = fmt.Sprintf("hidden to fit: %s • ", strings.Join(u.hiddenColumns, ", ")) + help
+	}
+	if u.priceUnit != 0 && !u.DisablePricing {
+		help = fmt.Sprintf("prices per %s • ", priceUnits[u.priceUnit].per) + help
+	}
+	if u.NormalizeAllocatable {
+		help = "usage of capacity • a: usage of allocatable • " + help
+	}
+	if u.usageMetrics && u.showActual {
+		help = "showing actual usage • u: show requests • " + help
+	} else if u.usageMetrics {
+		help = "showing requests • u: show actual usage • " + help
+	}
+	if u.showFootprint {
+		help = u.footprintView() + " • " + help
+	}
+	if u.status != "" {
+		help = u.status + " • " + help
+	}
+	if u.cursorNode != nil && !u.grouping {
+		if note, ok := u.Notes.Get(u.cursorNode.Name()); ok {
+			help = fmt.Sprintf("note: %s • %s", note.Text, help)
+		}
+	}
+	if u.frames != nil {
+		help = u.replayHelp() + help
+	}
+	return u.styleHelp(help)
+}
+
+// helpSeparator separates the keys of the help
+const helpSeparator = " • "
+
+// styleHelp styles the help, wrapping it between keys to the width of the terminal so that the terminal never wraps it
+// and its height can be left out of the page of nodes
+func (u *UIModel) styleHelp(help string) string {
+	if u.width <= 0 {
+		return helpStyle(help)
+	}
+	var lines []string
+	line := ""
+	for _, item := range strings.Split(help, helpSeparator) {
+		switch {
+		case line == "":
+			line = item
+		case lipgloss.Width(line+helpSeparator+item) > u.width:
+			lines = append(lines, helpStyle(line))
+			line = item
+		default:
+			line += helpSeparator + item
+		}
+	}
+	lines = append(lines, helpStyle(line))
+	return strings.Join(lines, "\n")
+}
+
+func (u *UIModel) writeNodeInfo(n *Node, w io.Writer, resources []v1.ResourceName) {
+	row := &nodeRow{
+		node:          n,
+		used:          n.Used(),
+		allocatable:   limitPods(n, u.usageBase(n.Allocatable(), n.Capacity())),
+		daemonSetUsed: n.DaemonSetUsed(),
+		reserved:      u.reservedResources(n),
+		limits:        n.Limits(),
+	}
+	if u.showActual {
+		row.used = n.ActualUsage()
+		// the metrics API doesn't report pods, but the pods on the node are their actual usage
+		if row.used != nil {
+			row.used[v1.ResourcePods] = n.Used()[v1.ResourcePods]
+		}
+	}
+	cells := u.tableCells()
+	for i := range u.rowsPerNode(resources) {
+		row.first = i == 0
+		if i < len(resources) {
+			row.resource = resources[i]
+		}
+		values := make([]string, len(cells))
+		for j, cell := range cells {
+			values[j] = u.cellValue(cell, row)
+		}
+		// rows that start with an empty cell aren't aligned with the others
+		if len(values) > 0 && values[0] == "" {
+			values[0] = " "
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+}
+
+// cellValue returns the value displayed in a cell of the node table, only the per-resource columns are displayed after
+// a node's first row and empty values aren't joined to the cell
+func (u *UIModel) cellValue(cell []nodeColumn, r *nodeRow) string {
+	var values []string
+	for k, c := range cell {
+		if !r.first && !c.perResource {
+			continue
+		}
+		if v := c.value(u, r); v != "" || k == 0 {
+			values = append(values, v)
+		}
+	}
+	return strings.Join(values, "/")
+}
+
+// nodeStatus returns the status of a node, starting with whether it's received a spot interruption notice or a
+// rebalance recommendation as those nodes are likely to be cordoned and deleted soon
+func (u *UIModel) nodeStatus(n *Node) string {
+	var status []string
+	if n.Interrupted() {
+		status = append(status, u.statusWord("Interrupted", u.style.red))
+	} else if n.RebalanceRecommended() {
+		status = append(status, u.statusWord("Rebalance", u.style.yellow))
+	}
+	if n.Cordoned() {
+		status = append(status, u.statusWord("Cordoned", nil))
+	}
+	if n.Deleting() {
+		status = append(status, u.statusWord("Deleting", nil))
+	}
+	if n.MaxPodsMismatch() {
+		status = append(status, u.statusWord("MaxPods", u.style.yellow))
+	}
+	if n.MissingProviderID() {
+		status = append(status, u.statusWord("NoProviderID", u.style.yellow))
+	} else if n.DuplicateProviderID() {
+		status = append(status, u.statusWord("DupProviderID", u.style.red))
+	}
+	if u.stale(n) {
+		status = append(status, u.statusWord("Stale", u.style.yellow))
+	}
+	if len(status) == 0 {
+		return "-"
+	}
+	return strings.Join(status, "/")
+}
+
+// expired returns true if the node is older than the maximum node lifetime
+func (u *UIModel) expired(n *Node) bool {
+	return u.MaxNodeLifetime > 0 && since(n.Created()) > u.MaxNodeLifetime
+}
+
+// countExpired returns the number of nodes that are older than the maximum node lifetime
+func (u *UIModel) countExpired() int {
+	if u.MaxNodeLifetime <= 0 {
+		return 0
+	}
+	count := 0
+	for _, c := range u.clusters {
+		count += c.expiredNodes(u.MaxNodeLifetime)
+	}
+	return count
+}
+
+// reservedResources returns the resources of the node whose allocatable amount is far below capacity
+func (u *UIModel) reservedResources(n *Node) []v1.ResourceName {
+	if u.MaxReserved <= 0 {
+		return nil
+	}
+	return n.ReservedResources(u.MaxReserved)
+}
+
+// capacityType returns the display name of the node's capacity type, nodes whose capacity type label doesn't match
+// EC2 also include the purchase option reported by EC2
+func capacityType(n *Node) string {
+	var ct string
+	if n.CapacityTypeMismatch() && n.IsSpot() {
+		ct = "Spot(On-Demand)"
+	} else if n.CapacityTypeMismatch() && n.IsOnDemand() {
+		ct = "On-Demand(Spot)"
+	} else if n.IsOnDemand() {
+		ct = "On-Demand"
+	} else if n.IsSpot() {
+		ct = "Spot"
+	} else if n.IsFargate() {
+		ct = "Fargate"
+	} else {
+		ct = "-"
+	}
+	if n.IsAuto() {
+		ct += "/Auto"
+	}
+	switch n.Tenancy() {
+	case TenancyDedicated:
+		ct += "/Dedicated"
+	case TenancyHost:
+		ct += "/Host"
+	}
+	return ct
 }
 
 func (u *UIModel) writeClusterSummary(resources []v1.ResourceName, stats Stats, w io.Writer) {
 	firstLine := true
 
+	base := u.usageBase(stats.AllocatableResources, stats.CapacityResources)
 	for _, res := range resources {
-		allocatable := stats.AllocatableResources[res]
+		allocatable := base[res]
 		used := stats.UsedResources[res]
+		if u.showActual {
+			used = stats.ActualUsedResources[res]
+		}
 		pctUsed := 0.0
 		if allocatable.AsApproximateFloat64() != 0 {
 			pctUsed = 100 * (used.AsApproximateFloat64() / allocatable.AsApproximateFloat64())
@@ -223,30 +972,121 @@ func (u *UIModel) writeClusterSummary(resources []v1.ResourceName, stats Stats,
 		}
 
 		u.progress.ShowPercentage = false
-		monthlyPrice := stats.TotalPrice * (365 * 24) / 12 // average hours per month
 		// message printer formats numbers nicely with commas
 		enPrinter := message.NewPrinter(language.English)
-		clusterPrice := enPrinter.Sprintf("$%0.3f/hour | $%0.3f/month", stats.TotalPrice, monthlyPrice)
+		clusterPrice := u.clusterCost(stats.TotalPrice)
 		if u.DisablePricing {
 			clusterPrice = ""
 		}
+		usage := fmt.Sprintf("%10s/%s", used.String(), allocatable.String())
+		if !u.RawQuantities {
+			usage = humanUsage(res, used, allocatable)
+		}
 		if firstLine {
-			enPrinter.Fprintf(w, "%d nodes\t(%10s/%s)\t%s\t%s\t%s\t%s\n",
-				stats.NumNodes, used.String(), allocatable.String(), pctUsedStr, res, u.progress.ViewAs(pctUsed/100.0), clusterPrice)
+			enPrinter.Fprintf(w, "%d nodes\t(%s)\t%s\t%s\t%s\t%s\n",
+				stats.NumNodes, usage, pctUsedStr, u.resourceName(res), u.progress.ViewAs(pctUsed/100.0), clusterPrice)
 		} else {
-			enPrinter.Fprintf(w, " \t%s/%s\t%s\t%s\t%s\t\n",
-				used.String(), allocatable.String(), pctUsedStr, res, u.progress.ViewAs(pctUsed/100.0))
+			enPrinter.Fprintf(w, " \t%s\t%s\t%s\t%s\t\n",
+				strings.TrimSpace(usage), pctUsedStr, u.resourceName(res), u.progress.ViewAs(pctUsed/100.0))
 		}
 		firstLine = false
 	}
 }
 
+// usageBase returns the resources that usage is measured against, the allocatable resources or the capacity when
+// normalizing to capacity
+func (u *UIModel) usageBase(allocatable, capacity v1.ResourceList) v1.ResourceList {
+	if u.NormalizeAllocatable {
+		return capacity
+	}
+	return allocatable
+}
+
+// writeContextSummary writes a single line summary of one of multiple clusters being displayed
+func (u *UIModel) writeContextSummary(name string, resources []v1.ResourceName, stats Stats, w io.Writer) {
+	enPrinter := message.NewPrinter(language.English)
+	enPrinter.Fprintf(w, "%s\t%d nodes\t%d pods", u.styleContextName(name), stats.NumNodes, stats.TotalPods)
+	base := u.usageBase(stats.AllocatableResources, stats.CapacityResources)
+	for _, res := range resources {
+		allocatable := base[res]
+		used := stats.UsedResources[res]
+		pctUsed := 0.0
+		if allocatable.AsApproximateFloat64() != 0 {
+			pctUsed = 100 * (used.AsApproximateFloat64() / allocatable.AsApproximateFloat64())
+		}
+		enPrinter.Fprintf(w, "\t%s %0.1f%%", u.resourceName(res), pctUsed)
+	}
+	if !u.DisablePricing {
+		fmt.Fprintf(w, "\t%s", cost(stats.TotalPrice, priceUnits[u.priceUnit]))
+	}
+	fmt.Fprintln(w)
+}
+
+// writeBreakdown writes a single line breaking down the nodes by zone and capacity type, as zone imbalance is a common
+// cause of pods failing to schedule
+func (u *UIModel) writeBreakdown(w io.Writer, stats Stats) {
+	if stats.NumNodes == 0 {
+		return
+	}
+	enPrinter := message.NewPrinter(language.English)
+	var zones []string
+	for zone := range stats.Zones {
+		zones = append(zones, zone)
+	}
+	sort.Slice(zones, func(a, b int) bool { return natsort.Compare(zones[a], zones[b]) })
+	var parts []string
+	for _, zone := range zones {
+		part := enPrinter.Sprintf("%s: %d nodes", zone, stats.Zones[zone].Nodes)
+		if !u.DisablePricing {
+			part += " " + cost(stats.Zones[zone].Price, priceUnits[u.priceUnit])
+		}
+		parts = append(parts, part)
+	}
+	var capacityTypes []string
+	for _, ct := range []string{"spot", "on-demand", "fargate", "other"} {
+		if b, ok := stats.CapacityTypes[ct]; ok {
+			capacityTypes = append(capacityTypes, fmt.Sprintf("%s %0.0f%%", ct, 100*float64(b.Nodes)/float64(stats.NumNodes)))
+		}
+	}
+	parts = append(parts, strings.Join(capacityTypes, " "))
+	if savings := u.spotSavingsSummary(stats); savings != "" {
+		parts = append(parts, u.style.green(savings))
+	}
+	fmt.Fprintln(w, strings.Join(parts, " | "))
+}
+
+// itemsPerPage returns the number of nodes displayed on each page. It's fitted to the height of the terminal unless a
+// page size is set, and with a fixed layout it's only fitted again when the terminal is resized so that nodes don't
+// move between pages as the cluster summary grows or shrinks.
+func (u *UIModel) itemsPerPage(nodes []*Node, b *strings.Builder) int {
+	if u.PageSize > 0 {
+		return u.PageSize
+	}
+	if u.FixedLayout && u.fixedPerPage > 0 {
+		return u.fixedPerPage
+	}
+	perPage := u.computeItemsPerPage(nodes, b)
+	// leave room for the table header, which is repeated for each cluster below the cluster's name
+	headers := 1
+	if len(u.clusters) > 1 {
+		headers = 2 * len(u.clusters)
+	}
+	if perPage > headers {
+		perPage -= headers
+	}
+	if u.FixedLayout && u.height > 0 {
+		u.fixedPerPage = perPage
+	}
+	return perPage
+}
+
 // computeItemsPerPage dynamically calculates the number of lines we can fit per page
 // taking into account header and footer text
 func (u *UIModel) computeItemsPerPage(nodes []*Node, b *strings.Builder) int {
 	var buf bytes.Buffer
-	u.writeNodeInfo(nodes[0], &buf, u.cluster.resources)
-	headerLines := strings.Count(b.String(), "\n") + 2
+	u.writeNodeInfo(nodes[0], &buf, u.Cluster().resources)
+	// the header is followed by the paginator and the help, which may be wrapped over several lines
+	headerLines := strings.Count(b.String(), "\n") + 1 + lipgloss.Height(u.helpView())
 	nodeLines := strings.Count(buf.String(), "\n")
 	if nodeLines == 0 {
 		nodeLines = 1
@@ -256,68 +1096,473 @@ func (u *UIModel) computeItemsPerPage(nodes []*Node, b *strings.Builder) int {
 
 type tickMsg time.Time
 
-func tickCmd() tea.Cmd {
-	return tea.Tick(100*time.Millisecond, func(t time.Time) tea.Msg {
+func (u *UIModel) tickCmd() tea.Cmd {
+	return tea.Tick(u.UpdateInterval, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
 func (u *UIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// anything other than a tick may change what's displayed
+	if _, ok := msg.(tickMsg); !ok {
+		u.dirty = true
+	}
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		u.height = msg.Height
-		return u, tickCmd()
+		u.width = msg.Width
+		// a fixed layout is fitted to the new height when it's next rendered
+		u.fixedPerPage = 0
+		return u, u.tickCmd()
 	case tea.KeyMsg:
+		if u.filtering {
+			return u, u.updateFilter(msg)
+		}
+		if u.editingSelector {
+			return u, u.updateSelector(msg)
+		}
+		if u.noting {
+			return u, u.updateNote(msg)
+		}
+		if u.actionNode != nil {
+			return u, u.updateNodeAction(msg)
+		}
+		if u.inspecting {
+			return u, u.updateInspector(msg)
+		}
+		if u.showPending {
+			switch msg.String() {
+			case "up", "k":
+				if u.pendingIndex > 0 {
+					u.pendingIndex--
+				}
+				return u, nil
+			case "down", "j":
+				u.pendingIndex++
+				return u, nil
+			case "p", "esc":
+				u.showPending = false
+				return u, nil
+			}
+		}
+		if u.grouping {
+			switch msg.String() {
+			case "up", "k":
+				if u.groupIndex > 0 {
+					u.groupIndex--
+				}
+				return u, nil
+			case "down", "j":
+				u.groupIndex++
+				return u, nil
+			case "enter", " ":
+				u.toggleGroup()
+				return u, nil
+			}
+		}
+		if u.comparing {
+			switch msg.String() {
+			case "c", "esc":
+				u.comparing = false
+			case "r":
+				u.RawQuantities = !u.RawQuantities
+			case "q", "ctrl+c":
+				return u, tea.Quit
+			}
+			return u, nil
+		}
+		if u.frames != nil && u.updateReplay(msg.String()) {
+			return u, nil
+		}
+		if u.showSpot {
+			switch msg.String() {
+			case "z", "esc":
+				u.showSpot = false
+			case "q", "ctrl+c":
+				return u, tea.Quit
+			}
+			return u, nil
+		}
+		if u.showNodePools {
+			switch msg.String() {
+			case "P", "esc":
+				u.showNodePools = false
+			case "$":
+				u.nextPriceUnit()
+			case "q", "ctrl+c":
+				return u, tea.Quit
+			}
+			return u, nil
+		}
+		if u.showTaints {
+			switch msg.String() {
+			case "t", "esc":
+				u.showTaints = false
+			case "q", "ctrl+c":
+				return u, tea.Quit
+			}
+			return u, nil
+		}
+		if !u.grouping && !u.showPending {
+			switch msg.String() {
+			case "up", "k":
+				if u.cursor > 0 {
+					u.cursor--
+				}
+				return u, nil
+			case "down", "j":
+				u.cursor++
+				return u, nil
+			case "home":
+				u.cursor = 0
+				return u, nil
+			case "end":
+				// clamped to the last node when displayed
+				u.cursor = math.MaxInt
+				return u, nil
+			case "enter":
+				if u.cursorNode != nil {
+					u.status = u.copyNodeName(u.cursorNode)
+				}
+				return u, nil
+			case "m":
+				if u.cursorNode != nil {
+					u.toggleMark(u.cursorNode)
+				}
+				return u, nil
+			case "c":
+				u.comparing = true
+				return u, nil
+			case "t":
+				if u.cursorNode != nil {
+					u.showTaints = true
+					u.taintNode = u.cursorNode
+				}
+				return u, nil
+			case "i":
+				if u.cursorNode != nil {
+					u.inspecting = true
+					u.inspectNode = u.cursorNode
+					u.inspectQuery = ""
+					u.inspectOffset = 0
+				}
+				return u, nil
+			case "n":
+				if u.cursorNode != nil {
+					u.noting = true
+					u.noteNode = u.cursorNode
+					note, _ := u.Notes.Get(u.noteNode.Name())
+					u.noteText = note.Text
+				}
+				return u, nil
+			}
+		}
 		switch msg.String() {
-		case "q", "esc", "ctrl+c":
+		case "g":
+			u.grouping = !u.grouping
+			return u, nil
+		case "?":
+			u.fullHelp = !u.fullHelp
+			return u, nil
+		case "p":
+			u.showPending = true
+			u.pendingIndex = 0
+			return u, nil
+		case "P":
+			u.showNodePools = true
+			return u, nil
+		case "z":
+			u.showSpot = true
+			return u, nil
+		case "/":
+			u.filtering = true
+			return u, nil
+		case "L":
+			u.editSelector()
+			return u, nil
+		case "e":
+			u.status = u.exportCSV()
+			return u, nil
+		case "y":
+			u.status = u.copySelector()
+			return u, nil
+		case "Y":
+			u.status = u.copyNodeList()
+			return u, nil
+		case "d":
+			u.showDaemonSets = !u.showDaemonSets
+			return u, nil
+		case "o":
+			u.showLimits = !u.showLimits
+			return u, nil
+		case "s":
+			u.nextSort()
+			return u, nil
+		case "S":
+			u.setSort(u.sortKey, !u.sortDescending)
+			return u, nil
+		case "u":
+			if u.usageMetrics {
+				u.showActual = !u.showActual
+			}
+			return u, nil
+		case "r":
+			u.RawQuantities = !u.RawQuantities
+			return u, nil
+		case "a":
+			u.NormalizeAllocatable = !u.NormalizeAllocatable
+			return u, nil
+		case "$":
+			u.nextPriceUnit()
+			return u, nil
+		case "f":
+			u.toggleFootprint()
+			return u, nil
+		case "x":
+			u.chooseNodeAction()
+			return u, nil
+		case "esc":
+			// the first escapes clear an applied filter, selector and quick filters
+			if u.filter != "" {
+				u.SetFilter("")
+				return u, nil
+			}
+			if !u.selector.Empty() {
+				u.applySelector(labels.Everything())
+				return u, nil
+			}
+			if len(u.quickFilters) > 0 {
+				u.quickFilters = nil
+				return u, nil
+			}
+			return u, tea.Quit
+		case "q", "ctrl+c":
 			return u, tea.Quit
+		default:
+			if u.toggleQuickFilter(msg.String()) {
+				return u, nil
+			}
 		}
+	case nodeActionMsg:
+		u.status = nodeActionStatus(msg)
+		return u, nil
 	case tickMsg:
-		return u, tickCmd()
+		if u.playing {
+			u.playFrames(time.Time(msg))
+		}
+		if time.Time(msg).Sub(u.lastScheduled) >= scheduledSampleInterval {
+			u.sample(time.Time(msg))
+		}
+		if u.showFootprint && time.Time(msg).Sub(u.footprint.sampled) >= footprintSampleInterval {
+			u.sampleFootprint(time.Time(msg))
+		}
+		return u, u.tickCmd()
 	}
 	var cmd tea.Cmd
+	page := u.paginator.Page
 	u.paginator, cmd = u.paginator.Update(msg)
+	// changing pages moves the cursor to the first node of the new page
+	if u.paginator.Page != page {
+		u.cursor = u.paginator.Page * u.paginator.PerPage
+	}
 	return u, cmd
 }
 
+// sample records the current percentage of pods that are bound to a node and accumulates the usage of GPU nodes and
+// NodePools
+func (u *UIModel) sample(now time.Time) {
+	var clusterStats []Stats
+	for _, c := range u.clusters {
+		clusterStats = append(clusterStats, c.Stats())
+	}
+	stats := MergeStats(clusterStats...)
+	u.gpuUsage.Accumulate(now, stats.Nodes)
+	u.nodePools.Accumulate(now, stats.Nodes)
+	if stats.TotalPods == 0 {
+		return
+	}
+	u.lastScheduled = now
+	u.dirty = true
+	u.scheduledHistory = append(u.scheduledHistory, 100*float64(stats.BoundPodCount)/float64(stats.TotalPods))
+	if len(u.scheduledHistory) > scheduledHistoryLen {
+		u.scheduledHistory = u.scheduledHistory[len(u.scheduledHistory)-scheduledHistoryLen:]
+	}
+}
+
+// updateFilter handles key presses while the filter prompt is open
+func (u *UIModel) updateFilter(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyEnter:
+		u.filtering = false
+	case tea.KeyEsc:
+		u.filtering = false
+		u.SetFilter("")
+	case tea.KeyBackspace:
+		if r := []rune(u.filter); len(r) > 0 {
+			u.SetFilter(string(r[:len(r)-1]))
+		}
+	case tea.KeySpace:
+		u.SetFilter(u.filter + " ")
+	case tea.KeyRunes:
+		u.SetFilter(u.filter + string(msg.Runes))
+	}
+	return nil
+}
+
+// updateNote handles key presses while a note is being edited, saving it on enter
+func (u *UIModel) updateNote(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyEnter:
+		u.noting = false
+		if err := u.Notes.Set(u.noteNode.Name(), strings.TrimSpace(u.noteText), time.Now()); err != nil {
+			u.status = fmt.Sprintf("saving note failed, %s", err)
+		}
+	case tea.KeyEsc:
+		u.noting = false
+	case tea.KeyBackspace:
+		if r := []rune(u.noteText); len(r) > 0 {
+			u.noteText = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		u.noteText += " "
+	case tea.KeyRunes:
+		u.noteText += string(msg.Runes)
+	}
+	return nil
+}
+
+// SetUsageSource sets the source of the resource usage that is displayed, either "requests" for the resources
+// requested by pods or "metrics" for the actual usage reported by the metrics API
+func (u *UIModel) SetUsageSource(source string) error {
+	switch source {
+	case "requests":
+		u.usageMetrics, u.showActual = false, false
+	case "metrics":
+		u.usageMetrics, u.showActual = true, true
+	default:
+		return fmt.Errorf("unknown usage source %q, must be 'requests' or 'metrics'", source)
+	}
+	return nil
+}
+
+// SetFilter restricts the displayed nodes to those matching the filter, see NewNodeFilter
+func (u *UIModel) SetFilter(filter string) {
+	u.filter = filter
+	u.nodeFilter = NewNodeFilter(filter)
+	u.paginator.Page = 0
+	u.cursor = 0
+	u.dirty = true
+}
+
 func (u *UIModel) SetResources(resources []string) {
-	u.cluster.resources = nil
+	var resourceNames []v1.ResourceName
 	for _, r := range resources {
-		u.cluster.resources = append(u.cluster.resources, v1.ResourceName(r))
+		resourceNames = append(resourceNames, v1.ResourceName(r))
+	}
+	for _, c := range u.clusters {
+		c.SetResources(resourceNames)
 	}
 }
 
-func makeNodeSorter(nodeSort string) func(lhs *Node, rhs *Node) bool {
-	sortOrder := func(b bool) bool { return b }
-	if strings.HasSuffix(nodeSort, "=asc") {
-		nodeSort = nodeSort[:len(nodeSort)-4]
-	}
-	if strings.HasSuffix(nodeSort, "=dsc") {
-		sortOrder = func(b bool) bool { return !b }
-		nodeSort = nodeSort[:len(nodeSort)-4]
+// nodeSorter orders the nodes by a node sort, see makeNodeSorter. A cluster keeps its nodes in order, computing the sort
+// key of each node when the node changes rather than re-sorting every node each time they're displayed.
+type nodeSorter struct {
+	// nodeSort is the node sort that the sorter was made from, of the form key=asc or key=dsc
+	nodeSort string
+	// key returns the key that the node is sorted by, scoring the node if needed
+	key  func(n *Node, s *scoreContext) sortKey
+	less func(lhs, rhs sortKey) bool
+	// volatile sorters have keys that change over time as well as when the node changes, so the nodes are re-sorted
+	// each time they're displayed
+	volatile bool
+}
+
+// sortKey is what a node is sorted by, only the fields used by the node's sorter are set
+type sortKey struct {
+	label      string
+	value      float64
+	created    time.Time
+	name       string
+	instanceID string
+}
+
+// compare returns -1, 0 or 1 as lhs is ordered before, along with or after rhs
+func (s *nodeSorter) compare(lhs, rhs sortKey) int {
+	switch {
+	case s.less(lhs, rhs):
+		return -1
+	case s.less(rhs, lhs):
+		return 1
 	}
+	return 0
+}
 
-	if nodeSort == "creation" {
-		return func(lhs *Node, rhs *Node) bool {
-			if lhs.Created() == rhs.Created() {
-				return sortOrder(natsort.Compare(lhs.Name(), rhs.Name()))
+// makeNodeSorter returns a sorter that orders the nodes by the node sort, a key of the form key=asc or key=dsc
+func makeNodeSorter(nodeSort string) *nodeSorter {
+	s := &nodeSorter{nodeSort: nodeSort}
+	key, descending := parseNodeSort(nodeSort)
+	byName := func(lhs, rhs sortKey) bool { return natsort.Compare(lhs.name, rhs.name) }
+	switch key {
+	case "name":
+		s.key = func(n *Node, _ *scoreContext) sortKey { return sortKey{name: n.Name()} }
+		s.less = byName
+	case "eks-node-viewer/node-health":
+		// health is sorted by the severity of the pressure conditions rather than by their names
+		s.key = func(n *Node, _ *scoreContext) sortKey {
+			return sortKey{value: float64(n.HealthSeverity()), name: n.Name()}
+		}
+		s.less = func(lhs, rhs sortKey) bool {
+			if lhs.value == rhs.value {
+				return byName(lhs, rhs)
 			}
-			return sortOrder(rhs.Created().Before(lhs.Created()))
+			return lhs.value < rhs.value
 		}
-	}
-
-	return func(lhs *Node, rhs *Node) bool {
-		lhsLabel, ok := lhs.node.Labels[nodeSort]
-		if !ok {
-			lhsLabel = lhs.ComputeLabel(nodeSort)
+	case "creation", "eks-node-viewer/node-age":
+		// the age is sorted by when the node was created rather than by the displayed duration, the newest node is the
+		// youngest
+		s.key = func(n *Node, _ *scoreContext) sortKey { return sortKey{created: n.Created(), name: n.Name()} }
+		s.less = func(lhs, rhs sortKey) bool {
+			if lhs.created.Equal(rhs.created) {
+				return byName(lhs, rhs)
+			}
+			return rhs.created.Before(lhs.created)
 		}
-		rhsLabel, ok := rhs.node.Labels[nodeSort]
-		if !ok {
-			rhsLabel = rhs.ComputeLabel(nodeSort)
+	case "eks-node-viewer/node-score":
+		// the score is relative to the other nodes and the ages of the nodes
+		s.volatile = true
+		s.key = func(n *Node, sc *scoreContext) sortKey {
+			return sortKey{value: math.Round(sc.score(n)), instanceID: n.InstanceID()}
 		}
-		if lhsLabel == rhsLabel {
-			return sortOrder(natsort.Compare(lhs.InstanceID(), rhs.InstanceID()))
+		s.less = func(lhs, rhs sortKey) bool {
+			if lhs.value == rhs.value {
+				return natsort.Compare(lhs.instanceID, rhs.instanceID)
+			}
+			return lhs.value < rhs.value
 		}
-		return sortOrder(natsort.Compare(lhsLabel, rhsLabel))
+	default:
+		// pods become unhealthy once their grace period ends without the node changing
+		s.volatile = key == "eks-node-viewer/node-unhealthy-pods"
+		s.key = func(n *Node, _ *scoreContext) sortKey {
+			label, ok := n.Label(key)
+			if !ok {
+				label = n.ComputeLabel(key)
+			}
+			return sortKey{label: label, instanceID: n.InstanceID()}
+		}
+		s.less = func(lhs, rhs sortKey) bool {
+			if lhs.label == rhs.label {
+				return natsort.Compare(lhs.instanceID, rhs.instanceID)
+			}
+			return natsort.Compare(lhs.label, rhs.label)
+		}
+	}
+	if descending {
+		asc := s.less
+		s.less = func(lhs, rhs sortKey) bool { return asc(rhs, lhs) }
 	}
+	return s
 }
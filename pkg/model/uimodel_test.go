@@ -0,0 +1,550 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+func TestWriteSnapshot(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "creation", style)
+	ui.SetResources([]string{"cpu"})
+
+	// more nodes than would fit on a page are all written
+	for i := 0; i < 50; i++ {
+		n := testNode(fmt.Sprintf("node-%d", i))
+		n.Spec.ProviderID = fmt.Sprintf("node-%d-id", i)
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+		node := model.NewNode(n)
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+
+	var buf bytes.Buffer
+	if err := ui.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	got := buf.String()
+	if !strings.Contains(got, "50 nodes") {
+		t.Errorf("expected the cluster summary, got\n%s", got)
+	}
+	for i := 0; i < 50; i++ {
+		if !strings.Contains(got, fmt.Sprintf("node-%d ", i)) {
+			t.Errorf("expected node-%d to be written", i)
+		}
+	}
+}
+
+func TestCursorPaging(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "creation", style)
+	ui.SetResources([]string{"cpu"})
+	for i := 0; i < 50; i++ {
+		n := testNode(fmt.Sprintf("node-%02d", i))
+		n.Spec.ProviderID = fmt.Sprintf("node-%02d-id", i)
+		n.CreationTimestamp = metav1.NewTime(time.Unix(int64(i), 0))
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+		node := model.NewNode(n)
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+	ui.Update(tea.WindowSizeMsg{Height: 20})
+
+	// newest nodes are first, so node-49 is on the first page
+	view := ui.View()
+	if !strings.Contains(view, "node-49") || strings.Contains(view, "node-00") {
+		t.Fatalf("expected the first page, got\n%s", view)
+	}
+	ui.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	if view := ui.View(); !strings.Contains(view, "node-00") || strings.Contains(view, "node-49") {
+		t.Errorf("expected end to display the last page, got\n%s", view)
+	}
+	ui.Update(tea.KeyMsg{Type: tea.KeyHome})
+	if view := ui.View(); !strings.Contains(view, "node-49") {
+		t.Errorf("expected home to display the first page, got\n%s", view)
+	}
+
+	// moving down past the bottom of the page displays the next page, less than 20 nodes fit on a page
+	for i := 0; i < 20; i++ {
+		ui.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if view := ui.View(); strings.Contains(view, "node-49") {
+		t.Errorf("expected moving down to display the next page, got\n%s", view)
+	}
+}
+
+func TestSortColumns(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "creation=dsc", style)
+	ui.SetResources([]string{"cpu"})
+	// creation order differs from name order
+	for i, name := range []string{"node-b", "node-c", "node-a"} {
+		n := testNode(name)
+		n.Spec.ProviderID = name + "-id"
+		n.CreationTimestamp = metav1.NewTime(time.Unix(int64(i), 0))
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+		node := model.NewNode(n)
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+	ui.Update(tea.WindowSizeMsg{Height: 40})
+
+	order := func(view string) string {
+		var names []string
+		for _, field := range strings.Fields(view) {
+			if strings.HasPrefix(field, "node-") {
+				names = append(names, field)
+			}
+		}
+		return strings.Join(names, ",")
+	}
+
+	view := ui.View()
+	if !strings.Contains(view, "sorted by creation ▼") {
+		t.Errorf("expected the header to indicate the sort, got\n%s", view)
+	}
+
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	view = ui.View()
+	if !strings.Contains(view, "NAME ▲") || order(view) != "node-a,node-b,node-c" {
+		t.Errorf("expected s to sort by name, got\n%s", view)
+	}
+
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	view = ui.View()
+	if !strings.Contains(view, "NAME ▼") || order(view) != "node-c,node-b,node-a" {
+		t.Errorf("expected S to reverse the sort, got\n%s", view)
+	}
+
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if view = ui.View(); !strings.Contains(view, "USAGE ▲") {
+		t.Errorf("expected s to sort by the next column, got\n%s", view)
+	}
+}
+
+func TestSortFollowsChanges(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "eks-node-viewer/node-price=asc", style)
+	ui.SetResources([]string{"cpu"})
+	nodes := map[string]*model.Node{}
+	for i, name := range []string{"node-a", "node-b", "node-c"} {
+		n := testNode(name)
+		n.Spec.ProviderID = name + "-id"
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+		node := model.NewNode(n)
+		node.SetPrice(float64(i + 1))
+		node.Show()
+		nodes[name] = ui.Cluster().AddNode(node)
+	}
+	ui.Update(tea.WindowSizeMsg{Height: 40})
+
+	order := func() string {
+		ui.Cluster().Invalidate()
+		var names []string
+		for _, field := range strings.Fields(ui.View()) {
+			if strings.HasPrefix(field, "node-") {
+				names = append(names, field)
+			}
+		}
+		return strings.Join(names, ",")
+	}
+	if got := order(); got != "node-a,node-b,node-c" {
+		t.Errorf("expected the nodes to be sorted by price, got %s", got)
+	}
+
+	// a node moves when its price changes, and leaves the list when it's hidden
+	nodes["node-a"].SetPrice(10)
+	if got := order(); got != "node-b,node-c,node-a" {
+		t.Errorf("expected node-a to move to the end, got %s", got)
+	}
+	nodes["node-c"].Hide()
+	if got := order(); got != "node-b,node-a" {
+		t.Errorf("expected node-c to be removed, got %s", got)
+	}
+}
+
+func TestViewRenderedWhenInvalidated(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "creation", style)
+	ui.SetResources([]string{"cpu"})
+	n := testNode("node-a")
+	n.Spec.ProviderID = "node-a-id"
+	n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+	node := model.NewNode(n)
+	node.Show()
+	node.SetPrice(1)
+	ui.Cluster().AddNode(node)
+	ui.Update(tea.WindowSizeMsg{Height: 40})
+
+	if view := ui.View(); !strings.Contains(view, "$1.0000") {
+		t.Fatalf("expected the node price, got\n%s", view)
+	}
+	// changes made directly to a node aren't rendered until the cluster is invalidated
+	node.SetPrice(2)
+	if view := ui.View(); !strings.Contains(view, "$1.0000") {
+		t.Errorf("expected the previous render, got\n%s", view)
+	}
+	ui.Cluster().Invalidate()
+	if view := ui.View(); !strings.Contains(view, "$2.0000") {
+		t.Errorf("expected the updated node price, got\n%s", view)
+	}
+}
+
+func TestViewCoalescesChanges(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "creation", style)
+	ui.SetResources([]string{"cpu"})
+	ui.FrameInterval = time.Hour
+	n := testNode("node-a")
+	n.Spec.ProviderID = "node-a-id"
+	node := model.NewNode(n)
+	node.Show()
+	node.SetPrice(1)
+	ui.Cluster().AddNode(node)
+	ui.Update(tea.WindowSizeMsg{Height: 40})
+
+	if view := ui.View(); !strings.Contains(view, "$1.0000") {
+		t.Fatalf("expected the node price, got\n%s", view)
+	}
+	// changes to the cluster wait for the next frame
+	node.SetPrice(2)
+	ui.Cluster().Invalidate()
+	if view := ui.View(); !strings.Contains(view, "$1.0000") {
+		t.Errorf("expected the previous render, got\n%s", view)
+	}
+	// but a key press is rendered immediately
+	ui.Update(tea.KeyMsg{Type: tea.KeyHome})
+	if view := ui.View(); !strings.Contains(view, "$2.0000") {
+		t.Errorf("expected the updated node price, got\n%s", view)
+	}
+}
+
+type testSpotPrices map[ec2types.InstanceType]map[string]float64
+
+func (t testSpotPrices) ZonalSpotPrices(instanceType ec2types.InstanceType) map[string]float64 {
+	return t[instanceType]
+}
+
+func TestSpotPrices(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "creation", style)
+	ui.SetResources([]string{"cpu"})
+	ui.SpotPrices = testSpotPrices{
+		"m5.large": {"us-west-2a": 0.04, "us-west-2b": 0.035, "us-west-2c": 0.05},
+	}
+	for i, zone := range []string{"us-west-2b", "us-west-2b", "us-west-2c"} {
+		n := testNode(fmt.Sprintf("node-%d", i))
+		n.Spec.ProviderID = fmt.Sprintf("node-%d-id", i)
+		n.Labels = map[string]string{v1.LabelInstanceTypeStable: "m5.large", v1.LabelTopologyZone: zone}
+		node := model.NewNode(n)
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+
+	ui.Update(tea.WindowSizeMsg{Height: 40})
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	view := ui.View()
+	for _, exp := range []string{"us-west-2a", "$0.0400", "$0.0350 (2)", "$0.0500 (1)", "z/esc: show nodes"} {
+		if !strings.Contains(view, exp) {
+			t.Errorf("expected the spot prices to contain %q, got\n%s", exp, view)
+		}
+	}
+
+	ui.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view := ui.View(); !strings.Contains(view, "node-0") {
+		t.Errorf("expected esc to return to the nodes, got\n%s", view)
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	newUI := func() *model.UIModel {
+		ui := model.NewUIModel(nil, "creation", style)
+		ui.SetResources([]string{"cpu"})
+		for i := 0; i < 50; i++ {
+			n := testNode(fmt.Sprintf("node-%02d", i))
+			n.Spec.ProviderID = fmt.Sprintf("node-%02d-id", i)
+			n.CreationTimestamp = metav1.NewTime(time.Unix(int64(i), 0))
+			n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+			node := model.NewNode(n)
+			node.Show()
+			ui.Cluster().AddNode(node)
+		}
+		ui.Update(tea.WindowSizeMsg{Height: 20})
+		return ui
+	}
+	displayed := func(ui *model.UIModel) int {
+		count := 0
+		for _, line := range strings.Split(ui.View(), "\n") {
+			if strings.HasPrefix(line, "node-") {
+				count++
+			}
+		}
+		return count
+	}
+
+	ui := newUI()
+	ui.PageSize = 5
+	if got := displayed(ui); got != 5 {
+		t.Errorf("expected 5 nodes on the page, got %d", got)
+	}
+
+	// a filter matching every node adds a line above the nodes
+	ui = newUI()
+	before := displayed(ui)
+	ui.SetFilter("node-")
+	if after := displayed(ui); after != before-1 {
+		t.Errorf("expected the filter to leave room for one less node, got %d then %d", before, after)
+	}
+
+	ui = newUI()
+	ui.FixedLayout = true
+	before = displayed(ui)
+	ui.SetFilter("node-")
+	if after := displayed(ui); after != before {
+		t.Errorf("expected a fixed layout to display %d nodes, got %d", before, after)
+	}
+	ui.Update(tea.WindowSizeMsg{Height: 30})
+	if after := displayed(ui); after <= before {
+		t.Errorf("expected resizing to fit more nodes than %d, got %d", before, after)
+	}
+}
+
+func TestInterruptionNotices(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	for i := range 3 {
+		n := testNode(fmt.Sprintf("node-%d", i))
+		n.Spec.ProviderID = fmt.Sprintf("node-%d-id", i)
+		node := model.NewNode(n)
+		switch i {
+		case 0:
+			node.SetInterrupted(time.Now())
+			node.SetRebalanceRecommended(time.Now())
+		case 1:
+			node.SetRebalanceRecommended(time.Now())
+		}
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+
+	var buf bytes.Buffer
+	if err := ui.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("writing snapshot, %s", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.Contains(buf.String(), "1 nodes received a spot interruption notice, 1 a rebalance recommendation") {
+		t.Errorf("expected the notices to be counted, got\n%s", buf.String())
+	}
+	for name, exp := range map[string]string{"node-0": "Interrupted", "node-1": "Rebalance"} {
+		found := false
+		for _, line := range lines {
+			if strings.HasPrefix(line, name+" ") {
+				found = strings.Contains(line, exp)
+			}
+		}
+		if !found {
+			t.Errorf("expected %s to have a status of %s, got\n%s", name, exp, buf.String())
+		}
+	}
+}
+
+func TestTaints(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	for _, name := range []string{"node-a", "node-b"} {
+		n := testNode(name)
+		n.Spec.ProviderID = name + "-id"
+		if name == "node-a" {
+			n.Spec.Taints = []v1.Taint{
+				{Key: "nvidia.com/gpu", Effect: v1.TaintEffectNoSchedule},
+				{Key: "dedicated", Value: "ml", Effect: v1.TaintEffectNoExecute},
+			}
+		} else {
+			// condition taints are already displayed as the node's readiness
+			n.Spec.Taints = []v1.Taint{{Key: "node.kubernetes.io/not-ready", Effect: v1.TaintEffectNoSchedule}}
+		}
+		node := model.NewNode(n)
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+	ui.Update(tea.WindowSizeMsg{Height: 40})
+
+	view := ui.View()
+	if !strings.Contains(view, "!node-a") || strings.Contains(view, "!node-b") {
+		t.Errorf("expected only node-a to be marked as tainted, got\n%s", view)
+	}
+	node, _ := ui.Cluster().GetNodeByName("node-a")
+	if got := node.ComputeLabel("eks-node-viewer/node-taints"); got != "2:nvidia.com/gpu,dedicated" {
+		t.Errorf("expected the taint summary, got %s", got)
+	}
+
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	view = ui.View()
+	if !strings.Contains(view, "node-a has 2 taints") || !strings.Contains(view, "ml") || !strings.Contains(view, "NoExecute") {
+		t.Errorf("expected the taints of the selected node, got\n%s", view)
+	}
+	ui.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view := ui.View(); strings.Contains(view, "node-a has 2 taints") {
+		t.Errorf("expected escape to show the nodes, got\n%s", view)
+	}
+}
+
+func TestHumanQuantities(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu", "memory"})
+	n := testNode("node-a")
+	n.Status.Allocatable = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2"),
+		v1.ResourceMemory: resource.MustParse("16252928Ki"),
+	}
+	node := model.NewNode(n)
+	node.Show()
+	ui.Cluster().AddNode(node)
+	ui.Update(tea.WindowSizeMsg{Height: 40})
+
+	if view := ui.View(); !strings.Contains(view, "0.0/15.5 GiB") || strings.Contains(view, "16252928Ki") {
+		t.Errorf("expected memory in GiB, got\n%s", view)
+	}
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	// the summed allocatable memory is displayed in its canonical form
+	if view := ui.View(); !strings.Contains(view, "0/15872Mi") || strings.Contains(view, "GiB") {
+		t.Errorf("expected raw memory quantities, got\n%s", view)
+	}
+}
+
+func TestNormalizeAllocatable(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "name", style)
+	ui.SetResources([]string{"cpu"})
+	n := testNode("node-a")
+	n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}
+	n.Status.Capacity = v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")}
+	node := model.NewNode(n)
+	node.Show()
+	ui.Cluster().AddNode(node)
+	// the pod's sidecar also requests a CPU
+	p := testPod("default", "web")
+	p.Spec.NodeName = "node-a"
+	p.Spec.Containers = []v1.Container{{Resources: v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+	}}}
+	ui.Cluster().AddPod(model.NewPod(p))
+	ui.Update(tea.WindowSizeMsg{Height: 40})
+
+	if view := ui.View(); !strings.Contains(view, "(2/4)") || !strings.Contains(view, "50.0%") {
+		t.Errorf("expected usage of allocatable, got\n%s", view)
+	}
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if view := ui.View(); !strings.Contains(view, "(2/8)") || !strings.Contains(view, "25.0%") ||
+		!strings.Contains(view, "usage of capacity") {
+		t.Errorf("expected usage of capacity, got\n%s", view)
+	}
+}
+
+func TestHelpFitsTerminal(t *testing.T) {
+	style, err := model.ParseStyle("#04B575,#FFFF00,#FF0000")
+	if err != nil {
+		t.Fatalf("parsing style, %s", err)
+	}
+	ui := model.NewUIModel(nil, "creation", style)
+	ui.SetResources([]string{"cpu"})
+	for i := 0; i < 50; i++ {
+		n := testNode(fmt.Sprintf("node-%02d", i))
+		n.Spec.ProviderID = fmt.Sprintf("node-%02d-id", i)
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}
+		node := model.NewNode(n)
+		node.Show()
+		ui.Cluster().AddNode(node)
+	}
+	ui.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+
+	check := func(fullHelp bool) {
+		view := strings.TrimSuffix(ui.View(), "\n")
+		if got := strings.Contains(view, "Y: copy nodes"); got != fullHelp {
+			t.Errorf("expected every key to be listed to be %t, got %t in\n%s", fullHelp, got, view)
+		}
+		lines := strings.Split(view, "\n")
+		// the help is wrapped to the terminal's width, and the page leaves room for it
+		for _, line := range lines {
+			if strings.Contains(line, "q: quit") || strings.Contains(line, "Y: copy nodes") {
+				if width := lipgloss.Width(line); width > 80 {
+					t.Errorf("expected the help to fit the terminal, got a line %d wide, %q", width, line)
+				}
+			}
+		}
+		if len(lines) > 30 {
+			t.Errorf("expected the view to fit the terminal, got %d lines\n%s", len(lines), view)
+		}
+	}
+	check(false)
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	check(true)
+	ui.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	check(false)
+}
@@ -0,0 +1,94 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricing
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// PriceLabel is the node label that overrides the price of a node
+const PriceLabel = "eks-node-viewer/instance-price"
+
+// Link is a provider in a chain along with the name that's reported for the nodes it prices
+type Link struct {
+	Name     string
+	Provider Provider
+}
+
+type chainProvider struct {
+	links []Link
+}
+
+// NewChainProvider returns a provider that prices each node with the first provider in the chain that has a price for
+// it, e.g. the price label, then a price file, then the cloud provider's pricing API and finally the static prices
+// embedded at build time. The name of the provider that priced each node is reported so that a wrong price can be
+// traced back to where it came from.
+func NewChainProvider(links ...Link) SourceProvider {
+	return &chainProvider{links: links}
+}
+
+func (c *chainProvider) NodePriceSource(n *model.Node) (float64, string, bool) {
+	for _, link := range c.links {
+		if price, ok := link.Provider.NodePrice(n); ok {
+			return price, link.Name, true
+		}
+	}
+	return math.NaN(), "", false
+}
+
+func (c *chainProvider) NodePrice(n *model.Node) (float64, bool) {
+	price, _, ok := c.NodePriceSource(n)
+	return price, ok
+}
+
+func (c *chainProvider) NodeDeleted(n *model.Node) {
+	for _, link := range c.links {
+		link.Provider.NodeDeleted(n)
+	}
+}
+
+func (c *chainProvider) OnUpdate(onUpdate func()) {
+	for _, link := range c.links {
+		link.Provider.OnUpdate(onUpdate)
+	}
+}
+
+type labelProvider struct{}
+
+// NewLabelProvider returns a provider that prices nodes with the hourly price in their eks-node-viewer/instance-price
+// label, nodes without the label or with a label that isn't a number have no price
+func NewLabelProvider() Provider {
+	return labelProvider{}
+}
+
+func (labelProvider) NodePrice(n *model.Node) (float64, bool) {
+	val, ok := n.Label(PriceLabel)
+	if !ok {
+		return math.NaN(), false
+	}
+	price, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return math.NaN(), false
+	}
+	return price, true
+}
+
+func (labelProvider) NodeDeleted(n *model.Node) {}
+
+// OnUpdate is a no-op as the price is updated along with the node's labels
+func (labelProvider) OnUpdate(onUpdate func()) {}
@@ -0,0 +1,67 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricing_test
+
+import (
+	"math"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+	"github.com/awslabs/eks-node-viewer/pkg/pricing"
+)
+
+func nodeWithLabels(name string, labels map[string]string) *model.Node {
+	return model.NewNode(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+	})
+}
+
+func TestChainProvider(t *testing.T) {
+	file := &fixedProvider{price: 1}
+	static := &fixedProvider{price: 2}
+	p := pricing.NewChainProvider(
+		pricing.Link{Name: "label", Provider: pricing.NewLabelProvider()},
+		pricing.Link{Name: "file", Provider: file},
+		pricing.Link{Name: "static", Provider: static},
+	)
+
+	for _, tc := range []struct {
+		labels map[string]string
+		price  float64
+		source string
+	}{
+		{map[string]string{pricing.PriceLabel: "0.5"}, 0.5, "label"},
+		{map[string]string{pricing.PriceLabel: "free"}, 1, "file"},
+		{nil, 1, "file"},
+	} {
+		price, source, ok := p.NodePriceSource(nodeWithLabels("mynode", tc.labels))
+		if !ok || price != tc.price || source != tc.source {
+			t.Errorf("expected %v to be priced at %f by %s, got %f by %s", tc.labels, tc.price, tc.source, price, source)
+		}
+	}
+
+	p.NodeDeleted(nodeWithLabels("deleted", nil))
+	if len(file.deleted) != 1 || len(static.deleted) != 1 {
+		t.Errorf("expected the deleted node to be passed to every provider, got file=%v static=%v", file.deleted, static.deleted)
+	}
+
+	labelOnly := pricing.NewChainProvider(pricing.Link{Name: "label", Provider: pricing.NewLabelProvider()})
+	if price, source, ok := labelOnly.NodePriceSource(nodeWithLabels("mynode", nil)); ok || source != "" || !math.IsNaN(price) {
+		t.Errorf("expected an unlabeled node to have no price, got %f by %q", price, source)
+	}
+}
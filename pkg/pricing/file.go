@@ -0,0 +1,82 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricing
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// InstancePrice is the hourly price of an instance type read from a price file. The spot price is used for spot nodes
+// in any zone that doesn't have its own spot price.
+type InstancePrice struct {
+	OnDemand  float64            `json:"onDemand,omitempty"`
+	Spot      float64            `json:"spot,omitempty"`
+	SpotZones map[string]float64 `json:"spotZones,omitempty"`
+}
+
+type fileProvider struct {
+	prices map[string]InstancePrice
+}
+
+// NewFileProvider returns a provider that reads prices from a YAML or JSON file mapping instance types to prices, e.g.
+//
+//	m5.large:
+//	  onDemand: 0.096
+//	  spot: 0.04
+//	  spotZones:
+//	    us-west-2a: 0.035
+//
+// Nodes with instance types that aren't in the file have no price.
+func NewFileProvider(path string) (Provider, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	prices := map[string]InstancePrice{}
+	if err := yaml.UnmarshalStrict(contents, &prices); err != nil {
+		return nil, fmt.Errorf("parsing %s, %w", path, err)
+	}
+	return &fileProvider{prices: prices}, nil
+}
+
+func (f *fileProvider) NodePrice(n *model.Node) (float64, bool) {
+	price, ok := f.prices[string(n.InstanceType())]
+	if !ok {
+		return 0, false
+	}
+	if n.IsSpot() {
+		if zonePrice, ok := price.SpotZones[n.Zone()]; ok {
+			return zonePrice, true
+		}
+		if price.Spot != 0 {
+			return price.Spot, true
+		}
+		return 0, false
+	}
+	if price.OnDemand != 0 {
+		return price.OnDemand, true
+	}
+	return 0, false
+}
+
+func (f *fileProvider) NodeDeleted(n *model.Node) {}
+
+// OnUpdate is a no-op as the prices are only read once
+func (f *fileProvider) OnUpdate(onUpdate func()) {}
@@ -0,0 +1,86 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricing_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+	"github.com/awslabs/eks-node-viewer/pkg/pricing"
+)
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	if err := os.WriteFile(path, []byte(`
+m5.large:
+  onDemand: 0.096
+  spot: 0.04
+  spotZones:
+    us-west-2a: 0.035
+c6g.xlarge:
+  onDemand: 0.136
+`), 0o600); err != nil {
+		t.Fatalf("writing price file, %s", err)
+	}
+	p, err := pricing.NewFileProvider(path)
+	if err != nil {
+		t.Fatalf("loading price file, %s", err)
+	}
+
+	for _, tc := range []struct {
+		instanceType string
+		capacityType string
+		zone         string
+		price        float64
+		ok           bool
+	}{
+		{"m5.large", "on-demand", "us-west-2a", 0.096, true},
+		{"m5.large", "spot", "us-west-2a", 0.035, true},
+		{"m5.large", "spot", "us-west-2b", 0.04, true},
+		{"c6g.xlarge", "spot", "us-west-2a", 0, false},
+		{"m6i.large", "on-demand", "us-west-2a", 0, false},
+	} {
+		node := model.NewNode(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "mynode",
+				Labels: map[string]string{
+					v1.LabelInstanceTypeStable:   tc.instanceType,
+					v1.LabelTopologyZone:         tc.zone,
+					"karpenter.sh/capacity-type": tc.capacityType,
+				},
+			},
+		})
+		price, ok := p.NodePrice(node)
+		if ok != tc.ok || price != tc.price {
+			t.Errorf("expected %s %s in %s to be priced at %f/%v, got %f/%v", tc.capacityType, tc.instanceType, tc.zone,
+				tc.price, tc.ok, price, ok)
+		}
+	}
+}
+
+func TestFileProviderUnknownField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	if err := os.WriteFile(path, []byte("m5.large:\n  price: 0.096\n"), 0o600); err != nil {
+		t.Fatalf("writing price file, %s", err)
+	}
+	if _, err := pricing.NewFileProvider(path); err == nil {
+		t.Errorf("expected an error for an unknown field")
+	}
+}
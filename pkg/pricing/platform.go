@@ -0,0 +1,60 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricing
+
+import (
+	"math"
+	"strings"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+type platformProvider struct {
+	providers map[string]Provider
+}
+
+// NewPlatformProvider returns a provider that prices each node with the provider for its platform. The platform is the
+// scheme of the node's provider ID, e.g. "aws", "azure" or "gce".
+func NewPlatformProvider(providers map[string]Provider) Provider {
+	return &platformProvider{providers: providers}
+}
+
+// Platform returns the platform that a node runs on based on its provider ID, or an empty string if it's unknown
+func Platform(n *model.Node) string {
+	platform, _, ok := strings.Cut(n.ProviderID(), "://")
+	if !ok {
+		return ""
+	}
+	return platform
+}
+
+func (p *platformProvider) NodePrice(n *model.Node) (float64, bool) {
+	if provider, ok := p.providers[Platform(n)]; ok {
+		return provider.NodePrice(n)
+	}
+	return math.NaN(), false
+}
+
+func (p *platformProvider) NodeDeleted(n *model.Node) {
+	if provider, ok := p.providers[Platform(n)]; ok {
+		provider.NodeDeleted(n)
+	}
+}
+
+func (p *platformProvider) OnUpdate(onUpdate func()) {
+	for _, provider := range p.providers {
+		provider.OnUpdate(onUpdate)
+	}
+}
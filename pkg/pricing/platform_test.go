@@ -0,0 +1,74 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricing_test
+
+import (
+	"math"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+	"github.com/awslabs/eks-node-viewer/pkg/pricing"
+)
+
+type fixedProvider struct {
+	price   float64
+	deleted []string
+}
+
+func (f *fixedProvider) NodePrice(n *model.Node) (float64, bool) { return f.price, true }
+func (f *fixedProvider) NodeDeleted(n *model.Node)               { f.deleted = append(f.deleted, n.Name()) }
+func (f *fixedProvider) OnUpdate(onUpdate func())                {}
+
+func nodeWithProviderID(name, providerID string) *model.Node {
+	return model.NewNode(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.NodeSpec{ProviderID: providerID},
+	})
+}
+
+func TestPlatformProvider(t *testing.T) {
+	aws := &fixedProvider{price: 1}
+	azure := &fixedProvider{price: 2}
+	p := pricing.NewPlatformProvider(map[string]pricing.Provider{"aws": aws, "azure": azure})
+
+	for _, tc := range []struct {
+		providerID string
+		platform   string
+		price      float64
+		ok         bool
+	}{
+		{"aws:///us-west-2a/i-0123456789abcdef0", "aws", 1, true},
+		{"azure:///subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachineScaleSets/vmss/virtualMachines/0", "azure", 2, true},
+		{"gce://project/us-central1-a/gke-node", "gce", 0, false},
+		{"", "", 0, false},
+	} {
+		node := nodeWithProviderID("mynode", tc.providerID)
+		if platform := pricing.Platform(node); platform != tc.platform {
+			t.Errorf("expected platform of %q to be %q, got %q", tc.providerID, tc.platform, platform)
+		}
+		price, ok := p.NodePrice(node)
+		if ok != tc.ok || (ok && price != tc.price) || (!ok && !math.IsNaN(price)) {
+			t.Errorf("expected %q to be priced at %f/%v, got %f/%v", tc.providerID, tc.price, tc.ok, price, ok)
+		}
+	}
+
+	p.NodeDeleted(nodeWithProviderID("azure-node", "azure:///subscriptions/sub"))
+	if len(aws.deleted) != 0 || len(azure.deleted) != 1 {
+		t.Errorf("expected the deleted node to be passed to only the azure provider, got aws=%v azure=%v", aws.deleted, azure.deleted)
+	}
+}
@@ -14,10 +14,52 @@ limitations under the License.
 
 package pricing
 
-import "github.com/awslabs/eks-node-viewer/pkg/model"
+import (
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
 
 // Provider provides node prices for display in the node viewer
 type Provider interface {
 	NodePrice(n *model.Node) (float64, bool)
+	// NodeDeleted is called when a node is removed from the cluster so that any pricing state, such as the reserved
+	// capacity applied to it, can be released
+	NodeDeleted(n *model.Node)
+	OnUpdate(onUpdate func())
+}
+
+// SourceProvider is a provider that also reports the name of the provider that priced a node, e.g. a chain of
+// providers
+type SourceProvider interface {
+	Provider
+	NodePriceSource(n *model.Node) (price float64, source string, ok bool)
+}
+
+// LifecycleProvider provides the purchase option reported by EC2 for a node's instance so that nodes with a capacity
+// type label that doesn't match how they were launched can be detected
+type LifecycleProvider interface {
+	InstanceLifecycle(instanceID string) (string, bool)
+	OnUpdate(onUpdate func())
+}
+
+// TenancyProvider is a LifecycleProvider that also provides the tenancy reported by EC2 for a node's instance, so that
+// nodes on dedicated hardware aren't displayed with the shared tenancy price
+type TenancyProvider interface {
+	LifecycleProvider
+	InstanceTenancy(instanceID string) (string, bool)
+}
+
+// OnDemandProvider provides the on-demand price of a node's instance type, regardless of how the node was launched,
+// so that spot nodes can be displayed with their savings compared to on-demand
+type OnDemandProvider interface {
+	OnDemandNodePrice(n *model.Node) (float64, bool)
+	OnUpdate(onUpdate func())
+}
+
+// InstanceTypeProvider provides the hardware of instance types, e.g. their vCPUs and memory, so that nodes can be
+// compared by their price per vCPU and GiB of memory
+type InstanceTypeProvider interface {
+	InstanceTypeInfo(instanceType ec2types.InstanceType) (model.InstanceTypeInfo, bool)
 	OnUpdate(onUpdate func())
 }
@@ -0,0 +1,106 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricing
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// regionalRefreshPeriod is how often the prices of a region are reloaded
+	regionalRefreshPeriod = 12 * time.Hour
+	// regionalRetryPeriod is how long to wait before retrying a region whose prices failed to load
+	regionalRetryPeriod = 5 * time.Minute
+)
+
+// RegionalCache lazily loads prices for each region the first time they are requested, so that providers for clouds
+// whose pricing APIs are queried per region only load the regions that the cluster's nodes are in
+type RegionalCache[T any] struct {
+	ctx  context.Context
+	load func(ctx context.Context, region string) (T, error)
+
+	mu            sync.Mutex
+	onUpdateFuncs []func()
+	regions       map[string]*regionalEntry[T]
+}
+
+type regionalEntry[T any] struct {
+	prices      T
+	loaded      bool
+	loading     bool
+	lastAttempt time.Time
+}
+
+// NewRegionalCache returns a cache that calls load in the background to load the prices of a region
+func NewRegionalCache[T any](ctx context.Context, load func(ctx context.Context, region string) (T, error)) *RegionalCache[T] {
+	return &RegionalCache[T]{
+		ctx:     ctx,
+		load:    load,
+		regions: map[string]*regionalEntry[T]{},
+	}
+}
+
+// OnUpdate registers a function that is called each time the prices of a region are loaded
+func (c *RegionalCache[T]) OnUpdate(onUpdate func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onUpdateFuncs = append(c.onUpdateFuncs, onUpdate)
+}
+
+// Get returns the prices for the region if they have been loaded. If they haven't been loaded, or are out of date,
+// they are loaded in the background.
+func (c *RegionalCache[T]) Get(region string) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.regions[region]
+	if !ok {
+		e = &regionalEntry[T]{}
+		c.regions[region] = e
+	}
+	retry := regionalRetryPeriod
+	if e.loaded {
+		retry = regionalRefreshPeriod
+	}
+	if !e.loading && time.Since(e.lastAttempt) > retry {
+		e.loading = true
+		e.lastAttempt = time.Now()
+		go c.loadRegion(region)
+	}
+	return e.prices, e.loaded
+}
+
+func (c *RegionalCache[T]) loadRegion(region string) {
+	prices, err := c.load(c.ctx, region)
+
+	c.mu.Lock()
+	e := c.regions[region]
+	e.loading = false
+	if err != nil {
+		c.mu.Unlock()
+		slog.Error("loading prices", "region", region, "error", err)
+		return
+	}
+	e.prices = prices
+	e.loaded = true
+	onUpdateFuncs := c.onUpdateFuncs
+	c.mu.Unlock()
+
+	for _, f := range onUpdateFuncs {
+		f()
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/awslabs/eks-node-viewer/pkg/pricing"
+)
+
+func TestRegionalCache(t *testing.T) {
+	loaded := make(chan string, 1)
+	c := pricing.NewRegionalCache(context.Background(), func(ctx context.Context, region string) (int, error) {
+		loaded <- region
+		return len(region), nil
+	})
+	updated := make(chan struct{}, 1)
+	c.OnUpdate(func() { updated <- struct{}{} })
+
+	if _, ok := c.Get("eastus"); ok {
+		t.Fatalf("expected prices to not be loaded before the first load completes")
+	}
+	select {
+	case region := <-loaded:
+		if region != "eastus" {
+			t.Errorf("expected eastus to be loaded, got %s", region)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the region to be loaded")
+	}
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the update callback")
+	}
+
+	prices, ok := c.Get("eastus")
+	if !ok || prices != len("eastus") {
+		t.Errorf("expected loaded prices %d, got %d/%v", len("eastus"), prices, ok)
+	}
+	// the region was just loaded, so it shouldn't be loaded again
+	select {
+	case region := <-loaded:
+		t.Errorf("expected no reload, got a load of %s", region)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// Line is a line written by WriteLine, the stats and nodes of the clusters at a point in time in the same format as
+// the API
+type Line struct {
+	Time  time.Time `json:"time"`
+	Stats Stats     `json:"stats"`
+	Nodes []Node    `json:"nodes"`
+}
+
+// WriteLine writes the stats and nodes of the clusters as a single line of JSON. Writing a line every interval gives
+// log pipelines such as Loki, CloudWatch Logs or vector a time series of the clusters from the same watching and
+// pricing as the terminal display.
+func WriteLine(w io.Writer, clusters []*model.Cluster, now time.Time) error {
+	st := (&Server{clusters: clusters}).state(now)
+	return json.NewEncoder(w).Encode(Line{Time: now, Stats: st.stats, Nodes: st.nodeList()})
+}
+
+// Snapshot is the stats, nodes and pods of the clusters at a point in time in the same format as the API
+type Snapshot struct {
+	Time  time.Time `json:"time"`
+	Stats Stats     `json:"stats"`
+	Nodes []Node    `json:"nodes"`
+	Pods  []Pod     `json:"pods"`
+}
+
+// NewSnapshot returns a snapshot of the clusters
+func NewSnapshot(clusters []*model.Cluster, now time.Time) Snapshot {
+	st := (&Server{clusters: clusters}).state(now)
+	return Snapshot{Time: now, Stats: st.stats, Nodes: st.nodeList(), Pods: st.podList()}
+}
+
+// Generation returns a counter that changes each time any of the clusters change
+func Generation(clusters []*model.Cluster) uint64 {
+	return (&Server{clusters: clusters}).generation()
+}
@@ -0,0 +1,81 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+	"github.com/awslabs/eks-node-viewer/pkg/server"
+)
+
+func TestWriteLine(t *testing.T) {
+	cluster := model.NewCluster()
+	cluster.AddNode(testNode("node-a", 0.5))
+	cluster.AddNode(testNode("node-b", 0.25))
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := server.WriteLine(&buf, []*model.Cluster{cluster}, now); err != nil {
+		t.Fatalf("writing line, %s", err)
+	}
+	cluster.DeleteNode("node-a-id")
+	if err := server.WriteLine(&buf, []*model.Cluster{cluster}, now.Add(30*time.Second)); err != nil {
+		t.Fatalf("writing line, %s", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected a line per write, got %d lines", len(lines))
+	}
+	var first, second server.Line
+	if err := json.Unmarshal(lines[0], &first); err != nil {
+		t.Fatalf("decoding line, %s", err)
+	}
+	if err := json.Unmarshal(lines[1], &second); err != nil {
+		t.Fatalf("decoding line, %s", err)
+	}
+	if !first.Time.Equal(now) || first.Stats.Total.Nodes != 2 || first.Stats.Total.PricePerHour != 0.75 || len(first.Nodes) != 2 {
+		t.Errorf("expected 2 nodes costing 0.75 per hour, got %+v", first)
+	}
+	if second.Stats.Total.Nodes != 1 || len(second.Nodes) != 1 || second.Nodes[0].Name != "node-b" {
+		t.Errorf("expected only node-b after node-a was deleted, got %+v", second)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	cluster := model.NewCluster()
+	cluster.AddNode(testNode("node-a", 0.5))
+	clusters := []*model.Cluster{cluster}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	generation := server.Generation(clusters)
+	snapshot := server.NewSnapshot(clusters, now)
+	if !snapshot.Time.Equal(now) || len(snapshot.Nodes) != 1 || snapshot.Nodes[0].Name != "node-a" ||
+		snapshot.Stats.Total.PricePerHour != 0.5 || snapshot.Pods == nil {
+		t.Errorf("expected node-a costing 0.5 per hour and no pods, got %+v", snapshot)
+	}
+
+	cluster.AddNode(testNode("node-b", 0.25))
+	if server.Generation(clusters) == generation {
+		t.Errorf("expected the generation to change after adding a node")
+	}
+	if snapshot = server.NewSnapshot(clusters, now); len(snapshot.Nodes) != 2 || snapshot.Stats.Total.Nodes != 2 {
+		t.Errorf("expected 2 nodes, got %+v", snapshot)
+	}
+}
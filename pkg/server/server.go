@@ -0,0 +1,325 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/websocket"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+)
+
+// Node is a node as it's returned by the API
+type Node struct {
+	Cluster      string            `json:"cluster,omitempty"`
+	Name         string            `json:"name"`
+	InstanceID   string            `json:"instanceID,omitempty"`
+	InstanceType string            `json:"instanceType,omitempty"`
+	CapacityType string            `json:"capacityType,omitempty"`
+	Zone         string            `json:"zone,omitempty"`
+	Created      time.Time         `json:"created"`
+	Ready        bool              `json:"ready"`
+	Cordoned     bool              `json:"cordoned"`
+	Deleting     bool              `json:"deleting"`
+	Pods         int               `json:"pods"`
+	Price        *float64          `json:"price,omitempty"`
+	PriceSource  string            `json:"priceSource,omitempty"`
+	Allocatable  v1.ResourceList   `json:"allocatable,omitempty"`
+	Used         v1.ResourceList   `json:"used,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+}
+
+// Pod is a pod as it's returned by the API
+type Pod struct {
+	Cluster   string      `json:"cluster,omitempty"`
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	Node      string      `json:"node,omitempty"`
+	Phase     v1.PodPhase `json:"phase"`
+}
+
+// Stats is the status of all of the clusters along with the status of each cluster by name
+type Stats struct {
+	Total    model.Status            `json:"total"`
+	Clusters map[string]model.Status `json:"clusters,omitempty"`
+}
+
+// Event is a change pushed to websocket clients. The first event is a snapshot of the nodes, pods and stats, followed
+// by an event for each node or pod that is added, changed or deleted and each time the stats change.
+type Event struct {
+	// Type is one of snapshot, node, node-deleted, pod, pod-deleted or stats
+	Type  string `json:"type"`
+	Nodes []Node `json:"nodes,omitempty"`
+	Pods  []Pod  `json:"pods,omitempty"`
+	Node  *Node  `json:"node,omitempty"`
+	Pod   *Pod   `json:"pod,omitempty"`
+	Stats *Stats `json:"stats,omitempty"`
+}
+
+// Server serves the nodes, pods and stats of the clusters being watched over HTTP so that dashboards can be built on
+// the same watching and pricing as the terminal display
+type Server struct {
+	clusters []*model.Cluster
+	// interval is how often websocket clients are checked for changes to push
+	interval time.Duration
+}
+
+// New returns a server for the clusters, changes are pushed to websocket clients at most once per interval
+func New(clusters []*model.Cluster, interval time.Duration) *Server {
+	return &Server{clusters: clusters, interval: interval}
+}
+
+// Handler returns the handler for the API:
+//
+//	GET /api/v1/nodes  the visible nodes
+//	GET /api/v1/pods   the pods
+//	GET /api/v1/stats  the status of the clusters
+//	GET /api/v1/watch  a websocket that pushes Events as the clusters change
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/nodes", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.state(time.Now()).nodeList())
+	})
+	mux.HandleFunc("GET /api/v1/pods", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.state(time.Now()).podList())
+	})
+	mux.HandleFunc("GET /api/v1/stats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.state(time.Now()).stats)
+	})
+	// the server has no handshake so that dashboards served from any origin can connect
+	mux.Handle("GET /api/v1/watch", websocket.Server{Handler: s.watch})
+	return mux
+}
+
+// ListenAndServe serves the API on the address until the context is done
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{Addr: addr, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("writing response", "error", err)
+	}
+}
+
+// watch sends a snapshot to a websocket client followed by the changes to the clusters, until the client disconnects
+func (s *Server) watch(ws *websocket.Conn) {
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel()
+	// clients don't send anything, so reading only detects when they disconnect
+	go func() {
+		io.Copy(io.Discard, ws)
+		cancel()
+	}()
+
+	prev := s.state(time.Now())
+	generation := s.generation()
+	if err := websocket.JSON.Send(ws, Event{Type: "snapshot", Nodes: prev.nodeList(), Pods: prev.podList(), Stats: &prev.stats}); err != nil {
+		return
+	}
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			// nothing is displayed differently unless a cluster changed
+			g := s.generation()
+			if g == generation {
+				continue
+			}
+			generation = g
+			next := s.state(now)
+			for _, e := range changes(prev, next) {
+				if err := websocket.JSON.Send(ws, e); err != nil {
+					return
+				}
+			}
+			prev = next
+		}
+	}
+}
+
+func (s *Server) generation() uint64 {
+	var generation uint64
+	for _, c := range s.clusters {
+		generation += c.Generation()
+	}
+	return generation
+}
+
+// state is the nodes and pods of the clusters keyed by cluster and name, along with their stats
+type state struct {
+	nodes     map[string]Node
+	nodeOrder []string
+	pods      map[string]Pod
+	podOrder  []string
+	stats     Stats
+}
+
+func (s *Server) state(now time.Time) state {
+	st := state{nodes: map[string]Node{}, pods: map[string]Pod{}, stats: Stats{Clusters: map[string]model.Status{}}}
+	var clusterStats []model.Stats
+	for _, c := range s.clusters {
+		cs := c.Stats()
+		clusterStats = append(clusterStats, cs)
+		if len(s.clusters) > 1 {
+			st.stats.Clusters[c.Name()] = model.NewStatus(cs, now)
+		}
+		for _, n := range cs.Nodes {
+			node := newNode(c.Name(), n)
+			key := c.Name() + "/" + node.Name
+			st.nodes[key] = node
+			st.nodeOrder = append(st.nodeOrder, key)
+		}
+		for _, p := range c.Pods() {
+			pod := Pod{Cluster: c.Name(), Namespace: p.Namespace(), Name: p.Name(), Node: p.NodeName(), Phase: p.Phase()}
+			key := c.Name() + "/" + pod.Namespace + "/" + pod.Name
+			st.pods[key] = pod
+			st.podOrder = append(st.podOrder, key)
+		}
+	}
+	st.stats.Total = model.NewStatus(model.MergeStats(clusterStats...), now)
+	return st
+}
+
+func newNode(cluster string, n *model.Node) Node {
+	node := Node{
+		Cluster:      cluster,
+		Name:         n.Name(),
+		InstanceID:   n.InstanceID(),
+		InstanceType: string(n.InstanceType()),
+		CapacityType: n.ComputeLabel("eks-node-viewer/node-capacity-type"),
+		Zone:         n.Zone(),
+		Created:      n.Created(),
+		Ready:        n.Ready(),
+		Cordoned:     n.Cordoned(),
+		Deleting:     n.Deleting(),
+		Pods:         n.NumPods(),
+		PriceSource:  n.PriceSource(),
+		Allocatable:  n.Allocatable(),
+		Used:         n.Used(),
+		Labels:       n.Labels(),
+	}
+	if n.HasPrice() {
+		price := n.Price()
+		node.Price = &price
+	}
+	return node
+}
+
+func (st state) nodeList() []Node {
+	nodes := make([]Node, 0, len(st.nodeOrder))
+	for _, key := range st.nodeOrder {
+		nodes = append(nodes, st.nodes[key])
+	}
+	return nodes
+}
+
+func (st state) podList() []Pod {
+	pods := make([]Pod, 0, len(st.podOrder))
+	for _, key := range st.podOrder {
+		pods = append(pods, st.pods[key])
+	}
+	return pods
+}
+
+// changes returns the events that turn the previous state into the next
+func changes(prev, next state) []Event {
+	events := nodeChanges(prev, next)
+	events = append(events, podChanges(prev, next)...)
+	if !sameStats(prev.stats, next.stats) {
+		events = append(events, Event{Type: "stats", Stats: &next.stats})
+	}
+	return events
+}
+
+func nodeChanges(prev, next state) []Event {
+	var events []Event
+	for _, key := range next.nodeOrder {
+		node := next.nodes[key]
+		if old, ok := prev.nodes[key]; !ok || !sameJSON(old, node) {
+			events = append(events, Event{Type: "node", Node: &node})
+		}
+	}
+	for _, key := range prev.nodeOrder {
+		if _, ok := next.nodes[key]; !ok {
+			node := prev.nodes[key]
+			events = append(events, Event{Type: "node-deleted", Node: &Node{Cluster: node.Cluster, Name: node.Name}})
+		}
+	}
+	return events
+}
+
+func podChanges(prev, next state) []Event {
+	var events []Event
+	for _, key := range next.podOrder {
+		pod := next.pods[key]
+		if old, ok := prev.pods[key]; !ok || old != pod {
+			events = append(events, Event{Type: "pod", Pod: &pod})
+		}
+	}
+	for _, key := range prev.podOrder {
+		if _, ok := next.pods[key]; !ok {
+			pod := prev.pods[key]
+			events = append(events, Event{Type: "pod-deleted", Pod: &pod})
+		}
+	}
+	return events
+}
+
+// sameStats returns true if the stats are the same other than when they were computed
+func sameStats(lhs, rhs Stats) bool {
+	withoutTime := func(st Stats) Stats {
+		clusters := map[string]model.Status{}
+		for name, cs := range st.Clusters {
+			cs.Updated = time.Time{}
+			clusters[name] = cs
+		}
+		st.Total.Updated = time.Time{}
+		st.Clusters = clusters
+		return st
+	}
+	return sameJSON(withoutTime(lhs), withoutTime(rhs))
+}
+
+// sameJSON returns true if the values encode to the same JSON, which compares resource quantities by their value rather
+// than by how they're stored
+func sameJSON(lhs, rhs any) bool {
+	lhsJSON, lhsErr := json.Marshal(lhs)
+	rhsJSON, rhsErr := json.Marshal(rhs)
+	return lhsErr == nil && rhsErr == nil && bytes.Equal(lhsJSON, rhsJSON)
+}
@@ -0,0 +1,119 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+	"github.com/awslabs/eks-node-viewer/pkg/server"
+)
+
+func testNode(name string, price float64) *model.Node {
+	n := model.NewNode(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{v1.LabelInstanceTypeStable: "m5.large"},
+		},
+		Spec: v1.NodeSpec{ProviderID: name + "-id"},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			Conditions:  []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}},
+		},
+	})
+	n.SetPrice(price)
+	n.SetPriceSource("file")
+	n.Show()
+	return n
+}
+
+func TestServer(t *testing.T) {
+	cluster := model.NewCluster()
+	cluster.AddNode(testNode("node-a", 0.5))
+	srv := httptest.NewServer(server.New([]*model.Cluster{cluster}, 10*time.Millisecond).Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/v1/nodes")
+	if err != nil {
+		t.Fatalf("getting nodes, %s", err)
+	}
+	var nodes []server.Node
+	if err := json.NewDecoder(resp.Body).Decode(&nodes); err != nil {
+		t.Fatalf("decoding nodes, %s", err)
+	}
+	resp.Body.Close()
+	if len(nodes) != 1 || nodes[0].Name != "node-a" || nodes[0].Price == nil || *nodes[0].Price != 0.5 ||
+		nodes[0].PriceSource != "file" || !nodes[0].Ready {
+		t.Errorf("expected node-a priced at 0.5 by file, got %+v", nodes)
+	}
+
+	resp, err = http.Get(srv.URL + "/api/v1/stats")
+	if err != nil {
+		t.Fatalf("getting stats, %s", err)
+	}
+	var stats server.Stats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatalf("decoding stats, %s", err)
+	}
+	resp.Body.Close()
+	if stats.Total.Nodes != 1 || stats.Total.PricePerHour != 0.5 {
+		t.Errorf("expected 1 node costing 0.5 per hour, got %+v", stats.Total)
+	}
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/v1/watch", "", srv.URL)
+	if err != nil {
+		t.Fatalf("connecting to watch, %s", err)
+	}
+	defer ws.Close()
+	var event server.Event
+	if err := websocket.JSON.Receive(ws, &event); err != nil {
+		t.Fatalf("receiving snapshot, %s", err)
+	}
+	if event.Type != "snapshot" || len(event.Nodes) != 1 || event.Stats == nil {
+		t.Errorf("expected a snapshot of 1 node, got %+v", event)
+	}
+
+	cluster.AddNode(testNode("node-b", 0.25))
+	cluster.DeleteNode("node-a-id")
+	// the changes may be pushed together or across two intervals
+	var added, deleted, updated bool
+	for !added || !deleted || !updated {
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		event = server.Event{}
+		if err := websocket.JSON.Receive(ws, &event); err != nil {
+			t.Fatalf("receiving changes, %s", err)
+		}
+		switch event.Type {
+		case "node":
+			added = added || event.Node.Name == "node-b"
+		case "node-deleted":
+			deleted = deleted || event.Node.Name == "node-a"
+		case "stats":
+			updated = updated || event.Stats.Total.PricePerHour == 0.25
+		default:
+			t.Fatalf("unexpected %s event", event.Type)
+		}
+	}
+}
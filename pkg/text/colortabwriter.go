@@ -28,6 +28,8 @@ type ColorTabWriter struct {
 
 	contents   [][][]byte
 	cellWidths []int
+	// maxCellWidth is the width that longer cells are truncated to, zero if they aren't truncated
+	maxCellWidth int
 }
 
 func NewColorTabWriter(output io.Writer, minWidth, tabWidth, padding int) *ColorTabWriter {
@@ -39,6 +41,12 @@ func NewColorTabWriter(output io.Writer, minWidth, tabWidth, padding int) *Color
 	}
 }
 
+// SetMaxCellWidth truncates cells that are wider than width when they're flushed, ending them with an ellipsis. A
+// width of zero leaves the cells as they are.
+func (c *ColorTabWriter) SetMaxCellWidth(width int) {
+	c.maxCellWidth = width
+}
+
 func (c *ColorTabWriter) Write(buf []byte) (n int, err error) {
 	for _, ch := range buf {
 		switch ch {
@@ -61,6 +69,10 @@ func (c *ColorTabWriter) Flush() {
 			c.cellWidths = append(c.cellWidths, 0)
 		}
 		for i, cell := range line {
+			if c.maxCellWidth > 0 {
+				cell = truncate(cell, c.maxCellWidth)
+				line[i] = cell
+			}
 			cellLen := strlen(cell)
 			if cellLen > c.cellWidths[i] {
 				c.cellWidths[i] = cellLen
@@ -118,7 +130,9 @@ func strlen(cell []byte) int {
 				nChars++
 			}
 		default:
-			if !inEscape {
+			// count runes rather than bytes so that cells with multi-byte characters, such as the blocks of a
+			// progress bar, align with plain text
+			if !inEscape && c&0xC0 != 0x80 {
 				nChars++
 			}
 		}
@@ -126,6 +140,42 @@ func strlen(cell []byte) int {
 	return nChars
 }
 
+// truncate shortens the cell to width characters, the last of which is an ellipsis. Escape sequences are kept, so a
+// colored cell is still reset after it's truncated.
+func truncate(cell []byte, width int) []byte {
+	if strlen(cell) <= width {
+		return cell
+	}
+	var truncated []byte
+	nChars := 0
+	inEscape := false
+	for _, c := range cell {
+		switch {
+		case c == 0x1b:
+			inEscape = true
+			truncated = append(truncated, c)
+		case inEscape:
+			if c == 'm' {
+				inEscape = false
+			}
+			truncated = append(truncated, c)
+		case c&0xC0 == 0x80:
+			// the remaining bytes of a multi-byte character are kept along with its first byte
+			if nChars < width {
+				truncated = append(truncated, c)
+			}
+		default:
+			nChars++
+			if nChars < width {
+				truncated = append(truncated, c)
+			} else if nChars == width {
+				truncated = append(truncated, "…"...)
+			}
+		}
+	}
+	return truncated
+}
+
 func (c *ColorTabWriter) newCell() {
 	if len(c.contents) == 0 {
 		c.newLine()
@@ -0,0 +1,175 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+)
+
+const instrumentationName = "github.com/awslabs/eks-node-viewer"
+
+var (
+	// apiDuration and pricingDuration are the latency histograms of the calls made to the API server and to fetch
+	// prices, they're created from the global meter so they're exported once Start sets the meter provider
+	apiDuration     = histogram("eks_node_viewer.api.duration", "Duration of list and watch calls to the Kubernetes API server")
+	pricingDuration = histogram("eks_node_viewer.pricing.fetch.duration", "Duration of fetching prices from the cloud provider's pricing APIs")
+)
+
+// Start configures global tracer and meter providers that export spans and metrics via OTLP/HTTP to the given endpoint
+// URL. If the endpoint is empty, the standard OTEL_EXPORTER_OTLP_* environment variables are used to configure the
+// exporters. The returned function flushes any pending spans and metrics and should be called before exiting.
+func Start(ctx context.Context, endpoint string, version string) (func(context.Context) error, error) {
+	var traceOpts []otlptracehttp.Option
+	var metricOpts []otlpmetrichttp.Option
+	if endpoint != "" {
+		tracesURL, err := signalURL(endpoint, "traces")
+		if err != nil {
+			return nil, err
+		}
+		metricsURL, err := signalURL(endpoint, "metrics")
+		if err != nil {
+			return nil, err
+		}
+		traceOpts = append(traceOpts, otlptracehttp.WithEndpointURL(tracesURL))
+		metricOpts = append(metricOpts, otlpmetrichttp.WithEndpointURL(metricsURL))
+	}
+	traceExporter, err := otlptracehttp.New(ctx, traceOpts...)
+	if err != nil {
+		return nil, err
+	}
+	metricExporter, err := otlpmetrichttp.New(ctx, metricOpts...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName("eks-node-viewer"),
+		semconv.ServiceVersion(version)))
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
+		sdkmetric.WithResource(res))
+	otel.SetMeterProvider(mp)
+	return func(ctx context.Context) error {
+		return multierr.Append(tp.Shutdown(ctx), mp.Shutdown(ctx))
+	}, nil
+}
+
+// signalURL returns the URL that traces or metrics are exported to. Like OTEL_EXPORTER_OTLP_ENDPOINT, the endpoint is
+// the collector's base URL that the signal's path is added to, e.g. http://localhost:4318 exports metrics to
+// http://localhost:4318/v1/metrics. An endpoint of the traces path, e.g. http://localhost:4318/v1/traces, exports
+// metrics to the same collector.
+func signalURL(endpoint, signal string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	base := strings.TrimSuffix(strings.TrimSuffix(u.Path, "/v1/traces"), "/")
+	u.Path = base + "/v1/" + signal
+	return u.String(), nil
+}
+
+// Tracer returns the tracer used for all eks-node-viewer spans. If tracing hasn't been started, this is a no-op tracer.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Meter returns the meter used for all eks-node-viewer metrics. If tracing hasn't been started, this is a no-op meter.
+func Meter() metric.Meter {
+	return otel.Meter(instrumentationName)
+}
+
+// histogram returns a histogram of durations in seconds, or a no-op histogram if it can't be created
+func histogram(name, description string) metric.Float64Histogram {
+	h, err := Meter().Float64Histogram(name, metric.WithUnit("s"), metric.WithDescription(description))
+	if err != nil {
+		otel.Handle(err)
+		return noop.Float64Histogram{}
+	}
+	return h
+}
+
+// StartSpan starts a new span with the given name and attributes
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartAPISpan starts a span for a call to the Kubernetes API server, its duration is also recorded in the API latency
+// histogram when it's ended with EndSpan
+func StartAPISpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return startTimedSpan(ctx, apiDuration, name, attrs...)
+}
+
+// StartPricingSpan starts a span for fetching prices, its duration is also recorded in the pricing latency histogram
+// when it's ended with EndSpan
+func StartPricingSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return startTimedSpan(ctx, pricingDuration, name, attrs...)
+}
+
+// timedSpan is a span whose duration is recorded in a histogram when it's ended with EndSpan, along with its name, its
+// attributes and whether it failed
+type timedSpan struct {
+	trace.Span
+	ctx       context.Context
+	histogram metric.Float64Histogram
+	start     time.Time
+	attrs     []attribute.KeyValue
+}
+
+func startTimedSpan(ctx context.Context, h metric.Float64Histogram, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := StartSpan(ctx, name, attrs...)
+	return ctx, &timedSpan{
+		Span:      span,
+		ctx:       ctx,
+		histogram: h,
+		start:     time.Now(),
+		attrs:     append([]attribute.KeyValue{attribute.String("operation", name)}, attrs...),
+	}
+}
+
+// EndSpan records the error, if any, on the span and ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	if ts, ok := span.(*timedSpan); ok {
+		ts.histogram.Record(ts.ctx, time.Since(ts.start).Seconds(),
+			metric.WithAttributes(append(ts.attrs, attribute.Bool("error", err != nil))...))
+	}
+	span.End()
+}
@@ -0,0 +1,40 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import "testing"
+
+func TestSignalURL(t *testing.T) {
+	for _, tc := range []struct {
+		endpoint string
+		traces   string
+		metrics  string
+	}{
+		{"http://localhost:4318", "http://localhost:4318/v1/traces", "http://localhost:4318/v1/metrics"},
+		{"http://localhost:4318/", "http://localhost:4318/v1/traces", "http://localhost:4318/v1/metrics"},
+		{"https://collector.example.com/v1/traces", "https://collector.example.com/v1/traces", "https://collector.example.com/v1/metrics"},
+		{"https://collector.example.com/otlp", "https://collector.example.com/otlp/v1/traces", "https://collector.example.com/otlp/v1/metrics"},
+	} {
+		for signal, exp := range map[string]string{"traces": tc.traces, "metrics": tc.metrics} {
+			got, err := signalURL(tc.endpoint, signal)
+			if err != nil {
+				t.Fatalf("parsing %s, %s", tc.endpoint, err)
+			}
+			if got != exp {
+				t.Errorf("expected %s of %s to be exported to %s, got %s", signal, tc.endpoint, exp, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package viewer runs the watching and pricing behind eks-node-viewer without the terminal display so that it can be
+// embedded in other tools, e.g. a Slack bot or an operator that reports the cost of a cluster.
+package viewer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+
+	"github.com/awslabs/eks-node-viewer/pkg/aws"
+	"github.com/awslabs/eks-node-viewer/pkg/client"
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+	"github.com/awslabs/eks-node-viewer/pkg/pricing"
+	"github.com/awslabs/eks-node-viewer/pkg/server"
+)
+
+// defaultSubscribeInterval is how often subscriptions check for changes when the options don't set an interval
+const defaultSubscribeInterval = time.Second
+
+// Options configures a Viewer, only the connection is required
+type Options struct {
+	// Connection is how to connect to the clusters
+	Connection client.Connection
+	// Contexts are the kubeconfig contexts of the clusters to watch, the current context is watched if it's empty
+	Contexts []string
+	// NodeSelector limits the nodes that are watched, every node is watched if it's nil
+	NodeSelector labels.Selector
+	// PodNamespace limits the pods that are watched to a single namespace
+	PodNamespace string
+	// Resources are the resources whose usage is tracked, only cpu is tracked if it's empty
+	Resources []v1.ResourceName
+	// Pricing prices the nodes, the prices embedded at build time are used if it's nil
+	Pricing pricing.Provider
+	// OnDemand provides the on-demand prices that spot savings are compared against
+	OnDemand pricing.OnDemandProvider
+	// InstanceTypes provides the capacity of instance types for nodes that haven't registered yet, the instance types
+	// embedded at build time are used if it's nil
+	InstanceTypes pricing.InstanceTypeProvider
+	// Lifecycle looks up the capacity type of nodes that aren't labeled with it
+	Lifecycle pricing.LifecycleProvider
+	// LegacyMachines also watches the v1alpha5 Machines of old Karpenter versions
+	LegacyMachines bool
+	// Interval is how often subscriptions check for changes, it defaults to one second
+	Interval time.Duration
+	// ForCluster returns the options used to watch a cluster given its identity, e.g. to price the cluster from its own
+	// region when the clusters are in different regions. The options are used as they are if it's nil.
+	ForCluster func(id client.Identity, opts Options) Options
+	// Cluster returns the cluster that the nodes and pods of the i'th context are added to. A new cluster named
+	// after the context is used if it's nil, the terminal display uses it to watch the clusters of its own model.
+	Cluster func(i int, context string) *model.Cluster
+}
+
+// Cluster is a cluster being watched
+type Cluster struct {
+	// Model is the nodes and pods of the cluster
+	Model *model.Cluster
+	// Controller watches the cluster, it's used to start the optional watches such as usage metrics
+	Controller *client.Controller
+	// Identity names the cluster from the kubeconfig
+	Identity client.Identity
+}
+
+// Viewer watches and prices the nodes and pods of one or more clusters
+type Viewer struct {
+	clusters []Cluster
+	models   []*model.Cluster
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	// wg tracks the subscriptions so that Stop waits for them to close their channels
+	wg sync.WaitGroup
+}
+
+// New starts watching the clusters of the contexts, call Stop to stop watching them
+func New(opts Options) (*Viewer, error) {
+	contexts := opts.Contexts
+	// an empty context name uses the current context
+	if len(contexts) == 0 {
+		contexts = []string{""}
+	}
+	if opts.Pricing == nil {
+		opts.Pricing = aws.NewStaticPricingProvider("")
+	}
+	if opts.InstanceTypes == nil {
+		opts.InstanceTypes = aws.NewStaticInstanceTypeProvider()
+	}
+	if opts.NodeSelector == nil {
+		opts.NodeSelector = labels.Everything()
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultSubscribeInterval
+	}
+
+	v := &Viewer{interval: interval}
+	for i, kubeContext := range contexts {
+		c, err := newCluster(opts, i, kubeContext)
+		if err != nil {
+			return nil, err
+		}
+		v.clusters = append(v.clusters, c)
+		v.models = append(v.models, c.Model)
+	}
+
+	// the clients are all created before any of the watches are started so that nothing is left running on an error
+	v.ctx, v.cancel = context.WithCancel(context.Background())
+	for i, c := range v.clusters {
+		c.Controller.Start(v.ctx)
+		// the skew of the local clock is the same for every cluster
+		if i == 0 {
+			c.Controller.StartClockSkew(v.ctx)
+		}
+	}
+	return v, nil
+}
+
+func newCluster(opts Options, i int, kubeContext string) (Cluster, error) {
+	cs, err := client.NewKubernetes(opts.Connection, kubeContext)
+	if err != nil {
+		return Cluster{}, fmt.Errorf("creating client, %w", err)
+	}
+	nodeClaimClient, err := client.NewNodeClaims(opts.Connection, kubeContext)
+	if err != nil {
+		return Cluster{}, fmt.Errorf("creating node claim client, %w", err)
+	}
+	nodeClaimV1Beta1Client, err := client.NewNodeClaimsV1Beta1(opts.Connection, kubeContext)
+	if err != nil {
+		return Cluster{}, fmt.Errorf("creating v1beta1 node claim client, %w", err)
+	}
+	var machineClient *rest.RESTClient
+	if opts.LegacyMachines {
+		if machineClient, err = client.NewMachines(opts.Connection, kubeContext); err != nil {
+			return Cluster{}, fmt.Errorf("creating machine client, %w", err)
+		}
+	}
+	id, err := client.Identify(opts.Connection, kubeContext)
+	if err != nil {
+		return Cluster{}, fmt.Errorf("identifying cluster, %w", err)
+	}
+	if opts.ForCluster != nil {
+		opts = opts.ForCluster(id, opts)
+	}
+
+	var cluster *model.Cluster
+	if opts.Cluster != nil {
+		cluster = opts.Cluster(i, kubeContext)
+	} else {
+		cluster = model.NewCluster()
+		cluster.SetName(kubeContext)
+	}
+	if len(opts.Resources) != 0 {
+		cluster.SetResources(opts.Resources)
+	}
+	controller := client.NewController(cs, nodeClaimClient, machineClient, cluster, opts.NodeSelector, opts.Pricing, opts.Lifecycle)
+	controller.SetPodNamespace(opts.PodNamespace)
+	controller.SetInstanceTypeProvider(opts.InstanceTypes)
+	controller.SetNodeClaimV1Beta1Client(nodeClaimV1Beta1Client)
+	if opts.OnDemand != nil {
+		controller.SetOnDemandProvider(opts.OnDemand)
+	}
+	return Cluster{Model: cluster, Controller: controller, Identity: id}, nil
+}
+
+// Clusters returns the clusters being watched in the order of their contexts
+func (v *Viewer) Clusters() []Cluster {
+	return v.clusters
+}
+
+// Controllers returns the controllers watching each cluster
+func (v *Viewer) Controllers() []*client.Controller {
+	controllers := make([]*client.Controller, 0, len(v.clusters))
+	for _, c := range v.clusters {
+		controllers = append(controllers, c.Controller)
+	}
+	return controllers
+}
+
+// WaitForSync waits for the initial list of nodes and pods of every cluster, it returns false if the context is done
+// or the viewer is stopped first
+func (v *Viewer) WaitForSync(ctx context.Context) bool {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-v.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	for _, c := range v.clusters {
+		if !c.Controller.WaitForSync(ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+// Snapshot returns the stats, nodes and pods of the clusters in the same format as the API served by --serve
+func (v *Viewer) Snapshot() server.Snapshot {
+	return server.NewSnapshot(v.models, time.Now())
+}
+
+// Subscribe returns a channel that receives a snapshot each time the clusters change, checking for changes once per
+// interval. A subscriber that falls behind only receives the latest snapshot. The channel is closed when the returned
+// function is called or the viewer is stopped, it's already closed if the viewer was stopped before subscribing.
+func (v *Viewer) Subscribe() (<-chan server.Snapshot, func()) {
+	ch := make(chan server.Snapshot, 1)
+	// Stop may already be waiting on the subscriptions, which can't be added to once it is
+	if v.ctx.Err() != nil {
+		close(ch)
+		return ch, func() {}
+	}
+	ctx, cancel := context.WithCancel(v.ctx)
+	v.wg.Add(1)
+	go func() {
+		defer v.wg.Done()
+		defer close(ch)
+		ticker := time.NewTicker(v.interval)
+		defer ticker.Stop()
+		var generation uint64
+		first := true
+		for {
+			if next := server.Generation(v.models); first || next != generation {
+				first, generation = false, next
+				snapshot := v.Snapshot()
+				// the unread snapshot is replaced so that a slow subscriber isn't handed stale clusters
+				select {
+				case <-ch:
+				default:
+				}
+				ch <- snapshot
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return ch, cancel
+}
+
+// Stop stops watching the clusters and closes the channels of every subscription
+func (v *Viewer) Stop() {
+	v.cancel()
+	v.wg.Wait()
+}
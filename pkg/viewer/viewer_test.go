@@ -0,0 +1,143 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package viewer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/awslabs/eks-node-viewer/pkg/model"
+	"github.com/awslabs/eks-node-viewer/pkg/server"
+)
+
+// testViewer returns a viewer of the clusters that isn't watching anything, so that the clusters only change when the
+// test changes them
+func testViewer(clusters ...*model.Cluster) *Viewer {
+	v := &Viewer{models: clusters, interval: time.Millisecond}
+	v.ctx, v.cancel = context.WithCancel(context.Background())
+	return v
+}
+
+func addPod(cluster *model.Cluster, name string) {
+	cluster.AddPod(model.NewPod(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}))
+}
+
+func receive(t *testing.T, ch <-chan server.Snapshot) server.Snapshot {
+	t.Helper()
+	select {
+	case s, ok := <-ch:
+		if !ok {
+			t.Fatalf("expected a snapshot, the channel was closed")
+		}
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a snapshot")
+	}
+	return server.Snapshot{}
+}
+
+func expectNone(t *testing.T, ch <-chan server.Snapshot) {
+	t.Helper()
+	select {
+	case s, ok := <-ch:
+		if ok {
+			t.Errorf("expected no snapshot while the cluster is unchanged, got one with %d pods", len(s.Pods))
+		}
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func expectClosed(t *testing.T, ch <-chan server.Snapshot) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("expected the channel to be closed")
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	cluster := model.NewCluster()
+	v := testViewer(cluster)
+	defer v.Stop()
+
+	ch, cancel := v.Subscribe()
+	defer cancel()
+	if got := len(receive(t, ch).Pods); got != 0 {
+		t.Errorf("expected an initial snapshot with 0 pods, got %d", got)
+	}
+	expectNone(t, ch)
+
+	addPod(cluster, "mypod")
+	if got := len(receive(t, ch).Pods); got != 1 {
+		t.Errorf("expected a snapshot with 1 pod, got %d", got)
+	}
+	expectNone(t, ch)
+}
+
+func TestSubscribeSlowSubscriber(t *testing.T) {
+	cluster := model.NewCluster()
+	v := testViewer(cluster)
+	defer v.Stop()
+
+	ch, cancel := v.Subscribe()
+	defer cancel()
+	// nothing is read while the cluster changes, each change is given many intervals to be picked up
+	for i := 0; i < 3; i++ {
+		addPod(cluster, fmt.Sprintf("pod-%d", i))
+		time.Sleep(100 * time.Millisecond)
+	}
+	if got := len(receive(t, ch).Pods); got != 3 {
+		t.Errorf("expected only the latest snapshot with 3 pods, got %d", got)
+	}
+	expectNone(t, ch)
+}
+
+func TestSubscribeCancel(t *testing.T) {
+	v := testViewer(model.NewCluster())
+	defer v.Stop()
+
+	ch, cancel := v.Subscribe()
+	cancel()
+	expectClosed(t, ch)
+}
+
+func TestStopClosesSubscriptions(t *testing.T) {
+	v := testViewer(model.NewCluster())
+	ch1, cancel1 := v.Subscribe()
+	defer cancel1()
+	ch2, cancel2 := v.Subscribe()
+	defer cancel2()
+
+	v.Stop()
+	expectClosed(t, ch1)
+	expectClosed(t, ch2)
+
+	// subscribing once the viewer is stopped doesn't start anything
+	ch3, cancel3 := v.Subscribe()
+	defer cancel3()
+	expectClosed(t, ch3)
+}